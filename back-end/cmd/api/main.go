@@ -1,43 +1,146 @@
 package main
 
 import (
-	"log"
+	"context"
+	"log/slog"
 	"mowesport/internal/config"
 	"mowesport/internal/database"
+	"mowesport/internal/logging"
 	"mowesport/internal/server"
+	"mowesport/internal/services"
+	"os"
 
 	"github.com/joho/godotenv"
 )
 
 func main() {
 	// Load environment variables
-	if err := godotenv.Load(); err != nil {
-		log.Println("Warning: .env file not found, using environment variables")
-	}
+	envErr := godotenv.Load()
 
 	// Load configuration
 	cfg := config.LoadConfig()
+	logging.Setup(cfg.LogLevel)
 
+	if envErr != nil {
+		slog.Warn(".env file not found, using environment variables")
+	}
 	if cfg.DatabaseURL == "" {
-		log.Fatal("DATABASE_URL is required")
+		fatal("DATABASE_URL is required", nil)
 	}
 
 	// Initialize database
 	db, err := database.NewDatabase()
 	if err != nil {
-		log.Fatal("Database initialization failed:", err)
+		fatal("database initialization failed", err)
 	}
 	defer db.Close()
 
 	if err := db.TestConnection(); err != nil {
-		log.Fatal("Database connection test failed:", err)
+		fatal("database connection test failed", err)
+	}
+
+	// Email outbox worker, on its own connection so delivery never shares the request connection
+	if cfg.EmailOutbox.WorkerEnabled {
+		workerDB, err := database.NewDatabase()
+		if err != nil {
+			fatal("email outbox database initialization failed", err)
+		}
+		defer workerDB.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go services.NewEmailOutboxService(workerDB, cfg).Run(ctx)
+		slog.Info("email outbox worker started", "poll_interval", cfg.EmailOutbox.PollInterval.String())
+	}
+
+	// Audit retention purge, also on its own connection
+	if cfg.Security.AuditLogging.Enabled && cfg.Security.AuditLogging.RetentionDays > 0 {
+		auditDB, err := database.NewDatabase()
+		if err != nil {
+			fatal("audit retention database initialization failed", err)
+		}
+		defer auditDB.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go services.NewSecurityAuditService(auditDB).RunRetentionPurge(ctx, cfg.Security.AuditLogging.RetentionDays, cfg.Security.AuditLogging.PurgeInterval)
+		slog.Info("audit retention purge started", "retention_days", cfg.Security.AuditLogging.RetentionDays)
+	}
+
+	// Security alert worker, evaluating alert rules against the audit trail
+	if cfg.Security.AuditLogging.EnableRealTimeAlerts {
+		alertDB, err := database.NewDatabase()
+		if err != nil {
+			fatal("security alert database initialization failed", err)
+		}
+		defer alertDB.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go services.NewSecurityAlertService(alertDB, cfg).Run(ctx)
+		slog.Info("security alert worker started", "poll_interval", cfg.Security.AuditLogging.Alerts.PollInterval.String())
+	}
+
+	// Account status scheduler, applying scheduled reactivations
+	if cfg.AccountStatus.SchedulerEnabled {
+		statusDB, err := database.NewDatabase()
+		if err != nil {
+			fatal("account status scheduler database initialization failed", err)
+		}
+		defer statusDB.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go services.NewAccountStatusService(statusDB, cfg).Run(ctx)
+		slog.Info("account status scheduler started", "interval", cfg.AccountStatus.SchedulerInterval.String())
+	}
+
+	// Data deletion worker, anonymizing approved requests once their grace period ends
+	if cfg.Privacy.DeletionWorkerEnabled {
+		privacyDB, err := database.NewDatabase()
+		if err != nil {
+			fatal("data deletion worker database initialization failed", err)
+		}
+		defer privacyDB.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go services.NewPersonalDataService(privacyDB, cfg).Run(ctx)
+		slog.Info("data deletion worker started", "interval", cfg.Privacy.DeletionWorkerInterval.String(), "grace_period", cfg.Privacy.DeletionGracePeriod.String())
+	}
+
+	// Guardian notifications, emailing match reminders and schedule changes for minor players
+	if cfg.Guardian.NotificationsEnabled {
+		guardianDB, err := database.NewDatabase()
+		if err != nil {
+			fatal("guardian notification worker database initialization failed", err)
+		}
+		defer guardianDB.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go services.NewGuardianService(guardianDB, cfg).Run(ctx)
+		slog.Info("guardian notification worker started", "interval", cfg.Guardian.NotificationInterval.String(), "reminder_lead", cfg.Guardian.MatchReminderLead.String())
 	}
 
 	// Initialize server with configuration
-	srv := server.NewServer(db, cfg)
+	srv, err := server.NewServer(db, cfg)
+	if err != nil {
+		fatal("server initialization failed", err)
+	}
 
-	log.Printf("Starting server on port %s", cfg.ServerPort)
+	slog.Info("starting server", "port", cfg.ServerPort, "environment", cfg.Environment)
 	if err := srv.Start(":" + cfg.ServerPort); err != nil {
-		log.Fatal("Server failed to start:", err)
+		fatal("server failed to start", err)
+	}
+}
+
+// fatal logs why the API cannot start and exits
+func fatal(message string, err error) {
+	if err != nil {
+		slog.Error(message, "error", err)
+	} else {
+		slog.Error(message)
 	}
+	os.Exit(1)
 }
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"mowesport/internal/config"
+	"mowesport/internal/services"
+)
+
+// Renders email templates with sample data without sending anything.
+// Set EMAIL_TEMPLATES_DIR to preview edited copies on disk.
+//
+//	go run ./cmd/email-preview                               # render every template in every locale
+//	go run ./cmd/email-preview -template invitation -locale en -format html > invitation.html
+func main() {
+	name := flag.String("template", "", "template to render (all templates when empty)")
+	locale := flag.String("locale", services.DefaultEmailLocale, "locale to render")
+	format := flag.String("format", "text", "output format: html or text")
+	flag.Parse()
+
+	emailService := services.NewEmailService(config.LoadConfig(), nil)
+
+	if *name != "" {
+		rendered, err := emailService.RenderTemplate(*name, *locale, services.EmailTemplateSample(*name))
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		if *format == "html" {
+			fmt.Print(rendered.HTML)
+			return
+		}
+		fmt.Printf("Subject: %s\nLocale: %s\n\n%s", rendered.Subject, rendered.Locale, rendered.Text)
+		return
+	}
+
+	names, err := emailService.TemplateNames()
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	failed := false
+	for _, templateName := range names {
+		for _, templateLocale := range services.SupportedEmailLocales {
+			rendered, err := emailService.RenderTemplate(templateName, templateLocale, services.EmailTemplateSample(templateName))
+			if err != nil {
+				failed = true
+				fmt.Printf("❌ %s (%s): %v\n", templateName, templateLocale, err)
+				continue
+			}
+			if rendered.Locale != templateLocale {
+				failed = true
+				fmt.Printf("❌ %s (%s): fell back to %s\n", templateName, templateLocale, rendered.Locale)
+				continue
+			}
+			fmt.Printf("✅ %s (%s): %s\n", templateName, templateLocale, rendered.Subject)
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
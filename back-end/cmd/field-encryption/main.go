@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"mowesport/internal/config"
+	"mowesport/internal/database"
+	"mowesport/internal/services"
+)
+
+// Manages the field encryption keys and converts stored values.
+//
+//	go run ./cmd/field-encryption generate-key   # print a new master key
+//	go run ./cmd/field-encryption status         # keys and rows waiting for re-encryption
+//	go run ./cmd/field-encryption reencrypt      # encrypt plaintext and move values to the active data key
+//	go run ./cmd/field-encryption rotate         # new data key; run reencrypt afterwards
+//	go run ./cmd/field-encryption rewrap         # after a master key change, with the old key as previous key
+//	go run ./cmd/field-encryption decrypt        # write plaintext back before rolling back migration 023
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: field-encryption generate-key|status|reencrypt|rotate|rewrap|decrypt")
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	command := flag.Arg(0)
+
+	if command == "generate-key" {
+		key, err := services.GenerateMasterKey()
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		fmt.Println(key)
+		return
+	}
+
+	cfg := config.LoadConfig()
+	db, err := database.NewDatabase()
+	if err != nil {
+		log.Fatalf("❌ Database initialization failed: %v", err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Hour)
+	defer cancel()
+
+	encryption := services.NewFieldEncryptionService(db, cfg)
+
+	switch command {
+	case "status":
+		status, err := encryption.Status(ctx)
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		fmt.Printf("Current master key: %s\n", status.CurrentMasterKeyID)
+		for _, key := range status.Keys {
+			marker := ""
+			if key.Status == "active" && key.Purpose == services.EncryptionKeyPurposeData {
+				marker = " (encrypting)"
+			}
+			fmt.Printf("  key %d %-11s %-7s master %s created %s%s\n",
+				key.KeyID, key.Purpose, key.Status, key.MasterKeyID, key.CreatedAt.Format(time.RFC3339), marker)
+		}
+		fmt.Printf("Keys to rewrap: %d\n", status.PendingRewrap)
+		fmt.Printf("Players to re-encrypt: %d\n", status.PendingPlayers)
+		fmt.Printf("2FA secrets to re-encrypt: %d\n", status.PendingTwoFactor)
+
+	case "reencrypt":
+		result, err := encryption.Reencrypt(ctx)
+		if err != nil {
+			log.Fatalf("❌ %v (%d players, %d 2FA secrets done)", err, result.Players, result.TwoFactorSecrets)
+		}
+		fmt.Printf("✅ Re-encrypted %d players and %d 2FA secrets\n", result.Players, result.TwoFactorSecrets)
+
+	case "rotate":
+		keyID, err := encryption.RotateDataKey(ctx, nil)
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		fmt.Printf("✅ Data key %d is now active; run reencrypt to move existing values to it\n", keyID)
+
+	case "rewrap":
+		count, err := encryption.RewrapKeys(ctx, nil)
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		fmt.Printf("✅ Rewrapped %d keys; previous master keys can now be removed\n", count)
+
+	case "decrypt":
+		result, err := encryption.DecryptAll(ctx)
+		if err != nil {
+			log.Fatalf("❌ %v (%d players, %d 2FA secrets done)", err, result.Players, result.TwoFactorSecrets)
+		}
+		fmt.Printf("✅ Decrypted %d players and %d 2FA secrets\n", result.Players, result.TwoFactorSecrets)
+
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
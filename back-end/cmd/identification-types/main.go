@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"mowesport/internal/config"
+	"mowesport/internal/database"
+	"mowesport/internal/services"
+)
+
+// Records the document type of identifications stored before migration 025.
+//
+//	go run ./cmd/identification-types status     # identifications still without a type
+//	go run ./cmd/identification-types backfill   # infer CC/TI/RC and list the rest for review
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: identification-types status|backfill")
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg := config.LoadConfig()
+	db, err := database.NewDatabase()
+	if err != nil {
+		log.Fatalf("❌ Database initialization failed: %v", err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+
+	identificationTypes := services.NewIdentificationTypeService(db, cfg)
+
+	switch flag.Arg(0) {
+	case "status":
+		status, err := identificationTypes.Status(ctx)
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		fmt.Printf("Users without identification type: %d\n", status.PendingUsers)
+		fmt.Printf("Players without identification type: %d\n", status.PendingPlayers)
+
+	case "backfill":
+		result, err := identificationTypes.Backfill(ctx)
+		if err != nil {
+			log.Fatalf("❌ %v (%d users, %d players done)", err, result.Users, result.Players)
+		}
+		fmt.Printf("✅ Typed %d users and %d players\n", result.Users, result.Players)
+		if len(result.ReviewUsers)+len(result.ReviewPlayers) == 0 {
+			return
+		}
+		fmt.Println("⚠️  Could not infer the document type of:")
+		for _, userID := range result.ReviewUsers {
+			fmt.Printf("  user %s\n", userID)
+		}
+		for _, playerID := range result.ReviewPlayers {
+			fmt.Printf("  player %s\n", playerID)
+		}
+
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
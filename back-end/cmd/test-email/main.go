@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"os"
+	"strings"
+
+	"mowesport/internal/config"
+	"mowesport/internal/services"
+)
+
+// Sends a message through EmailService to an in-process SMTP stand-in and checks
+// the MIME structure that actually went over the wire.
+func main() {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		log.Fatalf("Failed to start SMTP stand-in: %v", err)
+	}
+	defer listener.Close()
+
+	received := make(chan []byte, 1)
+	go serveSMTP(listener, received)
+
+	cfg := config.LoadConfig()
+	cfg.Environment = "test"
+	cfg.SMTPHost = "127.0.0.1"
+	cfg.SMTPPort = fmt.Sprint(listener.Addr().(*net.TCPAddr).Port)
+	cfg.SMTPTLSMode = config.SMTPTLSModeNone
+	cfg.SMTPUser = ""
+	cfg.FromEmail = "noreply@mowesport.com"
+	cfg.FromName = "Mowe Sport Ñandú"
+	cfg.ReplyToEmail = "soporte@mowesport.com"
+
+	subject := "Bienvenido a Mowe Sport – invitación de árbitro"
+	attachment := []byte("Reglamento oficial del torneo\nversión 2\n")
+	emailService := services.NewEmailService(cfg, nil)
+
+	fmt.Println("📧 Testing MIME email delivery")
+	fmt.Println("=" + strings.Repeat("=", 50))
+
+	messageID, err := emailService.DeliverNow(context.Background(), services.EmailData{
+		To:      "jugador@example.com",
+		Subject: subject,
+		Body:    `<html><body><h2>Hola Ana,</h2><p>Acepta tu invitación <a href="https://mowesport.com/accept">aquí</a>.</p></body></html>`,
+		IsHTML:  true,
+		Attachments: []services.EmailAttachment{
+			{Filename: "reglamento.txt", Content: attachment, MimeType: "text/plain"},
+		},
+	})
+	if err != nil {
+		log.Fatalf("❌ Delivery failed: %v", err)
+	}
+
+	raw := <-received
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		log.Fatalf("❌ Message is not valid RFC 5322: %v", err)
+	}
+
+	failures := 0
+	check := func(name string, ok bool, got interface{}) {
+		if ok {
+			fmt.Printf("✅ %s\n", name)
+			return
+		}
+		failures++
+		fmt.Printf("❌ %s (got %v)\n", name, got)
+	}
+
+	decoder := new(mime.WordDecoder)
+	from, _ := mail.ParseAddress(msg.Header.Get("From"))
+	check("From header carries sender name and address",
+		from != nil && from.Address == cfg.FromEmail && from.Name == cfg.FromName, msg.Header.Get("From"))
+	check("Reply-To header set from configuration",
+		strings.Contains(msg.Header.Get("Reply-To"), cfg.ReplyToEmail), msg.Header.Get("Reply-To"))
+	decodedSubject, _ := decoder.DecodeHeader(msg.Header.Get("Subject"))
+	check("Subject is RFC 2047 encoded and round-trips", decodedSubject == subject && isASCII(msg.Header.Get("Subject")), msg.Header.Get("Subject"))
+	_, dateErr := msg.Header.Date()
+	check("Date header parses", dateErr == nil, dateErr)
+	check("Message-ID header matches provider ID", msg.Header.Get("Message-ID") == messageID, msg.Header.Get("Message-ID"))
+
+	mediaType, params, _ := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	check("Top-level part is multipart/mixed", mediaType == "multipart/mixed", mediaType)
+
+	var textBody, htmlBody, attachmentName string
+	var attachmentBody []byte
+	walkParts(multipart.NewReader(msg.Body, params["boundary"]), func(part *multipart.Part, contentType string, body []byte) {
+		switch {
+		case part.FileName() != "":
+			attachmentName = part.FileName()
+			attachmentBody = body
+		case strings.HasPrefix(contentType, "text/plain"):
+			textBody = string(body)
+		case strings.HasPrefix(contentType, "text/html"):
+			htmlBody = string(body)
+		}
+	})
+
+	check("Plain-text alternative derived from HTML",
+		strings.Contains(textBody, "Hola Ana,") && strings.Contains(textBody, "aquí: https://mowesport.com/accept") && !strings.Contains(textBody, "<"), textBody)
+	check("HTML alternative preserved", strings.Contains(htmlBody, "<h2>Hola Ana,</h2>"), htmlBody)
+	check("Attachment filename preserved", attachmentName == "reglamento.txt", attachmentName)
+	check("Attachment content round-trips", bytes.Equal(attachmentBody, attachment), string(attachmentBody))
+
+	// Development email is captured in the dev mailbox instead of being sent
+	mailboxDir, err := os.MkdirTemp("", "mowesport-mailbox")
+	if err != nil {
+		log.Fatalf("❌ Failed to create mailbox directory: %v", err)
+	}
+	defer os.RemoveAll(mailboxDir)
+
+	cfg.Environment = "development"
+	cfg.DevMailboxDir = mailboxDir
+	devMessageID, err := services.NewEmailService(cfg, nil).DeliverNow(context.Background(), services.EmailData{
+		To:          "arbitro@example.com",
+		Subject:     subject,
+		Body:        `<p>Restablece tu contraseña <a href="https://mowesport.com/reset-password?token=abc&amp;x=1">aquí</a>.</p>`,
+		IsHTML:      true,
+		Attachments: []services.EmailAttachment{{Filename: "reglamento.txt", Content: attachment, MimeType: "text/plain"}},
+	})
+	check("Development delivery captured in mailbox", err == nil, err)
+
+	captured, err := services.NewDevMailbox(mailboxDir).Latest("arbitro@example.com")
+	check("Latest message found by recipient", err == nil && captured.MessageID == devMessageID, err)
+	if captured != nil {
+		check("Captured subject decoded", captured.Subject == subject, captured.Subject)
+		check("Captured links extracted",
+			len(captured.Links) > 0 && captured.Links[0] == "https://mowesport.com/reset-password?token=abc&x=1", captured.Links)
+		check("Captured attachment listed", captured.AttachmentCount == 1 && captured.Attachments[0].Filename == "reglamento.txt", captured.Attachments)
+	}
+
+	fmt.Println()
+	if failures > 0 {
+		fmt.Printf("❌ %d check(s) failed\n", failures)
+		os.Exit(1)
+	}
+	fmt.Println("✅ All email checks passed!")
+}
+
+// walkParts visits every leaf part, descending into nested multiparts and decoding transfer encodings
+func walkParts(reader *multipart.Reader, visit func(part *multipart.Part, contentType string, body []byte)) {
+	for {
+		part, err := reader.NextRawPart()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			log.Fatalf("❌ Invalid multipart body: %v", err)
+		}
+
+		contentType := part.Header.Get("Content-Type")
+		mediaType, params, _ := mime.ParseMediaType(contentType)
+		if strings.HasPrefix(mediaType, "multipart/") {
+			walkParts(multipart.NewReader(part, params["boundary"]), visit)
+			continue
+		}
+
+		var body io.Reader = part
+		switch strings.ToLower(part.Header.Get("Content-Transfer-Encoding")) {
+		case "quoted-printable":
+			body = quotedprintable.NewReader(part)
+		case "base64":
+			body = base64.NewDecoder(base64.StdEncoding, part)
+		}
+		content, err := io.ReadAll(body)
+		if err != nil {
+			log.Fatalf("❌ Failed to decode %s part: %v", mediaType, err)
+		}
+		visit(part, contentType, content)
+	}
+}
+
+// serveSMTP is a minimal SMTP stand-in that accepts one message and hands over its DATA
+func serveSMTP(listener net.Listener, received chan<- []byte) {
+	conn, err := listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	reader := textproto.NewReader(bufio.NewReader(conn))
+	reply := func(line string) { fmt.Fprintf(conn, "%s\r\n", line) }
+
+	reply("220 localhost ESMTP stand-in")
+	for {
+		line, err := reader.ReadLine()
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch command {
+		case "EHLO", "HELO":
+			reply("250-localhost")
+			reply("250 8BITMIME")
+		case "MAIL", "RCPT", "RSET", "NOOP":
+			reply("250 OK")
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			data, err := reader.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			received <- data
+			reply("250 OK queued")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+func isASCII(value string) bool {
+	for _, r := range value {
+		if r > 127 {
+			return false
+		}
+	}
+	return true
+}
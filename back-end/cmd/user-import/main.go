@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"mowesport/internal/config"
+	"mowesport/internal/database"
+	"mowesport/internal/models"
+	"mowesport/internal/services"
+
+	"github.com/google/uuid"
+)
+
+// Imports owners, referees, coaches and players from a CSV or XLSX file, acting as an
+// existing admin so the delegation matrix applies exactly as for POST /api/users/import.
+// Runs as a dry run unless -commit is given.
+//
+//	go run ./cmd/user-import -file liga.xlsx -city <uuid> -sport <uuid> -as admin@mowesport.com
+//	go run ./cmd/user-import -file liga.xlsx -city <uuid> -sport <uuid> -as admin@mowesport.com -commit -mode partial
+//	go run ./cmd/user-import -file liga.xlsx -resume <import uuid> -as admin@mowesport.com -commit
+func main() {
+	filePath := flag.String("file", "", "CSV or XLSX file to import")
+	cityID := flag.String("city", "", "city ID for every row")
+	sportID := flag.String("sport", "", "sport ID for every row")
+	tournamentID := flag.String("tournament", "", "tournament ID (optional, narrows the delegation scope)")
+	role := flag.String("role", "", "role for rows without a role column")
+	as := flag.String("as", "", "email of the admin performing the import")
+	commit := flag.Bool("commit", false, "commit the import (dry run otherwise)")
+	mode := flag.String("mode", models.UserImportModeAtomic, "commit mode: atomic or partial")
+	resume := flag.String("resume", "", "ID of a partial import to resume")
+	asJSON := flag.Bool("json", false, "print the full report as JSON")
+	flag.Parse()
+
+	if *filePath == "" || *as == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	data, err := os.ReadFile(*filePath)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	req := models.UserImportRequest{
+		FileName: filepath.Base(*filePath),
+		Role:     strings.ToLower(*role),
+		DryRun:   !*commit,
+		Mode:     *mode,
+	}
+	if *resume != "" {
+		id := mustParseUUID("resume", *resume)
+		req.ResumeImportID = &id
+	} else {
+		req.CityID = mustParseUUID("city", *cityID)
+		req.SportID = mustParseUUID("sport", *sportID)
+		if *tournamentID != "" {
+			id := mustParseUUID("tournament", *tournamentID)
+			req.TournamentID = &id
+		}
+	}
+
+	cfg := config.LoadConfig()
+	db, err := database.NewDatabase()
+	if err != nil {
+		log.Fatalf("❌ Database initialization failed: %v", err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	var requesterID uuid.UUID
+	err = db.GetConnection().QueryRow(ctx,
+		"SELECT user_id FROM user_profiles WHERE lower(email) = lower($1) AND is_active = true",
+		*as,
+	).Scan(&requesterID)
+	if err != nil {
+		log.Fatalf("❌ No active user with email %s", *as)
+	}
+
+	report, err := services.NewUserImportService(db, cfg).Import(ctx, &req, data, requesterID)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+	} else {
+		printReport(report)
+	}
+
+	if report.InvalidRows > 0 || report.FailedRows > 0 {
+		os.Exit(1)
+	}
+}
+
+func mustParseUUID(flagName, value string) uuid.UUID {
+	id, err := uuid.Parse(value)
+	if err != nil {
+		log.Fatalf("❌ -%s must be a UUID", flagName)
+	}
+	return id
+}
+
+func printReport(report *models.UserImportReport) {
+	for _, row := range report.Rows {
+		icon := "✅"
+		switch row.Status {
+		case models.UserImportRowInvalid, models.UserImportRowFailed:
+			icon = "❌"
+		case models.UserImportRowSkipped:
+			icon = "⏭️"
+		}
+		fmt.Printf("%s row %d %s (%s): %s\n", icon, row.RowNumber, row.Email, row.Role, row.Status)
+		for _, rowErr := range row.Errors {
+			fmt.Printf("     - %s\n", rowErr)
+		}
+	}
+
+	fmt.Println(strings.Repeat("=", 51))
+	if report.DryRun {
+		fmt.Printf("Dry run: %d valid, %d invalid of %d rows (nothing committed)\n", report.ValidRows, report.InvalidRows, report.TotalRows)
+		return
+	}
+	if report.ImportID == nil {
+		fmt.Printf("Nothing committed: %d invalid, %d failed of %d rows\n", report.InvalidRows, report.FailedRows, report.TotalRows)
+		return
+	}
+	fmt.Printf("Import %s (%s): %d imported, %d skipped, %d invalid, %d failed of %d rows\n",
+		report.ImportID, report.Status, report.ImportedRows, report.SkippedRows, report.InvalidRows, report.FailedRows, report.TotalRows)
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -11,6 +12,10 @@ type Config struct {
 	// Server configuration
 	ServerPort string
 
+	// CIDR ranges of reverse proxies whose X-Forwarded-For is trusted for the client IP.
+	// Empty uses the connection's address, ignoring client-supplied headers.
+	TrustedProxies []string
+
 	// Database configuration
 	DatabaseURL string
 
@@ -24,23 +29,98 @@ type Config struct {
 	SMTPPort     string
 	SMTPUser     string
 	SMTPPassword string
+	SMTPTLSMode  string
+	SMTPTimeout  time.Duration
 	FromEmail    string
 	FromName     string
+	ReplyToEmail string
+
+	// Directory with email template overrides; empty uses the embedded templates
+	EmailTemplatesDir string
+
+	// Directory where development email is captured instead of being sent
+	DevMailboxDir string
+
+	// Email outbox delivery
+	EmailOutbox EmailOutboxConfig
+
+	// Scheduled account status changes
+	AccountStatus AccountStatusConfig
+
+	// Personal data requests (Ley 1581 / Habeas Data)
+	Privacy PrivacyConfig
+
+	// Envelope encryption of sensitive fields
+	Encryption EncryptionConfig
+
+	// Guardians of minor players
+	Guardian GuardianConfig
 
 	// Application configuration
 	Environment  string
 	FrontendURL  string
 	SupportEmail string
 	Debug        bool
+	LogLevel     string // debug, info, warn or error
 
 	// Security configuration
 	Security SecurityConfig
 }
 
+// SMTP transport security modes
+const (
+	SMTPTLSModeStartTLS = "starttls" // plain connection upgraded with STARTTLS (port 587)
+	SMTPTLSModeImplicit = "tls"      // TLS from the first byte (port 465)
+	SMTPTLSModeNone     = "none"     // unencrypted, only for local relays and test servers
+)
+
+// EmailOutboxConfig controls the background email delivery worker
+type EmailOutboxConfig struct {
+	WorkerEnabled bool
+	PollInterval  time.Duration
+	BatchSize     int
+	MaxAttempts   int
+	BaseBackoff   time.Duration
+	MaxBackoff    time.Duration
+}
+
+// AccountStatusConfig controls the worker applying scheduled account status changes
+type AccountStatusConfig struct {
+	SchedulerEnabled  bool
+	SchedulerInterval time.Duration
+}
+
+// PrivacyConfig controls how approved data deletion requests are applied
+type PrivacyConfig struct {
+	DeletionGracePeriod    time.Duration // Between approval and anonymization; the user can cancel meanwhile
+	DeletionWorkerEnabled  bool
+	DeletionWorkerInterval time.Duration
+}
+
+// EncryptionConfig holds the master keys wrapping the field encryption data keys. The
+// master key comes from FIELD_ENCRYPTION_MASTER_KEY or, when unset, from the first line of
+// FIELD_ENCRYPTION_MASTER_KEY_FILE. Previous master keys (further lines of the file, or
+// FIELD_ENCRYPTION_PREVIOUS_MASTER_KEYS) only unwrap data keys until they are rewrapped.
+type EncryptionConfig struct {
+	MasterKey          string // Base64, 32 bytes
+	MasterKeyFile      string
+	PreviousMasterKeys []string
+	ReencryptBatchSize int
+}
+
+// GuardianConfig controls guardian consent and the match notifications sent to guardians
+type GuardianConfig struct {
+	ConsentDocumentVersion string // Version guardians must accept; bump it when the document changes
+	NotificationsEnabled   bool
+	NotificationInterval   time.Duration
+	MatchReminderLead      time.Duration // How long before kickoff guardians are reminded of a match
+}
+
 // LoadConfig loads configuration from environment variables
 func LoadConfig() *Config {
 	config := &Config{
 		ServerPort:           getEnv("SERVER_PORT", "8080"),
+		TrustedProxies:       getListEnv("TRUSTED_PROXIES", nil),
 		DatabaseURL:          getEnv("DATABASE_URL", ""),
 		JWTSecret:            getEnv("JWT_SECRET", "your-default-secret-key-change-in-production"),
 		JWTAccessExpiration:  getDurationEnv("JWT_ACCESS_EXPIRATION", time.Hour),
@@ -51,18 +131,108 @@ func LoadConfig() *Config {
 		SMTPPort:     getEnv("SMTP_PORT", "587"),
 		SMTPUser:     getEnv("SMTP_USER", ""),
 		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+		SMTPTLSMode:  getEnv("SMTP_TLS_MODE", SMTPTLSModeStartTLS),
+		SMTPTimeout:  getDurationEnv("SMTP_TIMEOUT", 30*time.Second),
 		FromEmail:    getEnv("FROM_EMAIL", "noreply@mowesport.com"),
 		FromName:     getEnv("FROM_NAME", "Mowe Sport"),
+		ReplyToEmail: getEnv("REPLY_TO_EMAIL", ""),
+
+		EmailTemplatesDir: getEnv("EMAIL_TEMPLATES_DIR", ""),
+		DevMailboxDir:     getEnv("DEV_MAILBOX_DIR", "tmp/mailbox"),
+
+		EmailOutbox: EmailOutboxConfig{
+			WorkerEnabled: getBoolEnv("EMAIL_OUTBOX_WORKER_ENABLED", true),
+			PollInterval:  getDurationEnv("EMAIL_OUTBOX_POLL_INTERVAL", 5*time.Second),
+			BatchSize:     getIntEnv("EMAIL_OUTBOX_BATCH_SIZE", 20),
+			MaxAttempts:   getIntEnv("EMAIL_OUTBOX_MAX_ATTEMPTS", 8),
+			BaseBackoff:   getDurationEnv("EMAIL_OUTBOX_BASE_BACKOFF", 30*time.Second),
+			MaxBackoff:    getDurationEnv("EMAIL_OUTBOX_MAX_BACKOFF", 2*time.Hour),
+		},
+
+		AccountStatus: AccountStatusConfig{
+			SchedulerEnabled:  getBoolEnv("ACCOUNT_STATUS_SCHEDULER_ENABLED", true),
+			SchedulerInterval: getDurationEnv("ACCOUNT_STATUS_SCHEDULER_INTERVAL", time.Minute),
+		},
+
+		Privacy: PrivacyConfig{
+			DeletionGracePeriod:    getDurationEnv("DATA_DELETION_GRACE_PERIOD", 30*24*time.Hour),
+			DeletionWorkerEnabled:  getBoolEnv("DATA_DELETION_WORKER_ENABLED", true),
+			DeletionWorkerInterval: getDurationEnv("DATA_DELETION_WORKER_INTERVAL", time.Hour),
+		},
+
+		Encryption: EncryptionConfig{
+			MasterKey:          getEnv("FIELD_ENCRYPTION_MASTER_KEY", ""),
+			MasterKeyFile:      getEnv("FIELD_ENCRYPTION_MASTER_KEY_FILE", ""),
+			PreviousMasterKeys: getListEnv("FIELD_ENCRYPTION_PREVIOUS_MASTER_KEYS", nil),
+			ReencryptBatchSize: getIntEnv("FIELD_ENCRYPTION_REENCRYPT_BATCH_SIZE", 200),
+		},
+
+		Guardian: GuardianConfig{
+			ConsentDocumentVersion: getEnv("GUARDIAN_CONSENT_DOCUMENT_VERSION", "2026-01"),
+			NotificationsEnabled:   getBoolEnv("GUARDIAN_NOTIFICATIONS_ENABLED", true),
+			NotificationInterval:   getDurationEnv("GUARDIAN_NOTIFICATION_INTERVAL", 15*time.Minute),
+			MatchReminderLead:      getDurationEnv("GUARDIAN_MATCH_REMINDER_LEAD", 48*time.Hour),
+		},
 
 		// Application configuration
 		Environment:  getEnv("ENVIRONMENT", "development"),
 		FrontendURL:  getEnv("FRONTEND_URL", "http://localhost:3000"),
 		SupportEmail: getEnv("SUPPORT_EMAIL", "support@mowesport.com"),
 		Debug:        getBoolEnv("DEBUG", true),
+		LogLevel:     getEnv("LOG_LEVEL", "info"),
 
 		Security: GetDefaultSecurityConfig(),
 	}
 
+	// Audit logging and retention can be tuned per deployment
+	config.Security.AuditLogging.Enabled = getBoolEnv("AUDIT_LOGGING_ENABLED", config.Security.AuditLogging.Enabled)
+	config.Security.AuditLogging.RetentionDays = getIntEnv("AUDIT_RETENTION_DAYS", config.Security.AuditLogging.RetentionDays)
+	config.Security.AuditLogging.PurgeInterval = getDurationEnv("AUDIT_PURGE_INTERVAL", config.Security.AuditLogging.PurgeInterval)
+
+	// Password policy
+	policy := &config.Security.PasswordPolicy
+	policy.MinLength = getIntEnv("PASSWORD_MIN_LENGTH", policy.MinLength)
+	policy.HistoryCount = getIntEnv("PASSWORD_HISTORY_COUNT", policy.HistoryCount)
+	policy.ExpirationDays = getIntEnv("PASSWORD_EXPIRATION_DAYS", policy.ExpirationDays)
+	policy.RejectBreached = getBoolEnv("PASSWORD_REJECT_BREACHED", policy.RejectBreached)
+
+	// Progressive account lockout
+	lockout := &config.Security.Lockout
+	lockout.MaxAttempts = getIntEnv("LOCKOUT_MAX_ATTEMPTS", lockout.MaxAttempts)
+	lockout.BaseDuration = getDurationEnv("LOCKOUT_BASE_DURATION", lockout.BaseDuration)
+	lockout.Multiplier = getFloatEnv("LOCKOUT_MULTIPLIER", lockout.Multiplier)
+	lockout.MaxDuration = getDurationEnv("LOCKOUT_MAX_DURATION", lockout.MaxDuration)
+
+	// Super admin impersonation
+	config.Security.Impersonation.Enabled = getBoolEnv("IMPERSONATION_ENABLED", config.Security.Impersonation.Enabled)
+	config.Security.Impersonation.TokenTTL = getDurationEnv("IMPERSONATION_TOKEN_TTL", config.Security.Impersonation.TokenTTL)
+
+	// Cross-account login attack detection
+	detection := &config.Security.SuspiciousActivityDetection
+	detection.LoginWindow = getDurationEnv("LOGIN_DETECTION_WINDOW", detection.LoginWindow)
+	detection.IPChallengeThreshold = getIntEnv("LOGIN_IP_CHALLENGE_THRESHOLD", detection.IPChallengeThreshold)
+	detection.IPBlockThreshold = getIntEnv("LOGIN_IP_BLOCK_THRESHOLD", detection.IPBlockThreshold)
+	detection.IPDistinctAccountsThreshold = getIntEnv("LOGIN_IP_DISTINCT_ACCOUNTS_THRESHOLD", detection.IPDistinctAccountsThreshold)
+	detection.SubnetChallengeThreshold = getIntEnv("LOGIN_SUBNET_CHALLENGE_THRESHOLD", detection.SubnetChallengeThreshold)
+	detection.SubnetBlockThreshold = getIntEnv("LOGIN_SUBNET_BLOCK_THRESHOLD", detection.SubnetBlockThreshold)
+	detection.GlobalChallengeThreshold = getIntEnv("LOGIN_GLOBAL_CHALLENGE_THRESHOLD", detection.GlobalChallengeThreshold)
+	detection.IPBlockDuration = getDurationEnv("LOGIN_IP_BLOCK_DURATION", detection.IPBlockDuration)
+
+	// Security alerting
+	alerts := &config.Security.AuditLogging.Alerts
+	config.Security.AuditLogging.EnableRealTimeAlerts = getBoolEnv("SECURITY_ALERTS_ENABLED", config.Security.AuditLogging.EnableRealTimeAlerts)
+	alerts.Channels = getListEnv("SECURITY_ALERT_CHANNELS", alerts.Channels)
+	alerts.EmailTo = getEnv("SECURITY_ALERT_EMAIL", config.SupportEmail)
+	alerts.WebhookURL = getEnv("SECURITY_ALERT_WEBHOOK_URL", "")
+	alerts.PollInterval = getDurationEnv("SECURITY_ALERT_POLL_INTERVAL", alerts.PollInterval)
+	alerts.Cooldown = getDurationEnv("SECURITY_ALERT_COOLDOWN", alerts.Cooldown)
+	for i := range alerts.Rules {
+		if alerts.Rules[i].Name == "failed_logins_per_account" {
+			alerts.Rules[i].Threshold = getIntEnv("SECURITY_ALERT_FAILED_LOGIN_THRESHOLD", alerts.Rules[i].Threshold)
+			alerts.Rules[i].Window = getDurationEnv("SECURITY_ALERT_FAILED_LOGIN_WINDOW", alerts.Rules[i].Window)
+		}
+	}
+
 	return config
 }
 
@@ -94,6 +264,22 @@ func getBoolEnv(key string, defaultValue bool) bool {
 	return defaultValue
 }
 
+// getListEnv gets a comma-separated list from environment variable with default value
+func getListEnv(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	list := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
 // getIntEnv gets integer from environment variable with default value
 func getIntEnv(key string, defaultValue int) int {
 	if value := os.Getenv(key); value != "" {
@@ -103,3 +289,13 @@ func getIntEnv(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+// getFloatEnv gets a float from environment variable with default value
+func getFloatEnv(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
@@ -12,6 +12,12 @@ type SecurityConfig struct {
 	// Password policy configuration
 	PasswordPolicy PasswordPolicyConfig `json:"password_policy"`
 
+	// Account lockout after failed logins
+	Lockout LockoutPolicyConfig `json:"lockout"`
+
+	// Super admin "view as user" sessions
+	Impersonation ImpersonationConfig `json:"impersonation"`
+
 	// Email validation configuration
 	EmailValidation EmailValidationConfig `json:"email_validation"`
 
@@ -51,7 +57,42 @@ type PasswordPolicyConfig struct {
 	RequireLowercase bool `json:"require_lowercase"`
 	RequireNumbers   bool `json:"require_numbers"`
 	RequireSpecial   bool `json:"require_special"`
-	ExpirationDays   int  `json:"expiration_days"`
+	ExpirationDays   int  `json:"expiration_days"` // 0 disables forced rotation
+	HistoryCount     int  `json:"history_count"`   // Previous passwords that cannot be reused
+	RejectBreached   bool `json:"reject_breached"` // Check the bundled common/breached password list
+}
+
+// LockoutPolicyConfig defines progressive account locking after failed logins.
+// Every MaxAttempts consecutive failures raise the lock level; each level multiplies
+// the previous lock duration by Multiplier, up to MaxDuration.
+type LockoutPolicyConfig struct {
+	MaxAttempts  int           `json:"max_attempts"`
+	BaseDuration time.Duration `json:"base_duration"`
+	Multiplier   float64       `json:"multiplier"`
+	MaxDuration  time.Duration `json:"max_duration"`
+}
+
+// LockDuration returns how long an account is locked after the given number of
+// consecutive failed logins, or zero when it stays unlocked
+func (p LockoutPolicyConfig) LockDuration(attempts int) time.Duration {
+	if p.MaxAttempts <= 0 || attempts < p.MaxAttempts {
+		return 0
+	}
+
+	duration := p.BaseDuration
+	for level := attempts/p.MaxAttempts - 1; level > 0 && duration < p.MaxDuration; level-- {
+		duration = time.Duration(float64(duration) * p.Multiplier)
+	}
+	if duration > p.MaxDuration {
+		duration = p.MaxDuration
+	}
+	return duration
+}
+
+// ImpersonationConfig defines super admin impersonation settings
+type ImpersonationConfig struct {
+	Enabled  bool          `json:"enabled"`
+	TokenTTL time.Duration `json:"token_ttl"` // Impersonation tokens cannot be refreshed
 }
 
 // EmailValidationConfig defines email validation settings
@@ -81,11 +122,42 @@ type IdentificationValidationConfig struct {
 
 // AuditLoggingConfig defines audit logging settings
 type AuditLoggingConfig struct {
-	Enabled                   bool   `json:"enabled"`
-	LogLevel                  string `json:"log_level"`
-	RetentionDays             int    `json:"retention_days"`
-	EnableRealTimeAlerts      bool   `json:"enable_real_time_alerts"`
-	CriticalEventNotification bool   `json:"critical_event_notification"`
+	Enabled                   bool          `json:"enabled"`
+	LogLevel                  string        `json:"log_level"`
+	RetentionDays             int           `json:"retention_days"`
+	PurgeInterval             time.Duration `json:"purge_interval"`
+	EnableRealTimeAlerts      bool          `json:"enable_real_time_alerts"`
+	CriticalEventNotification bool          `json:"critical_event_notification"`
+	Alerts                    AlertConfig   `json:"alerts"`
+}
+
+// Security alert channels; alerts are always stored for the in-app list
+const (
+	AlertChannelEmail   = "email"
+	AlertChannelWebhook = "webhook"
+)
+
+// AlertConfig defines how audit events are turned into alerts and delivered
+type AlertConfig struct {
+	Channels     []string          `json:"channels"`
+	EmailTo      string            `json:"email_to"`
+	WebhookURL   string            `json:"webhook_url"`
+	PollInterval time.Duration     `json:"poll_interval"`
+	Cooldown     time.Duration     `json:"cooldown"`
+	Rules        []AlertRuleConfig `json:"rules"`
+}
+
+// AlertRuleConfig raises an alert when Threshold matching events share the same
+// GroupBy value (user_id, ip_address or event_type) within Window
+type AlertRuleConfig struct {
+	Name        string        `json:"name"`
+	Title       string        `json:"title"`
+	EventTypes  []string      `json:"event_types"`  // empty matches every event type
+	MinSeverity string        `json:"min_severity"` // empty matches every severity
+	GroupBy     string        `json:"group_by"`
+	Threshold   int           `json:"threshold"`
+	Window      time.Duration `json:"window"`
+	Severity    string        `json:"severity"`
 }
 
 // SuspiciousActivityConfig defines suspicious activity detection settings
@@ -96,6 +168,17 @@ type SuspiciousActivityConfig struct {
 	EnablePatternDetection    bool     `json:"enable_pattern_detection"`
 	EnableBinaryDataDetection bool     `json:"enable_binary_data_detection"`
 	MaxRepeatedPatternCount   int      `json:"max_repeated_pattern_count"`
+
+	// Login failures counted across accounts within LoginWindow
+	LoginWindow                 time.Duration `json:"login_window"`
+	IPChallengeThreshold        int           `json:"ip_challenge_threshold"`         // failures from one IP before logins need an emailed code
+	IPBlockThreshold            int           `json:"ip_block_threshold"`             // failures from one IP before it is blocked
+	IPDistinctAccountsThreshold int           `json:"ip_distinct_accounts_threshold"` // accounts tried from one IP before it is blocked (password spraying)
+	SubnetChallengeThreshold    int           `json:"subnet_challenge_threshold"`
+	SubnetBlockThreshold        int           `json:"subnet_block_threshold"`
+	GlobalChallengeThreshold    int           `json:"global_challenge_threshold"` // platform-wide failures before every login needs a code
+	IPBlockDuration             time.Duration `json:"ip_block_duration"`
+	ChallengeExpiration         time.Duration `json:"challenge_expiration"`
 }
 
 // GetDefaultSecurityConfig returns the default security configuration
@@ -131,6 +214,18 @@ func GetDefaultSecurityConfig() SecurityConfig {
 			RequireNumbers:   true,
 			RequireSpecial:   true,
 			ExpirationDays:   90,
+			HistoryCount:     5,
+			RejectBreached:   true,
+		},
+		Lockout: LockoutPolicyConfig{
+			MaxAttempts:  5,
+			BaseDuration: 15 * time.Minute,
+			Multiplier:   4,
+			MaxDuration:  24 * time.Hour,
+		},
+		Impersonation: ImpersonationConfig{
+			Enabled:  true,
+			TokenTTL: 15 * time.Minute,
 		},
 		EmailValidation: EmailValidationConfig{
 			EnableRFC5322Validation:  true,
@@ -159,8 +254,52 @@ func GetDefaultSecurityConfig() SecurityConfig {
 			Enabled:                   true,
 			LogLevel:                  "INFO",
 			RetentionDays:             365,
+			PurgeInterval:             24 * time.Hour,
 			EnableRealTimeAlerts:      true,
 			CriticalEventNotification: true,
+			Alerts: AlertConfig{
+				Channels:     []string{AlertChannelEmail},
+				PollInterval: 30 * time.Second,
+				Cooldown:     30 * time.Minute,
+				Rules: []AlertRuleConfig{
+					{
+						Name:       "failed_logins_per_account",
+						Title:      "Repeated failed logins for one account",
+						EventTypes: []string{"LOGIN_FAILED"},
+						GroupBy:    "user_id",
+						Threshold:  5,
+						Window:     10 * time.Minute,
+						Severity:   "HIGH",
+					},
+					{
+						Name:       "failed_logins_per_ip",
+						Title:      "Repeated failed logins from one IP address",
+						EventTypes: []string{"LOGIN_FAILED"},
+						GroupBy:    "ip_address",
+						Threshold:  20,
+						Window:     10 * time.Minute,
+						Severity:   "HIGH",
+					},
+					{
+						Name:       "unauthorized_access",
+						Title:      "Repeated unauthorized access attempts",
+						EventTypes: []string{"UNAUTHORIZED_ACCESS", "PERMISSION_DENIED"},
+						GroupBy:    "user_id",
+						Threshold:  10,
+						Window:     5 * time.Minute,
+						Severity:   "HIGH",
+					},
+					{
+						Name:       "rate_limit_abuse",
+						Title:      "Rate limits repeatedly exceeded",
+						EventTypes: []string{"RATE_LIMIT_EXCEEDED"},
+						GroupBy:    "ip_address",
+						Threshold:  10,
+						Window:     5 * time.Minute,
+						Severity:   "MEDIUM",
+					},
+				},
+			},
 		},
 		SuspiciousActivityDetection: SuspiciousActivityConfig{
 			Enabled:                   true,
@@ -178,6 +317,15 @@ func GetDefaultSecurityConfig() SecurityConfig {
 				"delete from", "create table", "alter table", "exec(",
 				"execute(", "xp_", "sp_", "/*", "*/", "--", ";",
 			},
+			LoginWindow:                 15 * time.Minute,
+			IPChallengeThreshold:        10,
+			IPBlockThreshold:            30,
+			IPDistinctAccountsThreshold: 10,
+			SubnetChallengeThreshold:    30,
+			SubnetBlockThreshold:        100,
+			GlobalChallengeThreshold:    500,
+			IPBlockDuration:             time.Hour,
+			ChallengeExpiration:         10 * time.Minute,
 		},
 	}
 }
@@ -199,6 +347,31 @@ func (sc *SecurityConfig) ValidateSecurityConfig() error {
 	if sc.PasswordPolicy.MaxLength > 256 {
 		sc.PasswordPolicy.MaxLength = 256
 	}
+	if sc.PasswordPolicy.ExpirationDays < 0 {
+		sc.PasswordPolicy.ExpirationDays = 0
+	}
+	if sc.PasswordPolicy.HistoryCount < 0 {
+		sc.PasswordPolicy.HistoryCount = 0
+	}
+
+	// Validate lockout policy
+	if sc.Lockout.MaxAttempts <= 0 {
+		sc.Lockout.MaxAttempts = 5
+	}
+	if sc.Lockout.BaseDuration <= 0 {
+		sc.Lockout.BaseDuration = 15 * time.Minute
+	}
+	if sc.Lockout.Multiplier < 1 {
+		sc.Lockout.Multiplier = 1
+	}
+	if sc.Lockout.MaxDuration < sc.Lockout.BaseDuration {
+		sc.Lockout.MaxDuration = sc.Lockout.BaseDuration
+	}
+
+	// Validate impersonation
+	if sc.Impersonation.TokenTTL <= 0 || sc.Impersonation.TokenTTL > time.Hour {
+		sc.Impersonation.TokenTTL = 15 * time.Minute
+	}
 
 	// Validate email configuration
 	if sc.EmailValidation.MaxLength > 254 {
@@ -231,6 +404,15 @@ func (sc *SecurityConfig) ValidateSecurityConfig() error {
 		sc.SuspiciousActivityDetection.MaxSpecialCharPercentage > 1 {
 		sc.SuspiciousActivityDetection.MaxSpecialCharPercentage = 0.3
 	}
+	if sc.SuspiciousActivityDetection.LoginWindow <= 0 {
+		sc.SuspiciousActivityDetection.LoginWindow = 15 * time.Minute
+	}
+	if sc.SuspiciousActivityDetection.IPBlockDuration <= 0 {
+		sc.SuspiciousActivityDetection.IPBlockDuration = time.Hour
+	}
+	if sc.SuspiciousActivityDetection.ChallengeExpiration <= 0 {
+		sc.SuspiciousActivityDetection.ChallengeExpiration = 10 * time.Minute
+	}
 
 	return nil
 }
@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"os"
 	"time"
 
@@ -19,8 +20,6 @@ func NewDatabase() (*Database, error) {
 		return nil, fmt.Errorf("DATABASE_URL environment variable is not set")
 	}
 
-	fmt.Printf("Connecting to database with URL: %s\n", dbURL)
-
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
@@ -44,7 +43,8 @@ func NewDatabase() (*Database, error) {
 		return nil, fmt.Errorf("database ping error: %w", err)
 	}
 
-	fmt.Println("Database connection successful!")
+	// Never the URL itself, which holds the password
+	slog.Info("database connected", "host", config.Host, "database", config.Database)
 	return &Database{conn: conn}, nil
 }
 
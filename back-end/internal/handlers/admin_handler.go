@@ -2,11 +2,14 @@ package handlers
 
 import (
 	"context"
+	"fmt"
+	"log/slog"
 	"mowesport/internal/config"
 	"mowesport/internal/database"
 	"mowesport/internal/models"
 	"mowesport/internal/services"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -120,7 +123,7 @@ func (h *AdminHandler) RegisterAdmin(c echo.Context) error {
 	// Note: Input sanitization is now handled in the service layer for better security
 
 	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 30*time.Second)
 	defer cancel()
 
 	// Register admin
@@ -197,7 +200,7 @@ func (h *AdminHandler) ValidateEmail(c echo.Context) error {
 	}
 
 	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
 	defer cancel()
 
 	// Validate email with enhanced security
@@ -264,12 +267,21 @@ func (h *AdminHandler) GetAdminList(c echo.Context) error {
 	}
 
 	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 30*time.Second)
 	defer cancel()
 
 	// Get admin list
 	response, err := h.adminService.GetAdminList(ctx, &req)
 	if err != nil {
+		if strings.Contains(err.Error(), "invalid cursor") {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"success": false,
+				"error": map[string]interface{}{
+					"code":    "INVALID_CURSOR",
+					"message": err.Error(),
+				},
+			})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
 			"success": false,
 			"error": map[string]interface{}{
@@ -286,6 +298,66 @@ func (h *AdminHandler) GetAdminList(c echo.Context) error {
 	})
 }
 
+// ExportAdminList handles GET /api/admin/list/export, streaming the admins matching the
+// list filters as CSV
+func (h *AdminHandler) ExportAdminList(c echo.Context) error {
+	requesterID, _, ok := requesterFromToken(c)
+	if !ok {
+		return nil
+	}
+
+	var req models.AdminListRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_QUERY_PARAMS",
+				"message": "Invalid query parameters",
+				"details": err.Error(),
+			},
+		})
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "VALIDATION_ERROR",
+				"message": "Query parameter validation failed",
+				"details": validationErrorDetails(err),
+			},
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Minute)
+	defer cancel()
+
+	download := newCSVDownload(c, fmt.Sprintf("admins-%s.csv", time.Now().UTC().Format("20060102-150405")), []string{
+		"user_id", "email", "first_name", "last_name", "phone", "account_status",
+		"is_active", "city", "sport", "last_login_at", "created_at",
+	})
+	_, err := h.adminService.ExportAdminList(ctx, &req, requesterID, func(admin models.AdminSummary) error {
+		return download.Write([]string{
+			admin.UserID.String(), admin.Email, admin.FirstName, admin.LastName, csvOptional(admin.Phone), admin.AccountStatus,
+			strconv.FormatBool(admin.IsActive), csvOptional(admin.CityName), csvOptional(admin.SportName),
+			csvTimestamp(admin.LastLoginAt), csvTimestamp(&admin.CreatedAt),
+		})
+	})
+	if err != nil {
+		if download.Started() {
+			slog.ErrorContext(c.Request().Context(), "admin export interrupted", "error", err)
+			return nil
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INTERNAL_SERVER_ERROR",
+				"message": "Failed to export admin list",
+			},
+		})
+	}
+	return download.Finish()
+}
+
 // contains checks if a string contains a substring (case-insensitive)
 func contains(s, substr string) bool {
 	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"context"
+	"mowesport/internal/database"
+	"mowesport/internal/models"
+	"mowesport/internal/services"
+	"net/http"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type APIKeyHandler struct {
+	apiKeyService *services.APIKeyService
+	validator     *validator.Validate
+}
+
+func NewAPIKeyHandler(db *database.Database) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: services.NewAPIKeyService(db),
+		validator:     validator.New(),
+	}
+}
+
+// CreateAPIKey handles POST /api/admin/api-keys
+func (h *APIKeyHandler) CreateAPIKey(c echo.Context) error {
+	requesterID, requesterRole, ok := requesterFromToken(c)
+	if !ok {
+		return nil
+	}
+
+	var req models.APIKeyCreateRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_REQUEST_BODY",
+				"message": "Invalid request body format",
+				"details": err.Error(),
+			},
+		})
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "VALIDATION_ERROR",
+				"message": "Request validation failed",
+				"details": validationErrorDetails(err),
+			},
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	response, err := h.apiKeyService.CreateAPIKey(ctx, &req, requesterID, requesterRole)
+	if err != nil {
+		switch {
+		case contains(err.Error(), "insufficient permissions"):
+			return c.JSON(http.StatusForbidden, map[string]interface{}{
+				"success": false,
+				"error": map[string]interface{}{
+					"code":    "INSUFFICIENT_PERMISSIONS",
+					"message": "You can only issue keys within your own city/sport",
+				},
+			})
+		case contains(err.Error(), "tournament not found"):
+			return c.JSON(http.StatusNotFound, map[string]interface{}{
+				"success": false,
+				"error": map[string]interface{}{
+					"code":    "TOURNAMENT_NOT_FOUND",
+					"message": "The specified tournament does not exist",
+				},
+			})
+		case contains(err.Error(), "scope"), contains(err.Error(), "does not belong"):
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"success": false,
+				"error": map[string]interface{}{
+					"code":    "INVALID_SCOPE",
+					"message": "Invalid API key scope",
+					"details": err.Error(),
+				},
+			})
+		default:
+			return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+				"success": false,
+				"error": map[string]interface{}{
+					"code":    "INTERNAL_SERVER_ERROR",
+					"message": "Failed to create API key",
+					"details": err.Error(),
+				},
+			})
+		}
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// ListAPIKeys handles GET /api/admin/api-keys
+func (h *APIKeyHandler) ListAPIKeys(c echo.Context) error {
+	requesterID, requesterRole, ok := requesterFromToken(c)
+	if !ok {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	keys, err := h.apiKeyService.ListAPIKeys(ctx, requesterID, requesterRole)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INTERNAL_SERVER_ERROR",
+				"message": "Failed to retrieve API keys",
+				"details": err.Error(),
+			},
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    keys,
+	})
+}
+
+// RevokeAPIKey handles DELETE /api/admin/api-keys/:id
+func (h *APIKeyHandler) RevokeAPIKey(c echo.Context) error {
+	requesterID, requesterRole, ok := requesterFromToken(c)
+	if !ok {
+		return nil
+	}
+
+	apiKeyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_API_KEY_ID",
+				"message": "Invalid API key ID format",
+			},
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	if err := h.apiKeyService.RevokeAPIKey(ctx, apiKeyID, requesterID, requesterRole); err != nil {
+		switch {
+		case contains(err.Error(), "not found"):
+			return c.JSON(http.StatusNotFound, map[string]interface{}{
+				"success": false,
+				"error": map[string]interface{}{
+					"code":    "API_KEY_NOT_FOUND",
+					"message": "API key not found",
+				},
+			})
+		case contains(err.Error(), "insufficient permissions"):
+			return c.JSON(http.StatusForbidden, map[string]interface{}{
+				"success": false,
+				"error": map[string]interface{}{
+					"code":    "INSUFFICIENT_PERMISSIONS",
+					"message": "You can only revoke keys you created",
+				},
+			})
+		case contains(err.Error(), "already revoked"):
+			return c.JSON(http.StatusConflict, map[string]interface{}{
+				"success": false,
+				"error": map[string]interface{}{
+					"code":    "API_KEY_ALREADY_REVOKED",
+					"message": "API key is already revoked",
+				},
+			})
+		default:
+			return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+				"success": false,
+				"error": map[string]interface{}{
+					"code":    "INTERNAL_SERVER_ERROR",
+					"message": "Failed to revoke API key",
+					"details": err.Error(),
+				},
+			})
+		}
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "API key revoked successfully",
+	})
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mowesport/internal/database"
+	"mowesport/internal/models"
+	"mowesport/internal/services"
+	"net/http"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type AuditHandler struct {
+	auditService *services.SecurityAuditService
+	validator    *validator.Validate
+}
+
+func NewAuditHandler(db *database.Database) *AuditHandler {
+	return &AuditHandler{
+		auditService: services.NewSecurityAuditService(db),
+		validator:    validator.New(),
+	}
+}
+
+// ListEvents handles GET /api/admin/audit-events
+func (h *AuditHandler) ListEvents(c echo.Context) error {
+	req, filters, err := h.bindFilters(c)
+	if req == nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	page, err := h.auditService.ListSecurityEvents(ctx, filters, req.Page, req.Limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "AUDIT_QUERY_FAILED",
+				"message": "Failed to retrieve audit events",
+				"details": err.Error(),
+			},
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    page,
+	})
+}
+
+// ExportEvents handles GET /api/admin/audit-events/export, returning the filtered events as CSV
+func (h *AuditHandler) ExportEvents(c echo.Context) error {
+	req, filters, err := h.bindFilters(c)
+	if req == nil {
+		return err
+	}
+
+	requesterID, _, ok := requesterFromToken(c)
+	if !ok {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 60*time.Second)
+	defer cancel()
+
+	// Buffer the export so a query failure can still be reported as JSON
+	var body bytes.Buffer
+	rows, err := h.auditService.ExportSecurityEventsCSV(ctx, filters, &body)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "AUDIT_EXPORT_FAILED",
+				"message": "Failed to export audit events",
+				"details": err.Error(),
+			},
+		})
+	}
+
+	// Exports leave the platform, so they are audited themselves
+	h.auditService.LogSecurityEvent(ctx, services.SecurityEvent{
+		EventType:   services.EventTypeAuditLogExported,
+		Description: "Audit events exported as CSV",
+		UserID:      &requesterID,
+		Severity:    services.SeverityMedium,
+		Metadata: map[string]interface{}{
+			"rows":       rows,
+			"event_type": req.EventType,
+			"user_id":    req.UserID,
+			"ip_address": req.IPAddress,
+			"severity":   req.Severity,
+			"from":       req.From,
+			"to":         req.To,
+		},
+	})
+
+	filename := fmt.Sprintf("audit-events-%s.csv", time.Now().UTC().Format("20060102-150405"))
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+	return c.Blob(http.StatusOK, "text/csv; charset=utf-8", body.Bytes())
+}
+
+// Helper methods
+
+// bindFilters parses the query into service filters. When the request is nil an error
+// response has been written and the returned error is the result of writing it.
+func (h *AuditHandler) bindFilters(c echo.Context) (*models.AuditEventListRequest, services.SecurityEventFilters, error) {
+	var req models.AuditEventListRequest
+	var filters services.SecurityEventFilters
+
+	if err := c.Bind(&req); err != nil {
+		return nil, filters, c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_QUERY_PARAMS",
+				"message": "Invalid query parameters",
+				"details": "Dates must be RFC 3339, e.g. 2024-01-31T00:00:00Z",
+			},
+		})
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return nil, filters, c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "VALIDATION_ERROR",
+				"message": "Request validation failed",
+				"details": validationErrorDetails(err),
+			},
+		})
+	}
+
+	filters.EventType = req.EventType
+	filters.IPAddress = req.IPAddress
+	filters.Severity = req.Severity
+	filters.RequestID = req.RequestID
+	if req.UserID != "" {
+		userID := uuid.MustParse(req.UserID)
+		filters.UserID = &userID
+	}
+	if req.From != nil {
+		filters.StartTime = *req.From
+	}
+	if req.To != nil {
+		filters.EndTime = *req.To
+	}
+
+	return &req, filters, nil
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"mowesport/internal/config"
 	"mowesport/internal/database"
 	"mowesport/internal/models"
 	"mowesport/internal/services"
@@ -20,9 +21,9 @@ type AuthHandler struct {
 	validator   *validator.Validate
 }
 
-func NewAuthHandler(db *database.Database, jwtSecret string) *AuthHandler {
+func NewAuthHandler(db *database.Database, cfg *config.Config) *AuthHandler {
 	return &AuthHandler{
-		authService: services.NewAuthService(db, jwtSecret),
+		authService: services.NewAuthService(db, cfg),
 		validator:   validator.New(),
 	}
 }
@@ -53,7 +54,7 @@ func (h *AuthHandler) Login(c echo.Context) error {
 	}
 
 	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 30*time.Second)
 	defer cancel()
 
 	// Attempt login
@@ -94,11 +95,20 @@ func (h *AuthHandler) RefreshToken(c echo.Context) error {
 		})
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
 	defer cancel()
 
 	response, err := h.authService.RefreshToken(ctx, req.RefreshToken)
 	if err != nil {
+		if strings.Contains(err.Error(), "token_revoked") {
+			return c.JSON(http.StatusUnauthorized, map[string]interface{}{
+				"success": false,
+				"error": map[string]interface{}{
+					"code":    "TOKEN_REVOKED",
+					"message": "Your session is no longer valid, please sign in again",
+				},
+			})
+		}
 		return c.JSON(http.StatusUnauthorized, map[string]interface{}{
 			"success": false,
 			"error": map[string]interface{}{
@@ -148,7 +158,7 @@ func (h *AuthHandler) RequestPasswordRecovery(c echo.Context) error {
 		})
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
 	defer cancel()
 
 	err := h.authService.RequestPasswordRecovery(ctx, &req)
@@ -193,11 +203,15 @@ func (h *AuthHandler) ResetPassword(c echo.Context) error {
 		})
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
 	defer cancel()
 
 	err := h.authService.ResetPassword(ctx, &req)
 	if err != nil {
+		if resp, ok := passwordPolicyErrorResponse(err); ok {
+			return c.JSON(http.StatusBadRequest, resp)
+		}
+
 		if strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "expired") {
 			return c.JSON(http.StatusBadRequest, map[string]interface{}{
 				"success": false,
@@ -251,7 +265,7 @@ func (h *AuthHandler) Setup2FA(c echo.Context) error {
 		})
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
 	defer cancel()
 
 	response, err := h.authService.Setup2FA(ctx, userID)
@@ -320,7 +334,7 @@ func (h *AuthHandler) Verify2FA(c echo.Context) error {
 		})
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
 	defer cancel()
 
 	err = h.authService.Verify2FA(ctx, userID, &req)
@@ -399,7 +413,7 @@ func (h *AuthHandler) Disable2FA(c echo.Context) error {
 		})
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
 	defer cancel()
 
 	err = h.authService.Disable2FA(ctx, userID, &req)
@@ -467,7 +481,7 @@ func (h *AuthHandler) GetProfile(c echo.Context) error {
 		})
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
 	defer cancel()
 
 	profile, err := h.authService.GetUserProfile(ctx, userID)
@@ -497,6 +511,66 @@ func (h *AuthHandler) GetProfile(c echo.Context) error {
 	})
 }
 
+// UpdateLocale handles PUT /api/auth/locale
+func (h *AuthHandler) UpdateLocale(c echo.Context) error {
+	userID, _, ok := requesterFromToken(c)
+	if !ok {
+		return nil
+	}
+
+	var req models.LocaleUpdateRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_REQUEST_BODY",
+				"message": "Invalid request body format",
+			},
+		})
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "VALIDATION_ERROR",
+				"message": "Request validation failed",
+				"details": validationErrorDetails(err),
+			},
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	if err := h.authService.UpdatePreferredLocale(ctx, userID, req.Locale); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.JSON(http.StatusNotFound, map[string]interface{}{
+				"success": false,
+				"error": map[string]interface{}{
+					"code":    "USER_NOT_FOUND",
+					"message": "User profile not found",
+				},
+			})
+		}
+
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "LOCALE_UPDATE_FAILED",
+				"message": "Failed to update preferred locale",
+			},
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"locale": req.Locale,
+		},
+	})
+}
+
 // Helper methods
 
 func (h *AuthHandler) handleAuthError(c echo.Context, err error) error {
@@ -557,6 +631,15 @@ func (h *AuthHandler) handleAuthError(c echo.Context, err error) error {
 			},
 		})
 
+	case strings.Contains(errMsg, "email_not_verified"):
+		return c.JSON(http.StatusForbidden, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "EMAIL_NOT_VERIFIED",
+				"message": "Email address has not been verified",
+			},
+		})
+
 	case strings.Contains(errMsg, "two_factor_required"):
 		return c.JSON(http.StatusUnauthorized, map[string]interface{}{
 			"success": false,
@@ -566,6 +649,33 @@ func (h *AuthHandler) handleAuthError(c echo.Context, err error) error {
 			},
 		})
 
+	case strings.Contains(errMsg, "ip_blocked"):
+		return c.JSON(http.StatusTooManyRequests, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "LOGIN_TEMPORARILY_BLOCKED",
+				"message": "Too many failed logins from your network, try again later",
+			},
+		})
+
+	case strings.Contains(errMsg, "step_up_required"):
+		return c.JSON(http.StatusUnauthorized, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "STEP_UP_REQUIRED",
+				"message": "A verification code was sent to your email; repeat the login with challenge_code",
+			},
+		})
+
+	case strings.Contains(errMsg, "invalid_challenge_code"):
+		return c.JSON(http.StatusUnauthorized, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_CHALLENGE_CODE",
+				"message": "Invalid or expired verification code",
+			},
+		})
+
 	case strings.Contains(errMsg, "invalid_two_factor_code"):
 		return c.JSON(http.StatusUnauthorized, map[string]interface{}{
 			"success": false,
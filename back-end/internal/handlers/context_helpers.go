@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"mowesport/internal/models"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// requesterFromToken extracts the requester ID and primary role from the JWT identity.
+// On failure it writes the error response and returns ok=false.
+func requesterFromToken(c echo.Context) (uuid.UUID, string, bool) {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+
+	userIDStr, ok := claims["user_id"].(string)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_TOKEN",
+				"message": "Invalid user ID in token",
+			},
+		})
+		return uuid.Nil, "", false
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_USER_ID",
+				"message": "Invalid user ID format",
+			},
+		})
+		return uuid.Nil, "", false
+	}
+
+	role, _ := claims["primary_role"].(string)
+	return userID, role, true
+}
+
+// apiKeyFromContext returns the API key identity set by JWTMiddleware for requests
+// authenticated with an API key. On failure it writes the error response and returns ok=false.
+func apiKeyFromContext(c echo.Context) (*models.APIKeyIdentity, bool) {
+	identity, ok := c.Get("api_key").(*models.APIKeyIdentity)
+	if !ok {
+		c.JSON(http.StatusForbidden, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "API_KEY_REQUIRED",
+				"message": "This endpoint requires an API key",
+			},
+		})
+		return nil, false
+	}
+	return identity, true
+}
+
+// validationErrorDetails converts validator errors into field messages
+func validationErrorDetails(err error) map[string]string {
+	validationErrors := make(map[string]string)
+	fieldErrors, ok := err.(validator.ValidationErrors)
+	if !ok {
+		validationErrors["request"] = err.Error()
+		return validationErrors
+	}
+	for _, err := range fieldErrors {
+		field := err.Field()
+		switch err.Tag() {
+		case "required":
+			validationErrors[field] = field + " is required"
+		case "email":
+			validationErrors[field] = "Invalid email format"
+		case "min":
+			validationErrors[field] = field + " is too short"
+		case "max":
+			validationErrors[field] = field + " is too long"
+		case "uuid":
+			validationErrors[field] = "Invalid UUID format"
+		case "oneof":
+			validationErrors[field] = "Invalid value for " + field
+		default:
+			validationErrors[field] = "Invalid " + field
+		}
+	}
+	return validationErrors
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// csvFlushRows is how many rows are buffered before a streamed CSV is flushed
+const csvFlushRows = 100
+
+// csvDownload streams rows as a CSV attachment. The response only starts with the first
+// row (or on finish), so errors raised before any row can still be answered as JSON.
+type csvDownload struct {
+	c        echo.Context
+	fileName string
+	header   []string
+	writer   *csv.Writer
+	rows     int
+}
+
+func newCSVDownload(c echo.Context, fileName string, header []string) *csvDownload {
+	return &csvDownload{c: c, fileName: fileName, header: header}
+}
+
+// Started reports whether the response has been sent
+func (d *csvDownload) Started() bool {
+	return d.writer != nil
+}
+
+// Write adds one row, starting the response on the first
+func (d *csvDownload) Write(record []string) error {
+	if err := d.start(); err != nil {
+		return err
+	}
+	for i, value := range record {
+		record[i] = csvSafeCell(value)
+	}
+	if err := d.writer.Write(record); err != nil {
+		return err
+	}
+	d.rows++
+	if d.rows%csvFlushRows == 0 {
+		d.writer.Flush()
+		d.c.Response().Flush()
+	}
+	return d.writer.Error()
+}
+
+// Finish flushes the remaining rows, sending the header alone when there were none
+func (d *csvDownload) Finish() error {
+	if err := d.start(); err != nil {
+		return err
+	}
+	d.writer.Flush()
+	d.c.Response().Flush()
+	return d.writer.Error()
+}
+
+func (d *csvDownload) start() error {
+	if d.writer != nil {
+		return nil
+	}
+	response := d.c.Response()
+	response.Header().Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+	response.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", d.fileName))
+	response.Header().Set(echo.HeaderCacheControl, "no-store")
+	response.WriteHeader(http.StatusOK)
+
+	// UTF-8 byte order mark, so spreadsheet applications keep accents
+	if _, err := response.Write([]byte("\xef\xbb\xbf")); err != nil {
+		return err
+	}
+	d.writer = csv.NewWriter(response)
+	return d.writer.Write(d.header)
+}
+
+// csvSafeCell keeps exported cells from being run as formulas by spreadsheet applications
+func csvSafeCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func csvOptional(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+func csvTimestamp(value *time.Time) string {
+	if value == nil {
+		return ""
+	}
+	return value.UTC().Format(time.RFC3339)
+}
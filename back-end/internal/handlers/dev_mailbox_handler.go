@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"html/template"
+	"mime"
+	"mowesport/internal/config"
+	"mowesport/internal/models"
+	"mowesport/internal/services"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+// DevMailboxHandler exposes email captured in development. Its routes are only
+// registered when ENVIRONMENT=development and require no authentication.
+type DevMailboxHandler struct {
+	mailbox   *services.DevMailbox
+	validator *validator.Validate
+}
+
+func NewDevMailboxHandler(cfg *config.Config) *DevMailboxHandler {
+	return &DevMailboxHandler{
+		mailbox:   services.NewDevMailbox(cfg.DevMailboxDir),
+		validator: validator.New(),
+	}
+}
+
+// ListMessages handles GET /api/dev/mailbox
+func (h *DevMailboxHandler) ListMessages(c echo.Context) error {
+	var req models.DevMailboxListRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_QUERY_PARAMS",
+				"message": "Invalid query parameters",
+			},
+		})
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "VALIDATION_ERROR",
+				"message": "Request validation failed",
+				"details": validationErrorDetails(err),
+			},
+		})
+	}
+
+	messages, err := h.mailbox.List(req.To, req.Limit)
+	if err != nil {
+		return h.handleMailboxError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    messages,
+	})
+}
+
+// Inbox handles GET /api/dev/mailbox/inbox, a browsable list of captured email
+func (h *DevMailboxHandler) Inbox(c echo.Context) error {
+	messages, err := h.mailbox.List(c.QueryParam("to"), 200)
+	if err != nil {
+		return h.handleMailboxError(c, err)
+	}
+
+	var page strings.Builder
+	if err := inboxTemplate.Execute(&page, messages); err != nil {
+		return h.handleMailboxError(c, err)
+	}
+
+	return c.HTML(http.StatusOK, page.String())
+}
+
+// LatestMessage handles GET /api/dev/mailbox/latest?to=, for integration tests
+// that need the link from the last email sent to an address
+func (h *DevMailboxHandler) LatestMessage(c echo.Context) error {
+	to := c.QueryParam("to")
+	if to == "" {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "VALIDATION_ERROR",
+				"message": "Query parameter 'to' is required",
+			},
+		})
+	}
+
+	message, err := h.mailbox.Latest(to)
+	if err != nil {
+		return h.handleMailboxError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    message,
+	})
+}
+
+// GetMessage handles GET /api/dev/mailbox/:id
+func (h *DevMailboxHandler) GetMessage(c echo.Context) error {
+	message, err := h.mailbox.Get(c.Param("id"))
+	if err != nil {
+		return h.handleMailboxError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    message,
+	})
+}
+
+// RenderMessage handles GET /api/dev/mailbox/:id/html, showing the email as a client would
+func (h *DevMailboxHandler) RenderMessage(c echo.Context) error {
+	message, err := h.mailbox.Get(c.Param("id"))
+	if err != nil {
+		return h.handleMailboxError(c, err)
+	}
+
+	if message.HTML == "" {
+		return c.String(http.StatusOK, message.Text)
+	}
+	return c.HTML(http.StatusOK, message.HTML)
+}
+
+// RawMessage handles GET /api/dev/mailbox/:id/raw, the message as it would go over SMTP
+func (h *DevMailboxHandler) RawMessage(c echo.Context) error {
+	raw, err := h.mailbox.Raw(c.Param("id"))
+	if err != nil {
+		return h.handleMailboxError(c, err)
+	}
+
+	return c.Blob(http.StatusOK, "message/rfc822", raw)
+}
+
+// GetAttachment handles GET /api/dev/mailbox/:id/attachments/:index
+func (h *DevMailboxHandler) GetAttachment(c echo.Context) error {
+	index, err := strconv.Atoi(c.Param("index"))
+	if err != nil {
+		return h.handleMailboxError(c, err)
+	}
+
+	attachment, content, err := h.mailbox.Attachment(c.Param("id"), index)
+	if err != nil {
+		return h.handleMailboxError(c, err)
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}))
+	return c.Blob(http.StatusOK, attachment.MimeType, content)
+}
+
+// ClearMailbox handles DELETE /api/dev/mailbox
+func (h *DevMailboxHandler) ClearMailbox(c echo.Context) error {
+	deleted, err := h.mailbox.Clear()
+	if err != nil {
+		return h.handleMailboxError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"deleted": deleted,
+		},
+	})
+}
+
+// Helper methods
+
+func (h *DevMailboxHandler) handleMailboxError(c echo.Context, err error) error {
+	if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "invalid syntax") {
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "MESSAGE_NOT_FOUND",
+				"message": "Message not found",
+			},
+		})
+	}
+
+	return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+		"success": false,
+		"error": map[string]interface{}{
+			"code":    "MAILBOX_ERROR",
+			"message": "Failed to read the development mailbox",
+			"details": err.Error(),
+		},
+	})
+}
+
+var inboxTemplate = template.Must(template.New("inbox").Parse(`<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="UTF-8">
+	<title>Mowe Sport - Dev Mailbox</title>
+</head>
+<body style="font-family: Arial, sans-serif; color: #333; margin: 20px;">
+	<h2 style="color: #2c5aa0;">Dev Mailbox</h2>
+	<table style="border-collapse: collapse; width: 100%;">
+		<tr style="text-align: left; border-bottom: 1px solid #ccc;">
+			<th>Date</th><th>To</th><th>Subject</th><th>Attachments</th><th></th>
+		</tr>
+		{{range .}}
+		<tr style="border-bottom: 1px solid #eee;">
+			<td>{{.Date.Format "2006-01-02 15:04:05"}}</td>
+			<td>{{.To}}</td>
+			<td><a href="/api/dev/mailbox/{{.ID}}/html">{{.Subject}}</a></td>
+			<td>{{.AttachmentCount}}</td>
+			<td><a href="/api/dev/mailbox/{{.ID}}">json</a> · <a href="/api/dev/mailbox/{{.ID}}/raw">raw</a></td>
+		</tr>
+		{{else}}
+		<tr><td colspan="5">No email captured yet.</td></tr>
+		{{end}}
+	</table>
+</body>
+</html>
+`))
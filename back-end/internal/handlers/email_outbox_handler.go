@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"context"
+	"mowesport/internal/config"
+	"mowesport/internal/database"
+	"mowesport/internal/models"
+	"mowesport/internal/services"
+	"net/http"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type EmailOutboxHandler struct {
+	outboxService *services.EmailOutboxService
+	validator     *validator.Validate
+}
+
+func NewEmailOutboxHandler(db *database.Database, cfg *config.Config) *EmailOutboxHandler {
+	return &EmailOutboxHandler{
+		outboxService: services.NewEmailOutboxService(db, cfg),
+		validator:     validator.New(),
+	}
+}
+
+// ListMessages handles GET /api/admin/email-outbox (failed messages by default)
+func (h *EmailOutboxHandler) ListMessages(c echo.Context) error {
+	var req models.EmailOutboxListRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_QUERY_PARAMS",
+				"message": "Invalid query parameters",
+			},
+		})
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "VALIDATION_ERROR",
+				"message": "Request validation failed",
+				"details": validationErrorDetails(err),
+			},
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	messages, err := h.outboxService.ListMessages(ctx, &req)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INTERNAL_SERVER_ERROR",
+				"message": "Failed to retrieve outbox messages",
+				"details": err.Error(),
+			},
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    messages,
+	})
+}
+
+// RetryMessage handles POST /api/admin/email-outbox/:id/retry
+func (h *EmailOutboxHandler) RetryMessage(c echo.Context) error {
+	requesterID, _, ok := requesterFromToken(c)
+	if !ok {
+		return nil
+	}
+
+	messageID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_MESSAGE_ID",
+				"message": "Invalid message ID format",
+			},
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	if err := h.outboxService.RetryMessage(ctx, messageID, requesterID); err != nil {
+		switch {
+		case contains(err.Error(), "not found"):
+			return c.JSON(http.StatusNotFound, map[string]interface{}{
+				"success": false,
+				"error": map[string]interface{}{
+					"code":    "MESSAGE_NOT_FOUND",
+					"message": "Outbox message not found",
+				},
+			})
+		case contains(err.Error(), "only failed messages"):
+			return c.JSON(http.StatusConflict, map[string]interface{}{
+				"success": false,
+				"error": map[string]interface{}{
+					"code":    "MESSAGE_NOT_FAILED",
+					"message": "Only failed messages can be retried",
+				},
+			})
+		default:
+			return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+				"success": false,
+				"error": map[string]interface{}{
+					"code":    "INTERNAL_SERVER_ERROR",
+					"message": "Failed to retry message",
+					"details": err.Error(),
+				},
+			})
+		}
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Message queued for delivery",
+	})
+}
+
+// RetryAllFailed handles POST /api/admin/email-outbox/retry-failed
+func (h *EmailOutboxHandler) RetryAllFailed(c echo.Context) error {
+	requesterID, _, ok := requesterFromToken(c)
+	if !ok {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	count, err := h.outboxService.RetryAllFailed(ctx, requesterID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INTERNAL_SERVER_ERROR",
+				"message": "Failed to retry messages",
+				"details": err.Error(),
+			},
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"requeued": count,
+		},
+	})
+}
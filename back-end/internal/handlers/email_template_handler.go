@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"mowesport/internal/config"
+	"mowesport/internal/database"
+	"mowesport/internal/models"
+	"mowesport/internal/services"
+	"net/http"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+type EmailTemplateHandler struct {
+	emailService *services.EmailService
+	validator    *validator.Validate
+}
+
+func NewEmailTemplateHandler(db *database.Database, cfg *config.Config) *EmailTemplateHandler {
+	return &EmailTemplateHandler{
+		emailService: services.NewEmailService(cfg, services.NewSecurityAuditService(db)),
+		validator:    validator.New(),
+	}
+}
+
+// ListTemplates handles GET /api/admin/email-templates
+func (h *EmailTemplateHandler) ListTemplates(c echo.Context) error {
+	names, err := h.emailService.TemplateNames()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "TEMPLATE_LIST_FAILED",
+				"message": "Failed to list email templates",
+			},
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"templates": names,
+			"locales":   services.SupportedEmailLocales,
+		},
+	})
+}
+
+// PreviewTemplate handles GET /api/admin/email-templates/:name/preview.
+// The template is rendered with sample data and never sent; format=html returns the page itself.
+func (h *EmailTemplateHandler) PreviewTemplate(c echo.Context) error {
+	var req models.EmailTemplatePreviewRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_QUERY_PARAMS",
+				"message": "Invalid query parameters",
+			},
+		})
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "VALIDATION_ERROR",
+				"message": "Request validation failed",
+				"details": validationErrorDetails(err),
+			},
+		})
+	}
+
+	name := c.Param("name")
+	rendered, err := h.emailService.RenderTemplate(name, req.Locale, services.EmailTemplateSample(name))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.JSON(http.StatusNotFound, map[string]interface{}{
+				"success": false,
+				"error": map[string]interface{}{
+					"code":    "TEMPLATE_NOT_FOUND",
+					"message": "Email template not found",
+				},
+			})
+		}
+
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "TEMPLATE_RENDER_FAILED",
+				"message": "Failed to render email template",
+				"details": err.Error(),
+			},
+		})
+	}
+
+	switch req.Format {
+	case "html":
+		return c.HTML(http.StatusOK, rendered.HTML)
+	case "text":
+		return c.String(http.StatusOK, rendered.Text)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    rendered,
+	})
+}
@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"context"
+	"mowesport/internal/config"
+	"mowesport/internal/database"
+	"mowesport/internal/models"
+	"mowesport/internal/services"
+	"net/http"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+type EmailVerificationHandler struct {
+	verificationService *services.EmailVerificationService
+	validator           *validator.Validate
+}
+
+func NewEmailVerificationHandler(db *database.Database, cfg *config.Config) *EmailVerificationHandler {
+	return &EmailVerificationHandler{
+		verificationService: services.NewEmailVerificationService(db, cfg),
+		validator:           validator.New(),
+	}
+}
+
+// VerifyEmail handles POST /api/auth/verify-email
+func (h *EmailVerificationHandler) VerifyEmail(c echo.Context) error {
+	var req models.EmailVerificationRequest
+	if err := c.Bind(&req); err != nil || h.validator.Struct(&req) != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "VALIDATION_ERROR",
+				"message": "Verification token is required",
+			},
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	if err := h.verificationService.VerifyEmail(ctx, req.Token); err != nil {
+		return h.handleVerificationError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Email verified successfully",
+	})
+}
+
+// ResendVerification handles POST /api/auth/resend-verification
+func (h *EmailVerificationHandler) ResendVerification(c echo.Context) error {
+	var req models.ResendVerificationRequest
+	if err := c.Bind(&req); err != nil || h.validator.Struct(&req) != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "VALIDATION_ERROR",
+				"message": "Valid email is required",
+			},
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 30*time.Second)
+	defer cancel()
+
+	if err := h.verificationService.ResendSignupVerification(ctx, req.Email); err != nil {
+		return h.handleVerificationError(c, err)
+	}
+
+	// Always return success for security (don't reveal if email exists)
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "If the account exists and is unverified, a verification link has been sent",
+	})
+}
+
+// RequestEmailChange handles POST /api/auth/change-email
+func (h *EmailVerificationHandler) RequestEmailChange(c echo.Context) error {
+	requesterID, _, ok := requesterFromToken(c)
+	if !ok {
+		return nil
+	}
+
+	var req models.EmailChangeRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_REQUEST_BODY",
+				"message": "Invalid request body format",
+			},
+		})
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "VALIDATION_ERROR",
+				"message": "Request validation failed",
+				"details": validationErrorDetails(err),
+			},
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 30*time.Second)
+	defer cancel()
+
+	status, err := h.verificationService.RequestEmailChange(ctx, requesterID, &req)
+	if err != nil {
+		return h.handleVerificationError(c, err)
+	}
+
+	return c.JSON(http.StatusAccepted, map[string]interface{}{
+		"success": true,
+		"data":    status,
+	})
+}
+
+// ConfirmEmailChange handles POST /api/auth/confirm-email-change
+func (h *EmailVerificationHandler) ConfirmEmailChange(c echo.Context) error {
+	var req models.EmailVerificationRequest
+	if err := c.Bind(&req); err != nil || h.validator.Struct(&req) != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "VALIDATION_ERROR",
+				"message": "Confirmation token is required",
+			},
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	status, err := h.verificationService.ConfirmEmailChange(ctx, req.Token)
+	if err != nil {
+		return h.handleVerificationError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    status,
+	})
+}
+
+// handleVerificationError maps verification service errors to responses
+func (h *EmailVerificationHandler) handleVerificationError(c echo.Context, err error) error {
+	errMsg := err.Error()
+
+	switch {
+	case contains(errMsg, "invalid or expired token"):
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_TOKEN",
+				"message": "Invalid, used or expired link",
+			},
+		})
+	case contains(errMsg, "resend throttled"):
+		return c.JSON(http.StatusTooManyRequests, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "RESEND_THROTTLED",
+				"message": "Too many emails requested, please try again later",
+			},
+		})
+	case contains(errMsg, "invalid credentials"):
+		return c.JSON(http.StatusUnauthorized, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_CREDENTIALS",
+				"message": "Current password is incorrect",
+			},
+		})
+	case contains(errMsg, "email already exists"):
+		return c.JSON(http.StatusConflict, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "EMAIL_ALREADY_EXISTS",
+				"message": "Email already exists",
+			},
+		})
+	case contains(errMsg, "must be different"):
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "SAME_EMAIL",
+				"message": "New email must be different from the current email",
+			},
+		})
+	case contains(errMsg, "already verified"):
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"success": true,
+			"message": "Email already verified",
+		})
+	case contains(errMsg, "user not found"):
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "USER_NOT_FOUND",
+				"message": "User not found",
+			},
+		})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "EMAIL_VERIFICATION_ERROR",
+				"message": "Failed to process email verification",
+			},
+		})
+	}
+}
@@ -0,0 +1,437 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"mowesport/internal/config"
+	"mowesport/internal/database"
+	"mowesport/internal/models"
+	"mowesport/internal/services"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type GuardianHandler struct {
+	guardianService   *services.GuardianService
+	securityValidator *services.SecurityValidationService
+	validator         *validator.Validate
+}
+
+func NewGuardianHandler(db *database.Database, cfg *config.Config) *GuardianHandler {
+	return &GuardianHandler{
+		guardianService:   services.NewGuardianService(db, cfg),
+		securityValidator: services.NewSecurityValidationService(),
+		validator:         validator.New(),
+	}
+}
+
+// RegisterMinorPlayer handles POST /api/users/register/minor-player
+func (h *GuardianHandler) RegisterMinorPlayer(c echo.Context) error {
+	requesterID, _, ok := requesterFromToken(c)
+	if !ok {
+		return nil
+	}
+
+	var req models.MinorPlayerRegistrationRequest
+	if !h.bindAndValidate(c, &req) {
+		return nil
+	}
+
+	// Same contact checks as other registrations; the guardian's contact details are the minor's.
+	// The date of birth format is checked by the validator.
+	dateOfBirth, _ := time.Parse("2006-01-02", req.DateOfBirth)
+	err := h.securityValidator.ValidateRegistrationFields(req.Guardian.Email, req.Guardian.Phone, req.IdentificationType, req.Identification)
+	if err == nil {
+		if err = h.securityValidator.ValidatePlayerIdentification(req.IdentificationType, req.Identification, dateOfBirth, time.Now()); err != nil {
+			err = fmt.Errorf("identification validation failed: %w", err)
+		}
+	}
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "VALIDATION_ERROR",
+				"message": "Validation failed",
+				"details": err.Error(),
+			},
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 30*time.Second)
+	defer cancel()
+
+	registration, err := h.guardianService.RegisterMinorPlayer(ctx, &req, requesterID)
+	if err != nil {
+		return h.handleGuardianError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"success": true,
+		"data":    registration,
+	})
+}
+
+// ListGuardians handles GET /api/users/players/:playerId/guardians
+func (h *GuardianHandler) ListGuardians(c echo.Context) error {
+	playerID, ok := playerIDParam(c)
+	if !ok {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	guardians, err := h.guardianService.ListGuardians(ctx, playerID)
+	if err != nil {
+		return h.handleGuardianError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    guardians,
+	})
+}
+
+// LinkGuardian handles POST /api/users/players/:playerId/guardians
+func (h *GuardianHandler) LinkGuardian(c echo.Context) error {
+	requesterID, _, ok := requesterFromToken(c)
+	if !ok {
+		return nil
+	}
+	playerID, ok := playerIDParam(c)
+	if !ok {
+		return nil
+	}
+
+	var req models.GuardianLinkRequest
+	if !h.bindAndValidate(c, &req) {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 30*time.Second)
+	defer cancel()
+
+	guardian, err := h.guardianService.LinkGuardian(ctx, playerID, &req.Guardian, requesterID)
+	if err != nil {
+		return h.handleGuardianError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"success": true,
+		"data":    guardian,
+	})
+}
+
+// UnlinkGuardian handles DELETE /api/users/players/:playerId/guardians/:linkId
+func (h *GuardianHandler) UnlinkGuardian(c echo.Context) error {
+	requesterID, _, ok := requesterFromToken(c)
+	if !ok {
+		return nil
+	}
+	playerID, ok := playerIDParam(c)
+	if !ok {
+		return nil
+	}
+
+	linkID, err := uuid.Parse(c.Param("linkId"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_LINK_ID",
+				"message": "Invalid guardian link ID format",
+			},
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	if err := h.guardianService.UnlinkGuardian(ctx, playerID, linkID, requesterID); err != nil {
+		return h.handleGuardianError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Guardian unlinked",
+	})
+}
+
+// ListMyPlayers handles GET /api/auth/guardian/players
+func (h *GuardianHandler) ListMyPlayers(c echo.Context) error {
+	requesterID, _, ok := requesterFromToken(c)
+	if !ok {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	players, err := h.guardianService.ListGuardedPlayers(ctx, requesterID)
+	if err != nil {
+		return h.handleGuardianError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    players,
+	})
+}
+
+// GetMyPlayer handles GET /api/auth/guardian/players/:playerId
+func (h *GuardianHandler) GetMyPlayer(c echo.Context) error {
+	requesterID, _, ok := requesterFromToken(c)
+	if !ok {
+		return nil
+	}
+	playerID, ok := playerIDParam(c)
+	if !ok {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	player, err := h.guardianService.GetGuardedPlayer(ctx, requesterID, playerID)
+	if err != nil {
+		return h.handleGuardianError(c, err)
+	}
+
+	c.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    player,
+	})
+}
+
+// UpdateMyPlayer handles PUT /api/auth/guardian/players/:playerId
+func (h *GuardianHandler) UpdateMyPlayer(c echo.Context) error {
+	requesterID, _, ok := requesterFromToken(c)
+	if !ok {
+		return nil
+	}
+	playerID, ok := playerIDParam(c)
+	if !ok {
+		return nil
+	}
+
+	var req models.GuardedPlayerUpdateRequest
+	if !h.bindAndValidate(c, &req) {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	player, err := h.guardianService.UpdateGuardedPlayer(ctx, requesterID, playerID, &req)
+	if err != nil {
+		return h.handleGuardianError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    player,
+	})
+}
+
+// GiveConsent handles POST /api/auth/guardian/players/:playerId/consent
+func (h *GuardianHandler) GiveConsent(c echo.Context) error {
+	requesterID, _, ok := requesterFromToken(c)
+	if !ok {
+		return nil
+	}
+	playerID, ok := playerIDParam(c)
+	if !ok {
+		return nil
+	}
+
+	var req models.GuardianConsentRequest
+	if !h.bindAndValidate(c, &req) {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	consent, err := h.guardianService.RecordConsent(ctx, requesterID, playerID, req.DocumentVersion)
+	if err != nil {
+		return h.handleGuardianError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"success": true,
+		"data":    consent,
+	})
+}
+
+// RevokeConsent handles DELETE /api/auth/guardian/players/:playerId/consent
+func (h *GuardianHandler) RevokeConsent(c echo.Context) error {
+	requesterID, _, ok := requesterFromToken(c)
+	if !ok {
+		return nil
+	}
+	playerID, ok := playerIDParam(c)
+	if !ok {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	if err := h.guardianService.RevokeConsent(ctx, requesterID, playerID); err != nil {
+		return h.handleGuardianError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Consent revoked",
+	})
+}
+
+// UpdateNotifications handles PUT /api/auth/guardian/players/:playerId/notifications
+func (h *GuardianHandler) UpdateNotifications(c echo.Context) error {
+	requesterID, _, ok := requesterFromToken(c)
+	if !ok {
+		return nil
+	}
+	playerID, ok := playerIDParam(c)
+	if !ok {
+		return nil
+	}
+
+	var req models.GuardianNotificationsRequest
+	if !h.bindAndValidate(c, &req) {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	if err := h.guardianService.SetMatchNotifications(ctx, requesterID, playerID, *req.NotifyMatches); err != nil {
+		return h.handleGuardianError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"player_id":      playerID,
+			"notify_matches": *req.NotifyMatches,
+		},
+	})
+}
+
+// bindAndValidate binds the request body into req and validates it. On failure it writes
+// the error response and returns false.
+func (h *GuardianHandler) bindAndValidate(c echo.Context, req interface{}) bool {
+	if err := c.Bind(req); err != nil {
+		c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_REQUEST_BODY",
+				"message": "Invalid request body format",
+			},
+		})
+		return false
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "VALIDATION_ERROR",
+				"message": "Request validation failed",
+				"details": validationErrorDetails(err),
+			},
+		})
+		return false
+	}
+
+	return true
+}
+
+// playerIDParam parses the :playerId path parameter. On failure it writes the error
+// response and returns ok=false.
+func playerIDParam(c echo.Context) (uuid.UUID, bool) {
+	playerID, err := uuid.Parse(c.Param("playerId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_PLAYER_ID",
+				"message": "Invalid player ID format",
+			},
+		})
+		return uuid.Nil, false
+	}
+	return playerID, true
+}
+
+func (h *GuardianHandler) handleGuardianError(c echo.Context, err error) error {
+	errMsg := err.Error()
+
+	switch {
+	case strings.Contains(errMsg, "player not found"), strings.Contains(errMsg, "team not found"), strings.Contains(errMsg, "guardian link not found"):
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "RESOURCE_NOT_FOUND",
+				"message": errMsg,
+			},
+		})
+	case strings.Contains(errMsg, "no active consent"):
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "CONSENT_NOT_FOUND",
+				"message": errMsg,
+			},
+		})
+	case strings.Contains(errMsg, "insufficient permissions"):
+		return c.JSON(http.StatusForbidden, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INSUFFICIENT_PERMISSIONS",
+				"message": errMsg,
+			},
+		})
+	case strings.Contains(errMsg, "already linked"), strings.Contains(errMsg, "already registered"):
+		return c.JSON(http.StatusConflict, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "GUARDIAN_CONFLICT",
+				"message": errMsg,
+			},
+		})
+	case strings.Contains(errMsg, "version is outdated"):
+		return c.JSON(http.StatusConflict, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "CONSENT_DOCUMENT_OUTDATED",
+				"message": errMsg,
+			},
+		})
+	case strings.Contains(errMsg, "not a minor"), strings.Contains(errMsg, "not active"), strings.Contains(errMsg, "own guardian"),
+		strings.Contains(errMsg, "are required"), strings.Contains(errMsg, "invalid"), strings.Contains(errMsg, "no fields to update"):
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "VALIDATION_ERROR",
+				"message": "Request validation failed",
+				"details": errMsg,
+			},
+		})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INTERNAL_SERVER_ERROR",
+				"message": "Failed to process guardian request",
+			},
+		})
+	}
+}
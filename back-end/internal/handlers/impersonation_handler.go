@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"context"
+	"mowesport/internal/config"
+	"mowesport/internal/database"
+	"mowesport/internal/models"
+	"mowesport/internal/services"
+	"net/http"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type ImpersonationHandler struct {
+	impersonationService *services.ImpersonationService
+	validator            *validator.Validate
+}
+
+func NewImpersonationHandler(db *database.Database, cfg *config.Config) *ImpersonationHandler {
+	return &ImpersonationHandler{
+		impersonationService: services.NewImpersonationService(db, cfg),
+		validator:            validator.New(),
+	}
+}
+
+// StartImpersonation handles POST /api/admin/impersonate/:id (Super Admin only)
+func (h *ImpersonationHandler) StartImpersonation(c echo.Context) error {
+	requesterID, _, ok := requesterFromToken(c)
+	if !ok {
+		return nil
+	}
+
+	subjectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_USER_ID",
+				"message": "Invalid user ID format",
+			},
+		})
+	}
+
+	var req models.ImpersonationRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_REQUEST_BODY",
+				"message": "Invalid request body format",
+			},
+		})
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "VALIDATION_ERROR",
+				"message": "A reason for the impersonation is required",
+				"details": validationErrorDetails(err),
+			},
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	session, err := h.impersonationService.Start(ctx, requesterID, subjectID, req.Reason)
+	if err != nil {
+		errMsg := err.Error()
+		switch {
+		case contains(errMsg, "impersonation disabled"):
+			return c.JSON(http.StatusForbidden, map[string]interface{}{
+				"success": false,
+				"error": map[string]interface{}{
+					"code":    "IMPERSONATION_DISABLED",
+					"message": "Impersonation is disabled",
+				},
+			})
+		case contains(errMsg, "user not found"):
+			return c.JSON(http.StatusNotFound, map[string]interface{}{
+				"success": false,
+				"error": map[string]interface{}{
+					"code":    "USER_NOT_FOUND",
+					"message": "User not found",
+				},
+			})
+		case contains(errMsg, "cannot impersonate"):
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"success": false,
+				"error": map[string]interface{}{
+					"code":    "IMPERSONATION_NOT_ALLOWED",
+					"message": "This user cannot be impersonated",
+					"details": errMsg,
+				},
+			})
+		default:
+			return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+				"success": false,
+				"error": map[string]interface{}{
+					"code":    "INTERNAL_SERVER_ERROR",
+					"message": "Failed to start impersonation",
+				},
+			})
+		}
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    session,
+	})
+}
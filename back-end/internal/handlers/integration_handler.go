@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"context"
+	"mowesport/internal/database"
+	"mowesport/internal/models"
+	"mowesport/internal/services"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// IntegrationHandler serves machine integrations (scoreboards, overlays, scorer tablets)
+// authenticated with API keys
+type IntegrationHandler struct {
+	matchService *services.MatchService
+	validator    *validator.Validate
+}
+
+func NewIntegrationHandler(db *database.Database) *IntegrationHandler {
+	return &IntegrationHandler{
+		matchService: services.NewMatchService(db),
+		validator:    validator.New(),
+	}
+}
+
+// ListMatches handles GET /api/integrations/matches (matches:read)
+func (h *IntegrationHandler) ListMatches(c echo.Context) error {
+	key, ok := apiKeyFromContext(c)
+	if !ok {
+		return nil
+	}
+
+	var req models.MatchListRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_QUERY_PARAMS",
+				"message": "Invalid query parameters",
+				"details": err.Error(),
+			},
+		})
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "VALIDATION_ERROR",
+				"message": "Query parameter validation failed",
+				"details": validationErrorDetails(err),
+			},
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	matches, err := h.matchService.ListMatches(ctx, &req, key)
+	if err != nil {
+		return h.handleMatchError(c, err, "Failed to retrieve matches")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    matches,
+	})
+}
+
+// GetMatch handles GET /api/integrations/matches/:matchId (matches:read, within the key's scope)
+func (h *IntegrationHandler) GetMatch(c echo.Context) error {
+	matchID, err := uuid.Parse(c.Param("matchId"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_MATCH_ID",
+				"message": "Invalid match ID format",
+			},
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	match, err := h.matchService.GetMatch(ctx, matchID)
+	if err != nil {
+		return h.handleMatchError(c, err, "Failed to retrieve match")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    match,
+	})
+}
+
+// CreateMatchEvent handles POST /api/integrations/matches/:matchId/events (events:write,
+// within the key's scope)
+func (h *IntegrationHandler) CreateMatchEvent(c echo.Context) error {
+	key, ok := apiKeyFromContext(c)
+	if !ok {
+		return nil
+	}
+
+	matchID, err := uuid.Parse(c.Param("matchId"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_MATCH_ID",
+				"message": "Invalid match ID format",
+			},
+		})
+	}
+
+	var req models.MatchEventCreateRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_REQUEST_BODY",
+				"message": "Invalid request body format",
+			},
+		})
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "VALIDATION_ERROR",
+				"message": "Request validation failed",
+				"details": validationErrorDetails(err),
+			},
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	event, err := h.matchService.RecordEvent(ctx, matchID, &req, key)
+	if err != nil {
+		return h.handleMatchError(c, err, "Failed to record match event")
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"success": true,
+		"data":    event,
+	})
+}
+
+// handleMatchError maps match service errors to responses
+func (h *IntegrationHandler) handleMatchError(c echo.Context, err error, fallback string) error {
+	errMsg := err.Error()
+
+	switch {
+	case strings.Contains(errMsg, "match not found"):
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "MATCH_NOT_FOUND",
+				"message": "Match not found",
+			},
+		})
+	case strings.Contains(errMsg, "not in progress"):
+		return c.JSON(http.StatusConflict, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "MATCH_NOT_IN_PROGRESS",
+				"message": "Events can only be recorded while the match is live or at half time",
+			},
+		})
+	case strings.HasPrefix(errMsg, "invalid "):
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_REQUEST",
+				"message": errMsg,
+			},
+		})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INTERNAL_SERVER_ERROR",
+				"message": fallback,
+			},
+		})
+	}
+}
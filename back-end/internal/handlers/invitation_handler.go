@@ -0,0 +1,276 @@
+package handlers
+
+import (
+	"context"
+	"mowesport/internal/config"
+	"mowesport/internal/database"
+	"mowesport/internal/models"
+	"mowesport/internal/services"
+	"net/http"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type InvitationHandler struct {
+	invitationService *services.InvitationService
+	validator         *validator.Validate
+}
+
+func NewInvitationHandler(db *database.Database, cfg *config.Config) *InvitationHandler {
+	return &InvitationHandler{
+		invitationService: services.NewInvitationService(db, cfg),
+		validator:         validator.New(),
+	}
+}
+
+// GetInvitation handles GET /api/auth/invitations/:token
+func (h *InvitationHandler) GetInvitation(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	details, err := h.invitationService.GetInvitation(ctx, c.Param("token"))
+	if err != nil {
+		return h.handleInvitationError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    details,
+	})
+}
+
+// AcceptInvitation handles POST /api/auth/invitations/accept
+func (h *InvitationHandler) AcceptInvitation(c echo.Context) error {
+	var req models.AcceptInvitationRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_REQUEST_BODY",
+				"message": "Invalid request body format",
+			},
+		})
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "VALIDATION_ERROR",
+				"message": "Request validation failed",
+				"details": validationErrorDetails(err),
+			},
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	response, err := h.invitationService.AcceptInvitation(ctx, &req)
+	if err != nil {
+		return h.handleInvitationError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// ListInvitations handles GET /api/admin/invitations
+func (h *InvitationHandler) ListInvitations(c echo.Context) error {
+	requesterID, requesterRole, ok := requesterFromToken(c)
+	if !ok {
+		return nil
+	}
+
+	var req models.InvitationListRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_QUERY_PARAMS",
+				"message": "Invalid query parameters",
+			},
+		})
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "VALIDATION_ERROR",
+				"message": "Request validation failed",
+				"details": validationErrorDetails(err),
+			},
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	invitations, err := h.invitationService.ListInvitations(ctx, &req, requesterID, requesterRole)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INTERNAL_SERVER_ERROR",
+				"message": "Failed to retrieve invitations",
+				"details": err.Error(),
+			},
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    invitations,
+	})
+}
+
+// ResendInvitation handles POST /api/admin/invitations/:id/resend
+func (h *InvitationHandler) ResendInvitation(c echo.Context) error {
+	requesterID, requesterRole, ok := requesterFromToken(c)
+	if !ok {
+		return nil
+	}
+
+	invitationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_INVITATION_ID",
+				"message": "Invalid invitation ID format",
+			},
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 30*time.Second)
+	defer cancel()
+
+	invitation, err := h.invitationService.ResendInvitation(ctx, invitationID, requesterID, requesterRole)
+	if err != nil {
+		return h.handleInvitationError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    invitation,
+		"message": "Invitation sent again",
+	})
+}
+
+// RevokeInvitation handles DELETE /api/admin/invitations/:id
+func (h *InvitationHandler) RevokeInvitation(c echo.Context) error {
+	requesterID, requesterRole, ok := requesterFromToken(c)
+	if !ok {
+		return nil
+	}
+
+	invitationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_INVITATION_ID",
+				"message": "Invalid invitation ID format",
+			},
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	if err := h.invitationService.RevokeInvitation(ctx, invitationID, requesterID, requesterRole); err != nil {
+		return h.handleInvitationError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Invitation revoked successfully",
+	})
+}
+
+// handleInvitationError maps invitation service errors to responses
+func (h *InvitationHandler) handleInvitationError(c echo.Context, err error) error {
+	errMsg := err.Error()
+
+	switch {
+	case contains(errMsg, "invalid or expired invitation"), contains(errMsg, "no longer pending"):
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_INVITATION",
+				"message": "Invalid, used, revoked or expired invitation",
+			},
+		})
+	case contains(errMsg, "weak password"):
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "WEAK_PASSWORD",
+				"message": "Password does not meet security requirements",
+				"details": errMsg,
+			},
+		})
+	case contains(errMsg, "breached password"):
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "PASSWORD_BREACHED",
+				"message": "Password is too common or has appeared in a data breach",
+			},
+		})
+	case contains(errMsg, "invitation not found"):
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVITATION_NOT_FOUND",
+				"message": "Invitation not found",
+			},
+		})
+	case contains(errMsg, "insufficient permissions"):
+		return c.JSON(http.StatusForbidden, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INSUFFICIENT_PERMISSIONS",
+				"message": "You can only manage invitations within your own city/sport",
+			},
+		})
+	case contains(errMsg, "already accepted"):
+		return c.JSON(http.StatusConflict, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVITATION_ALREADY_ACCEPTED",
+				"message": "Invitation has already been accepted",
+			},
+		})
+	case contains(errMsg, "already revoked"):
+		return c.JSON(http.StatusConflict, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVITATION_ALREADY_REVOKED",
+				"message": "Invitation has already been revoked",
+			},
+		})
+	case contains(errMsg, "resend throttled"):
+		return c.JSON(http.StatusTooManyRequests, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "RESEND_THROTTLED",
+				"message": "Invitation was sent recently, please try again later",
+			},
+		})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVITATION_ERROR",
+				"message": "Failed to process invitation",
+			},
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"context"
+	"mowesport/internal/config"
+	"mowesport/internal/database"
+	"mowesport/internal/models"
+	"mowesport/internal/services"
+	"net/http"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type IPBlockHandler struct {
+	loginProtection *services.LoginProtectionService
+	validator       *validator.Validate
+}
+
+func NewIPBlockHandler(db *database.Database, cfg *config.Config) *IPBlockHandler {
+	return &IPBlockHandler{
+		loginProtection: services.NewLoginProtectionService(db, cfg),
+		validator:       validator.New(),
+	}
+}
+
+// ListBlocks handles GET /api/admin/ip-blocks (active blocks by default)
+func (h *IPBlockHandler) ListBlocks(c echo.Context) error {
+	var req models.IPBlockListRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_QUERY_PARAMS",
+				"message": "Invalid query parameters",
+			},
+		})
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "VALIDATION_ERROR",
+				"message": "Request validation failed",
+				"details": validationErrorDetails(err),
+			},
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	blocks, err := h.loginProtection.ListBlocks(ctx, req.Status != "all", req.Limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INTERNAL_SERVER_ERROR",
+				"message": "Failed to retrieve IP blocks",
+				"details": err.Error(),
+			},
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    blocks,
+	})
+}
+
+// LiftBlock handles DELETE /api/admin/ip-blocks/:id
+func (h *IPBlockHandler) LiftBlock(c echo.Context) error {
+	requesterID, _, ok := requesterFromToken(c)
+	if !ok {
+		return nil
+	}
+
+	blockID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_BLOCK_ID",
+				"message": "Invalid block ID format",
+			},
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	if err := h.loginProtection.LiftBlock(ctx, blockID, requesterID); err != nil {
+		if contains(err.Error(), "not found") {
+			return c.JSON(http.StatusNotFound, map[string]interface{}{
+				"success": false,
+				"error": map[string]interface{}{
+					"code":    "BLOCK_NOT_FOUND",
+					"message": "Active IP block not found",
+				},
+			})
+		}
+
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INTERNAL_SERVER_ERROR",
+				"message": "Failed to lift IP block",
+				"details": err.Error(),
+			},
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "IP block lifted",
+	})
+}
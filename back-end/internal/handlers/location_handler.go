@@ -36,7 +36,7 @@ func NewLocationHandler(db *database.Database) *LocationHandler {
 // GetCities handles GET /api/cities
 func (h *LocationHandler) GetCities(c echo.Context) error {
 	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
 	defer cancel()
 
 	// Query cities from database
@@ -94,7 +94,7 @@ func (h *LocationHandler) GetCities(c echo.Context) error {
 // GetSports handles GET /api/sports
 func (h *LocationHandler) GetSports(c echo.Context) error {
 	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
 	defer cancel()
 
 	// Query sports from database
@@ -3,9 +3,12 @@ package handlers
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"mowesport/internal/config"
 	"mowesport/internal/database"
 	"mowesport/internal/services"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/go-playground/validator/v10"
@@ -18,6 +21,7 @@ import (
 type PasswordHandler struct {
 	db                       *database.Database
 	temporaryPasswordService *services.TemporaryPasswordService
+	passwordPolicy           *services.PasswordPolicyService
 	validator                *validator.Validate
 }
 
@@ -32,10 +36,11 @@ type ChangePasswordResponse struct {
 	Success bool   `json:"success"`
 }
 
-func NewPasswordHandler(db *database.Database) *PasswordHandler {
+func NewPasswordHandler(db *database.Database, cfg *config.Config) *PasswordHandler {
 	return &PasswordHandler{
 		db:                       db,
 		temporaryPasswordService: services.NewTemporaryPasswordService(db),
+		passwordPolicy:           services.NewPasswordPolicyService(db, cfg),
 		validator:                validator.New(),
 	}
 }
@@ -94,7 +99,7 @@ func (h *PasswordHandler) ChangePassword(c echo.Context) error {
 	}
 
 	// Get current user data
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
 	defer cancel()
 
 	var currentPasswordHash string
@@ -123,6 +128,20 @@ func (h *PasswordHandler) ChangePassword(c echo.Context) error {
 		})
 	}
 
+	// Apply the password policy, including reuse of recent passwords
+	if err := h.passwordPolicy.ValidateForUser(ctx, userID, req.NewPassword); err != nil {
+		if resp, ok := passwordPolicyErrorResponse(err); ok {
+			return c.JSON(http.StatusBadRequest, resp)
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "DATABASE_ERROR",
+				"message": "Failed to check password history",
+			},
+		})
+	}
+
 	// Hash new password
 	hashedNewPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
 	if err != nil {
@@ -136,13 +155,31 @@ func (h *PasswordHandler) ChangePassword(c echo.Context) error {
 	}
 
 	// Update password and clear temporary password expiration
-	_, err = h.db.GetConnection().Exec(ctx, `
+	tx, err := h.db.GetConnection().Begin(ctx)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "PASSWORD_UPDATE_ERROR",
+				"message": "Failed to update password",
+			},
+		})
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
 		UPDATE user_profiles 
 		SET password_hash = $1, 
 		    token_expiration_date = NULL,
 		    updated_at = NOW()
 		WHERE user_id = $2
 	`, string(hashedNewPassword), userID)
+	if err == nil {
+		err = h.passwordPolicy.RecordChange(ctx, tx, userID, string(hashedNewPassword))
+	}
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
 
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
@@ -159,7 +196,7 @@ func (h *PasswordHandler) ChangePassword(c echo.Context) error {
 	if err != nil {
 		// Log error but don't fail the request
 		// The password was already updated successfully
-		c.Logger().Errorf("Failed to mark temporary password as used: %v", err)
+		slog.ErrorContext(c.Request().Context(), "failed to mark temporary password as used", "error", err)
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
@@ -189,7 +226,7 @@ func (h *PasswordHandler) CheckPasswordStatus(c echo.Context) error {
 		})
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
 	defer cancel()
 
 	// Check if password is temporary
@@ -209,6 +246,27 @@ func (h *PasswordHandler) CheckPasswordStatus(c echo.Context) error {
 		"requires_change": isTemporary,
 	}
 
+	if !isTemporary {
+		// Regular passwords expire after the policy's ExpirationDays
+		var changedAt time.Time
+		err = h.db.GetConnection().QueryRow(ctx,
+			"SELECT password_changed_at FROM user_profiles WHERE user_id = $1",
+			userID,
+		).Scan(&changedAt)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+				"success": false,
+				"error": map[string]interface{}{
+					"code":    "DATABASE_ERROR",
+					"message": "Failed to check password status",
+				},
+			})
+		}
+		response["password_changed_at"] = changedAt
+		expirationDate = h.passwordPolicy.ExpiresAt(changedAt)
+		response["requires_change"] = h.passwordPolicy.IsExpired(changedAt)
+	}
+
 	if expirationDate != nil {
 		response["expires_at"] = expirationDate
 		response["is_expired"] = time.Now().After(*expirationDate)
@@ -224,68 +282,6 @@ func (h *PasswordHandler) CheckPasswordStatus(c echo.Context) error {
 	})
 }
 
-// RegenerateTemporaryPassword handles POST /api/admin/{id}/regenerate-password (Super Admin only)
-func (h *PasswordHandler) RegenerateTemporaryPassword(c echo.Context) error {
-	// Get admin ID from URL parameter
-	adminIDStr := c.Param("id")
-	adminID, err := uuid.Parse(adminIDStr)
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]interface{}{
-			"success": false,
-			"error": map[string]interface{}{
-				"code":    "INVALID_ADMIN_ID",
-				"message": "Invalid administrator ID",
-			},
-		})
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	// Verify the admin exists
-	var adminEmail, firstName, lastName string
-	err = h.db.GetConnection().QueryRow(ctx, `
-		SELECT email, first_name, last_name 
-		FROM user_profiles 
-		WHERE user_id = $1 AND primary_role = 'city_admin'
-	`, adminID).Scan(&adminEmail, &firstName, &lastName)
-
-	if err != nil {
-		return c.JSON(http.StatusNotFound, map[string]interface{}{
-			"success": false,
-			"error": map[string]interface{}{
-				"code":    "ADMIN_NOT_FOUND",
-				"message": "Administrator not found",
-			},
-		})
-	}
-
-	// Generate new temporary password
-	tempPassword, err := h.temporaryPasswordService.RegenerateTemporaryPassword(ctx, adminID, 24)
-	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
-			"success": false,
-			"error": map[string]interface{}{
-				"code":    "PASSWORD_GENERATION_ERROR",
-				"message": "Failed to generate new temporary password",
-			},
-		})
-	}
-
-	// TODO: Send email with new temporary password
-	// For now, return the password in the response (development only)
-
-	return c.JSON(http.StatusOK, map[string]interface{}{
-		"success": true,
-		"data": map[string]interface{}{
-			"message":            "Temporary password regenerated successfully",
-			"temporary_password": tempPassword, // Remove in production
-			"admin_email":        adminEmail,
-			"expires_in_hours":   24,
-		},
-	})
-}
-
 // formatValidationErrors formats validation errors for API response
 func (h *PasswordHandler) formatValidationErrors(err error) map[string]string {
 	errors := make(map[string]string)
@@ -308,3 +304,29 @@ func (h *PasswordHandler) formatValidationErrors(err error) map[string]string {
 
 	return errors
 }
+
+// passwordPolicyErrorResponse maps PasswordPolicyService rejections to a 400 response body
+func passwordPolicyErrorResponse(err error) (map[string]interface{}, bool) {
+	errMsg := err.Error()
+
+	var code, message string
+	switch {
+	case strings.Contains(errMsg, "weak password"):
+		code, message = "WEAK_PASSWORD", "Password does not meet security requirements"
+	case strings.Contains(errMsg, "breached password"):
+		code, message = "PASSWORD_BREACHED", "Password is too common or has appeared in a data breach"
+	case strings.Contains(errMsg, "password reused"):
+		code, message = "PASSWORD_REUSED", "Password was used recently, choose a different one"
+	default:
+		return nil, false
+	}
+
+	return map[string]interface{}{
+		"success": false,
+		"error": map[string]interface{}{
+			"code":    code,
+			"message": message,
+			"details": errMsg,
+		},
+	}, true
+}
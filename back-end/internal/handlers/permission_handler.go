@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"mowesport/internal/database"
+	"mowesport/internal/services"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type PermissionHandler struct {
+	permissionService *services.ViewPermissionService
+}
+
+func NewPermissionHandler(db *database.Database) *PermissionHandler {
+	return &PermissionHandler{
+		permissionService: services.NewViewPermissionService(db),
+	}
+}
+
+// GetMyPermissions handles GET /api/auth/permissions
+func (h *PermissionHandler) GetMyPermissions(c echo.Context) error {
+	requesterID, _, ok := requesterFromToken(c)
+	if !ok {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	permissions, err := h.permissionService.GetEffectivePermissions(ctx, requesterID)
+	if err != nil {
+		if contains(err.Error(), "not found") {
+			return c.JSON(http.StatusNotFound, map[string]interface{}{
+				"success": false,
+				"error": map[string]interface{}{
+					"code":    "USER_NOT_FOUND",
+					"message": "User not found or inactive",
+				},
+			})
+		}
+
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INTERNAL_SERVER_ERROR",
+				"message": "Failed to resolve permissions",
+				"details": err.Error(),
+			},
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    permissions,
+	})
+}
+
+// GetPermissionMatrix handles GET /api/admin/permissions/matrix
+func (h *PermissionHandler) GetPermissionMatrix(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	matrix, err := h.permissionService.GetPermissionMatrix(ctx)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INTERNAL_SERVER_ERROR",
+				"message": "Failed to build permission matrix",
+				"details": err.Error(),
+			},
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    matrix,
+	})
+}
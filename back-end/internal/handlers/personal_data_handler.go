@@ -0,0 +1,348 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"mowesport/internal/config"
+	"mowesport/internal/database"
+	"mowesport/internal/models"
+	"mowesport/internal/services"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type PersonalDataHandler struct {
+	personalDataService *services.PersonalDataService
+	validator           *validator.Validate
+}
+
+func NewPersonalDataHandler(db *database.Database, cfg *config.Config) *PersonalDataHandler {
+	return &PersonalDataHandler{
+		personalDataService: services.NewPersonalDataService(db, cfg),
+		validator:           validator.New(),
+	}
+}
+
+// ExportMyData handles GET /api/auth/personal-data?format=json|zip
+func (h *PersonalDataHandler) ExportMyData(c echo.Context) error {
+	requesterID, _, ok := requesterFromToken(c)
+	if !ok {
+		return nil
+	}
+
+	return h.export(c, requesterID, requesterID, services.DataAccessPurposeSelfExport)
+}
+
+// ExportUserData handles GET /api/users/:id/personal-data, used by admins answering a
+// data-subject request on the user's behalf
+func (h *PersonalDataHandler) ExportUserData(c echo.Context) error {
+	requesterID, _, ok := requesterFromToken(c)
+	if !ok {
+		return nil
+	}
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_USER_ID",
+				"message": "Invalid user ID format",
+			},
+		})
+	}
+
+	return h.export(c, userID, requesterID, services.DataAccessPurposeAdminExport)
+}
+
+func (h *PersonalDataHandler) export(c echo.Context, userID, requesterID uuid.UUID, purpose string) error {
+	format := c.QueryParam("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "zip" {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_FORMAT",
+				"message": "Format must be json or zip",
+			},
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 30*time.Second)
+	defer cancel()
+
+	export, err := h.personalDataService.Export(ctx, userID, requesterID, purpose)
+	if err != nil {
+		return h.handlePersonalDataError(c, err)
+	}
+
+	if format == "json" {
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"success": true,
+			"data":    export,
+		})
+	}
+
+	archive, err := services.ExportArchive(export)
+	if err != nil {
+		return h.handlePersonalDataError(c, err)
+	}
+
+	fileName := fmt.Sprintf("personal-data-%s-%s.zip", userID, export.GeneratedAt.Format("20060102"))
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", fileName))
+	c.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+	return c.Blob(http.StatusOK, "application/zip", archive)
+}
+
+// RequestDeletion handles POST /api/auth/personal-data/deletion
+func (h *PersonalDataHandler) RequestDeletion(c echo.Context) error {
+	requesterID, _, ok := requesterFromToken(c)
+	if !ok {
+		return nil
+	}
+
+	var req models.DataDeletionCreateRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_REQUEST_BODY",
+				"message": "Invalid request body format",
+			},
+		})
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "VALIDATION_ERROR",
+				"message": "Request validation failed",
+				"details": validationErrorDetails(err),
+			},
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	request, err := h.personalDataService.RequestDeletion(ctx, requesterID, req.Reason)
+	if err != nil {
+		return h.handlePersonalDataError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"success": true,
+		"data":    request,
+	})
+}
+
+// GetMyDeletionRequests handles GET /api/auth/personal-data/deletion
+func (h *PersonalDataHandler) GetMyDeletionRequests(c echo.Context) error {
+	requesterID, _, ok := requesterFromToken(c)
+	if !ok {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	requests, err := h.personalDataService.GetDeletionRequests(ctx, requesterID)
+	if err != nil {
+		return h.handlePersonalDataError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    requests,
+	})
+}
+
+// CancelDeletion handles DELETE /api/auth/personal-data/deletion
+func (h *PersonalDataHandler) CancelDeletion(c echo.Context) error {
+	requesterID, _, ok := requesterFromToken(c)
+	if !ok {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	if err := h.personalDataService.CancelDeletion(ctx, requesterID); err != nil {
+		return h.handlePersonalDataError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Deletion request cancelled",
+	})
+}
+
+// ListDeletionRequests handles GET /api/admin/data-deletion-requests
+func (h *PersonalDataHandler) ListDeletionRequests(c echo.Context) error {
+	var req models.DataDeletionListRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_QUERY_PARAMS",
+				"message": "Invalid query parameters",
+			},
+		})
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "VALIDATION_ERROR",
+				"message": "Request validation failed",
+				"details": validationErrorDetails(err),
+			},
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	requests, err := h.personalDataService.ListDeletionRequests(ctx, &req)
+	if err != nil {
+		return h.handlePersonalDataError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    requests,
+	})
+}
+
+// ApproveDeletion handles POST /api/admin/data-deletion-requests/:id/approve
+func (h *PersonalDataHandler) ApproveDeletion(c echo.Context) error {
+	return h.review(c, true)
+}
+
+// RejectDeletion handles POST /api/admin/data-deletion-requests/:id/reject
+func (h *PersonalDataHandler) RejectDeletion(c echo.Context) error {
+	return h.review(c, false)
+}
+
+func (h *PersonalDataHandler) review(c echo.Context, approve bool) error {
+	requesterID, _, ok := requesterFromToken(c)
+	if !ok {
+		return nil
+	}
+
+	requestID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_REQUEST_ID",
+				"message": "Invalid deletion request ID format",
+			},
+		})
+	}
+
+	var req models.DataDeletionReviewRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_REQUEST_BODY",
+				"message": "Invalid request body format",
+			},
+		})
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "VALIDATION_ERROR",
+				"message": "Request validation failed",
+				"details": validationErrorDetails(err),
+			},
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	var request *models.DataDeletionRequest
+	if approve {
+		request, err = h.personalDataService.ApproveDeletion(ctx, requestID, requesterID, req.Notes)
+	} else {
+		request, err = h.personalDataService.RejectDeletion(ctx, requestID, requesterID, req.Notes)
+	}
+	if err != nil {
+		return h.handlePersonalDataError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    request,
+	})
+}
+
+func (h *PersonalDataHandler) handlePersonalDataError(c echo.Context, err error) error {
+	errMsg := err.Error()
+
+	switch {
+	case strings.Contains(errMsg, "user not found"):
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "USER_NOT_FOUND",
+				"message": "User not found",
+			},
+		})
+	case strings.Contains(errMsg, "deletion request not found"), strings.Contains(errMsg, "no open deletion request"):
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "DELETION_REQUEST_NOT_FOUND",
+				"message": errMsg,
+			},
+		})
+	case strings.Contains(errMsg, "already open"), strings.Contains(errMsg, "already anonymized"), strings.Contains(errMsg, "not pending"):
+		return c.JSON(http.StatusConflict, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "DELETION_REQUEST_CONFLICT",
+				"message": errMsg,
+			},
+		})
+	case strings.Contains(errMsg, "insufficient permissions"), strings.Contains(errMsg, "handed over"):
+		return c.JSON(http.StatusForbidden, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INSUFFICIENT_PERMISSIONS",
+				"message": errMsg,
+			},
+		})
+	case strings.Contains(errMsg, "notes are required"):
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "VALIDATION_ERROR",
+				"message": "Request validation failed",
+				"details": map[string]string{"notes": "Notes are required to reject a deletion request"},
+			},
+		})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INTERNAL_SERVER_ERROR",
+				"message": "Failed to process personal data request",
+			},
+		})
+	}
+}
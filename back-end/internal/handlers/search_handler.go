@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"context"
+	"mowesport/internal/database"
+	"mowesport/internal/models"
+	"mowesport/internal/services"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type SearchHandler struct {
+	searchService *services.SearchService
+	validator     *validator.Validate
+}
+
+func NewSearchHandler(db *database.Database) *SearchHandler {
+	return &SearchHandler{
+		searchService: services.NewSearchService(db),
+		validator:     validator.New(),
+	}
+}
+
+// Search handles GET /api/search. Authentication is optional: anonymous callers and API
+// keys get public results only.
+func (h *SearchHandler) Search(c echo.Context) error {
+	var req models.SearchRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_QUERY_PARAMS",
+				"message": "Invalid query parameters",
+				"details": err.Error(),
+			},
+		})
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "VALIDATION_ERROR",
+				"message": "Query parameter validation failed",
+				"details": validationErrorDetails(err),
+			},
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	response, err := h.searchService.Search(ctx, &req, searchCaller(c))
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid ") || strings.Contains(err.Error(), "is required") {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"success": false,
+				"error": map[string]interface{}{
+					"code":    "INVALID_SEARCH",
+					"message": err.Error(),
+				},
+			})
+		}
+		if strings.Contains(err.Error(), "not found or inactive") {
+			return c.JSON(http.StatusUnauthorized, map[string]interface{}{
+				"success": false,
+				"error": map[string]interface{}{
+					"code":    "USER_NOT_FOUND",
+					"message": "User not found or inactive",
+				},
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INTERNAL_SERVER_ERROR",
+				"message": "Failed to search",
+			},
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// searchCaller returns the signed-in user, or nil for anonymous callers and API keys
+func searchCaller(c echo.Context) *uuid.UUID {
+	token, ok := c.Get("user").(*jwt.Token)
+	if !ok {
+		return nil
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil
+	}
+	if method, _ := claims["auth_method"].(string); method == "api_key" {
+		return nil
+	}
+	userIDStr, _ := claims["user_id"].(string)
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return nil
+	}
+	return &userID
+}
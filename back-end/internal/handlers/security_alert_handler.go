@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"context"
+	"mowesport/internal/config"
+	"mowesport/internal/database"
+	"mowesport/internal/models"
+	"mowesport/internal/services"
+	"net/http"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type SecurityAlertHandler struct {
+	alertService *services.SecurityAlertService
+	validator    *validator.Validate
+}
+
+func NewSecurityAlertHandler(db *database.Database, cfg *config.Config) *SecurityAlertHandler {
+	return &SecurityAlertHandler{
+		alertService: services.NewSecurityAlertService(db, cfg),
+		validator:    validator.New(),
+	}
+}
+
+// ListAlerts handles GET /api/admin/security-alerts, the in-app alert list
+func (h *SecurityAlertHandler) ListAlerts(c echo.Context) error {
+	var req models.SecurityAlertListRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_QUERY_PARAMS",
+				"message": "Invalid query parameters",
+			},
+		})
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "VALIDATION_ERROR",
+				"message": "Request validation failed",
+				"details": validationErrorDetails(err),
+			},
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	alerts, err := h.alertService.ListAlerts(ctx, req.Status, req.Limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INTERNAL_SERVER_ERROR",
+				"message": "Failed to retrieve security alerts",
+				"details": err.Error(),
+			},
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    alerts,
+	})
+}
+
+// AcknowledgeAlert handles POST /api/admin/security-alerts/:id/acknowledge
+func (h *SecurityAlertHandler) AcknowledgeAlert(c echo.Context) error {
+	requesterID, _, ok := requesterFromToken(c)
+	if !ok {
+		return nil
+	}
+
+	alertID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_ALERT_ID",
+				"message": "Invalid alert ID format",
+			},
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	alert, err := h.alertService.AcknowledgeAlert(ctx, alertID, requesterID)
+	if err != nil {
+		switch {
+		case contains(err.Error(), "not found"):
+			return c.JSON(http.StatusNotFound, map[string]interface{}{
+				"success": false,
+				"error": map[string]interface{}{
+					"code":    "ALERT_NOT_FOUND",
+					"message": "Security alert not found",
+				},
+			})
+		case contains(err.Error(), "already acknowledged"):
+			return c.JSON(http.StatusConflict, map[string]interface{}{
+				"success": false,
+				"error": map[string]interface{}{
+					"code":    "ALERT_ALREADY_ACKNOWLEDGED",
+					"message": "Security alert was already acknowledged",
+				},
+			})
+		default:
+			return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+				"success": false,
+				"error": map[string]interface{}{
+					"code":    "INTERNAL_SERVER_ERROR",
+					"message": "Failed to acknowledge security alert",
+					"details": err.Error(),
+				},
+			})
+		}
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    alert,
+	})
+}
@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"mowesport/internal/config"
+	"mowesport/internal/database"
+	"mowesport/internal/models"
+	"mowesport/internal/services"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type UserImportHandler struct {
+	importService *services.UserImportService
+}
+
+func NewUserImportHandler(db *database.Database, cfg *config.Config) *UserImportHandler {
+	return &UserImportHandler{
+		importService: services.NewUserImportService(db, cfg),
+	}
+}
+
+// ImportUsers handles POST /api/users/import (multipart form).
+// Fields: file (.csv or .xlsx), city_id, sport_id, tournament_id, role (for rows without
+// a role column), dry_run (default true), mode (atomic or partial), resume_import_id.
+func (h *UserImportHandler) ImportUsers(c echo.Context) error {
+	requesterID, _, ok := requesterFromToken(c)
+	if !ok {
+		return nil
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_REQUEST_BODY",
+				"message": "A .csv or .xlsx file is required in the file field",
+			},
+		})
+	}
+	if fileHeader.Size > services.UserImportMaxFileSize {
+		return c.JSON(http.StatusRequestEntityTooLarge, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "FILE_TOO_LARGE",
+				"message": "Import files are limited to 5 MB",
+			},
+		})
+	}
+
+	req := models.UserImportRequest{
+		FileName: fileHeader.Filename,
+		Role:     strings.ToLower(strings.TrimSpace(c.FormValue("role"))),
+		DryRun:   c.FormValue("dry_run") != "false",
+		Mode:     c.FormValue("mode"),
+	}
+
+	invalidField := func(field string) error {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "VALIDATION_ERROR",
+				"message": "Request validation failed",
+				"details": map[string]string{field: "Invalid UUID format"},
+			},
+		})
+	}
+
+	if resumeID := c.FormValue("resume_import_id"); resumeID != "" {
+		id, err := uuid.Parse(resumeID)
+		if err != nil {
+			return invalidField("resume_import_id")
+		}
+		req.ResumeImportID = &id
+	} else {
+		if req.CityID, err = uuid.Parse(c.FormValue("city_id")); err != nil {
+			return invalidField("city_id")
+		}
+		if req.SportID, err = uuid.Parse(c.FormValue("sport_id")); err != nil {
+			return invalidField("sport_id")
+		}
+		if tournamentID := c.FormValue("tournament_id"); tournamentID != "" {
+			id, err := uuid.Parse(tournamentID)
+			if err != nil {
+				return invalidField("tournament_id")
+			}
+			req.TournamentID = &id
+		}
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_REQUEST_BODY",
+				"message": "Unable to read the uploaded file",
+			},
+		})
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, services.UserImportMaxFileSize+1))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_REQUEST_BODY",
+				"message": "Unable to read the uploaded file",
+			},
+		})
+	}
+
+	// Committing registers every row and queues its invitation
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Minute)
+	defer cancel()
+
+	report, err := h.importService.Import(ctx, &req, data, requesterID)
+	if err != nil {
+		return h.handleImportError(c, err)
+	}
+
+	if !report.DryRun && report.ImportID == nil && (report.InvalidRows > 0 || report.FailedRows > 0) {
+		return c.JSON(http.StatusUnprocessableEntity, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "IMPORT_NOT_COMMITTED",
+				"message": "The import has invalid or failing rows and nothing was committed; fix them or use partial mode",
+				"details": report,
+			},
+		})
+	}
+
+	status := http.StatusOK
+	if report.ImportID != nil {
+		status = http.StatusCreated
+	}
+	return c.JSON(status, map[string]interface{}{
+		"success": true,
+		"data":    report,
+	})
+}
+
+// GetImport handles GET /api/users/import/:importId
+func (h *UserImportHandler) GetImport(c echo.Context) error {
+	requesterID, requesterRole, ok := requesterFromToken(c)
+	if !ok {
+		return nil
+	}
+
+	importID, err := uuid.Parse(c.Param("importId"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_IMPORT_ID",
+				"message": "Invalid import ID format",
+			},
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	report, err := h.importService.GetImport(ctx, importID, requesterID, requesterRole)
+	if err != nil {
+		return h.handleImportError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    report,
+	})
+}
+
+func (h *UserImportHandler) handleImportError(c echo.Context, err error) error {
+	errMsg := err.Error()
+
+	switch {
+	case strings.Contains(errMsg, "invalid file"), strings.Contains(errMsg, "invalid mode"), strings.Contains(errMsg, "invalid role"):
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_IMPORT_FILE",
+				"message": errMsg,
+			},
+		})
+	case strings.Contains(errMsg, "import not found"):
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "IMPORT_NOT_FOUND",
+				"message": "Import not found",
+			},
+		})
+	case strings.Contains(errMsg, "import already completed"):
+		return c.JSON(http.StatusConflict, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "IMPORT_COMPLETED",
+				"message": "Import already completed, there is nothing to resume",
+			},
+		})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "IMPORT_ERROR",
+				"message": "Failed to import users",
+			},
+		})
+	}
+}
@@ -3,10 +3,14 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"log/slog"
+	"mowesport/internal/config"
 	"mowesport/internal/database"
 	"mowesport/internal/models"
 	"mowesport/internal/services"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,18 +18,27 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type UserManagementHandler struct {
-	userService *services.UserManagementService
-	validator   *validator.Validate
+	userService       *services.UserManagementService
+	adminService      *services.AdminService
+	invitationService *services.InvitationService
+	delegation        *services.DelegationService
+	securityValidator *services.SecurityValidationService
+	fieldEncryption   *services.FieldEncryptionService
+	validator         *validator.Validate
 }
 
-func NewUserManagementHandler(db *database.Database) *UserManagementHandler {
+func NewUserManagementHandler(db *database.Database, cfg *config.Config) *UserManagementHandler {
 	return &UserManagementHandler{
-		userService: services.NewUserManagementService(db),
-		validator:   validator.New(),
+		userService:       services.NewUserManagementService(db, cfg),
+		adminService:      services.NewAdminService(db, cfg),
+		invitationService: services.NewInvitationService(db, cfg),
+		delegation:        services.NewDelegationService(db),
+		securityValidator: services.NewSecurityValidationService(),
+		fieldEncryption:   services.NewFieldEncryptionService(db, cfg),
+		validator:         validator.New(),
 	}
 }
 
@@ -72,7 +85,7 @@ func (h *UserManagementHandler) GetUserProfile(c echo.Context) error {
 	}
 
 	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
 	defer cancel()
 
 	// Get user profile
@@ -183,7 +196,7 @@ func (h *UserManagementHandler) UpdateUserProfile(c echo.Context) error {
 	}
 
 	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 30*time.Second)
 	defer cancel()
 
 	// Update user profile
@@ -209,6 +222,17 @@ func (h *UserManagementHandler) UpdateUserProfile(c echo.Context) error {
 			})
 		}
 
+		if strings.Contains(err.Error(), "invalid identification format") || strings.Contains(err.Error(), "invalid phone format") {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"success": false,
+				"error": map[string]interface{}{
+					"code":    "VALIDATION_ERROR",
+					"message": "Validation failed",
+					"details": err.Error(),
+				},
+			})
+		}
+
 		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
 			"success": false,
 			"error": map[string]interface{}{
@@ -281,7 +305,7 @@ func (h *UserManagementHandler) GetUserList(c echo.Context) error {
 	}
 
 	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 30*time.Second)
 	defer cancel()
 
 	// Get user list
@@ -297,6 +321,16 @@ func (h *UserManagementHandler) GetUserList(c echo.Context) error {
 			})
 		}
 
+		if strings.Contains(err.Error(), "invalid cursor") {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"success": false,
+				"error": map[string]interface{}{
+					"code":    "INVALID_CURSOR",
+					"message": err.Error(),
+				},
+			})
+		}
+
 		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
 			"success": false,
 			"error": map[string]interface{}{
@@ -313,6 +347,76 @@ func (h *UserManagementHandler) GetUserList(c echo.Context) error {
 	})
 }
 
+// ExportUserList handles GET /api/users/export, streaming the users matching the list
+// filters as CSV
+func (h *UserManagementHandler) ExportUserList(c echo.Context) error {
+	requesterID, _, ok := requesterFromToken(c)
+	if !ok {
+		return nil
+	}
+
+	var req models.UserListRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_QUERY_PARAMS",
+				"message": "Invalid query parameters",
+				"details": err.Error(),
+			},
+		})
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "VALIDATION_ERROR",
+				"message": "Query parameter validation failed",
+				"details": h.formatValidationErrors(err),
+			},
+		})
+	}
+
+	// Large exports take longer than a page
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Minute)
+	defer cancel()
+
+	download := newCSVDownload(c, fmt.Sprintf("users-%s.csv", time.Now().UTC().Format("20060102-150405")), []string{
+		"user_id", "email", "first_name", "last_name", "phone", "primary_role",
+		"account_status", "is_active", "last_login_at", "created_at",
+	})
+	_, err := h.userService.ExportUserList(ctx, &req, requesterID, func(user models.UserSummary) error {
+		return download.Write([]string{
+			user.UserID.String(), user.Email, user.FirstName, user.LastName, csvOptional(user.Phone), user.PrimaryRole,
+			user.AccountStatus, strconv.FormatBool(user.IsActive), csvTimestamp(user.LastLoginAt), csvTimestamp(&user.CreatedAt),
+		})
+	})
+	if err != nil {
+		if download.Started() {
+			// Too late for an error response; the truncated file is all the client gets
+			slog.ErrorContext(c.Request().Context(), "user export interrupted", "error", err)
+			return nil
+		}
+		if strings.Contains(err.Error(), "insufficient permissions") {
+			return c.JSON(http.StatusForbidden, map[string]interface{}{
+				"success": false,
+				"error": map[string]interface{}{
+					"code":    "INSUFFICIENT_PERMISSIONS",
+					"message": "Admin permissions required",
+				},
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INTERNAL_SERVER_ERROR",
+				"message": "Failed to export user list",
+			},
+		})
+	}
+	return download.Finish()
+}
+
 // AssignUserRole handles POST /api/users/roles
 func (h *UserManagementHandler) AssignUserRole(c echo.Context) error {
 	// Extract user from JWT token
@@ -369,20 +473,14 @@ func (h *UserManagementHandler) AssignUserRole(c echo.Context) error {
 	}
 
 	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 30*time.Second)
 	defer cancel()
 
 	// Assign role
 	roleAssignment, err := h.userService.AssignUserRole(ctx, &req, requesterID)
 	if err != nil {
-		if strings.Contains(err.Error(), "insufficient permissions") {
-			return c.JSON(http.StatusForbidden, map[string]interface{}{
-				"success": false,
-				"error": map[string]interface{}{
-					"code":    "INSUFFICIENT_PERMISSIONS",
-					"message": "Admin permissions required",
-				},
-			})
+		if status, resp, ok := delegationErrorResponse(err); ok {
+			return c.JSON(status, resp)
 		}
 
 		if strings.Contains(err.Error(), "user not found") {
@@ -440,6 +538,7 @@ func (h *UserManagementHandler) AssignUserRole(c echo.Context) error {
 		UserID:           roleAssignment.UserID,
 		CityID:           roleAssignment.CityID,
 		SportID:          roleAssignment.SportID,
+		TournamentID:     roleAssignment.TournamentID,
 		RoleName:         roleAssignment.RoleName,
 		AssignedByUserID: roleAssignment.AssignedByUserID,
 		IsActive:         roleAssignment.IsActive,
@@ -496,7 +595,7 @@ func (h *UserManagementHandler) RevokeUserRole(c echo.Context) error {
 	}
 
 	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 30*time.Second)
 	defer cancel()
 
 	// Revoke role
@@ -507,7 +606,7 @@ func (h *UserManagementHandler) RevokeUserRole(c echo.Context) error {
 				"success": false,
 				"error": map[string]interface{}{
 					"code":    "INSUFFICIENT_PERMISSIONS",
-					"message": "Admin permissions required",
+					"message": "You cannot revoke this role in its city/sport/tournament",
 				},
 			})
 		}
@@ -593,7 +692,7 @@ func (h *UserManagementHandler) GetUserRoles(c echo.Context) error {
 	}
 
 	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
 	defer cancel()
 
 	// Get user roles
@@ -693,7 +792,7 @@ func (h *UserManagementHandler) SetViewPermission(c echo.Context) error {
 	}
 
 	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 30*time.Second)
 	defer cancel()
 
 	// Set view permission
@@ -709,6 +808,18 @@ func (h *UserManagementHandler) SetViewPermission(c echo.Context) error {
 			})
 		}
 
+		if strings.Contains(err.Error(), "unknown view") || strings.Contains(err.Error(), "invalid role name") ||
+			strings.Contains(err.Error(), "user_id or role_name") || strings.Contains(err.Error(), "both user_id and role_name") {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"success": false,
+				"error": map[string]interface{}{
+					"code":    "INVALID_VIEW_PERMISSION",
+					"message": "Invalid view permission request",
+					"details": err.Error(),
+				},
+			})
+		}
+
 		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
 			"success": false,
 			"error": map[string]interface{}{
@@ -806,11 +917,11 @@ func (h *UserManagementHandler) UpdateAccountStatus(c echo.Context) error {
 	}
 
 	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 30*time.Second)
 	defer cancel()
 
 	// Update account status
-	err = h.userService.UpdateAccountStatus(ctx, userID, req.Status, req.Reason, requesterID)
+	change, err := h.userService.UpdateAccountStatus(ctx, userID, req.Status, req.Reason, requesterID)
 	if err != nil {
 		if strings.Contains(err.Error(), "insufficient permissions") {
 			return c.JSON(http.StatusForbidden, map[string]interface{}{
@@ -854,12 +965,343 @@ func (h *UserManagementHandler) UpdateAccountStatus(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"success": true,
-		"data": map[string]interface{}{
-			"message": "Account status updated successfully",
-		},
+		"data":    change,
+		"message": "Account status updated successfully",
+	})
+}
+
+// ScheduleReactivation handles POST /api/users/:id/status/reactivation
+func (h *UserManagementHandler) ScheduleReactivation(c echo.Context) error {
+	requesterID, _, ok := requesterFromToken(c)
+	if !ok {
+		return nil
+	}
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_USER_ID",
+				"message": "Invalid user ID format",
+			},
+		})
+	}
+
+	var req models.AccountReactivationRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_REQUEST_BODY",
+				"message": "Invalid request body format",
+			},
+		})
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "VALIDATION_ERROR",
+				"message": "Request validation failed",
+				"details": h.formatValidationErrors(err),
+			},
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	schedule, err := h.userService.ScheduleReactivation(ctx, userID, &req, requesterID)
+	if err != nil {
+		return h.handleStatusScheduleError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"success": true,
+		"data":    schedule,
+	})
+}
+
+// CancelScheduledReactivation handles DELETE /api/users/:id/status/reactivation
+func (h *UserManagementHandler) CancelScheduledReactivation(c echo.Context) error {
+	requesterID, _, ok := requesterFromToken(c)
+	if !ok {
+		return nil
+	}
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_USER_ID",
+				"message": "Invalid user ID format",
+			},
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	if err := h.userService.CancelScheduledReactivation(ctx, userID, requesterID); err != nil {
+		return h.handleStatusScheduleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Scheduled reactivation cancelled",
+	})
+}
+
+// GetStatusSchedules handles GET /api/users/:id/status/schedules
+func (h *UserManagementHandler) GetStatusSchedules(c echo.Context) error {
+	requesterID, _, ok := requesterFromToken(c)
+	if !ok {
+		return nil
+	}
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_USER_ID",
+				"message": "Invalid user ID format",
+			},
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	schedules, err := h.userService.GetStatusSchedules(ctx, userID, requesterID)
+	if err != nil {
+		return h.handleStatusScheduleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    schedules,
+	})
+}
+
+// GetAccountStatusCapabilities handles GET /api/users/status/capabilities
+func (h *UserManagementHandler) GetAccountStatusCapabilities(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    models.AllAccountStatusCapabilities(),
+	})
+}
+
+// LockAccount handles POST /api/users/:id/lock
+func (h *UserManagementHandler) LockAccount(c echo.Context) error {
+	requesterID, _, ok := requesterFromToken(c)
+	if !ok {
+		return nil
+	}
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_USER_ID",
+				"message": "Invalid user ID format",
+			},
+		})
+	}
+
+	var req models.AccountLockRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_REQUEST_BODY",
+				"message": "Invalid request body format",
+			},
+		})
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "VALIDATION_ERROR",
+				"message": "Request validation failed",
+				"details": h.formatValidationErrors(err),
+			},
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	status, err := h.userService.LockAccount(ctx, userID, time.Duration(req.DurationMinutes)*time.Minute, req.Reason, requesterID)
+	if err != nil {
+		return h.handleAccountLockError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    status,
+	})
+}
+
+// UnlockAccount handles POST /api/users/:id/unlock
+func (h *UserManagementHandler) UnlockAccount(c echo.Context) error {
+	requesterID, _, ok := requesterFromToken(c)
+	if !ok {
+		return nil
+	}
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_USER_ID",
+				"message": "Invalid user ID format",
+			},
+		})
+	}
+
+	// The body is optional; a reason is recorded when given
+	var req models.AccountUnlockRequest
+	if c.Request().ContentLength > 0 {
+		if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"success": false,
+				"error": map[string]interface{}{
+					"code":    "INVALID_REQUEST_BODY",
+					"message": "Invalid request body format",
+				},
+			})
+		}
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "VALIDATION_ERROR",
+				"message": "Request validation failed",
+				"details": h.formatValidationErrors(err),
+			},
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	status, err := h.userService.UnlockAccount(ctx, userID, req.Reason, requesterID)
+	if err != nil {
+		return h.handleAccountLockError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    status,
 	})
 }
 
+// handleAccountLockError maps lock and unlock service errors to responses
+func (h *UserManagementHandler) handleAccountLockError(c echo.Context, err error) error {
+	errMsg := err.Error()
+
+	switch {
+	case strings.Contains(errMsg, "insufficient permissions"):
+		return c.JSON(http.StatusForbidden, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INSUFFICIENT_PERMISSIONS",
+				"message": "Admin permissions required",
+			},
+		})
+	case strings.Contains(errMsg, "user not found"):
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "USER_NOT_FOUND",
+				"message": "User not found",
+			},
+		})
+	case strings.Contains(errMsg, "cannot lock your own account"):
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "CANNOT_LOCK_SELF",
+				"message": "You cannot lock your own account",
+			},
+		})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INTERNAL_SERVER_ERROR",
+				"message": "Failed to update account lock",
+			},
+		})
+	}
+}
+
+// handleStatusScheduleError maps scheduled status change errors to responses
+func (h *UserManagementHandler) handleStatusScheduleError(c echo.Context, err error) error {
+	errMsg := err.Error()
+
+	switch {
+	case strings.Contains(errMsg, "insufficient permissions"):
+		return c.JSON(http.StatusForbidden, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INSUFFICIENT_PERMISSIONS",
+				"message": "Admin permissions required",
+			},
+		})
+	case strings.Contains(errMsg, "user not found"):
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "USER_NOT_FOUND",
+				"message": "User not found",
+			},
+		})
+	case strings.Contains(errMsg, "must be in the future"):
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_SCHEDULE_TIME",
+				"message": "Scheduled time must be in the future",
+			},
+		})
+	case strings.Contains(errMsg, "account already"):
+		return c.JSON(http.StatusConflict, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "ACCOUNT_ALREADY_ACTIVE",
+				"message": "Account is already active",
+			},
+		})
+	case strings.Contains(errMsg, "no pending scheduled"):
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "SCHEDULE_NOT_FOUND",
+				"message": "No pending scheduled reactivation",
+			},
+		})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INTERNAL_SERVER_ERROR",
+				"message": "Failed to process scheduled status change",
+			},
+		})
+	}
+}
+
 // Helper method to format validation errors
 func (h *UserManagementHandler) formatValidationErrors(err error) map[string]string {
 	validationErrors := make(map[string]string)
@@ -889,7 +1331,7 @@ func (h *UserManagementHandler) formatValidationErrors(err error) map[string]str
 
 // Hierarchical User Registration Methods
 
-// RegisterCityAdmin handles POST /api/users/register/city-admin (Super Admin only)
+// RegisterCityAdmin handles POST /api/users/register/city-admin (per the delegation matrix)
 func (h *UserManagementHandler) RegisterCityAdmin(c echo.Context) error {
 	// Extract user from JWT token
 	user := c.Get("user").(*jwt.Token)
@@ -942,9 +1384,43 @@ func (h *UserManagementHandler) RegisterCityAdmin(c echo.Context) error {
 		})
 	}
 
+	// City admins are created under the delegation matrix like every other role
+	cityID, err := uuid.Parse(req.CityID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_CITY_ID",
+				"message": "Invalid city ID format",
+			},
+		})
+	}
+	sportID, err := uuid.Parse(req.SportID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_SPORT_ID",
+				"message": "Invalid sport ID format",
+			},
+		})
+	}
+	scope := services.ResourceScope{CityID: &cityID, SportID: &sportID}
+	if err := h.delegation.Authorize(c.Request().Context(), requesterID, services.DelegationCreate, models.RoleCityAdmin, scope); err != nil {
+		if status, resp, ok := delegationErrorResponse(err); ok {
+			return c.JSON(status, resp)
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "REGISTRATION_ERROR",
+				"message": err.Error(),
+			},
+		})
+	}
+
 	// Use the existing admin service for registration
-	adminService := services.NewAdminService(h.userService.GetDB(), nil) // Pass nil for config if not needed
-	response, err := adminService.RegisterAdmin(context.Background(), &req, requesterID)
+	response, err := h.adminService.RegisterAdmin(c.Request().Context(), &req, requesterID)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
 			"success": false,
@@ -961,22 +1437,27 @@ func (h *UserManagementHandler) RegisterCityAdmin(c echo.Context) error {
 	})
 }
 
-// RegisterOwner handles POST /api/users/register/owner (City Admin only)
+// RegisterTournamentAdmin handles POST /api/users/register/tournament-admin (per the delegation matrix)
+func (h *UserManagementHandler) RegisterTournamentAdmin(c echo.Context) error {
+	return h.registerUserWithRole(c, models.RoleTournamentAdmin)
+}
+
+// RegisterOwner handles POST /api/users/register/owner (per the delegation matrix)
 func (h *UserManagementHandler) RegisterOwner(c echo.Context) error {
 	return h.registerUserWithRole(c, models.RoleOwner)
 }
 
-// RegisterReferee handles POST /api/users/register/referee (City Admin only)
+// RegisterReferee handles POST /api/users/register/referee (per the delegation matrix)
 func (h *UserManagementHandler) RegisterReferee(c echo.Context) error {
 	return h.registerUserWithRole(c, models.RoleReferee)
 }
 
-// RegisterPlayer handles POST /api/users/register/player (Owner only)
+// RegisterPlayer handles POST /api/users/register/player (per the delegation matrix)
 func (h *UserManagementHandler) RegisterPlayer(c echo.Context) error {
 	return h.registerUserWithRole(c, models.RolePlayer)
 }
 
-// RegisterCoach handles POST /api/users/register/coach (Owner only)
+// RegisterCoach handles POST /api/users/register/coach (per the delegation matrix)
 func (h *UserManagementHandler) RegisterCoach(c echo.Context) error {
 	return h.registerUserWithRole(c, models.RoleCoach)
 }
@@ -997,7 +1478,7 @@ func (h *UserManagementHandler) ValidateEmailUniqueness(c echo.Context) error {
 	// Check if email exists in user_profiles table
 	var exists bool
 	err := h.userService.GetDB().GetConnection().QueryRow(
-		context.Background(),
+		c.Request().Context(),
 		"SELECT EXISTS(SELECT 1 FROM user_profiles WHERE email = $1)",
 		email,
 	).Scan(&exists)
@@ -1063,10 +1544,13 @@ func (h *UserManagementHandler) registerUserWithRole(c echo.Context, role string
 		Email          string `json:"email" validate:"required,email"`
 		Phone          string `json:"phone,omitempty" validate:"omitempty,min=10,max=20"`
 		Identification string `json:"identification,omitempty" validate:"omitempty,min=5,max=50"`
-		PhotoURL       string `json:"photo_url,omitempty" validate:"omitempty,url"`
-		CityID         string `json:"city_id,omitempty" validate:"omitempty,uuid"`
-		SportID        string `json:"sport_id,omitempty" validate:"omitempty,uuid"`
-		AccountStatus  string `json:"account_status,omitempty" validate:"omitempty,oneof=active suspended payment_pending disabled"`
+		// IdentificationType defaults to CC when an identification is given
+		IdentificationType string `json:"identification_type,omitempty" validate:"omitempty,oneof=CC TI RC CE PEP PPT PA NIT"`
+		PhotoURL           string `json:"photo_url,omitempty" validate:"omitempty,url"`
+		CityID             string `json:"city_id,omitempty" validate:"omitempty,uuid"`
+		SportID            string `json:"sport_id,omitempty" validate:"omitempty,uuid"`
+		TournamentID       string `json:"tournament_id,omitempty" validate:"omitempty,uuid"`
+		AccountStatus      string `json:"account_status,omitempty" validate:"omitempty,oneof=active suspended payment_pending disabled"`
 
 		// Player-specific fields
 		DateOfBirth      string `json:"date_of_birth,omitempty"`
@@ -1112,18 +1596,55 @@ func (h *UserManagementHandler) registerUserWithRole(c echo.Context, role string
 		})
 	}
 
-	// Generate temporary password
-	tempPassword := h.generateTemporaryPassword()
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(tempPassword), bcrypt.DefaultCost)
+	// Same contact checks as bulk imports
+	if err := h.securityValidator.ValidateRegistrationFields(req.Email, req.Phone, req.IdentificationType, req.Identification); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "VALIDATION_ERROR",
+				"message": "Validation failed",
+				"details": err.Error(),
+			},
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 30*time.Second)
+	defer cancel()
+
+	// Resolve the city/sport/tournament the new user belongs to and check the
+	// requester may create the role there
+	var cityID, sportID, tournamentID *uuid.UUID
+	if req.CityID != "" {
+		id := uuid.MustParse(req.CityID)
+		cityID = &id
+	}
+	if req.SportID != "" {
+		id := uuid.MustParse(req.SportID)
+		sportID = &id
+	}
+	if req.TournamentID != "" {
+		id := uuid.MustParse(req.TournamentID)
+		tournamentID = &id
+	}
+
+	scope, err := h.delegation.ResolveScope(ctx, role, cityID, sportID, tournamentID)
+	if err == nil {
+		err = h.delegation.Authorize(ctx, requesterID, services.DelegationCreate, role, scope)
+	}
 	if err != nil {
+		if status, resp, ok := delegationErrorResponse(err); ok {
+			return c.JSON(status, resp)
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
 			"success": false,
 			"error": map[string]interface{}{
-				"code":    "PASSWORD_HASH_ERROR",
-				"message": "Error processing password",
+				"code":    "AUTHORIZATION_ERROR",
+				"message": "Error checking registration permissions",
 			},
 		})
 	}
+	cityID, sportID = scope.CityID, scope.SportID
+	tournamentID = services.AssignmentTournament(role, scope)
 
 	// Set default account status
 	accountStatus := req.AccountStatus
@@ -1143,20 +1664,36 @@ func (h *UserManagementHandler) registerUserWithRole(c echo.Context, role string
 	if req.PhotoURL != "" {
 		photoURL = &req.PhotoURL
 	}
+	identificationType := services.IdentificationTypeFor(req.IdentificationType, req.Identification)
 
-	err = h.userService.GetDB().GetConnection().QueryRow(
-		context.Background(),
+	// User, role assignment, player record and invitation are created together
+	tx, err := h.userService.GetDB().GetConnection().Begin(ctx)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "DATABASE_ERROR",
+				"message": "Error starting transaction",
+			},
+		})
+	}
+	defer tx.Rollback(ctx)
+
+	// The password is chosen by the invitee when accepting the invitation
+	err = tx.QueryRow(
+		ctx,
 		`INSERT INTO user_profiles (user_id, email, password_hash, first_name, last_name, phone, 
-		 identification, photo_url, primary_role, is_active, account_status, failed_login_attempts, 
-		 two_factor_enabled, created_at, updated_at) 
-		 VALUES (gen_random_uuid(), $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, NOW(), NOW()) 
+		 identification, identification_type, photo_url, primary_role, is_active, account_status, failed_login_attempts, 
+		 two_factor_enabled, email_verified_at, created_at, updated_at) 
+		 VALUES (gen_random_uuid(), $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, NULL, NOW(), NOW()) 
 		 RETURNING user_id`,
 		req.Email,
-		string(hashedPassword),
+		services.InvitationPendingPasswordHash,
 		req.FirstName,
 		req.LastName,
 		phone,
 		identification,
+		identificationType,
 		photoURL,
 		role,
 		true, // is_active
@@ -1184,48 +1721,20 @@ func (h *UserManagementHandler) registerUserWithRole(c echo.Context, role string
 		})
 	}
 
-	// Create role assignment if city_id and sport_id are provided
+	// Create role assignment when the user is bound to a city and sport
 	var roleAssignmentID *uuid.UUID
-	if req.CityID != "" && req.SportID != "" {
-		cityUUID, err := uuid.Parse(req.CityID)
-		if err != nil {
-			return c.JSON(http.StatusBadRequest, map[string]interface{}{
-				"success": false,
-				"error": map[string]interface{}{
-					"code":    "INVALID_CITY_ID",
-					"message": "Invalid city ID format",
-				},
-			})
-		}
-
-		sportUUID, err := uuid.Parse(req.SportID)
-		if err != nil {
-			return c.JSON(http.StatusBadRequest, map[string]interface{}{
-				"success": false,
-				"error": map[string]interface{}{
-					"code":    "INVALID_SPORT_ID",
-					"message": "Invalid sport ID format",
-				},
-			})
-		}
-
+	if cityID != nil && sportID != nil {
 		var assignmentID uuid.UUID
-		err = h.userService.GetDB().GetConnection().QueryRow(
-			context.Background(),
+		err = tx.QueryRow(
+			ctx,
 			`INSERT INTO user_roles_by_city_sport (role_assignment_id, user_id, city_id, sport_id, 
-			 role_name, assigned_by_user_id, is_active, created_at) 
-			 VALUES (gen_random_uuid(), $1, $2, $3, $4, $5, $6, NOW()) 
+			 tournament_id, role_name, assigned_by_user_id, is_active, created_at) 
+			 VALUES (gen_random_uuid(), $1, $2, $3, $4, $5, $6, $7, NOW()) 
 			 RETURNING role_assignment_id`,
-			userID, cityUUID, sportUUID, role, requesterID, true,
+			userID, cityID, sportID, tournamentID, role, requesterID, true,
 		).Scan(&assignmentID)
 
 		if err != nil {
-			// If role assignment fails, we should probably delete the user too
-			h.userService.GetDB().GetConnection().Exec(
-				context.Background(),
-				"DELETE FROM user_profiles WHERE user_id = $1",
-				userID,
-			)
 			return c.JSON(http.StatusInternalServerError, map[string]interface{}{
 				"success": false,
 				"error": map[string]interface{}{
@@ -1251,6 +1760,17 @@ func (h *UserManagementHandler) registerUserWithRole(c echo.Context, role string
 			})
 		}
 
+		if err := h.securityValidator.ValidatePlayerIdentification(req.IdentificationType, req.Identification, dateOfBirth, time.Now()); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"success": false,
+				"error": map[string]interface{}{
+					"code":    "VALIDATION_ERROR",
+					"message": "Validation failed",
+					"details": "identification validation failed: " + err.Error(),
+				},
+			})
+		}
+
 		// Create player record
 		var emergencyContactJSON, medicalInfoJSON *string
 		if req.EmergencyContact.Name != "" {
@@ -1283,23 +1803,42 @@ func (h *UserManagementHandler) registerUserWithRole(c echo.Context, role string
 			position = &req.Position
 		}
 
-		_, err = h.userService.GetDB().GetConnection().Exec(
-			context.Background(),
+		// Sensitive player fields are stored encrypted
+		sensitive := services.PlayerSensitiveFields{
+			Identification:   identification,
+			BloodType:        bloodType,
+			EmergencyContact: emergencyContactJSON,
+			MedicalInfo:      medicalInfoJSON,
+		}
+		if err := h.fieldEncryption.EncryptPlayerFields(ctx, &sensitive); err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+				"success": false,
+				"error": map[string]interface{}{
+					"code":    "PLAYER_CREATION_ERROR",
+					"message": "Error encrypting player record",
+				},
+			})
+		}
+
+		_, err = tx.Exec(
+			ctx,
 			`INSERT INTO players (player_id, user_profile_id, first_name, last_name, date_of_birth, 
-			 identification, blood_type, email, phone, photo_url, emergency_contact, medical_info, 
-			 preferred_position, is_active, created_at, updated_at) 
-			 VALUES (gen_random_uuid(), $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, NOW(), NOW())`,
+			 identification, identification_bidx, identification_type, blood_type, email, phone, photo_url, emergency_contact, 
+			 medical_info, preferred_position, is_active, created_at, updated_at) 
+			 VALUES (gen_random_uuid(), $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, NOW(), NOW())`,
 			userID,
 			req.FirstName,
 			req.LastName,
 			dateOfBirth,
-			identification,
-			bloodType,
+			sensitive.Identification,
+			sensitive.IdentificationIndex,
+			identificationType,
+			sensitive.BloodType,
 			req.Email,
 			phone,
 			photoURL,
-			emergencyContactJSON,
-			medicalInfoJSON,
+			sensitive.EmergencyContact,
+			sensitive.MedicalInfo,
 			position,
 			true, // is_active
 		)
@@ -1315,20 +1854,55 @@ func (h *UserManagementHandler) registerUserWithRole(c echo.Context, role string
 		}
 	}
 
+	invitation, invitationToken, err := h.invitationService.CreateInvitation(ctx, tx, userID, req.Email, role, cityID, sportID, requesterID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVITATION_ERROR",
+				"message": "Error creating invitation",
+			},
+		})
+	}
+
+	if err := h.invitationService.QueueInvitation(ctx, tx, invitation.InvitationID, invitationToken); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVITATION_ERROR",
+				"message": "Error queueing invitation email",
+			},
+		})
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "DATABASE_ERROR",
+				"message": "Error committing registration",
+			},
+		})
+	}
+
 	// Prepare response
 	response := map[string]interface{}{
-		"user_id":            userID,
-		"email":              req.Email,
-		"first_name":         req.FirstName,
-		"last_name":          req.LastName,
-		"primary_role":       role,
-		"temporary_password": tempPassword,
-		"message":            "User registered successfully",
+		"user_id":               userID,
+		"email":                 req.Email,
+		"first_name":            req.FirstName,
+		"last_name":             req.LastName,
+		"primary_role":          role,
+		"invitation_id":         invitation.InvitationID,
+		"invitation_expires_at": invitation.ExpiresAt,
+		"message":               "User registered successfully. Invitation link sent via email.",
 	}
 
 	if roleAssignmentID != nil {
 		response["role_assignment_id"] = *roleAssignmentID
 	}
+	if tournamentID != nil {
+		response["tournament_id"] = *tournamentID
+	}
 
 	return c.JSON(http.StatusCreated, map[string]interface{}{
 		"success": true,
@@ -1336,14 +1910,29 @@ func (h *UserManagementHandler) registerUserWithRole(c echo.Context, role string
 	})
 }
 
-// Helper method to generate temporary password
-func (h *UserManagementHandler) generateTemporaryPassword() string {
-	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789!@#$%^&*"
-	const length = 12
-
-	password := make([]byte, length)
-	for i := range password {
-		password[i] = charset[time.Now().UnixNano()%int64(len(charset))]
-	}
-	return string(password)
+// delegationErrorResponse maps DelegationService rejections to a response status and body
+func delegationErrorResponse(err error) (int, map[string]interface{}, bool) {
+	errMsg := err.Error()
+
+	var status int
+	var code, message string
+	switch {
+	case strings.Contains(errMsg, "insufficient permissions"):
+		status, code, message = http.StatusForbidden, "INSUFFICIENT_PERMISSIONS", "You cannot delegate this role in the requested city/sport/tournament"
+	case strings.Contains(errMsg, "tournament not found"):
+		status, code, message = http.StatusNotFound, "TOURNAMENT_NOT_FOUND", "Tournament not found"
+	case strings.Contains(errMsg, "invalid scope"):
+		status, code, message = http.StatusBadRequest, "INVALID_SCOPE", "Invalid city/sport/tournament for this role"
+	default:
+		return 0, nil, false
+	}
+
+	return status, map[string]interface{}{
+		"success": false,
+		"error": map[string]interface{}{
+			"code":    code,
+			"message": message,
+			"details": errMsg,
+		},
+	}, true
 }
@@ -0,0 +1,102 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+)
+
+// requestLog correlates the records of one request: its ID and the audit events
+// recorded while serving it
+type requestLog struct {
+	id            string
+	mu            sync.Mutex
+	auditEventIDs []string
+}
+
+type requestLogKey struct{}
+
+// ParseLevel reads LOG_LEVEL (debug, info, warn or error); anything else is info
+func ParseLevel(value string) slog.Level {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// New creates a JSON logger that redacts secrets and adds the request ID of the context
+// to every record logged with one
+func New(w io.Writer, level slog.Level) *slog.Logger {
+	return slog.New(contextHandler{slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: redactAttr,
+	})})
+}
+
+// Setup makes the JSON logger on stdout the default of slog and of the standard log package
+func Setup(level string) {
+	slog.SetDefault(New(os.Stdout, ParseLevel(level)))
+}
+
+// WithRequestID returns a context whose log records carry the request ID
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestLogKey{}, &requestLog{id: requestID})
+}
+
+// RequestID returns the request ID set by WithRequestID, if any
+func RequestID(ctx context.Context) string {
+	if log, ok := ctx.Value(requestLogKey{}).(*requestLog); ok {
+		return log.id
+	}
+	return ""
+}
+
+// AddAuditEvent notes an audit event recorded for the request, so the request's log
+// record can point to it
+func AddAuditEvent(ctx context.Context, eventID string) {
+	if log, ok := ctx.Value(requestLogKey{}).(*requestLog); ok {
+		log.mu.Lock()
+		log.auditEventIDs = append(log.auditEventIDs, eventID)
+		log.mu.Unlock()
+	}
+}
+
+// AuditEventIDs returns the audit events recorded for the request so far
+func AuditEventIDs(ctx context.Context) []string {
+	log, ok := ctx.Value(requestLogKey{}).(*requestLog)
+	if !ok {
+		return nil
+	}
+	log.mu.Lock()
+	defer log.mu.Unlock()
+	return append([]string(nil), log.auditEventIDs...)
+}
+
+// contextHandler adds the request ID of the context to each record
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if requestID := RequestID(ctx); requestID != "" {
+		record.AddAttrs(slog.String("request_id", requestID))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
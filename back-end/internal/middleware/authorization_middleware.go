@@ -0,0 +1,246 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"mowesport/internal/database"
+	"mowesport/internal/models"
+	"mowesport/internal/services"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+const authzCacheKey = "authz_cache"
+
+// ScopeResolver resolves the city/sport scopes of the resource targeted by a request
+type ScopeResolver func(ctx context.Context, c echo.Context) ([]services.ResourceScope, error)
+
+// ScopeAuthorizer enforces city/sport scoped authorization on top of role checks
+type ScopeAuthorizer struct {
+	policy       services.AuthorizationPolicy
+	scopeLookup  *services.ScopeLookupService
+	auditService *services.SecurityAuditService
+}
+
+// NewScopeAuthorizer creates a scope authorizer backed by role assignments
+func NewScopeAuthorizer(db *database.Database) *ScopeAuthorizer {
+	return &ScopeAuthorizer{
+		policy:       services.NewRoleAssignmentPolicy(db),
+		scopeLookup:  services.NewScopeLookupService(db),
+		auditService: services.NewSecurityAuditService(db),
+	}
+}
+
+// RequireScope requires the caller to hold one of the roles in every scope the
+// target resource belongs to. Decisions are cached for the lifetime of the request.
+func (a *ScopeAuthorizer) RequireScope(resolve ScopeResolver, roles ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			user := c.Get("user").(*jwt.Token)
+			claims := user.Claims.(jwt.MapClaims)
+
+			userIDStr, _ := claims["user_id"].(string)
+			userID, err := uuid.Parse(userIDStr)
+			if err != nil {
+				return c.JSON(http.StatusUnauthorized, map[string]interface{}{
+					"success": false,
+					"error": map[string]interface{}{
+						"code":    "INVALID_USER_ID",
+						"message": "Invalid user ID format",
+					},
+				})
+			}
+
+			ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+			defer cancel()
+
+			scopes, err := resolve(ctx, c)
+			if err != nil {
+				if strings.Contains(err.Error(), "not found") {
+					return c.JSON(http.StatusNotFound, map[string]interface{}{
+						"success": false,
+						"error": map[string]interface{}{
+							"code":    "RESOURCE_NOT_FOUND",
+							"message": "The requested resource does not exist",
+							"details": err.Error(),
+						},
+					})
+				}
+				return c.JSON(http.StatusBadRequest, map[string]interface{}{
+					"success": false,
+					"error": map[string]interface{}{
+						"code":    "INVALID_SCOPE",
+						"message": "Could not determine the city/sport of the resource",
+						"details": err.Error(),
+					},
+				})
+			}
+
+			for _, scope := range scopes {
+				allowed, err := a.authorize(ctx, c, userID, roles, scope)
+				if err != nil {
+					return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+						"success": false,
+						"error": map[string]interface{}{
+							"code":    "AUTHORIZATION_ERROR",
+							"message": "Failed to evaluate permissions",
+						},
+					})
+				}
+				if !allowed {
+					a.auditService.LogUnauthorizedAccess(ctx, c.Request().Method+" "+c.Path(), &userID, c.RealIP(), c.Request().UserAgent(), map[string]interface{}{
+						"path":     c.Request().URL.Path,
+						"roles":    roles,
+						"city_id":  scope.CityID,
+						"sport_id": scope.SportID,
+					})
+					return c.JSON(http.StatusForbidden, map[string]interface{}{
+						"success": false,
+						"error": map[string]interface{}{
+							"code":    "INSUFFICIENT_PERMISSIONS",
+							"message": "You are not authorized for this city/sport",
+						},
+					})
+				}
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// RequireAPIKeyScope requires the resource to lie within the tournament/city/sport scope of
+// the API key making the request. Mount it after RequireRole(models.RoleAPIClient).
+func (a *ScopeAuthorizer) RequireAPIKeyScope(resolve ScopeResolver) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			identity, ok := c.Get(APIKeyContextKey).(*models.APIKeyIdentity)
+			if !ok {
+				return c.JSON(http.StatusForbidden, map[string]interface{}{
+					"success": false,
+					"error": map[string]interface{}{
+						"code":    "API_KEY_REQUIRED",
+						"message": "This endpoint requires an API key",
+					},
+				})
+			}
+
+			ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+			defer cancel()
+
+			scopes, err := resolve(ctx, c)
+			if err != nil {
+				if strings.Contains(err.Error(), "not found") {
+					return c.JSON(http.StatusNotFound, map[string]interface{}{
+						"success": false,
+						"error": map[string]interface{}{
+							"code":    "RESOURCE_NOT_FOUND",
+							"message": "The requested resource does not exist",
+							"details": err.Error(),
+						},
+					})
+				}
+				return c.JSON(http.StatusBadRequest, map[string]interface{}{
+					"success": false,
+					"error": map[string]interface{}{
+						"code":    "INVALID_SCOPE",
+						"message": "Could not determine the city/sport of the resource",
+						"details": err.Error(),
+					},
+				})
+			}
+
+			for _, scope := range scopes {
+				if !identity.Covers(scopeID(scope.TournamentID), scopeID(scope.CityID), scopeID(scope.SportID)) {
+					a.auditService.LogUnauthorizedAccess(ctx, c.Request().Method+" "+c.Path(), nil, c.RealIP(), c.Request().UserAgent(), map[string]interface{}{
+						"path":          c.Request().URL.Path,
+						"api_key_id":    identity.APIKeyID,
+						"tournament_id": scope.TournamentID,
+						"city_id":       scope.CityID,
+						"sport_id":      scope.SportID,
+					})
+					return c.JSON(http.StatusForbidden, map[string]interface{}{
+						"success": false,
+						"error": map[string]interface{}{
+							"code":    "API_KEY_SCOPE_MISMATCH",
+							"message": "The resource is outside the scope of this API key",
+						},
+					})
+				}
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// scopeID reads an optional scope field; resources without one only match unscoped keys
+func scopeID(id *uuid.UUID) uuid.UUID {
+	if id == nil {
+		return uuid.Nil
+	}
+	return *id
+}
+
+// UserParamScope resolves the scopes of the user identified by a path parameter
+func (a *ScopeAuthorizer) UserParamScope(param string) ScopeResolver {
+	return func(ctx context.Context, c echo.Context) ([]services.ResourceScope, error) {
+		userID, err := uuid.Parse(c.Param(param))
+		if err != nil {
+			return nil, fmt.Errorf("invalid user ID format")
+		}
+		return a.scopeLookup.ScopesForUser(ctx, userID)
+	}
+}
+
+// MatchParamScope resolves the scope of the match identified by a path parameter
+func (a *ScopeAuthorizer) MatchParamScope(param string) ScopeResolver {
+	return func(ctx context.Context, c echo.Context) ([]services.ResourceScope, error) {
+		matchID, err := uuid.Parse(c.Param(param))
+		if err != nil {
+			return nil, fmt.Errorf("invalid match ID format")
+		}
+		scope, err := a.scopeLookup.ScopeForMatch(ctx, matchID)
+		if err != nil {
+			return nil, err
+		}
+		return []services.ResourceScope{scope}, nil
+	}
+}
+
+// PlayerParamScope resolves the scopes of the player identified by a path parameter
+func (a *ScopeAuthorizer) PlayerParamScope(param string) ScopeResolver {
+	return func(ctx context.Context, c echo.Context) ([]services.ResourceScope, error) {
+		playerID, err := uuid.Parse(c.Param(param))
+		if err != nil {
+			return nil, fmt.Errorf("invalid player ID format")
+		}
+		return a.scopeLookup.ScopesForPlayer(ctx, playerID)
+	}
+}
+
+// authorize evaluates the policy once per user/roles/scope within a request
+func (a *ScopeAuthorizer) authorize(ctx context.Context, c echo.Context, userID uuid.UUID, roles []string, scope services.ResourceScope) (bool, error) {
+	cache, ok := c.Get(authzCacheKey).(map[string]bool)
+	if !ok {
+		cache = make(map[string]bool)
+		c.Set(authzCacheKey, cache)
+	}
+
+	key := userID.String() + "|" + strings.Join(roles, ",") + "|" + scope.String()
+	if allowed, found := cache[key]; found {
+		return allowed, nil
+	}
+
+	allowed, err := a.policy.Authorize(ctx, userID, roles, scope)
+	if err != nil {
+		return false, err
+	}
+	cache[key] = allowed
+	return allowed, nil
+}
@@ -1,17 +1,158 @@
 package middleware
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"mowesport/internal/models"
+	"mowesport/internal/services"
 	"net/http"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 )
 
+// APIKeyContextKey holds the *models.APIKeyIdentity of requests authenticated with an API key
+const APIKeyContextKey = "api_key"
+
+// APIKeyAuthenticator resolves a raw API key into its identity
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, rawKey string) (*models.APIKeyIdentity, error)
+}
+
+// passwordChangeRoutes remain reachable with a token issued for an expired password
+var passwordChangeRoutes = map[string]bool{
+	"/api/auth/change-password": true,
+	"/api/auth/password-status": true,
+	"/api/auth/profile":         true,
+	"/api/auth/logout":          true,
+}
+
+// dataSubjectRoutes exercise Habeas Data rights, which no account status may restrict
+var dataSubjectRoutes = map[string]bool{
+	"/api/auth/personal-data":          true,
+	"/api/auth/personal-data/deletion": true,
+}
+
+// impersonationWriteRoutes classifies every state-changing route for impersonation tokens,
+// which may read anything but only make the writes marked true. Support sessions reproduce
+// what a user sees; they never change credentials, roles, accounts or data on the user's
+// behalf. CheckImpersonationRoutes refuses to start the server with an unclassified route.
+var impersonationWriteRoutes = map[string]bool{
+	"POST /api/auth/logout": true,
+	"PUT /api/auth/locale":  true,
+
+	// Public routes, which never carry an impersonation token
+	"POST /api/auth/login":                false,
+	"POST /api/auth/signup":               false,
+	"POST /api/auth/forgot-password":      false,
+	"POST /api/auth/reset-password":       false,
+	"POST /api/auth/refresh":              false,
+	"POST /api/auth/verify-email":         false,
+	"POST /api/auth/resend-verification":  false,
+	"POST /api/auth/confirm-email-change": false,
+	"POST /api/auth/invitations/accept":   false,
+	"DELETE /api/dev/mailbox":             false,
+
+	// Credentials, 2FA and personal data of the impersonated user
+	"POST /api/auth/2fa/setup":                               false,
+	"POST /api/auth/2fa/verify":                              false,
+	"POST /api/auth/2fa/disable":                             false,
+	"POST /api/auth/change-password":                         false,
+	"POST /api/auth/change-email":                            false,
+	"POST /api/auth/personal-data/deletion":                  false,
+	"DELETE /api/auth/personal-data/deletion":                false,
+	"PUT /api/auth/guardian/players/:playerId":               false,
+	"POST /api/auth/guardian/players/:playerId/consent":      false,
+	"DELETE /api/auth/guardian/players/:playerId/consent":    false,
+	"PUT /api/auth/guardian/players/:playerId/notifications": false,
+
+	// Administration
+	"POST /api/admin/register":                           false,
+	"POST /api/admin/api-keys":                           false,
+	"DELETE /api/admin/api-keys/:id":                     false,
+	"POST /api/admin/invitations/:id/resend":             false,
+	"DELETE /api/admin/invitations/:id":                  false,
+	"POST /api/admin/email-outbox/retry-failed":          false,
+	"POST /api/admin/email-outbox/:id/retry":             false,
+	"POST /api/admin/security-alerts/:id/acknowledge":    false,
+	"DELETE /api/admin/ip-blocks/:id":                    false,
+	"POST /api/admin/impersonate/:id":                    false,
+	"POST /api/admin/data-deletion-requests/:id/approve": false,
+	"POST /api/admin/data-deletion-requests/:id/reject":  false,
+
+	// Machine integrations, which only accept API keys
+	"POST /api/integrations/matches/:matchId/events": false,
+
+	// User management: accounts, roles and registrations
+	"PUT /api/users/:id":                                    false,
+	"PATCH /api/users/:id/status":                           false,
+	"POST /api/users/:id/lock":                              false,
+	"POST /api/users/:id/unlock":                            false,
+	"POST /api/users/:id/status/reactivation":               false,
+	"DELETE /api/users/:id/status/reactivation":             false,
+	"POST /api/users/roles":                                 false,
+	"DELETE /api/users/roles/:roleId":                       false,
+	"POST /api/users/permissions":                           false,
+	"POST /api/users/register/city-admin":                   false,
+	"POST /api/users/register/tournament-admin":             false,
+	"POST /api/users/register/owner":                        false,
+	"POST /api/users/register/referee":                      false,
+	"POST /api/users/register/player":                       false,
+	"POST /api/users/register/coach":                        false,
+	"POST /api/users/register/minor-player":                 false,
+	"POST /api/users/players/:playerId/guardians":           false,
+	"DELETE /api/users/players/:playerId/guardians/:linkId": false,
+	"POST /api/users/import":                                false,
+}
+
+// impersonationAllows reports whether an impersonation token may call the route
+func impersonationAllows(method, path string) bool {
+	if isSafeMethod(method) {
+		return true
+	}
+	return impersonationWriteRoutes[method+" "+path]
+}
+
+// CheckImpersonationRoutes fails when a state-changing route is mounted without being
+// classified in impersonationWriteRoutes, so new routes cannot slip past the allowlist
+func CheckImpersonationRoutes(routes []*echo.Route) error {
+	var unclassified []string
+	for _, route := range routes {
+		if isSafeMethod(route.Method) || route.Method == echo.RouteNotFound {
+			continue
+		}
+		if _, ok := impersonationWriteRoutes[route.Method+" "+route.Path]; !ok {
+			unclassified = append(unclassified, route.Method+" "+route.Path)
+		}
+	}
+	if len(unclassified) > 0 {
+		sort.Strings(unclassified)
+		return fmt.Errorf("routes not classified for impersonation: %s", strings.Join(unclassified, ", "))
+	}
+	return nil
+}
+
+// ImpersonationAuditor records every request made with an impersonation token
+type ImpersonationAuditor interface {
+	LogImpersonatedRequest(ctx context.Context, impersonation services.Impersonation, method, path string, status int)
+}
+
+// TokenRevocationChecker reports whether an access token was revoked after it was issued
+type TokenRevocationChecker interface {
+	IsTokenRevoked(ctx context.Context, userID uuid.UUID, tokenVersion int) (bool, error)
+}
+
 // JWTConfig holds JWT configuration
 type JWTConfig struct {
-	Secret []byte
+	Secret        []byte
+	APIKeys       APIKeyAuthenticator    // Optional, enables API key authentication
+	Impersonation ImpersonationAuditor   // Optional, audits impersonated requests
+	Revocation    TokenRevocationChecker // Optional, rejects tokens revoked by account status changes
 }
 
 // NewJWTConfig creates a new JWT configuration
@@ -21,10 +162,19 @@ func NewJWTConfig(secret string) *JWTConfig {
 	}
 }
 
-// JWTMiddleware creates a JWT middleware with custom validation
+// JWTMiddleware creates a JWT middleware with custom validation.
+// When API keys are configured, an X-API-Key header (or a bearer API key) is
+// accepted as well and populates the same "user" identity.
 func (config *JWTConfig) JWTMiddleware() echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
+			// Machine integrations authenticate with an API key instead of a JWT
+			if config.APIKeys != nil {
+				if rawKey := apiKeyFromRequest(c); rawKey != "" {
+					return config.authenticateAPIKey(c, next, rawKey)
+				}
+			}
+
 			// Get token from Authorization header
 			authHeader := c.Request().Header.Get("Authorization")
 			if authHeader == "" {
@@ -103,14 +253,276 @@ func (config *JWTConfig) JWTMiddleware() echo.MiddlewareFunc {
 				})
 			}
 
+			// Account status changes and deactivation revoke tokens issued before them
+			if config.Revocation != nil {
+				if resp, status := config.checkRevocation(c, claims); resp != nil {
+					return c.JSON(status, resp)
+				}
+			}
+
+			// Read-only statuses (e.g. payment_pending) may only read, apart from managing their
+			// session and their personal data
+			capabilities := models.CapabilitiesForStatus(stringClaim(claims, "account_status"))
+			if capabilities.ReadOnly && !isSafeMethod(c.Request().Method) && !passwordChangeRoutes[c.Path()] && !dataSubjectRoutes[c.Path()] {
+				return c.JSON(http.StatusForbidden, map[string]interface{}{
+					"success": false,
+					"error": map[string]interface{}{
+						"code":    "ACCOUNT_READ_ONLY",
+						"message": "Your account is read-only in its current status",
+						"details": map[string]interface{}{
+							"account_status": capabilities.Status,
+							"notice":         capabilities.Notice,
+						},
+					},
+				})
+			}
+
+			// An expired password limits the token to rotating it
+			if required, _ := claims["password_change_required"].(bool); required && !passwordChangeRoutes[c.Path()] {
+				return c.JSON(http.StatusForbidden, map[string]interface{}{
+					"success": false,
+					"error": map[string]interface{}{
+						"code":    "PASSWORD_CHANGE_REQUIRED",
+						"message": "Your password has expired and must be changed",
+					},
+				})
+			}
+
 			// Store token in context for use in handlers
 			c.Set("user", token)
 
+			if impersonated, _ := claims["impersonation"].(bool); impersonated {
+				return config.serveImpersonated(c, next, claims)
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// OptionalJWTMiddleware authenticates requests that carry a token or API key, like
+// JWTMiddleware (invalid credentials are still rejected), and lets anonymous requests
+// through without a "user" identity
+func (config *JWTConfig) OptionalJWTMiddleware() echo.MiddlewareFunc {
+	authenticate := config.JWTMiddleware()
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		authenticated := authenticate(next)
+		return func(c echo.Context) error {
+			if c.Request().Header.Get("Authorization") == "" && c.Request().Header.Get("X-API-Key") == "" {
+				return next(c)
+			}
+			return authenticated(c)
+		}
+	}
+}
+
+// serveImpersonated applies the impersonation restrictions and audits the request
+// under the super admin who started the session
+func (config *JWTConfig) serveImpersonated(c echo.Context, next echo.HandlerFunc, claims jwt.MapClaims) error {
+	var impersonation services.Impersonation
+	var errs [3]error
+	impersonation.ActorID, errs[0] = uuid.Parse(stringClaim(claims, "impersonated_by"))
+	impersonation.SubjectID, errs[1] = uuid.Parse(stringClaim(claims, "user_id"))
+	impersonation.SessionID, errs[2] = uuid.Parse(stringClaim(claims, "impersonation_session_id"))
+	for _, err := range errs {
+		if err != nil {
+			return c.JSON(http.StatusUnauthorized, map[string]interface{}{
+				"success": false,
+				"error": map[string]interface{}{
+					"code":    "INVALID_TOKEN_CLAIMS",
+					"message": "Invalid impersonation claims",
+				},
+			})
+		}
+	}
+
+	ctx := services.WithImpersonation(c.Request().Context(), impersonation)
+	c.SetRequest(c.Request().WithContext(ctx))
+
+	var err error
+	if !impersonationAllows(c.Request().Method, c.Path()) {
+		err = c.JSON(http.StatusForbidden, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "IMPERSONATION_FORBIDDEN",
+				"message": "This action is not available while impersonating a user",
+			},
+		})
+	} else {
+		err = next(c)
+	}
+
+	if config.Impersonation != nil {
+		status := c.Response().Status
+		var httpErr *echo.HTTPError
+		if errors.As(err, &httpErr) {
+			status = httpErr.Code
+		}
+		config.Impersonation.LogImpersonatedRequest(ctx, impersonation, c.Request().Method, c.Path(), status)
+	}
+
+	return err
+}
+
+// checkRevocation returns an error response when the token's version is no longer current
+func (config *JWTConfig) checkRevocation(c echo.Context, claims jwt.MapClaims) (map[string]interface{}, int) {
+	userID, err := uuid.Parse(stringClaim(claims, "user_id"))
+	if err != nil {
+		return map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_TOKEN_CLAIMS",
+				"message": "Invalid token claims",
+			},
+		}, http.StatusUnauthorized
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	version, _ := claims["token_version"].(float64)
+	revoked, err := config.Revocation.IsTokenRevoked(ctx, userID, int(version))
+	if err != nil {
+		return map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "TOKEN_VALIDATION_ERROR",
+				"message": "Failed to validate token",
+			},
+		}, http.StatusInternalServerError
+	}
+	if revoked {
+		return map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "TOKEN_REVOKED",
+				"message": "Your session is no longer valid, please sign in again",
+			},
+		}, http.StatusUnauthorized
+	}
+
+	return nil, 0
+}
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+func stringClaim(claims jwt.MapClaims, key string) string {
+	value, _ := claims[key].(string)
+	return value
+}
+
+// authenticateAPIKey validates an API key and stores an equivalent token in context
+func (config *JWTConfig) authenticateAPIKey(c echo.Context, next echo.HandlerFunc, rawKey string) error {
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	identity, err := config.APIKeys.AuthenticateAPIKey(ctx, rawKey)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    "INVALID_API_KEY",
+				"message": "Invalid, revoked or expired API key",
+			},
+		})
+	}
+
+	// Mirror the access token claims so handlers read a single identity shape.
+	// user_id is the key itself, never the admin who created it.
+	claims := jwt.MapClaims{
+		"user_id":       identity.APIKeyID.String(),
+		"primary_role":  models.RoleAPIClient,
+		"type":          "access",
+		"auth_method":   "api_key",
+		"api_key_id":    identity.APIKeyID.String(),
+		"api_key_name":  identity.Name,
+		"permissions":   identity.Permissions,
+		"created_by":    identity.CreatedByUserID.String(),
+		"tournament_id": uuidClaim(identity.TournamentID),
+		"city_id":       uuidClaim(identity.CityID),
+		"sport_id":      uuidClaim(identity.SportID),
+	}
+	c.Set("user", &jwt.Token{Claims: claims, Valid: true})
+	c.Set(APIKeyContextKey, identity)
+
+	return next(c)
+}
+
+// RequireAPIPermission requires API key identities to hold the given permission.
+// Regular user tokens pass through and are governed by role checks instead.
+func RequireAPIPermission(permission string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			user := c.Get("user").(*jwt.Token)
+			claims := user.Claims.(jwt.MapClaims)
+
+			if method, _ := claims["auth_method"].(string); method != "api_key" {
+				return next(c)
+			}
+
+			permissions, _ := claims["permissions"].([]string)
+			for _, granted := range permissions {
+				if granted == permission {
+					return next(c)
+				}
+			}
+
+			return c.JSON(http.StatusForbidden, map[string]interface{}{
+				"success": false,
+				"error": map[string]interface{}{
+					"code":    "INSUFFICIENT_API_KEY_PERMISSIONS",
+					"message": "API key lacks the " + permission + " permission",
+				},
+			})
+		}
+	}
+}
+
+// RequireUserToken rejects API key identities on endpoints that act on the signed-in
+// user's own account, where the key's id would otherwise stand in for a user id
+func RequireUserToken() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			user := c.Get("user").(*jwt.Token)
+			claims := user.Claims.(jwt.MapClaims)
+
+			if method, _ := claims["auth_method"].(string); method == "api_key" {
+				return c.JSON(http.StatusForbidden, map[string]interface{}{
+					"success": false,
+					"error": map[string]interface{}{
+						"code":    "USER_TOKEN_REQUIRED",
+						"message": "This endpoint requires a user session, API keys are not accepted",
+					},
+				})
+			}
+
 			return next(c)
 		}
 	}
 }
 
+// apiKeyFromRequest extracts an API key from X-API-Key or a bearer credential
+func apiKeyFromRequest(c echo.Context) string {
+	if key := c.Request().Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	authHeader := c.Request().Header.Get("Authorization")
+	if strings.HasPrefix(authHeader, "Bearer mws_") {
+		return strings.TrimPrefix(authHeader, "Bearer ")
+	}
+	return ""
+}
+
+// uuidClaim renders an optional UUID as a claim value
+func uuidClaim(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
+
 // RequireRole creates middleware that requires specific roles
 func RequireRole(allowedRoles ...string) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
@@ -92,6 +92,13 @@ func EmailValidationRateLimit() echo.MiddlewareFunc {
 	return limiter.RateLimit(20, 1*time.Minute)
 }
 
+// VerificationEmailRateLimit applies rate limiting for verification email resends
+func VerificationEmailRateLimit() echo.MiddlewareFunc {
+	limiter := NewRateLimiter()
+	// Allow 5 resend requests per 15 minutes per IP (per-account throttling is done in the service)
+	return limiter.RateLimit(5, 15*time.Minute)
+}
+
 // GeneralAPIRateLimit applies general rate limiting for API endpoints
 func GeneralAPIRateLimit() echo.MiddlewareFunc {
 	limiter := NewRateLimiter()
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"mowesport/internal/logging"
+	"mowesport/internal/services"
+	"regexp"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// validRequestID accepts request IDs forwarded by proxies and clients; anything else
+// (too long, or characters that could forge log fields) is replaced
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,64}$`)
+
+// RequestContext assigns the request ID (the client's X-Request-ID when valid, otherwise a
+// new one, echoed in the response) and stores it with the client IP and user agent in the
+// request's context.Context, so services can attribute audit events and log records
+// without echo access
+func RequestContext() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			requestID := c.Request().Header.Get(echo.HeaderXRequestID)
+			if !validRequestID.MatchString(requestID) {
+				requestID = uuid.NewString()
+			}
+			c.Response().Header().Set(echo.HeaderXRequestID, requestID)
+
+			ctx := services.WithRequestInfo(c.Request().Context(), services.RequestInfo{
+				IPAddress: c.RealIP(),
+				UserAgent: c.Request().UserAgent(),
+				RequestID: requestID,
+			})
+			ctx = logging.WithRequestID(ctx, requestID)
+			c.SetRequest(c.Request().WithContext(ctx))
+
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"log/slog"
+	"mowesport/internal/logging"
+	"mowesport/internal/services"
+	"net/http"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+)
+
+// RequestLogger logs one record per request with its route, status and latency, the
+// authenticated identity and the IDs of the audit events recorded while serving it.
+// Server errors are logged at error level. It must run inside RequestContext.
+func RequestLogger() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			err := next(c)
+			if err != nil {
+				// Render the error now so the logged status is the one sent
+				c.Error(err)
+			}
+
+			// The route pattern, not the URL, so tokens in paths and query strings stay out of logs
+			route := c.Path()
+			if route == "" {
+				route = c.Request().URL.Path
+			}
+			status := c.Response().Status
+
+			attrs := []slog.Attr{
+				slog.String("method", c.Request().Method),
+				slog.String("route", route),
+				slog.Int("status", status),
+				slog.Int64("latency_ms", time.Since(start).Milliseconds()),
+				slog.String("remote_ip", c.RealIP()),
+				slog.Int64("bytes_out", c.Response().Size),
+			}
+			if user, ok := c.Get("user").(*jwt.Token); ok {
+				if claims, ok := user.Claims.(jwt.MapClaims); ok {
+					attrs = append(attrs, slog.String("user_id", stringClaim(claims, "user_id")))
+					if method := stringClaim(claims, "auth_method"); method != "" {
+						attrs = append(attrs, slog.String("auth_method", method))
+					}
+				}
+			}
+			ctx := c.Request().Context()
+			if impersonation, ok := services.ImpersonationFromContext(ctx); ok {
+				attrs = append(attrs, slog.String("impersonated_by", impersonation.ActorID.String()))
+			}
+			if eventIDs := logging.AuditEventIDs(ctx); len(eventIDs) > 0 {
+				attrs = append(attrs, slog.Any("audit_event_ids", eventIDs))
+			}
+
+			level := slog.LevelInfo
+			if status >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+			if err != nil {
+				attrs = append(attrs, slog.Any("error", err))
+			}
+			slog.LogAttrs(ctx, level, "request", attrs...)
+
+			return nil
+		}
+	}
+}
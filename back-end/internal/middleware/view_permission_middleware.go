@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"context"
+	"mowesport/internal/database"
+	"mowesport/internal/services"
+	"net/http"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// ViewPermissionGuard enforces the named view registry backed by user_view_permissions
+type ViewPermissionGuard struct {
+	permissionService *services.ViewPermissionService
+	auditService      *services.SecurityAuditService
+}
+
+// NewViewPermissionGuard creates a new view permission guard
+func NewViewPermissionGuard(db *database.Database) *ViewPermissionGuard {
+	return &ViewPermissionGuard{
+		permissionService: services.NewViewPermissionService(db),
+		auditService:      services.NewSecurityAuditService(db),
+	}
+}
+
+// RequireView creates middleware that requires access to a named view
+func (g *ViewPermissionGuard) RequireView(viewName string) echo.MiddlewareFunc {
+	if _, ok := services.LookupView(viewName); !ok {
+		panic("middleware: unknown view " + viewName)
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			user := c.Get("user").(*jwt.Token)
+			claims := user.Claims.(jwt.MapClaims)
+
+			userIDStr, _ := claims["user_id"].(string)
+			userID, err := uuid.Parse(userIDStr)
+			if err != nil {
+				return c.JSON(http.StatusUnauthorized, map[string]interface{}{
+					"success": false,
+					"error": map[string]interface{}{
+						"code":    "INVALID_USER_ID",
+						"message": "Invalid user ID format",
+					},
+				})
+			}
+
+			ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+			defer cancel()
+
+			allowed, err := g.permissionService.HasViewPermission(ctx, userID, viewName)
+			if err != nil || !allowed {
+				g.auditService.LogUnauthorizedAccess(ctx, "view:"+viewName, &userID, c.RealIP(), c.Request().UserAgent(), map[string]interface{}{
+					"path":      c.Request().URL.Path,
+					"view_name": viewName,
+				})
+				return c.JSON(http.StatusForbidden, map[string]interface{}{
+					"success": false,
+					"error": map[string]interface{}{
+						"code":    "VIEW_PERMISSION_DENIED",
+						"message": "You do not have access to this section",
+					},
+				})
+			}
+
+			return next(c)
+		}
+	}
+}
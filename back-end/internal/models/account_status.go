@@ -0,0 +1,104 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Account notices shown to users whose status limits what they can do
+const (
+	AccountNoticeSettlePayment = "settle_payment"
+)
+
+// AccountStatusCapabilities defines what an account can do in a given status
+type AccountStatusCapabilities struct {
+	Status          string `json:"status"`
+	CanLogin        bool   `json:"can_login"`
+	ReadOnly        bool   `json:"read_only"`        // Only safe (GET) requests are accepted
+	HoldTournaments bool   `json:"hold_tournaments"` // Tournaments administered by the account are put on hold
+	Notice          string `json:"notice,omitempty"` // Shown to the user after login, e.g. settle_payment
+}
+
+var accountStatusCapabilities = map[string]AccountStatusCapabilities{
+	AccountStatusActive: {
+		Status:   AccountStatusActive,
+		CanLogin: true,
+	},
+	AccountStatusPaymentPending: {
+		Status:   AccountStatusPaymentPending,
+		CanLogin: true,
+		ReadOnly: true,
+		Notice:   AccountNoticeSettlePayment,
+	},
+	AccountStatusSuspended: {
+		Status:          AccountStatusSuspended,
+		HoldTournaments: true,
+	},
+	AccountStatusDisabled: {
+		Status:          AccountStatusDisabled,
+		HoldTournaments: true,
+	},
+}
+
+// CapabilitiesForStatus returns the capabilities of an account status.
+// Unknown statuses get no capabilities.
+func CapabilitiesForStatus(status string) AccountStatusCapabilities {
+	if capabilities, ok := accountStatusCapabilities[status]; ok {
+		return capabilities
+	}
+	return AccountStatusCapabilities{Status: status}
+}
+
+// AllAccountStatusCapabilities returns the capabilities of every account status
+func AllAccountStatusCapabilities() []AccountStatusCapabilities {
+	return []AccountStatusCapabilities{
+		accountStatusCapabilities[AccountStatusActive],
+		accountStatusCapabilities[AccountStatusPaymentPending],
+		accountStatusCapabilities[AccountStatusSuspended],
+		accountStatusCapabilities[AccountStatusDisabled],
+	}
+}
+
+// AccountStatusChange is the outcome of an account status update
+type AccountStatusChange struct {
+	UserID              uuid.UUID `json:"user_id"`
+	PreviousStatus      string    `json:"previous_status"`
+	Status              string    `json:"status"`
+	Reason              string    `json:"reason,omitempty"`
+	TokensRevoked       bool      `json:"tokens_revoked"`
+	TournamentsHeld     int       `json:"tournaments_held"`
+	TournamentsReleased int       `json:"tournaments_released"`
+	ChangedAt           time.Time `json:"changed_at"`
+}
+
+// Account status schedule states
+const (
+	AccountScheduleStatusPending   = "pending"
+	AccountScheduleStatusApplied   = "applied"
+	AccountScheduleStatusCancelled = "cancelled"
+	AccountScheduleStatusFailed    = "failed"
+)
+
+// AccountStatusSchedule is a status change applied by the scheduler at ScheduledFor
+type AccountStatusSchedule struct {
+	ScheduleID      uuid.UUID  `json:"schedule_id" db:"schedule_id"`
+	UserID          uuid.UUID  `json:"user_id" db:"user_id"`
+	TargetStatus    string     `json:"target_status" db:"target_status"`
+	Reason          string     `json:"reason" db:"reason"`
+	ScheduledFor    time.Time  `json:"scheduled_for" db:"scheduled_for"`
+	NotifyUser      bool       `json:"notify_user" db:"notify_user"`
+	Status          string     `json:"status" db:"status"`
+	CreatedByUserID *uuid.UUID `json:"created_by_user_id,omitempty" db:"created_by_user_id"`
+	AppliedAt       *time.Time `json:"applied_at,omitempty" db:"applied_at"`
+	LastError       *string    `json:"last_error,omitempty" db:"last_error"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+}
+
+// AccountReactivationRequest schedules an account to return to active status.
+// NotifyUser defaults to true.
+type AccountReactivationRequest struct {
+	ScheduledFor time.Time `json:"scheduled_for" validate:"required"`
+	Reason       string    `json:"reason" validate:"required,min=5,max=500"`
+	NotifyUser   *bool     `json:"notify_user,omitempty"`
+}
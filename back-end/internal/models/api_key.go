@@ -0,0 +1,83 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKey represents a scoped key for machine integrations
+type APIKey struct {
+	APIKeyID        uuid.UUID  `json:"api_key_id" db:"api_key_id"`
+	Name            string     `json:"name" db:"name"`
+	KeyPrefix       string     `json:"key_prefix" db:"key_prefix"`
+	KeyHash         string     `json:"-" db:"key_hash"` // Never expose in JSON
+	Permissions     []string   `json:"permissions" db:"permissions"`
+	TournamentID    *uuid.UUID `json:"tournament_id" db:"tournament_id"`
+	CityID          *uuid.UUID `json:"city_id" db:"city_id"`
+	SportID         *uuid.UUID `json:"sport_id" db:"sport_id"`
+	CreatedByUserID uuid.UUID  `json:"created_by_user_id" db:"created_by_user_id"`
+	ExpiresAt       *time.Time `json:"expires_at" db:"expires_at"`
+	LastUsedAt      *time.Time `json:"last_used_at" db:"last_used_at"`
+	RevokedAt       *time.Time `json:"revoked_at" db:"revoked_at"`
+	IsActive        bool       `json:"is_active" db:"is_active"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// APIKeyCreateRequest for creating a new API key
+type APIKeyCreateRequest struct {
+	Name          string     `json:"name" validate:"required,min=3,max=100"`
+	Permissions   []string   `json:"permissions" validate:"required,min=1,dive,oneof=matches:read events:write tournaments:read teams:read players:read statistics:read"`
+	TournamentID  *uuid.UUID `json:"tournament_id,omitempty"`
+	CityID        *uuid.UUID `json:"city_id,omitempty"`
+	SportID       *uuid.UUID `json:"sport_id,omitempty"`
+	ExpiresInDays int        `json:"expires_in_days,omitempty" validate:"omitempty,min=1,max=365"`
+}
+
+// APIKeyCreateResponse is returned once, the raw key cannot be retrieved later
+type APIKeyCreateResponse struct {
+	APIKey
+	Key     string `json:"key"`
+	Message string `json:"message"`
+}
+
+// APIKeyIdentity is the request identity resolved from a valid API key
+type APIKeyIdentity struct {
+	APIKeyID        uuid.UUID
+	Name            string
+	Permissions     []string
+	TournamentID    *uuid.UUID
+	CityID          *uuid.UUID
+	SportID         *uuid.UUID
+	CreatedByUserID uuid.UUID
+}
+
+// Covers reports whether a resource of the tournament, city and sport is within the
+// key's scope. Each scope field the key sets must match; unset fields match anything.
+func (k *APIKeyIdentity) Covers(tournamentID, cityID, sportID uuid.UUID) bool {
+	if k.TournamentID != nil && *k.TournamentID != tournamentID {
+		return false
+	}
+	if k.CityID != nil && *k.CityID != cityID {
+		return false
+	}
+	if k.SportID != nil && *k.SportID != sportID {
+		return false
+	}
+	return true
+}
+
+// API key permission constants
+const (
+	APIPermissionMatchesRead     = "matches:read"
+	APIPermissionEventsWrite     = "events:write"
+	APIPermissionTournamentsRead = "tournaments:read"
+	APIPermissionTeamsRead       = "teams:read"
+	APIPermissionPlayersRead     = "players:read"
+	APIPermissionStatisticsRead  = "statistics:read"
+)
+
+// RoleAPIClient is the primary role carried by API key identities. It is not a
+// user role, so role-gated endpoints reject API keys unless they opt in.
+const RoleAPIClient = "api_client"
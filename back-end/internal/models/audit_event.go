@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// AuditEventListRequest for GET /api/admin/audit-events and its CSV export
+type AuditEventListRequest struct {
+	EventType string     `query:"event_type" validate:"omitempty,max=50"`
+	UserID    string     `query:"user_id" validate:"omitempty,uuid"`
+	IPAddress string     `query:"ip_address" validate:"omitempty,ip"`
+	Severity  string     `query:"severity" validate:"omitempty,oneof=LOW MEDIUM HIGH CRITICAL"`
+	RequestID string     `query:"request_id" validate:"omitempty,max=100"`
+	From      *time.Time `query:"from"`
+	To        *time.Time `query:"to"`
+	Page      int        `query:"page" validate:"omitempty,min=1"`
+	Limit     int        `query:"limit" validate:"omitempty,min=1,max=200"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// DevMailboxListRequest filters captured development email
+type DevMailboxListRequest struct {
+	To    string `query:"to" validate:"omitempty,email"`
+	Limit int    `query:"limit" validate:"omitempty,min=1,max=200"`
+}
+
+// DevMailboxMessage summarizes an email captured by the development mailbox
+type DevMailboxMessage struct {
+	ID              string    `json:"id"`
+	MessageID       string    `json:"message_id"`
+	From            string    `json:"from"`
+	To              string    `json:"to"`
+	ReplyTo         string    `json:"reply_to,omitempty"`
+	Subject         string    `json:"subject"`
+	Date            time.Time `json:"date"`
+	AttachmentCount int       `json:"attachment_count"`
+}
+
+// DevMailboxMessageDetail is a captured email with its decoded parts
+type DevMailboxMessageDetail struct {
+	DevMailboxMessage
+	Headers     map[string][]string    `json:"headers"`
+	Text        string                 `json:"text"`
+	HTML        string                 `json:"html"`
+	Links       []string               `json:"links"`
+	Attachments []DevMailboxAttachment `json:"attachments"`
+}
+
+// DevMailboxAttachment describes an attachment of a captured email
+type DevMailboxAttachment struct {
+	Index    int    `json:"index"`
+	Filename string `json:"filename"`
+	MimeType string `json:"mime_type"`
+	Size     int    `json:"size"`
+}
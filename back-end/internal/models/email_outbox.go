@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EmailOutboxMessage is a queued outgoing email (the body is not exposed)
+type EmailOutboxMessage struct {
+	MessageID         uuid.UUID  `json:"message_id" db:"message_id"`
+	ToEmail           string     `json:"to_email" db:"to_email"`
+	Subject           string     `json:"subject" db:"subject"`
+	Status            string     `json:"status" db:"status"`
+	Attempts          int        `json:"attempts" db:"attempts"`
+	MaxAttempts       int        `json:"max_attempts" db:"max_attempts"`
+	NextAttemptAt     time.Time  `json:"next_attempt_at" db:"next_attempt_at"`
+	LastError         *string    `json:"last_error" db:"last_error"`
+	ProviderMessageID *string    `json:"provider_message_id" db:"provider_message_id"`
+	SentAt            *time.Time `json:"sent_at" db:"sent_at"`
+	FailedAt          *time.Time `json:"failed_at" db:"failed_at"`
+	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// EmailOutboxListRequest for GET /api/admin/email-outbox
+type EmailOutboxListRequest struct {
+	Status string `query:"status" validate:"omitempty,oneof=pending sending sent failed"`
+	Limit  int    `query:"limit" validate:"omitempty,min=1,max=200"`
+}
+
+// Email outbox status constants
+const (
+	EmailStatusPending = "pending"
+	EmailStatusSending = "sending"
+	EmailStatusSent    = "sent"
+	EmailStatusFailed  = "failed" // dead-lettered after max attempts
+)
@@ -0,0 +1,7 @@
+package models
+
+// EmailTemplatePreviewRequest selects the locale and output of a template preview
+type EmailTemplatePreviewRequest struct {
+	Locale string `query:"locale" validate:"omitempty,oneof=es-CO en"`
+	Format string `query:"format" validate:"omitempty,oneof=html text json"`
+}
@@ -0,0 +1,134 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Relationships of a guardian to a minor player
+const (
+	GuardianRelationshipMother        = "mother"
+	GuardianRelationshipFather        = "father"
+	GuardianRelationshipLegalGuardian = "legal_guardian"
+	GuardianRelationshipOther         = "other"
+)
+
+// PlayerGuardian is a guardian account linked to a minor player
+type PlayerGuardian struct {
+	GuardianLinkID uuid.UUID        `json:"guardian_link_id" db:"guardian_link_id"`
+	PlayerID       uuid.UUID        `json:"player_id" db:"player_id"`
+	GuardianUserID uuid.UUID        `json:"guardian_user_id" db:"guardian_user_id"`
+	Email          string           `json:"email"`
+	FirstName      string           `json:"first_name"`
+	LastName       string           `json:"last_name"`
+	Relationship   string           `json:"relationship" db:"relationship"`
+	NotifyMatches  bool             `json:"notify_matches" db:"notify_matches"`
+	LinkedByUserID *uuid.UUID       `json:"linked_by_user_id,omitempty" db:"linked_by_user_id"`
+	CreatedAt      time.Time        `json:"created_at" db:"created_at"`
+	InvitationSent bool             `json:"invitation_sent,omitempty"` // A new guardian account was created and invited
+	Consent        *GuardianConsent `json:"consent"`                   // Latest unrevoked consent, if any
+}
+
+// GuardianConsent is a guardian's consent for a minor to take part in tournaments
+type GuardianConsent struct {
+	ConsentID       uuid.UUID  `json:"consent_id" db:"consent_id"`
+	PlayerID        uuid.UUID  `json:"player_id" db:"player_id"`
+	GuardianUserID  uuid.UUID  `json:"guardian_user_id" db:"guardian_user_id"`
+	DocumentVersion string     `json:"document_version" db:"document_version"`
+	ConsentedAt     time.Time  `json:"consented_at" db:"consented_at"`
+	RevokedAt       *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	IsCurrent       bool       `json:"is_current"` // Accepted the current version of the consent document
+}
+
+// GuardedPlayer is a minor player as seen by their guardian. Sensitive fields are only
+// filled in when a single player is requested.
+type GuardedPlayer struct {
+	PlayerID               uuid.UUID        `json:"player_id"`
+	FirstName              string           `json:"first_name"`
+	LastName               string           `json:"last_name"`
+	DateOfBirth            time.Time        `json:"date_of_birth"`
+	Email                  *string          `json:"email"`
+	Phone                  *string          `json:"phone"`
+	PhotoURL               *string          `json:"photo_url"`
+	HeightCM               *int             `json:"height_cm"`
+	WeightKG               *float64         `json:"weight_kg"`
+	PreferredPosition      *string          `json:"preferred_position"`
+	DominantFoot           *string          `json:"dominant_foot"`
+	BloodType              *string          `json:"blood_type,omitempty"`
+	EmergencyContact       json.RawMessage  `json:"emergency_contact,omitempty"`
+	MedicalInfo            json.RawMessage  `json:"medical_info,omitempty"`
+	Relationship           string           `json:"relationship"`
+	NotifyMatches          bool             `json:"notify_matches"`
+	Consent                *GuardianConsent `json:"consent"`
+	ConsentDocumentVersion string           `json:"consent_document_version"` // Version to accept when consent is missing or outdated
+}
+
+// GuardianContact identifies the guardian to link. An account is created and invited
+// when the email is not registered yet.
+type GuardianContact struct {
+	Email        string `json:"email" validate:"required,email"`
+	FirstName    string `json:"first_name" validate:"omitempty,min=2,max=100"`
+	LastName     string `json:"last_name" validate:"omitempty,min=2,max=100"`
+	Phone        string `json:"phone" validate:"omitempty,min=10,max=20"`
+	Relationship string `json:"relationship" validate:"required,oneof=mother father legal_guardian other"`
+}
+
+// GuardianLinkRequest for POST /api/users/players/:playerId/guardians
+type GuardianLinkRequest struct {
+	Guardian GuardianContact `json:"guardian"`
+}
+
+// MinorPlayerRegistrationRequest for POST /api/users/register/minor-player. The player
+// gets no login of their own; the guardian acts on their behalf.
+type MinorPlayerRegistrationRequest struct {
+	FirstName          string          `json:"first_name" validate:"required,min=2,max=100"`
+	LastName           string          `json:"last_name" validate:"required,min=2,max=100"`
+	DateOfBirth        string          `json:"date_of_birth" validate:"required,datetime=2006-01-02"`
+	Identification     string          `json:"identification" validate:"required,min=5,max=50"`
+	IdentificationType string          `json:"identification_type" validate:"required,oneof=TI RC CE PEP PPT PA"`
+	BloodType          string          `json:"blood_type,omitempty" validate:"omitempty,max=5"`
+	Gender             string          `json:"gender,omitempty" validate:"omitempty,oneof=male female"`
+	Position           string          `json:"position,omitempty" validate:"omitempty,max=50"`
+	JerseyNumber       *int            `json:"jersey_number,omitempty" validate:"omitempty,min=1,max=99"`
+	TeamID             string          `json:"team_id" validate:"required,uuid"`
+	EmergencyContact   json.RawMessage `json:"emergency_contact,omitempty"`
+	MedicalInfo        json.RawMessage `json:"medical_info,omitempty"`
+	Guardian           GuardianContact `json:"guardian"`
+}
+
+// MinorPlayerRegistration is the result of registering a minor player
+type MinorPlayerRegistration struct {
+	PlayerID  uuid.UUID      `json:"player_id"`
+	TeamID    uuid.UUID      `json:"team_id"`
+	FirstName string         `json:"first_name"`
+	LastName  string         `json:"last_name"`
+	Guardian  PlayerGuardian `json:"guardian"`
+}
+
+// GuardianConsentRequest for POST /api/auth/guardian/players/:playerId/consent
+type GuardianConsentRequest struct {
+	DocumentVersion string `json:"document_version" validate:"required,max=20"`
+	Accept          bool   `json:"accept" validate:"eq=true"`
+}
+
+// GuardedPlayerUpdateRequest for PUT /api/auth/guardian/players/:playerId. Only the
+// fields present are changed.
+type GuardedPlayerUpdateRequest struct {
+	Email             *string         `json:"email,omitempty" validate:"omitempty,email"`
+	Phone             *string         `json:"phone,omitempty" validate:"omitempty,min=10,max=20"`
+	PhotoURL          *string         `json:"photo_url,omitempty" validate:"omitempty,url"`
+	HeightCM          *int            `json:"height_cm,omitempty" validate:"omitempty,min=100,max=250"`
+	WeightKG          *float64        `json:"weight_kg,omitempty" validate:"omitempty,min=20,max=200"`
+	PreferredPosition *string         `json:"preferred_position,omitempty" validate:"omitempty,max=50"`
+	DominantFoot      *string         `json:"dominant_foot,omitempty" validate:"omitempty,oneof=left right both"`
+	BloodType         *string         `json:"blood_type,omitempty" validate:"omitempty,max=5"`
+	EmergencyContact  json.RawMessage `json:"emergency_contact,omitempty"`
+	MedicalInfo       json.RawMessage `json:"medical_info,omitempty"`
+}
+
+// GuardianNotificationsRequest for PUT /api/auth/guardian/players/:playerId/notifications
+type GuardianNotificationsRequest struct {
+	NotifyMatches *bool `json:"notify_matches" validate:"required"`
+}
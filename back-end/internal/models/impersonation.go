@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ImpersonationRequest for POST /api/admin/impersonate/:id
+type ImpersonationRequest struct {
+	Reason string `json:"reason" validate:"required,min=5,max=500"`
+}
+
+// ImpersonationResponse carries a short-lived access token acting as the subject.
+// No refresh token is issued; a new session must be started when it expires.
+type ImpersonationResponse struct {
+	SessionID   uuid.UUID `json:"session_id"`
+	ActorID     uuid.UUID `json:"actor_id"`
+	SubjectID   uuid.UUID `json:"subject_id"`
+	Email       string    `json:"email"`
+	FirstName   string    `json:"first_name"`
+	LastName    string    `json:"last_name"`
+	PrimaryRole string    `json:"primary_role"`
+	Token       string    `json:"token"`
+	ExpiresIn   int       `json:"expires_in"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
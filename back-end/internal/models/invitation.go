@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Invitation represents an account invitation sent by an admin
+type Invitation struct {
+	InvitationID    uuid.UUID  `json:"invitation_id" db:"invitation_id"`
+	UserID          uuid.UUID  `json:"user_id" db:"user_id"`
+	Email           string     `json:"email" db:"email"`
+	FirstName       string     `json:"first_name" db:"first_name"`
+	LastName        string     `json:"last_name" db:"last_name"`
+	RoleName        string     `json:"role_name" db:"role_name"`
+	CityID          *uuid.UUID `json:"city_id" db:"city_id"`
+	SportID         *uuid.UUID `json:"sport_id" db:"sport_id"`
+	InvitedByUserID uuid.UUID  `json:"invited_by_user_id" db:"invited_by_user_id"`
+	Status          string     `json:"status"`
+	ExpiresAt       time.Time  `json:"expires_at" db:"expires_at"`
+	AcceptedAt      *time.Time `json:"accepted_at" db:"accepted_at"`
+	RevokedAt       *time.Time `json:"revoked_at" db:"revoked_at"`
+	SendCount       int        `json:"send_count" db:"send_count"`
+	LastSentAt      time.Time  `json:"last_sent_at" db:"last_sent_at"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+}
+
+// InvitationListRequest for GET /api/admin/invitations
+type InvitationListRequest struct {
+	Status string `query:"status" validate:"omitempty,oneof=pending accepted revoked expired"`
+}
+
+// InvitationDetailsResponse is shown to the invitee before accepting
+type InvitationDetailsResponse struct {
+	Email     string    `json:"email"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	RoleName  string    `json:"role_name"`
+	CityName  *string   `json:"city_name,omitempty"`
+	SportName *string   `json:"sport_name,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// AcceptInvitationRequest sets the invitee's own password
+type AcceptInvitationRequest struct {
+	Token           string `json:"token" validate:"required"`
+	Password        string `json:"password" validate:"required,min=8"`
+	ConfirmPassword string `json:"confirm_password" validate:"required,eqfield=Password"`
+	EnableTwoFactor bool   `json:"enable_two_factor"`
+}
+
+// AcceptInvitationResponse for POST /api/auth/invitations/accept
+type AcceptInvitationResponse struct {
+	UserID         uuid.UUID         `json:"user_id"`
+	Email          string            `json:"email"`
+	TwoFactorSetup *Setup2FAResponse `json:"two_factor_setup,omitempty"`
+	Message        string            `json:"message"`
+}
+
+// Invitation status constants
+const (
+	InvitationStatusPending  = "pending"
+	InvitationStatusAccepted = "accepted"
+	InvitationStatusRevoked  = "revoked"
+	InvitationStatusExpired  = "expired"
+)
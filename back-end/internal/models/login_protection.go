@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IPBlock is a temporary login block for an IP address or subnet
+type IPBlock struct {
+	BlockID      uuid.UUID  `json:"block_id" db:"block_id"`
+	Network      string     `json:"network" db:"network"`
+	Reason       string     `json:"reason" db:"reason"`
+	FailureCount int        `json:"failure_count" db:"failure_count"`
+	ExpiresAt    time.Time  `json:"expires_at" db:"expires_at"`
+	LiftedBy     *uuid.UUID `json:"lifted_by,omitempty" db:"lifted_by"`
+	LiftedAt     *time.Time `json:"lifted_at,omitempty" db:"lifted_at"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	Active       bool       `json:"active"`
+}
+
+// IPBlockListRequest for GET /api/admin/ip-blocks
+type IPBlockListRequest struct {
+	Status string `query:"status" validate:"omitempty,oneof=active all"`
+	Limit  int    `query:"limit" validate:"omitempty,min=1,max=200"`
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Match statuses during which events can be recorded
+const (
+	MatchStatusLive     = "live"
+	MatchStatusHalfTime = "half_time"
+)
+
+// Match is a match as read by machine integrations (scoreboards, overlays)
+type Match struct {
+	MatchID         uuid.UUID    `json:"match_id" db:"match_id"`
+	TournamentID    uuid.UUID    `json:"tournament_id" db:"tournament_id"`
+	TournamentName  string       `json:"tournament_name"`
+	CityID          uuid.UUID    `json:"city_id"`
+	SportID         uuid.UUID    `json:"sport_id" db:"sport_id"`
+	HomeTeamID      uuid.UUID    `json:"home_team_id" db:"home_team_id"`
+	HomeTeamName    string       `json:"home_team_name"`
+	AwayTeamID      uuid.UUID    `json:"away_team_id" db:"away_team_id"`
+	AwayTeamName    string       `json:"away_team_name"`
+	MatchDate       time.Time    `json:"match_date" db:"match_date"`
+	MatchTime       string       `json:"match_time" db:"match_time"`
+	Venue           *string      `json:"venue" db:"venue"`
+	HomeTeamScore   int          `json:"home_team_score" db:"home_team_score"`
+	AwayTeamScore   int          `json:"away_team_score" db:"away_team_score"`
+	Status          string       `json:"status" db:"status"`
+	ActualStartTime *time.Time   `json:"actual_start_time" db:"actual_start_time"`
+	ActualEndTime   *time.Time   `json:"actual_end_time" db:"actual_end_time"`
+	UpdatedAt       time.Time    `json:"updated_at" db:"updated_at"`
+	Events          []MatchEvent `json:"events,omitempty"` // Only when a single match is requested
+}
+
+// MatchEvent is a goal, card, substitution or other event of a match
+type MatchEvent struct {
+	EventID         uuid.UUID  `json:"event_id" db:"event_id"`
+	MatchID         uuid.UUID  `json:"match_id" db:"match_id"`
+	TeamID          uuid.UUID  `json:"team_id" db:"team_id"`
+	PlayerID        *uuid.UUID `json:"player_id" db:"player_id"`
+	RelatedPlayerID *uuid.UUID `json:"related_player_id" db:"related_player_id"`
+	EventType       string     `json:"event_type" db:"event_type"`
+	EventMinute     int        `json:"event_minute" db:"event_minute"`
+	AdditionalTime  int        `json:"additional_time" db:"additional_time"`
+	Description     *string    `json:"description" db:"description"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+}
+
+// MatchListRequest for GET /api/integrations/matches. Results are limited to the scope
+// of the API key.
+type MatchListRequest struct {
+	TournamentID string `query:"tournament_id" validate:"omitempty,uuid"`
+	Status       string `query:"status" validate:"omitempty,oneof=scheduled live half_time completed cancelled postponed abandoned"`
+	Date         string `query:"date" validate:"omitempty,datetime=2006-01-02"`
+	Limit        int    `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+// MatchEventCreateRequest for POST /api/integrations/matches/:matchId/events
+type MatchEventCreateRequest struct {
+	TeamID          uuid.UUID  `json:"team_id" validate:"required"`
+	PlayerID        *uuid.UUID `json:"player_id,omitempty"`
+	RelatedPlayerID *uuid.UUID `json:"related_player_id,omitempty"`
+	EventType       string     `json:"event_type" validate:"required,oneof=goal own_goal penalty_goal missed_penalty yellow_card red_card substitution_in substitution_out assist corner_kick free_kick offside foul injury timeout other"`
+	EventMinute     int        `json:"event_minute" validate:"min=0,max=200"`
+	AdditionalTime  int        `json:"additional_time" validate:"min=0,max=30"`
+	Description     string     `json:"description" validate:"omitempty,max=500"`
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ViewDefinition describes a named view in the permission registry
+type ViewDefinition struct {
+	Name         string   `json:"view_name"`
+	Label        string   `json:"label"`
+	DefaultRoles []string `json:"default_roles"`
+}
+
+// EffectivePermission is the resolved access to a single view
+type EffectivePermission struct {
+	ViewName string `json:"view_name"`
+	Label    string `json:"label"`
+	Allowed  bool   `json:"allowed"`
+	Source   string `json:"source"` // default, role, user or super_admin
+}
+
+// EffectivePermissionsResponse for GET /api/auth/permissions
+type EffectivePermissionsResponse struct {
+	UserID      uuid.UUID             `json:"user_id"`
+	PrimaryRole string                `json:"primary_role"`
+	Views       map[string]bool       `json:"views"`
+	Permissions []EffectivePermission `json:"permissions"`
+}
+
+// PermissionOverride is a stored user_view_permissions row with readable context
+type PermissionOverride struct {
+	PermissionID       uuid.UUID  `json:"permission_id"`
+	UserID             *uuid.UUID `json:"user_id"`
+	UserEmail          *string    `json:"user_email,omitempty"`
+	UserName           *string    `json:"user_name,omitempty"`
+	RoleName           *string    `json:"role_name"`
+	ViewName           string     `json:"view_name"`
+	IsAllowed          bool       `json:"is_allowed"`
+	ConfiguredByUserID uuid.UUID  `json:"configured_by_user_id"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+}
+
+// PermissionMatrixResponse for the super admin permission review
+type PermissionMatrixResponse struct {
+	Views         []ViewDefinition           `json:"views"`
+	Roles         []string                   `json:"roles"`
+	RoleMatrix    map[string]map[string]bool `json:"role_matrix"` // view -> role -> allowed
+	UserOverrides []PermissionOverride       `json:"user_overrides"`
+	RoleOverrides []PermissionOverride       `json:"role_overrides"`
+}
+
+// Permission source constants
+const (
+	PermissionSourceDefault    = "default"
+	PermissionSourceRole       = "role"
+	PermissionSourceUser       = "user"
+	PermissionSourceSuperAdmin = "super_admin"
+)
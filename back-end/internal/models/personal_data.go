@@ -0,0 +1,154 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Data deletion request states
+const (
+	DataDeletionStatusPending   = "pending"   // Waiting for admin review
+	DataDeletionStatusApproved  = "approved"  // Anonymized once the grace period ends
+	DataDeletionStatusRejected  = "rejected"  // Refused by an admin, e.g. a legal retention duty
+	DataDeletionStatusCancelled = "cancelled" // Withdrawn by the user
+	DataDeletionStatusCompleted = "completed" // Personal data anonymized
+	DataDeletionStatusFailed    = "failed"    // Anonymization failed; see LastError
+)
+
+// DataDeletionRequest is a data-subject request to anonymize the user's personal data
+type DataDeletionRequest struct {
+	RequestID        uuid.UUID  `json:"request_id" db:"request_id"`
+	UserID           uuid.UUID  `json:"user_id" db:"user_id"`
+	Email            string     `json:"email,omitempty"`
+	FirstName        string     `json:"first_name,omitempty"`
+	LastName         string     `json:"last_name,omitempty"`
+	Reason           *string    `json:"reason,omitempty" db:"reason"`
+	Status           string     `json:"status" db:"status"`
+	RequestedAt      time.Time  `json:"requested_at" db:"requested_at"`
+	ReviewedByUserID *uuid.UUID `json:"reviewed_by_user_id,omitempty" db:"reviewed_by_user_id"`
+	ReviewedAt       *time.Time `json:"reviewed_at,omitempty" db:"reviewed_at"`
+	ReviewNotes      *string    `json:"review_notes,omitempty" db:"review_notes"`
+	ScheduledFor     *time.Time `json:"scheduled_for,omitempty" db:"scheduled_for"`
+	CompletedAt      *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+	LastError        *string    `json:"last_error,omitempty" db:"last_error"`
+}
+
+// DataDeletionCreateRequest for POST /api/auth/personal-data/deletion
+type DataDeletionCreateRequest struct {
+	Reason string `json:"reason" validate:"omitempty,max=1000"`
+}
+
+// DataDeletionReviewRequest approves or rejects a deletion request. Rejections need notes.
+type DataDeletionReviewRequest struct {
+	Notes string `json:"notes" validate:"omitempty,max=1000"`
+}
+
+// DataDeletionListRequest for GET /api/admin/data-deletion-requests
+type DataDeletionListRequest struct {
+	Status string `query:"status" validate:"omitempty,oneof=pending approved rejected cancelled completed failed"`
+}
+
+// PersonalDataExport is everything stored about a user, returned for data-subject access requests
+type PersonalDataExport struct {
+	GeneratedAt      time.Time                    `json:"generated_at"`
+	Profile          PersonalDataProfile          `json:"profile"`
+	Players          []PersonalDataPlayer         `json:"players"`
+	TeamMemberships  []PersonalDataTeamMembership `json:"team_memberships"`
+	Roles            []PersonalDataRole           `json:"roles"`
+	Statistics       []PersonalDataStatistic      `json:"statistics"`
+	AuditEntries     []PersonalDataAuditEntry     `json:"audit_entries"`
+	DeletionRequests []DataDeletionRequest        `json:"deletion_requests"`
+}
+
+// PersonalDataProfile is the exported user_profiles row, without credentials
+type PersonalDataProfile struct {
+	UserID             uuid.UUID  `json:"user_id"`
+	Email              string     `json:"email"`
+	FirstName          string     `json:"first_name"`
+	LastName           string     `json:"last_name"`
+	Phone              *string    `json:"phone"`
+	Identification     *string    `json:"identification"`
+	IdentificationType *string    `json:"identification_type"`
+	PhotoURL           *string    `json:"photo_url"`
+	PrimaryRole        string     `json:"primary_role"`
+	AccountStatus      string     `json:"account_status"`
+	PreferredLocale    string     `json:"preferred_locale"`
+	TwoFactorEnabled   bool       `json:"two_factor_enabled"`
+	EmailVerifiedAt    *time.Time `json:"email_verified_at"`
+	LastLoginAt        *time.Time `json:"last_login_at"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+}
+
+// PersonalDataPlayer is an exported players row linked to the user
+type PersonalDataPlayer struct {
+	PlayerID           uuid.UUID       `json:"player_id"`
+	FirstName          string          `json:"first_name"`
+	LastName           string          `json:"last_name"`
+	DateOfBirth        time.Time       `json:"date_of_birth"`
+	Identification     *string         `json:"identification"`
+	IdentificationType *string         `json:"identification_type"`
+	BloodType          *string         `json:"blood_type"`
+	Gender             *string         `json:"gender"`
+	Nationality        *string         `json:"nationality"`
+	Email              *string         `json:"email"`
+	Phone              *string         `json:"phone"`
+	PhotoURL           *string         `json:"photo_url"`
+	HeightCM           *int            `json:"height_cm"`
+	WeightKG           *float64        `json:"weight_kg"`
+	EmergencyContact   json.RawMessage `json:"emergency_contact"`
+	MedicalInfo        json.RawMessage `json:"medical_info"`
+	PreferredPosition  *string         `json:"preferred_position"`
+	DominantFoot       *string         `json:"dominant_foot"`
+	CreatedAt          time.Time       `json:"created_at"`
+	RedactedFields     []string        `json:"redacted_fields,omitempty"` // Withheld without the medical data permission
+}
+
+// PersonalDataTeamMembership is a team the user's player record belongs or belonged to
+type PersonalDataTeamMembership struct {
+	TeamID       uuid.UUID  `json:"team_id"`
+	TeamName     string     `json:"team_name"`
+	PlayerID     uuid.UUID  `json:"player_id"`
+	JoinDate     time.Time  `json:"join_date"`
+	LeaveDate    *time.Time `json:"leave_date"`
+	Position     *string    `json:"position"`
+	JerseyNumber *int       `json:"jersey_number"`
+	IsActive     bool       `json:"is_active"`
+}
+
+// PersonalDataRole is a role assignment of the user
+type PersonalDataRole struct {
+	RoleName     string     `json:"role_name"`
+	CityName     *string    `json:"city_name"`
+	SportName    *string    `json:"sport_name"`
+	TournamentID *uuid.UUID `json:"tournament_id,omitempty"`
+	IsActive     bool       `json:"is_active"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// PersonalDataStatistic is a tournament statistics line of the user's player record
+type PersonalDataStatistic struct {
+	PlayerID       uuid.UUID `json:"player_id"`
+	TournamentName string    `json:"tournament_name"`
+	TeamName       string    `json:"team_name"`
+	MatchesPlayed  int       `json:"matches_played"`
+	MinutesPlayed  int       `json:"minutes_played"`
+	GoalsScored    int       `json:"goals_scored"`
+	Assists        int       `json:"assists"`
+	YellowCards    int       `json:"yellow_cards"`
+	RedCards       int       `json:"red_cards"`
+	Wins           int       `json:"wins"`
+	Draws          int       `json:"draws"`
+	Losses         int       `json:"losses"`
+}
+
+// PersonalDataAuditEntry is an audit trail event about the user
+type PersonalDataAuditEntry struct {
+	EventType   string    `json:"event_type"`
+	Description string    `json:"description"`
+	IPAddress   *string   `json:"ip_address"`
+	UserAgent   *string   `json:"user_agent"`
+	Timestamp   time.Time `json:"timestamp"`
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+// Search result types
+const (
+	SearchTypeTeam       = "team"
+	SearchTypePlayer     = "player"
+	SearchTypeTournament = "tournament"
+	SearchTypeUser       = "user"
+)
+
+// SearchRequest for GET /api/search. Filters given here take precedence over the ones
+// read from the search text.
+type SearchRequest struct {
+	Query   string `query:"q" validate:"omitempty,max=200"`
+	Types   string `query:"types" validate:"omitempty,max=100"` // Comma separated, all visible types when empty
+	CityID  string `query:"city_id" validate:"omitempty,uuid"`
+	SportID string `query:"sport_id" validate:"omitempty,uuid"`
+	MaxAge  int    `query:"max_age" validate:"omitempty,min=5,max=99"` // Players of this age or younger
+	Limit   int    `query:"limit" validate:"omitempty,min=1,max=50"`
+}
+
+// SearchResult is a ranked match of any type
+type SearchResult struct {
+	Type      string     `json:"type"`
+	ID        uuid.UUID  `json:"id"`
+	Title     string     `json:"title"`
+	Subtitle  *string    `json:"subtitle,omitempty"` // Short name, current team, location or email
+	CityID    *uuid.UUID `json:"city_id,omitempty"`
+	CityName  *string    `json:"city_name,omitempty"`
+	SportID   *uuid.UUID `json:"sport_id,omitempty"`
+	SportName *string    `json:"sport_name,omitempty"`
+	Rank      float64    `json:"rank"`
+}
+
+// SearchInterpretation is how the search text was read: the words matched against
+// names and the filters recognized in it
+type SearchInterpretation struct {
+	Text      string     `json:"text"`
+	Types     []string   `json:"types"`
+	CityID    *uuid.UUID `json:"city_id,omitempty"`
+	CityName  *string    `json:"city_name,omitempty"`
+	SportID   *uuid.UUID `json:"sport_id,omitempty"`
+	SportName *string    `json:"sport_name,omitempty"`
+	MaxAge    *int       `json:"max_age,omitempty"`
+}
+
+// SearchResponse for GET /api/search
+type SearchResponse struct {
+	Results     []SearchResult       `json:"results"`
+	Interpreted SearchInterpretation `json:"interpreted"`
+	PublicOnly  bool                 `json:"public_only"` // Anonymous callers only get public results
+}
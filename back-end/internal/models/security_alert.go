@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SecurityAlert is an incident raised from the audit log by an alert rule
+type SecurityAlert struct {
+	AlertID         uuid.UUID  `json:"alert_id" db:"alert_id"`
+	RuleName        string     `json:"rule_name" db:"rule_name"`
+	Severity        string     `json:"severity" db:"severity"`
+	Title           string     `json:"title" db:"title"`
+	Description     string     `json:"description" db:"description"`
+	UserID          *uuid.UUID `json:"user_id,omitempty" db:"user_id"`
+	IPAddresses     []string   `json:"ip_addresses" db:"ip_addresses"`
+	EventCount      int        `json:"event_count" db:"event_count"`
+	SuppressedCount int        `json:"suppressed_count" db:"suppressed_count"`
+	FirstEventAt    time.Time  `json:"first_event_at" db:"first_event_at"`
+	LastEventAt     time.Time  `json:"last_event_at" db:"last_event_at"`
+	LastNotifiedAt  *time.Time `json:"last_notified_at" db:"last_notified_at"`
+	Status          string     `json:"status" db:"status"`
+	AcknowledgedBy  *uuid.UUID `json:"acknowledged_by,omitempty" db:"acknowledged_by"`
+	AcknowledgedAt  *time.Time `json:"acknowledged_at,omitempty" db:"acknowledged_at"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+}
+
+// SecurityAlertListRequest for GET /api/admin/security-alerts
+type SecurityAlertListRequest struct {
+	Status string `query:"status" validate:"omitempty,oneof=open acknowledged"`
+	Limit  int    `query:"limit" validate:"omitempty,min=1,max=200"`
+}
+
+// Security alert status constants
+const (
+	SecurityAlertStatusOpen         = "open"
+	SecurityAlertStatusAcknowledged = "acknowledged"
+)
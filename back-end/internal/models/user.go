@@ -14,6 +14,7 @@ type UserProfile struct {
 	LastName            string     `json:"last_name" db:"last_name"`
 	Phone               *string    `json:"phone" db:"phone"`
 	Identification      *string    `json:"identification" db:"identification"`
+	IdentificationType  *string    `json:"identification_type" db:"identification_type"`
 	PhotoURL            *string    `json:"photo_url" db:"photo_url"`
 	PrimaryRole         string     `json:"primary_role" db:"primary_role"`
 	IsActive            bool       `json:"is_active" db:"is_active"`
@@ -25,6 +26,10 @@ type UserProfile struct {
 	TokenExpirationDate *time.Time `json:"-" db:"token_expiration_date"` // Never expose in JSON
 	TwoFactorSecret     *string    `json:"-" db:"two_factor_secret"`     // Never expose in JSON
 	TwoFactorEnabled    bool       `json:"two_factor_enabled" db:"two_factor_enabled"`
+	EmailVerifiedAt     *time.Time `json:"email_verified_at" db:"email_verified_at"`
+	PreferredLocale     string     `json:"preferred_locale" db:"preferred_locale"`
+	PasswordChangedAt   time.Time  `json:"password_changed_at" db:"password_changed_at"`
+	TokenVersion        int        `json:"-" db:"token_version"`
 	CreatedAt           time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt           time.Time  `json:"updated_at" db:"updated_at"`
 }
@@ -35,6 +40,7 @@ type UserRoleByCitySport struct {
 	UserID           uuid.UUID  `json:"user_id" db:"user_id"`
 	CityID           *uuid.UUID `json:"city_id" db:"city_id"`
 	SportID          *uuid.UUID `json:"sport_id" db:"sport_id"`
+	TournamentID     *uuid.UUID `json:"tournament_id,omitempty" db:"tournament_id"`
 	RoleName         string     `json:"role_name" db:"role_name"`
 	AssignedByUserID *uuid.UUID `json:"assigned_by_user_id" db:"assigned_by_user_id"`
 	IsActive         bool       `json:"is_active" db:"is_active"`
@@ -63,17 +69,19 @@ type SignupRequest struct {
 }
 
 type SignupResponse struct {
-	UserID    uuid.UUID `json:"user_id"`
-	FirstName string    `json:"first_name"`
-	LastName  string    `json:"last_name"`
-	Email     string    `json:"email"`
-	Message   string    `json:"message"`
+	UserID                    uuid.UUID `json:"user_id"`
+	FirstName                 string    `json:"first_name"`
+	LastName                  string    `json:"last_name"`
+	Email                     string    `json:"email"`
+	RequiresEmailVerification bool      `json:"requires_email_verification"`
+	Message                   string    `json:"message"`
 }
 
 type LoginRequest struct {
 	Email         string `json:"email" validate:"required,email"`
 	Password      string `json:"password" validate:"required"`
 	TwoFactorCode string `json:"two_factor_code,omitempty"`
+	ChallengeCode string `json:"challenge_code,omitempty"` // emailed code, when the login answered STEP_UP_REQUIRED
 }
 
 type LoginResponse struct {
@@ -87,6 +95,8 @@ type LoginResponse struct {
 	ExpiresIn              int        `json:"expires_in"`
 	RequiresPasswordChange bool       `json:"requires_password_change,omitempty"`
 	PasswordExpiresAt      *time.Time `json:"password_expires_at,omitempty"`
+	AccountStatus          string     `json:"account_status,omitempty"`
+	AccountNotice          string     `json:"account_notice,omitempty"` // e.g. settle_payment for read-only accounts
 }
 
 // Password recovery structs
@@ -99,6 +109,36 @@ type PasswordResetRequest struct {
 	NewPassword string `json:"new_password" validate:"required,min=8"`
 }
 
+// Email verification structs
+type EmailVerificationRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type EmailChangeRequest struct {
+	NewEmail string `json:"new_email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+}
+
+// LocaleUpdateRequest sets the language used for the user's emails
+type LocaleUpdateRequest struct {
+	Locale string `json:"locale" validate:"required,oneof=es-CO en"`
+}
+
+type EmailChangeStatusResponse struct {
+	RequestID    uuid.UUID  `json:"request_id"`
+	NewEmail     string     `json:"new_email"`
+	OldConfirmed bool       `json:"old_confirmed"`
+	NewConfirmed bool       `json:"new_confirmed"`
+	Completed    bool       `json:"completed"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty"`
+	Message      string     `json:"message"`
+}
+
 // 2FA structs
 type Setup2FAResponse struct {
 	Secret string `json:"secret"`
@@ -116,26 +156,32 @@ type AdminRegistrationRequest struct {
 	Email          string `json:"email" validate:"required,email"`
 	Phone          string `json:"phone,omitempty" validate:"omitempty,min=10,max=20"`
 	Identification string `json:"identification,omitempty" validate:"omitempty,min=5,max=50"`
-	CityID         string `json:"city_id" validate:"required,uuid"`
-	SportID        string `json:"sport_id" validate:"required,uuid"`
-	AccountStatus  string `json:"account_status,omitempty" validate:"omitempty,oneof=active suspended payment_pending disabled"`
-	PhotoURL       string `json:"photo_url,omitempty" validate:"omitempty,url"`
+	// IdentificationType defaults to CC when an identification is given
+	IdentificationType string `json:"identification_type,omitempty" validate:"omitempty,oneof=CC TI RC CE PEP PPT PA NIT"`
+	CityID             string `json:"city_id" validate:"required,uuid"`
+	SportID            string `json:"sport_id" validate:"required,uuid"`
+	AccountStatus      string `json:"account_status,omitempty" validate:"omitempty,oneof=active suspended payment_pending disabled"`
+	PhotoURL           string `json:"photo_url,omitempty" validate:"omitempty,url"`
+	// PreferredLocale is the language of the invitation and later emails (es-CO by default)
+	PreferredLocale string `json:"preferred_locale,omitempty" validate:"omitempty,oneof=es-CO en"`
 }
 
 type AdminRegistrationResponse struct {
-	UserID            uuid.UUID `json:"user_id"`
-	FirstName         string    `json:"first_name"`
-	LastName          string    `json:"last_name"`
-	Email             string    `json:"email"`
-	Phone             *string   `json:"phone,omitempty"`
-	Identification    *string   `json:"identification,omitempty"`
-	CityID            uuid.UUID `json:"city_id"`
-	SportID           uuid.UUID `json:"sport_id"`
-	AccountStatus     string    `json:"account_status"`
-	PhotoURL          *string   `json:"photo_url,omitempty"`
-	RoleAssignmentID  uuid.UUID `json:"role_assignment_id"`
-	TemporaryPassword string    `json:"temporary_password,omitempty"` // Only for development/testing
-	Message           string    `json:"message"`
+	UserID              uuid.UUID `json:"user_id"`
+	FirstName           string    `json:"first_name"`
+	LastName            string    `json:"last_name"`
+	Email               string    `json:"email"`
+	Phone               *string   `json:"phone,omitempty"`
+	Identification      *string   `json:"identification,omitempty"`
+	IdentificationType  *string   `json:"identification_type,omitempty"`
+	CityID              uuid.UUID `json:"city_id"`
+	SportID             uuid.UUID `json:"sport_id"`
+	AccountStatus       string    `json:"account_status"`
+	PhotoURL            *string   `json:"photo_url,omitempty"`
+	RoleAssignmentID    uuid.UUID `json:"role_assignment_id"`
+	InvitationID        uuid.UUID `json:"invitation_id"`
+	InvitationExpiresAt time.Time `json:"invitation_expires_at"`
+	Message             string    `json:"message"`
 }
 
 // Email validation response
@@ -145,16 +191,21 @@ type EmailValidationResponse struct {
 	Message  string `json:"message,omitempty"`
 }
 
-// Admin list structs
+// Admin list structs. Pagination works as for UserListRequest.
 type AdminListRequest struct {
-	Page      int    `query:"page" validate:"omitempty,min=1"`
-	Limit     int    `query:"limit" validate:"omitempty,min=1,max=100"`
-	Search    string `query:"search" validate:"omitempty,max=100"`
-	CityID    string `query:"city_id" validate:"omitempty,uuid"`
-	SportID   string `query:"sport_id" validate:"omitempty,uuid"`
-	Status    string `query:"status" validate:"omitempty,oneof=active suspended payment_pending disabled"`
-	SortBy    string `query:"sort_by" validate:"omitempty,oneof=first_name last_name email created_at last_login_at"`
-	SortOrder string `query:"sort_order" validate:"omitempty,oneof=asc desc"`
+	Cursor        string `query:"cursor" validate:"omitempty,max=500"`
+	Page          int    `query:"page" validate:"omitempty,min=1"`
+	Limit         int    `query:"limit" validate:"omitempty,min=1,max=100"`
+	Search        string `query:"search" validate:"omitempty,max=100"`
+	CityID        string `query:"city_id" validate:"omitempty,uuid"`
+	SportID       string `query:"sport_id" validate:"omitempty,uuid"`
+	Status        string `query:"status" validate:"omitempty,oneof=active suspended payment_pending disabled"`
+	CreatedFrom   string `query:"created_from" validate:"omitempty,datetime=2006-01-02"`
+	CreatedTo     string `query:"created_to" validate:"omitempty,datetime=2006-01-02"`
+	LastLoginFrom string `query:"last_login_from" validate:"omitempty,datetime=2006-01-02"`
+	LastLoginTo   string `query:"last_login_to" validate:"omitempty,datetime=2006-01-02"`
+	SortBy        string `query:"sort_by" validate:"omitempty,oneof=first_name last_name email created_at last_login_at"`
+	SortOrder     string `query:"sort_order" validate:"omitempty,oneof=asc desc"`
 }
 
 type AdminSummary struct {
@@ -174,12 +225,13 @@ type AdminSummary struct {
 
 type AdminListResponse struct {
 	Admins     []AdminSummary `json:"admins"`
-	Total      int            `json:"total"`
-	Page       int            `json:"page"`
+	Total      *int           `json:"total,omitempty"`
+	Page       int            `json:"page,omitempty"`
 	Limit      int            `json:"limit"`
-	TotalPages int            `json:"total_pages"`
+	TotalPages int            `json:"total_pages,omitempty"`
 	HasNext    bool           `json:"has_next"`
 	HasPrev    bool           `json:"has_prev"`
+	NextCursor string         `json:"next_cursor,omitempty"`
 }
 
 // User role constants
@@ -202,6 +254,18 @@ const (
 	AccountStatusDisabled       = "disabled"
 )
 
+// Identification document types. Players are people, so NIT only applies to user profiles.
+const (
+	IdentificationTypeCC  = "CC"  // Cédula de ciudadanía, from age 18
+	IdentificationTypeTI  = "TI"  // Tarjeta de identidad, ages 7 to 17
+	IdentificationTypeRC  = "RC"  // Registro civil, under 7
+	IdentificationTypeCE  = "CE"  // Cédula de extranjería
+	IdentificationTypePEP = "PEP" // Permiso especial de permanencia
+	IdentificationTypePPT = "PPT" // Permiso por protección temporal
+	IdentificationTypePA  = "PA"  // Passport
+	IdentificationTypeNIT = "NIT" // Organizations, with check digit
+)
+
 // User management request/response structs
 
 // UserUpdateRequest for updating user profiles
@@ -210,17 +274,21 @@ type UserUpdateRequest struct {
 	LastName       *string `json:"last_name,omitempty" validate:"omitempty,min=2,max=100"`
 	Phone          *string `json:"phone,omitempty" validate:"omitempty,min=10,max=20"`
 	Identification *string `json:"identification,omitempty" validate:"omitempty,min=5,max=50"`
-	PhotoURL       *string `json:"photo_url,omitempty" validate:"omitempty,url"`
-	IsActive       *bool   `json:"is_active,omitempty"`
-	AccountStatus  *string `json:"account_status,omitempty" validate:"omitempty,oneof=active suspended payment_pending disabled"`
+	// IdentificationType is checked against the identification, new or current
+	IdentificationType *string `json:"identification_type,omitempty" validate:"omitempty,oneof=CC TI RC CE PEP PPT PA NIT"`
+	PhotoURL           *string `json:"photo_url,omitempty" validate:"omitempty,url"`
+	IsActive           *bool   `json:"is_active,omitempty"`
+	AccountStatus      *string `json:"account_status,omitempty" validate:"omitempty,oneof=active suspended payment_pending disabled"`
+	PreferredLocale    *string `json:"preferred_locale,omitempty" validate:"omitempty,oneof=es-CO en"`
 }
 
 // RoleAssignmentRequest for assigning roles to users
 type RoleAssignmentRequest struct {
-	UserID   uuid.UUID  `json:"user_id" validate:"required"`
-	CityID   *uuid.UUID `json:"city_id,omitempty"`
-	SportID  *uuid.UUID `json:"sport_id,omitempty"`
-	RoleName string     `json:"role_name" validate:"required,oneof=city_admin tournament_admin owner coach referee player client"`
+	UserID       uuid.UUID  `json:"user_id" validate:"required"`
+	CityID       *uuid.UUID `json:"city_id,omitempty"`
+	SportID      *uuid.UUID `json:"sport_id,omitempty"`
+	TournamentID *uuid.UUID `json:"tournament_id,omitempty"` // Required for tournament_admin
+	RoleName     string     `json:"role_name" validate:"required,oneof=city_admin tournament_admin owner coach referee player client"`
 }
 
 // ViewPermissionRequest for setting view permissions
@@ -237,14 +305,44 @@ type AccountStatusUpdateRequest struct {
 	Reason string `json:"reason,omitempty"`
 }
 
-// UserListRequest for paginated user listing
+// AccountLockRequest for locking an account by an admin; without a duration
+// the lockout policy's maximum lock duration is used
+type AccountLockRequest struct {
+	DurationMinutes int    `json:"duration_minutes,omitempty" validate:"omitempty,min=1,max=43200"`
+	Reason          string `json:"reason" validate:"required,max=500"`
+}
+
+// AccountUnlockRequest for lifting a lock and resetting failed login attempts
+type AccountUnlockRequest struct {
+	Reason string `json:"reason,omitempty" validate:"max=500"`
+}
+
+// AccountLockStatus is the lock state of an account after a lock or unlock
+type AccountLockStatus struct {
+	UserID              uuid.UUID  `json:"user_id"`
+	Locked              bool       `json:"locked"`
+	LockedUntil         *time.Time `json:"locked_until,omitempty"`
+	FailedLoginAttempts int        `json:"failed_login_attempts"`
+}
+
+// UserListRequest for user listing and export. Pages follow the next_cursor of the previous
+// response; page numbers are still accepted but shift while users are added or removed.
 type UserListRequest struct {
+	Cursor        string `query:"cursor" validate:"omitempty,max=500"`
 	Page          int    `query:"page" validate:"omitempty,min=1"`
 	Limit         int    `query:"limit" validate:"omitempty,min=1,max=100"`
 	Search        string `query:"search" validate:"omitempty,max=100"`
 	Role          string `query:"role" validate:"omitempty,oneof=super_admin city_admin tournament_admin owner coach referee player client"`
 	AccountStatus string `query:"account_status" validate:"omitempty,oneof=active suspended payment_pending disabled"`
 	IsActive      *bool  `query:"is_active" validate:"omitempty"`
+	// CityID, SportID and AssignedRole must all match one active role assignment
+	CityID        string `query:"city_id" validate:"omitempty,uuid"`
+	SportID       string `query:"sport_id" validate:"omitempty,uuid"`
+	AssignedRole  string `query:"assigned_role" validate:"omitempty,oneof=super_admin city_admin tournament_admin owner coach referee player client"`
+	CreatedFrom   string `query:"created_from" validate:"omitempty,datetime=2006-01-02"`
+	CreatedTo     string `query:"created_to" validate:"omitempty,datetime=2006-01-02"`
+	LastLoginFrom string `query:"last_login_from" validate:"omitempty,datetime=2006-01-02"`
+	LastLoginTo   string `query:"last_login_to" validate:"omitempty,datetime=2006-01-02"`
 	SortBy        string `query:"sort_by" validate:"omitempty,oneof=first_name last_name email primary_role created_at last_login_at"`
 	SortOrder     string `query:"sort_order" validate:"omitempty,oneof=asc desc"`
 }
@@ -264,15 +362,17 @@ type UserSummary struct {
 	CreatedAt     time.Time  `json:"created_at"`
 }
 
-// UserListResponse for paginated user responses
+// UserListResponse for paginated user responses. Totals are only counted for the first
+// page or a page number, not when following a cursor.
 type UserListResponse struct {
 	Users      []UserSummary `json:"users"`
-	Total      int           `json:"total"`
-	Page       int           `json:"page"`
+	Total      *int          `json:"total,omitempty"`
+	Page       int           `json:"page,omitempty"`
 	Limit      int           `json:"limit"`
-	TotalPages int           `json:"total_pages"`
+	TotalPages int           `json:"total_pages,omitempty"`
 	HasNext    bool          `json:"has_next"`
 	HasPrev    bool          `json:"has_prev"`
+	NextCursor string        `json:"next_cursor,omitempty"`
 }
 
 // RoleAssignmentResponse for role assignment responses
@@ -281,6 +381,7 @@ type RoleAssignmentResponse struct {
 	UserID           uuid.UUID  `json:"user_id"`
 	CityID           *uuid.UUID `json:"city_id"`
 	SportID          *uuid.UUID `json:"sport_id"`
+	TournamentID     *uuid.UUID `json:"tournament_id,omitempty"`
 	RoleName         string     `json:"role_name"`
 	AssignedByUserID *uuid.UUID `json:"assigned_by_user_id"`
 	IsActive         bool       `json:"is_active"`
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// User import modes
+const (
+	UserImportModeAtomic  = "atomic"  // Every row commits in one transaction, or none does
+	UserImportModePartial = "partial" // Each row commits on its own; failed rows can be retried by resuming
+)
+
+// User import states
+const (
+	UserImportStatusRunning   = "running"
+	UserImportStatusCompleted = "completed"
+	UserImportStatusPartial   = "partial"
+	UserImportStatusFailed    = "failed"
+)
+
+// User import row outcomes
+const (
+	UserImportRowValid    = "valid"    // Dry run: the row would be imported
+	UserImportRowInvalid  = "invalid"  // The row failed validation
+	UserImportRowImported = "imported" // The row was committed
+	UserImportRowFailed   = "failed"   // The row passed validation but could not be committed
+	UserImportRowSkipped  = "skipped"  // Already imported by the resumed import
+)
+
+// UserImportRequest describes an uploaded import file. City and sport apply to every
+// row; Role is used for rows without a role column.
+type UserImportRequest struct {
+	FileName       string     `json:"file_name"`
+	CityID         uuid.UUID  `json:"city_id"`
+	SportID        uuid.UUID  `json:"sport_id"`
+	TournamentID   *uuid.UUID `json:"tournament_id,omitempty"`
+	Role           string     `json:"role,omitempty"`
+	DryRun         bool       `json:"dry_run"`
+	Mode           string     `json:"mode"`
+	ResumeImportID *uuid.UUID `json:"resume_import_id,omitempty"`
+}
+
+// UserImportRow is one parsed row of an import file
+type UserImportRow struct {
+	RowNumber          int    `json:"row"`
+	Role               string `json:"role"`
+	FirstName          string `json:"first_name"`
+	LastName           string `json:"last_name"`
+	Email              string `json:"email"`
+	Phone              string `json:"phone,omitempty"`
+	Identification     string `json:"identification,omitempty"`
+	IdentificationType string `json:"identification_type,omitempty"` // CC when empty
+	Team               string `json:"team,omitempty"`                // Team name; owners create it, players join it
+	DateOfBirth        string `json:"date_of_birth,omitempty"`
+	Position           string `json:"position,omitempty"`
+	JerseyNumber       *int   `json:"jersey_number,omitempty"`
+	BloodType          string `json:"blood_type,omitempty"`
+}
+
+// UserImportRowResult is the outcome of one row
+type UserImportRowResult struct {
+	RowNumber int        `json:"row"`
+	Email     string     `json:"email"`
+	Role      string     `json:"role"`
+	Status    string     `json:"status"`
+	UserID    *uuid.UUID `json:"user_id,omitempty"`
+	Errors    []string   `json:"errors,omitempty"`
+}
+
+// UserImportReport summarizes a dry run or a committed import
+type UserImportReport struct {
+	ImportID     *uuid.UUID            `json:"import_id,omitempty"`
+	FileName     string                `json:"file_name"`
+	DryRun       bool                  `json:"dry_run"`
+	Mode         string                `json:"mode"`
+	Status       string                `json:"status,omitempty"`
+	TotalRows    int                   `json:"total_rows"`
+	ValidRows    int                   `json:"valid_rows"`
+	InvalidRows  int                   `json:"invalid_rows"`
+	ImportedRows int                   `json:"imported_rows"`
+	SkippedRows  int                   `json:"skipped_rows"`
+	FailedRows   int                   `json:"failed_rows"`
+	Rows         []UserImportRowResult `json:"rows"`
+	CreatedAt    *time.Time            `json:"created_at,omitempty"`
+	CompletedAt  *time.Time            `json:"completed_at,omitempty"`
+}
@@ -2,9 +2,11 @@ package server
 
 import (
 	"context"
+	"log/slog"
 	"mowesport/internal/handlers"
 	"mowesport/internal/middleware"
 	"mowesport/internal/models"
+	"mowesport/internal/services"
 	"net/http"
 	"strings"
 	"time"
@@ -30,10 +32,35 @@ func (s *Server) setupRoutes() {
 
 	// JWT configuration
 	jwtConfig := middleware.NewJWTConfig(s.config.JWTSecret)
+	jwtConfig.APIKeys = services.NewAPIKeyService(s.db)
+	jwtConfig.Impersonation = services.NewImpersonationService(s.db, s.config)
+	jwtConfig.Revocation = services.NewAccountStatusService(s.db, s.config)
+
+	// Search (public results for anonymous callers, more for signed-in users)
+	searchHandler := handlers.NewSearchHandler(s.db)
+	api.GET("/search", middleware.GeneralAPIRateLimit()(jwtConfig.OptionalJWTMiddleware()(searchHandler.Search)))
+
+	// Scoped authorization: roles only apply within the caller's assigned city/sport
+	authz := middleware.NewScopeAuthorizer(s.db)
+
+	// Machine integrations (scoreboards, overlays, scorer tablets). API keys only; each
+	// route requires a key permission and the match must lie within the key's scope.
+	integrationHandler := handlers.NewIntegrationHandler(s.db)
+	matchScope := authz.MatchParamScope("matchId")
+	integrations := api.Group("/integrations")
+	integrations.Use(jwtConfig.JWTMiddleware())
+	integrations.Use(middleware.RequireRole(models.RoleAPIClient))
+	integrations.GET("/matches", middleware.RequireAPIPermission(models.APIPermissionMatchesRead)(integrationHandler.ListMatches))
+	integrations.GET("/matches/:matchId", middleware.RequireAPIPermission(models.APIPermissionMatchesRead)(authz.RequireAPIKeyScope(matchScope)(integrationHandler.GetMatch)))
+	integrations.POST("/matches/:matchId/events", middleware.RequireAPIPermission(models.APIPermissionEventsWrite)(authz.RequireAPIKeyScope(matchScope)(integrationHandler.CreateMatchEvent)))
 
 	// Initialize handlers
-	authHandler := handlers.NewAuthHandler(s.db, s.config.JWTSecret)
-	passwordHandler := handlers.NewPasswordHandler(s.db)
+	authHandler := handlers.NewAuthHandler(s.db, s.config)
+	passwordHandler := handlers.NewPasswordHandler(s.db, s.config)
+	permissionHandler := handlers.NewPermissionHandler(s.db)
+
+	// Named view permissions (registry + user_view_permissions overrides)
+	views := middleware.NewViewPermissionGuard(s.db)
 
 	// Auth routes
 	auth := api.Group("/auth")
@@ -45,11 +72,24 @@ func (s *Server) setupRoutes() {
 	auth.POST("/reset-password", authHandler.ResetPassword)
 	auth.POST("/refresh", authHandler.RefreshToken)
 
-	// Protected auth endpoints (require authentication)
+	// Email verification endpoints (links are opened from email, no session required)
+	verificationHandler := handlers.NewEmailVerificationHandler(s.db, s.config)
+	auth.POST("/verify-email", verificationHandler.VerifyEmail)
+	auth.POST("/resend-verification", middleware.VerificationEmailRateLimit()(verificationHandler.ResendVerification))
+	auth.POST("/confirm-email-change", verificationHandler.ConfirmEmailChange)
+
+	// Invitation acceptance (invitees have no password until they accept)
+	invitationHandler := handlers.NewInvitationHandler(s.db, s.config)
+	auth.GET("/invitations/:token", invitationHandler.GetInvitation)
+	auth.POST("/invitations/accept", invitationHandler.AcceptInvitation)
+
+	// Protected auth endpoints (require a user session; API keys are rejected)
 	authProtected := auth.Group("")
 	authProtected.Use(jwtConfig.JWTMiddleware())
+	authProtected.Use(middleware.RequireUserToken())
 	authProtected.POST("/logout", authHandler.Logout)
 	authProtected.GET("/profile", authHandler.GetProfile)
+	authProtected.PUT("/locale", authHandler.UpdateLocale)
 	authProtected.POST("/2fa/setup", authHandler.Setup2FA)
 	authProtected.POST("/2fa/verify", authHandler.Verify2FA)
 	authProtected.POST("/2fa/disable", authHandler.Disable2FA)
@@ -57,63 +97,176 @@ func (s *Server) setupRoutes() {
 	// Password management endpoints
 	authProtected.POST("/change-password", passwordHandler.ChangePassword)
 	authProtected.GET("/password-status", passwordHandler.CheckPasswordStatus)
+	authProtected.POST("/change-email", verificationHandler.RequestEmailChange)
+
+	// Effective view permissions for the current user
+	authProtected.GET("/permissions", permissionHandler.GetMyPermissions)
+
+	// Habeas Data: export of the user's personal data and deletion requests
+	personalDataHandler := handlers.NewPersonalDataHandler(s.db, s.config)
+	authProtected.GET("/personal-data", personalDataHandler.ExportMyData)
+	authProtected.POST("/personal-data/deletion", personalDataHandler.RequestDeletion)
+	authProtected.GET("/personal-data/deletion", personalDataHandler.GetMyDeletionRequests)
+	authProtected.DELETE("/personal-data/deletion", personalDataHandler.CancelDeletion)
+
+	// Guardians acting on behalf of their minor players
+	guardianHandler := handlers.NewGuardianHandler(s.db, s.config)
+	authProtected.GET("/guardian/players", guardianHandler.ListMyPlayers)
+	authProtected.GET("/guardian/players/:playerId", guardianHandler.GetMyPlayer)
+	authProtected.PUT("/guardian/players/:playerId", guardianHandler.UpdateMyPlayer)
+	authProtected.POST("/guardian/players/:playerId/consent", guardianHandler.GiveConsent)
+	authProtected.DELETE("/guardian/players/:playerId/consent", guardianHandler.RevokeConsent)
+	authProtected.PUT("/guardian/players/:playerId/notifications", guardianHandler.UpdateNotifications)
 
 	// Protected routes
 	protected := api.Group("/protected")
 	protected.Use(jwtConfig.JWTMiddleware())
+	protected.Use(middleware.RequireUserToken())
 	protected.GET("/profile", s.handleProfile) // Example protected route
 
-	// Admin routes (require authentication)
+	// Admin routes (require a user session; API keys are rejected)
 	admin := api.Group("/admin")
 	admin.Use(jwtConfig.JWTMiddleware())
+	admin.Use(middleware.RequireUserToken())
 
 	// Initialize admin handler
 	adminHandler := handlers.NewAdminHandler(s.db, s.config)
 
 	// Admin registration endpoint (requires super admin)
-	admin.POST("/register", middleware.RequireSuperAdminRole()(adminHandler.RegisterAdmin))
+	admin.POST("/register", middleware.RequireSuperAdminRole()(views.RequireView(services.ViewAdmins)(adminHandler.RegisterAdmin)))
 
 	// Email validation endpoint (requires authentication)
 	admin.GET("/validate-email", adminHandler.ValidateEmail)
 
 	// Admin list endpoint (requires super admin)
-	admin.GET("/list", middleware.RequireSuperAdminRole()(adminHandler.GetAdminList))
-
-	// Password management for admins (requires super admin)
-	admin.POST("/:id/regenerate-password", middleware.RequireSuperAdminRole()(passwordHandler.RegenerateTemporaryPassword))
+	admin.GET("/list", middleware.RequireSuperAdminRole()(views.RequireView(services.ViewAdmins)(adminHandler.GetAdminList)))
+	admin.GET("/list/export", middleware.RequireSuperAdminRole()(views.RequireView(services.ViewAdmins)(adminHandler.ExportAdminList)))
+
+	// View permission matrix review (requires super admin)
+	admin.GET("/permissions/matrix", middleware.RequireSuperAdminRole()(permissionHandler.GetPermissionMatrix))
+
+	// API key management for machine integrations (super admin or city admin)
+	apiKeyHandler := handlers.NewAPIKeyHandler(s.db)
+	admin.POST("/api-keys", middleware.RequireAdminRole()(apiKeyHandler.CreateAPIKey))
+	admin.GET("/api-keys", middleware.RequireAdminRole()(apiKeyHandler.ListAPIKeys))
+	admin.DELETE("/api-keys/:id", middleware.RequireAdminRole()(apiKeyHandler.RevokeAPIKey))
+
+	// Pending invitation management (super admin, or admins within their city/sport)
+	admin.GET("/invitations", middleware.RequireAdminRole()(invitationHandler.ListInvitations))
+	admin.POST("/invitations/:id/resend", middleware.RequireAdminRole()(invitationHandler.ResendInvitation))
+	admin.DELETE("/invitations/:id", middleware.RequireAdminRole()(invitationHandler.RevokeInvitation))
+
+	// Email outbox dead letters (requires super admin)
+	outboxHandler := handlers.NewEmailOutboxHandler(s.db, s.config)
+	admin.GET("/email-outbox", middleware.RequireSuperAdminRole()(outboxHandler.ListMessages))
+	admin.POST("/email-outbox/retry-failed", middleware.RequireSuperAdminRole()(outboxHandler.RetryAllFailed))
+	admin.POST("/email-outbox/:id/retry", middleware.RequireSuperAdminRole()(outboxHandler.RetryMessage))
+
+	// Email template previews with sample data (requires super admin)
+	templateHandler := handlers.NewEmailTemplateHandler(s.db, s.config)
+	admin.GET("/email-templates", middleware.RequireSuperAdminRole()(templateHandler.ListTemplates))
+	admin.GET("/email-templates/:name/preview", middleware.RequireSuperAdminRole()(templateHandler.PreviewTemplate))
+
+	// Security audit trail (requires super admin)
+	auditHandler := handlers.NewAuditHandler(s.db)
+	admin.GET("/audit-events", middleware.RequireSuperAdminRole()(auditHandler.ListEvents))
+	admin.GET("/audit-events/export", middleware.RequireSuperAdminRole()(auditHandler.ExportEvents))
+
+	// Security alerts raised from the audit trail (requires super admin)
+	alertHandler := handlers.NewSecurityAlertHandler(s.db, s.config)
+	admin.GET("/security-alerts", middleware.RequireSuperAdminRole()(alertHandler.ListAlerts))
+	admin.POST("/security-alerts/:id/acknowledge", middleware.RequireSuperAdminRole()(alertHandler.AcknowledgeAlert))
+
+	// Login blocks from cross-account attack detection (requires super admin)
+	ipBlockHandler := handlers.NewIPBlockHandler(s.db, s.config)
+	admin.GET("/ip-blocks", middleware.RequireSuperAdminRole()(ipBlockHandler.ListBlocks))
+	admin.DELETE("/ip-blocks/:id", middleware.RequireSuperAdminRole()(ipBlockHandler.LiftBlock))
+
+	// "View as user" sessions for support (super admin only)
+	impersonationHandler := handlers.NewImpersonationHandler(s.db, s.config)
+	admin.POST("/impersonate/:id", middleware.RequireSuperAdminRole()(impersonationHandler.StartImpersonation))
+
+	// Review of personal data deletion requests (super admin only)
+	admin.GET("/data-deletion-requests", middleware.RequireSuperAdminRole()(personalDataHandler.ListDeletionRequests))
+	admin.POST("/data-deletion-requests/:id/approve", middleware.RequireSuperAdminRole()(personalDataHandler.ApproveDeletion))
+	admin.POST("/data-deletion-requests/:id/reject", middleware.RequireSuperAdminRole()(personalDataHandler.RejectDeletion))
+
+	// Development mail catcher (only in development, no authentication)
+	if s.config.Environment == "development" {
+		mailboxHandler := handlers.NewDevMailboxHandler(s.config)
+		mailbox := api.Group("/dev/mailbox")
+		mailbox.GET("", mailboxHandler.ListMessages)
+		mailbox.DELETE("", mailboxHandler.ClearMailbox)
+		mailbox.GET("/inbox", mailboxHandler.Inbox)
+		mailbox.GET("/latest", mailboxHandler.LatestMessage)
+		mailbox.GET("/:id", mailboxHandler.GetMessage)
+		mailbox.GET("/:id/html", mailboxHandler.RenderMessage)
+		mailbox.GET("/:id/raw", mailboxHandler.RawMessage)
+		mailbox.GET("/:id/attachments/:index", mailboxHandler.GetAttachment)
+	}
 
-	// User management routes (require authentication)
+	// User management routes (require a user session; API keys are rejected)
 	users := api.Group("/users")
 	users.Use(jwtConfig.JWTMiddleware())
+	users.Use(middleware.RequireUserToken())
+	users.Use(views.RequireView(services.ViewUsers))
 
 	// Import user management handler
-	userHandler := handlers.NewUserManagementHandler(s.db)
+	userHandler := handlers.NewUserManagementHandler(s.db, s.config)
+
+	targetUserScope := authz.UserParamScope("id")
 
 	// User CRUD endpoints (require admin permissions)
 	users.GET("", middleware.RequireAdminRole()(userHandler.GetUserList))
-	users.GET("/:id", middleware.RequireAdminRole()(userHandler.GetUserProfile))
-	users.PUT("/:id", middleware.RequireAdminRole()(userHandler.UpdateUserProfile))
-	users.PATCH("/:id/status", middleware.RequireAdminRole()(userHandler.UpdateAccountStatus))
-
-	// Role management endpoints (require admin permissions)
-	users.POST("/roles", middleware.RequireAdminRole()(userHandler.AssignUserRole))
-	users.DELETE("/roles/:roleId", middleware.RequireAdminRole()(userHandler.RevokeUserRole))
-	users.GET("/:id/roles", middleware.RequireAdminRole()(userHandler.GetUserRoles))
+	users.GET("/export", middleware.RequireAdminRole()(userHandler.ExportUserList))
+	users.GET("/:id", middleware.RequireAdminRole()(authz.RequireScope(targetUserScope, models.RoleCityAdmin)(userHandler.GetUserProfile)))
+	users.PUT("/:id", middleware.RequireAdminRole()(authz.RequireScope(targetUserScope, models.RoleCityAdmin)(userHandler.UpdateUserProfile)))
+	users.PATCH("/:id/status", middleware.RequireAdminRole()(authz.RequireScope(targetUserScope, models.RoleCityAdmin)(userHandler.UpdateAccountStatus)))
+	users.POST("/:id/lock", middleware.RequireAdminRole()(authz.RequireScope(targetUserScope, models.RoleCityAdmin)(userHandler.LockAccount)))
+	users.POST("/:id/unlock", middleware.RequireAdminRole()(authz.RequireScope(targetUserScope, models.RoleCityAdmin)(userHandler.UnlockAccount)))
+	users.POST("/:id/status/reactivation", middleware.RequireAdminRole()(authz.RequireScope(targetUserScope, models.RoleCityAdmin)(userHandler.ScheduleReactivation)))
+	users.DELETE("/:id/status/reactivation", middleware.RequireAdminRole()(authz.RequireScope(targetUserScope, models.RoleCityAdmin)(userHandler.CancelScheduledReactivation)))
+	users.GET("/:id/status/schedules", middleware.RequireAdminRole()(authz.RequireScope(targetUserScope, models.RoleCityAdmin)(userHandler.GetStatusSchedules)))
+	users.GET("/status/capabilities", userHandler.GetAccountStatusCapabilities)
+	users.GET("/:id/personal-data", middleware.RequireAdminRole()(authz.RequireScope(targetUserScope, models.RoleCityAdmin)(personalDataHandler.ExportUserData)))
+
+	// Role management endpoints. The delegation matrix decides which roles the
+	// requester may assign or revoke, and in which city/sport/tournament.
+	delegators := func(action, role string) echo.MiddlewareFunc {
+		return middleware.RequireRole(services.DelegatorRoles(action, role)...)
+	}
+	users.POST("/roles", middleware.RequireRole(services.DelegatingRoles(services.DelegationAssign)...)(userHandler.AssignUserRole))
+	users.DELETE("/roles/:roleId", middleware.RequireRole(services.DelegatingRoles(services.DelegationRevoke)...)(userHandler.RevokeUserRole))
+	users.GET("/:id/roles", middleware.RequireAdminRole()(authz.RequireScope(targetUserScope, models.RoleCityAdmin)(userHandler.GetUserRoles)))
 
 	// View permission endpoints (require super admin permissions)
 	users.POST("/permissions", middleware.RequireSuperAdminRole()(userHandler.SetViewPermission))
 
-	// Hierarchical user registration endpoints
-	// Super Admin can register City Admins
-	users.POST("/register/city-admin", middleware.RequireSuperAdminRole()(userHandler.RegisterCityAdmin))
-
-	// City Admin can register Owners and Referees
-	users.POST("/register/owner", middleware.RequireAdminRole()(userHandler.RegisterOwner))
-	users.POST("/register/referee", middleware.RequireAdminRole()(userHandler.RegisterReferee))
+	// Hierarchical user registration endpoints, gated by the delegation matrix
+	// (services.DelegationMatrix) for the role being created
+	users.POST("/register/city-admin", delegators(services.DelegationCreate, models.RoleCityAdmin)(userHandler.RegisterCityAdmin))
+	users.POST("/register/tournament-admin", delegators(services.DelegationCreate, models.RoleTournamentAdmin)(userHandler.RegisterTournamentAdmin))
+	users.POST("/register/owner", delegators(services.DelegationCreate, models.RoleOwner)(userHandler.RegisterOwner))
+	users.POST("/register/referee", delegators(services.DelegationCreate, models.RoleReferee)(views.RequireView(services.ViewReferees)(userHandler.RegisterReferee)))
+	users.POST("/register/player", delegators(services.DelegationCreate, models.RolePlayer)(views.RequireView(services.ViewPlayers)(userHandler.RegisterPlayer)))
+	users.POST("/register/coach", delegators(services.DelegationCreate, models.RoleCoach)(views.RequireView(services.ViewPlayers)(userHandler.RegisterCoach)))
+
+	// Minor players have no login; a guardian account acts on their behalf. Guardians are
+	// managed by whoever may create players in the city/sport of the player's teams.
+	playerScope := authz.PlayerParamScope("playerId")
+	guardianManagers := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return delegators(services.DelegationCreate, models.RolePlayer)(views.RequireView(services.ViewPlayers)(
+			authz.RequireScope(playerScope, services.DelegatorRoles(services.DelegationCreate, models.RolePlayer)...)(next)))
+	}
+	users.POST("/register/minor-player", delegators(services.DelegationCreate, models.RolePlayer)(views.RequireView(services.ViewPlayers)(guardianHandler.RegisterMinorPlayer)))
+	users.GET("/players/:playerId/guardians", guardianManagers(guardianHandler.ListGuardians))
+	users.POST("/players/:playerId/guardians", guardianManagers(guardianHandler.LinkGuardian))
+	users.DELETE("/players/:playerId/guardians/:linkId", guardianManagers(guardianHandler.UnlinkGuardian))
 
-	// Owner can register Players and Coaches
-	users.POST("/register/player", middleware.RequireOwnerRole()(userHandler.RegisterPlayer))
-	users.POST("/register/coach", middleware.RequireOwnerRole()(userHandler.RegisterCoach))
+	// Bulk registration from CSV/XLSX files; each row is checked against the delegation matrix
+	importHandler := handlers.NewUserImportHandler(s.db, s.config)
+	users.POST("/import", middleware.RequireRole(services.DelegatingRoles(services.DelegationCreate)...)(importHandler.ImportUsers))
+	users.GET("/import/:importId", middleware.RequireRole(services.DelegatingRoles(services.DelegationCreate)...)(importHandler.GetImport))
 
 	// Email validation endpoint for user registration
 	users.GET("/validate-email", userHandler.ValidateEmailUniqueness)
@@ -315,6 +468,23 @@ func (s *Server) handleSignup(c echo.Context) error {
 		})
 	}
 
+	// Apply the password policy before creating the account
+	passwordPolicy := services.NewPasswordPolicyService(s.db, s.config)
+	if err := passwordPolicy.Validate(req.Password); err != nil {
+		code, message := "WEAK_PASSWORD", "Password does not meet security requirements"
+		if strings.Contains(err.Error(), "breached password") {
+			code, message = "PASSWORD_BREACHED", "Password is too common or has appeared in a data breach"
+		}
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error": map[string]interface{}{
+				"code":    code,
+				"message": message,
+				"details": err.Error(),
+			},
+		})
+	}
+
 	// Hash the password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
@@ -334,12 +504,13 @@ func (s *Server) handleSignup(c echo.Context) error {
 		phone = &req.Phone
 	}
 
+	// Accounts start unverified until the emailed link is used
 	err = s.db.GetConnection().QueryRow(
 		context.Background(),
 		`INSERT INTO user_profiles (user_id, email, password_hash, first_name, last_name, phone, 
 		 primary_role, is_active, account_status, failed_login_attempts, two_factor_enabled, 
-		 created_at, updated_at) 
-		 VALUES (gen_random_uuid(), $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW(), NOW()) 
+		 email_verified_at, created_at, updated_at) 
+		 VALUES (gen_random_uuid(), $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NULL, NOW(), NOW()) 
 		 RETURNING user_id, first_name, last_name, email, created_at`,
 		req.Email,
 		string(hashedPassword),
@@ -373,14 +544,27 @@ func (s *Server) handleSignup(c echo.Context) error {
 		})
 	}
 
+	// Start the password history; a failure only weakens reuse checks for this account
+	if err := passwordPolicy.RecordChange(context.Background(), s.db.GetConnection(), userProfile.UserID, string(hashedPassword)); err != nil {
+		slog.ErrorContext(c.Request().Context(), "failed to record password history", "user_id", userProfile.UserID, "error", err)
+	}
+
+	// Send the verification link; the user can request a resend if delivery fails
+	message := "User registered successfully. Check your email to verify your account"
+	verificationService := services.NewEmailVerificationService(s.db, s.config)
+	if err := verificationService.SendSignupVerification(c.Request().Context(), userProfile.UserID); err != nil {
+		message = "User registered successfully. We could not send the verification email, please request a new one"
+	}
+
 	return c.JSON(http.StatusCreated, map[string]interface{}{
 		"success": true,
 		"data": models.SignupResponse{
-			UserID:    userProfile.UserID,
-			FirstName: userProfile.FirstName,
-			LastName:  userProfile.LastName,
-			Email:     userProfile.Email,
-			Message:   "User registered successfully",
+			UserID:                    userProfile.UserID,
+			FirstName:                 userProfile.FirstName,
+			LastName:                  userProfile.LastName,
+			Email:                     userProfile.Email,
+			RequiresEmailVerification: true,
+			Message:                   message,
 		},
 	})
 }
@@ -1,8 +1,11 @@
 package server
 
 import (
+	"fmt"
 	"mowesport/internal/config"
 	"mowesport/internal/database"
+	appmiddleware "mowesport/internal/middleware"
+	"net"
 	"net/http"
 
 	"github.com/labstack/echo/v4"
@@ -15,18 +18,29 @@ type Server struct {
 	router *echo.Echo
 }
 
-func NewServer(db *database.Database, cfg *config.Config) *Server {
+func NewServer(db *database.Database, cfg *config.Config) (*Server, error) {
 	e := echo.New()
 
-	// Configure minimal logging middleware
-	e.Use(middleware.LoggerWithConfig(middleware.LoggerConfig{
-		Format: "${status} ${method} ${uri}\n",
-	}))
+	e.HideBanner = true
+	e.HidePort = true
+
+	// Client IPs drive login protection and audit events, so X-Forwarded-For is only
+	// believed when it comes from a configured proxy
+	ipExtractor, err := clientIPExtractor(cfg.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+	e.IPExtractor = ipExtractor
+
+	// Request IDs first, so the request log and everything logged while serving it carry one
+	e.Use(appmiddleware.RequestContext())
+	e.Use(appmiddleware.RequestLogger())
 	e.Use(middleware.Recover())
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowOrigins:     []string{"http://localhost:5173"},
 		AllowMethods:     []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete, http.MethodOptions},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Requested-With"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Requested-With", echo.HeaderXRequestID},
+		ExposeHeaders:    []string{echo.HeaderXRequestID},
 		AllowCredentials: true,
 	}))
 
@@ -37,7 +51,34 @@ func NewServer(db *database.Database, cfg *config.Config) *Server {
 	}
 
 	server.setupRoutes()
-	return server
+
+	// Impersonation tokens are refused every write that is not explicitly allowed
+	if err := appmiddleware.CheckImpersonationRoutes(e.Routes()); err != nil {
+		return nil, err
+	}
+	return server, nil
+}
+
+// clientIPExtractor uses the connection's address, or X-Forwarded-For as set by the
+// trusted proxies when there are any
+func clientIPExtractor(trustedProxies []string) (echo.IPExtractor, error) {
+	if len(trustedProxies) == 0 {
+		return echo.ExtractIPDirect(), nil
+	}
+
+	options := []echo.TrustOption{
+		echo.TrustLoopback(false),
+		echo.TrustLinkLocal(false),
+		echo.TrustPrivateNet(false),
+	}
+	for _, cidr := range trustedProxies {
+		_, ipRange, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TRUSTED_PROXIES entry %q: %w", cidr, err)
+		}
+		options = append(options, echo.TrustIPRange(ipRange))
+	}
+	return echo.ExtractIPFromXFFHeader(options...), nil
 }
 
 func (s *Server) Start(address string) error {
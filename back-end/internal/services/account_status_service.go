@@ -0,0 +1,433 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mowesport/internal/config"
+	"mowesport/internal/database"
+	"mowesport/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// accountScheduleBatchSize bounds the scheduled changes applied per scheduler pass
+const accountScheduleBatchSize = 50
+
+// AccountStatusService applies account status changes and their effects: live tokens are
+// revoked through token_version, and tournaments administered by suspended or disabled
+// accounts are put on hold until the account is active again. It also applies scheduled
+// status changes such as reactivations.
+type AccountStatusService struct {
+	db           *database.Database
+	config       config.AccountStatusConfig
+	emailService *EmailService
+	auditService *SecurityAuditService
+}
+
+// NewAccountStatusService creates a new account status service
+func NewAccountStatusService(db *database.Database, cfg *config.Config) *AccountStatusService {
+	auditService := NewSecurityAuditService(db)
+
+	return &AccountStatusService{
+		db:           db,
+		config:       cfg.AccountStatus,
+		emailService: NewEmailService(cfg, auditService),
+		auditService: auditService,
+	}
+}
+
+// ChangeStatus sets the account status of a user. A manual change cancels any pending
+// scheduled change for the account.
+func (s *AccountStatusService) ChangeStatus(ctx context.Context, userID uuid.UUID, status, reason string, changedBy uuid.UUID) (*models.AccountStatusChange, error) {
+	tx, err := s.db.GetConnection().Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	change, err := s.Apply(ctx, tx, userID, status, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE account_status_schedules SET status = $2
+		WHERE user_id = $1 AND status = $3
+	`, userID, models.AccountScheduleStatusCancelled, models.AccountScheduleStatusPending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel scheduled status change: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.logStatusChange(ctx, change, &changedBy, nil)
+	return change, nil
+}
+
+// Apply changes the account status within the caller's transaction. Tokens are only
+// revoked when the status actually changes.
+func (s *AccountStatusService) Apply(ctx context.Context, tx pgx.Tx, userID uuid.UUID, status, reason string) (*models.AccountStatusChange, error) {
+	change := &models.AccountStatusChange{UserID: userID, Status: status, Reason: reason}
+
+	err := tx.QueryRow(ctx,
+		"SELECT account_status FROM user_profiles WHERE user_id = $1 FOR UPDATE",
+		userID,
+	).Scan(&change.PreviousStatus)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("user not found")
+		}
+		return nil, fmt.Errorf("failed to load account status: %w", err)
+	}
+	change.TokensRevoked = change.PreviousStatus != status
+
+	err = tx.QueryRow(ctx, `
+		UPDATE user_profiles
+		SET account_status = $2,
+		    account_status_reason = NULLIF($3, ''),
+		    account_status_changed_at = NOW(),
+		    token_version = token_version + CASE WHEN $4 THEN 1 ELSE 0 END,
+		    updated_at = NOW()
+		WHERE user_id = $1
+		RETURNING account_status_changed_at
+	`, userID, status, reason, change.TokensRevoked).Scan(&change.ChangedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update account status: %w", err)
+	}
+
+	// Holds are tagged with the status that caused them so that only those are released
+	if models.CapabilitiesForStatus(status).HoldTournaments {
+		result, err := tx.Exec(ctx, `
+			UPDATE tournaments SET on_hold_since = NOW(), on_hold_reason = $2, updated_at = NOW()
+			WHERE admin_user_id = $1 AND on_hold_since IS NULL
+			AND status NOT IN ('completed', 'cancelled')
+		`, userID, "account_"+status)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hold tournaments: %w", err)
+		}
+		change.TournamentsHeld = int(result.RowsAffected())
+	} else {
+		result, err := tx.Exec(ctx, `
+			UPDATE tournaments SET on_hold_since = NULL, on_hold_reason = NULL, updated_at = NOW()
+			WHERE admin_user_id = $1 AND on_hold_reason LIKE 'account\_%'
+		`, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to release tournaments: %w", err)
+		}
+		change.TournamentsReleased = int(result.RowsAffected())
+	}
+
+	return change, nil
+}
+
+// IsTokenRevoked reports whether an access token issued with tokenVersion was revoked
+// by a later account status change, or the account was deactivated
+func (s *AccountStatusService) IsTokenRevoked(ctx context.Context, userID uuid.UUID, tokenVersion int) (bool, error) {
+	var currentVersion int
+	var isActive bool
+	err := s.db.GetConnection().QueryRow(ctx,
+		"SELECT token_version, is_active FROM user_profiles WHERE user_id = $1",
+		userID,
+	).Scan(&currentVersion, &isActive)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return true, nil
+		}
+		return false, fmt.Errorf("failed to check token version: %w", err)
+	}
+	return !isActive || currentVersion != tokenVersion, nil
+}
+
+// ScheduleChange schedules a status change for userID, replacing any pending one
+func (s *AccountStatusService) ScheduleChange(ctx context.Context, userID uuid.UUID, targetStatus string, scheduledFor time.Time, reason string, notifyUser bool, scheduledBy uuid.UUID) (*models.AccountStatusSchedule, error) {
+	if !scheduledFor.After(time.Now()) {
+		return nil, fmt.Errorf("scheduled time must be in the future")
+	}
+
+	tx, err := s.db.GetConnection().Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var currentStatus string
+	err = tx.QueryRow(ctx,
+		"SELECT account_status FROM user_profiles WHERE user_id = $1 FOR UPDATE",
+		userID,
+	).Scan(&currentStatus)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("user not found")
+		}
+		return nil, fmt.Errorf("failed to load account status: %w", err)
+	}
+	if currentStatus == targetStatus {
+		return nil, fmt.Errorf("account already %s", targetStatus)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE account_status_schedules SET status = $2
+		WHERE user_id = $1 AND status = $3
+	`, userID, models.AccountScheduleStatusCancelled, models.AccountScheduleStatusPending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to replace scheduled status change: %w", err)
+	}
+
+	var schedule models.AccountStatusSchedule
+	err = tx.QueryRow(ctx, `
+		INSERT INTO account_status_schedules (user_id, target_status, reason, scheduled_for, notify_user, created_by_user_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING schedule_id, user_id, target_status, reason, scheduled_for, notify_user, status,
+		          created_by_user_id, applied_at, last_error, created_at
+	`, userID, targetStatus, reason, scheduledFor, notifyUser, scheduledBy).Scan(
+		&schedule.ScheduleID, &schedule.UserID, &schedule.TargetStatus, &schedule.Reason,
+		&schedule.ScheduledFor, &schedule.NotifyUser, &schedule.Status, &schedule.CreatedByUserID,
+		&schedule.AppliedAt, &schedule.LastError, &schedule.CreatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to schedule status change: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.auditService.LogSecurityEvent(ctx, SecurityEvent{
+		EventType:   EventTypeAccountStatusScheduled,
+		Description: fmt.Sprintf("Account status change to %s scheduled for %s by admin %s", targetStatus, scheduledFor.UTC().Format(time.RFC3339), scheduledBy),
+		UserID:      &userID,
+		Metadata: map[string]interface{}{
+			"schedule_id":   schedule.ScheduleID,
+			"target_status": targetStatus,
+			"scheduled_for": scheduledFor,
+			"scheduled_by":  scheduledBy,
+			"reason":        reason,
+			"notify_user":   notifyUser,
+		},
+	})
+
+	return &schedule, nil
+}
+
+// CancelScheduledChange cancels the pending scheduled change of userID
+func (s *AccountStatusService) CancelScheduledChange(ctx context.Context, userID, cancelledBy uuid.UUID) error {
+	var scheduleID uuid.UUID
+	err := s.db.GetConnection().QueryRow(ctx, `
+		UPDATE account_status_schedules SET status = $2
+		WHERE user_id = $1 AND status = $3
+		RETURNING schedule_id
+	`, userID, models.AccountScheduleStatusCancelled, models.AccountScheduleStatusPending).Scan(&scheduleID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("no pending scheduled status change")
+		}
+		return fmt.Errorf("failed to cancel scheduled status change: %w", err)
+	}
+
+	s.auditService.LogSecurityEvent(ctx, SecurityEvent{
+		EventType:   EventTypeAccountStatusScheduled,
+		Description: fmt.Sprintf("Scheduled account status change cancelled by admin %s", cancelledBy),
+		UserID:      &userID,
+		Metadata: map[string]interface{}{
+			"schedule_id":  scheduleID,
+			"cancelled_by": cancelledBy,
+		},
+	})
+
+	return nil
+}
+
+// ListScheduledChanges returns the scheduled status changes of userID, newest first
+func (s *AccountStatusService) ListScheduledChanges(ctx context.Context, userID uuid.UUID) ([]models.AccountStatusSchedule, error) {
+	rows, err := s.db.GetConnection().Query(ctx, `
+		SELECT schedule_id, user_id, target_status, reason, scheduled_for, notify_user, status,
+		       created_by_user_id, applied_at, last_error, created_at
+		FROM account_status_schedules
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT 50
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query scheduled status changes: %w", err)
+	}
+	defer rows.Close()
+
+	schedules := []models.AccountStatusSchedule{}
+	for rows.Next() {
+		var schedule models.AccountStatusSchedule
+		if err := rows.Scan(
+			&schedule.ScheduleID, &schedule.UserID, &schedule.TargetStatus, &schedule.Reason,
+			&schedule.ScheduledFor, &schedule.NotifyUser, &schedule.Status, &schedule.CreatedByUserID,
+			&schedule.AppliedAt, &schedule.LastError, &schedule.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan scheduled status change: %w", err)
+		}
+		schedules = append(schedules, schedule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over scheduled status changes: %w", err)
+	}
+
+	return schedules, nil
+}
+
+// Run applies due scheduled status changes until the context is cancelled
+func (s *AccountStatusService) Run(ctx context.Context) {
+	interval := s.config.SchedulerInterval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.ProcessDue(ctx); err != nil {
+			slog.ErrorContext(ctx, "failed to apply scheduled status changes", "component", "account_status", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessDue applies the scheduled status changes that are due and returns how many were applied
+func (s *AccountStatusService) ProcessDue(ctx context.Context) (int, error) {
+	rows, err := s.db.GetConnection().Query(ctx, `
+		SELECT schedule_id FROM account_status_schedules
+		WHERE status = $1 AND scheduled_for <= NOW()
+		ORDER BY scheduled_for
+		LIMIT $2
+	`, models.AccountScheduleStatusPending, accountScheduleBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query due status changes: %w", err)
+	}
+	scheduleIDs := []uuid.UUID{}
+	for rows.Next() {
+		var scheduleID uuid.UUID
+		if err := rows.Scan(&scheduleID); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan due status change: %w", err)
+		}
+		scheduleIDs = append(scheduleIDs, scheduleID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating over due status changes: %w", err)
+	}
+
+	applied := 0
+	for _, scheduleID := range scheduleIDs {
+		ok, err := s.applySchedule(ctx, scheduleID)
+		if err != nil {
+			// The change stays visible to admins as failed instead of being retried forever
+			if _, markErr := s.db.GetConnection().Exec(ctx,
+				"UPDATE account_status_schedules SET status = $2, last_error = $3 WHERE schedule_id = $1",
+				scheduleID, models.AccountScheduleStatusFailed, err.Error(),
+			); markErr != nil {
+				return applied, fmt.Errorf("failed to record failure of %s: %w", scheduleID, markErr)
+			}
+			continue
+		}
+		if ok {
+			applied++
+		}
+	}
+
+	return applied, nil
+}
+
+// applySchedule applies one scheduled change and queues the notification in the same
+// transaction. It returns false when the schedule was cancelled or applied meanwhile.
+func (s *AccountStatusService) applySchedule(ctx context.Context, scheduleID uuid.UUID) (bool, error) {
+	tx, err := s.db.GetConnection().Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var schedule models.AccountStatusSchedule
+	err = tx.QueryRow(ctx, `
+		SELECT schedule_id, user_id, target_status, reason, notify_user, created_by_user_id
+		FROM account_status_schedules
+		WHERE schedule_id = $1 AND status = $2
+		FOR UPDATE SKIP LOCKED
+	`, scheduleID, models.AccountScheduleStatusPending).Scan(
+		&schedule.ScheduleID, &schedule.UserID, &schedule.TargetStatus, &schedule.Reason,
+		&schedule.NotifyUser, &schedule.CreatedByUserID,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to claim scheduled status change: %w", err)
+	}
+
+	change, err := s.Apply(ctx, tx, schedule.UserID, schedule.TargetStatus, schedule.Reason)
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := tx.Exec(ctx,
+		"UPDATE account_status_schedules SET status = $2, applied_at = NOW() WHERE schedule_id = $1",
+		scheduleID, models.AccountScheduleStatusApplied,
+	); err != nil {
+		return false, fmt.Errorf("failed to mark scheduled status change as applied: %w", err)
+	}
+
+	if schedule.NotifyUser && schedule.TargetStatus == models.AccountStatusActive {
+		var email, firstName, locale string
+		err := tx.QueryRow(ctx,
+			"SELECT email, first_name, preferred_locale FROM user_profiles WHERE user_id = $1",
+			schedule.UserID,
+		).Scan(&email, &firstName, &locale)
+		if err != nil {
+			return false, fmt.Errorf("failed to load user for notification: %w", err)
+		}
+		if err := s.emailService.SendReactivationEmail(ctx, tx, email, firstName, schedule.Reason, locale); err != nil {
+			return false, fmt.Errorf("failed to queue reactivation email: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.logStatusChange(ctx, change, schedule.CreatedByUserID, &schedule.ScheduleID)
+	return true, nil
+}
+
+// logStatusChange audits a status change; changedBy is the admin who made or scheduled it
+func (s *AccountStatusService) logStatusChange(ctx context.Context, change *models.AccountStatusChange, changedBy, scheduleID *uuid.UUID) {
+	description := fmt.Sprintf("Account status changed from %s to %s", change.PreviousStatus, change.Status)
+	if scheduleID != nil {
+		description += " by scheduled change"
+	} else if changedBy != nil {
+		description += fmt.Sprintf(" by admin %s", *changedBy)
+	}
+
+	s.auditService.LogSecurityEvent(ctx, SecurityEvent{
+		EventType:   EventTypeAccountStatusChanged,
+		Description: description,
+		UserID:      &change.UserID,
+		Metadata: map[string]interface{}{
+			"updated_user_id":      change.UserID,
+			"updated_by":           changedBy,
+			"previous_status":      change.PreviousStatus,
+			"new_status":           change.Status,
+			"reason":               change.Reason,
+			"tokens_revoked":       change.TokensRevoked,
+			"tournaments_held":     change.TournamentsHeld,
+			"tournaments_released": change.TournamentsReleased,
+			"schedule_id":          scheduleID,
+		},
+	})
+}
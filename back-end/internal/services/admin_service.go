@@ -12,13 +12,14 @@ import (
 	"time"
 
 	"github.com/google/uuid"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type AdminService struct {
 	db                *database.Database
 	securityValidator *SecurityValidationService
+	auditService      *SecurityAuditService
 	emailService      *EmailService
+	invitationService *InvitationService
 	config            *config.Config
 }
 
@@ -29,7 +30,9 @@ func NewAdminService(db *database.Database, cfg *config.Config) *AdminService {
 	return &AdminService{
 		db:                db,
 		securityValidator: NewSecurityValidationService(),
+		auditService:      auditService,
 		emailService:      emailService,
+		invitationService: NewInvitationService(db, cfg),
 		config:            cfg,
 	}
 }
@@ -39,18 +42,26 @@ func (s *AdminService) RegisterAdmin(ctx context.Context, req *models.AdminRegis
 	// Rate limiting check
 	clientIP := s.securityValidator.GetClientIP(ctx)
 	if err := s.securityValidator.CheckRateLimit(ctx, clientIP, 5, 15*time.Minute); err != nil {
-		s.securityValidator.LogSecurityEvent(ctx, "RATE_LIMIT_EXCEEDED", "Admin registration rate limit exceeded", map[string]interface{}{
-			"ip":    clientIP,
-			"email": req.Email,
+		s.auditService.LogSecurityEvent(ctx, SecurityEvent{
+			EventType:   EventTypeRateLimitExceeded,
+			Description: "Admin registration rate limit exceeded",
+			Metadata: map[string]interface{}{
+				"ip":    clientIP,
+				"email": req.Email,
+			},
 		})
 		return nil, fmt.Errorf("rate limit exceeded: %w", err)
 	}
 
 	// Comprehensive security validation
 	if err := s.validateSecurityRequirements(ctx, req); err != nil {
-		s.securityValidator.LogSecurityEvent(ctx, "SECURITY_VALIDATION_FAILED", "Security validation failed for admin registration", map[string]interface{}{
-			"email": req.Email,
-			"error": err.Error(),
+		s.auditService.LogSecurityEvent(ctx, SecurityEvent{
+			EventType:   EventTypeDataValidationFailed,
+			Description: "Security validation failed for admin registration",
+			Metadata: map[string]interface{}{
+				"email": req.Email,
+				"error": err.Error(),
+			},
 		})
 		return nil, err
 	}
@@ -77,18 +88,6 @@ func (s *AdminService) RegisterAdmin(ctx context.Context, req *models.AdminRegis
 		return nil, err
 	}
 
-	// Generate secure temporary password
-	tempPassword, err := s.generateTemporaryPassword()
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate temporary password: %w", err)
-	}
-
-	// Hash the temporary password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(tempPassword), bcrypt.DefaultCost)
-	if err != nil {
-		return nil, fmt.Errorf("failed to hash password: %w", err)
-	}
-
 	// Set default account status if not provided
 	accountStatus := req.AccountStatus
 	if accountStatus == "" {
@@ -107,17 +106,18 @@ func (s *AdminService) RegisterAdmin(ctx context.Context, req *models.AdminRegis
 	if req.PhotoURL != "" {
 		photoURL = &req.PhotoURL
 	}
+	identificationType := IdentificationTypeFor(req.IdentificationType, req.Identification)
 
 	_, err = tx.Exec(ctx, `
 		INSERT INTO user_profiles (
 			user_id, email, password_hash, first_name, last_name, phone, 
-			identification, photo_url, primary_role, is_active, account_status,
-			failed_login_attempts, two_factor_enabled, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, NOW(), NOW())
+			identification, identification_type, photo_url, primary_role, is_active, account_status,
+			failed_login_attempts, two_factor_enabled, email_verified_at, preferred_locale, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, NULL, $15, NOW(), NOW())
 	`,
-		userID, req.Email, string(hashedPassword), req.FirstName, req.LastName,
-		phone, identification, photoURL, models.RoleCityAdmin, true, accountStatus,
-		0, false,
+		userID, req.Email, InvitationPendingPasswordHash, req.FirstName, req.LastName,
+		phone, identification, identificationType, photoURL, models.RoleCityAdmin, true, accountStatus,
+		0, false, NormalizeEmailLocale(req.PreferredLocale),
 	)
 	if err != nil {
 		if strings.Contains(err.Error(), "unique constraint") || strings.Contains(err.Error(), "duplicate key") {
@@ -152,6 +152,15 @@ func (s *AdminService) RegisterAdmin(ctx context.Context, req *models.AdminRegis
 		return nil, fmt.Errorf("failed to create role assignment: %w", err)
 	}
 
+	// The admin sets their own password through a single-use invitation link
+	invitation, invitationToken, err := s.invitationService.CreateInvitation(ctx, tx, userID, req.Email, models.RoleCityAdmin, &cityUUID, &sportUUID, registeredByUserID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.invitationService.QueueInvitation(ctx, tx, invitation.InvitationID, invitationToken); err != nil {
+		return nil, err
+	}
+
 	// Commit transaction
 	if err := tx.Commit(ctx); err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
@@ -159,57 +168,38 @@ func (s *AdminService) RegisterAdmin(ctx context.Context, req *models.AdminRegis
 
 	// Prepare response
 	response := &models.AdminRegistrationResponse{
-		UserID:            userID,
-		FirstName:         req.FirstName,
-		LastName:          req.LastName,
-		Email:             req.Email,
-		Phone:             phone,
-		Identification:    identification,
-		CityID:            cityUUID,
-		SportID:           sportUUID,
-		AccountStatus:     accountStatus,
-		PhotoURL:          photoURL,
-		RoleAssignmentID:  roleAssignmentID,
-		TemporaryPassword: tempPassword, // Only for development - remove in production
-		Message:           "Admin registered successfully. Temporary password sent via email.",
+		UserID:              userID,
+		FirstName:           req.FirstName,
+		LastName:            req.LastName,
+		Email:               req.Email,
+		Phone:               phone,
+		Identification:      identification,
+		IdentificationType:  identificationType,
+		CityID:              cityUUID,
+		SportID:             sportUUID,
+		AccountStatus:       accountStatus,
+		PhotoURL:            photoURL,
+		RoleAssignmentID:    roleAssignmentID,
+		InvitationID:        invitation.InvitationID,
+		InvitationExpiresAt: invitation.ExpiresAt,
+		Message:             "Admin registered successfully. Invitation link sent via email.",
 	}
 
 	// Log successful registration
-	s.securityValidator.LogSecurityEvent(ctx, "ADMIN_REGISTERED", "New admin successfully registered", map[string]interface{}{
-		"admin_id":           userID,
-		"admin_email":        req.Email,
-		"registered_by":      registeredByUserID,
-		"city_id":            cityUUID,
-		"sport_id":           sportUUID,
-		"role_assignment_id": roleAssignmentID,
+	s.auditService.LogSecurityEvent(ctx, SecurityEvent{
+		EventType:   EventTypeAdminRegistration,
+		Description: "New admin successfully registered",
+		UserID:      &registeredByUserID,
+		Metadata: map[string]interface{}{
+			"admin_id":           userID,
+			"admin_email":        req.Email,
+			"registered_by":      registeredByUserID,
+			"city_id":            cityUUID,
+			"sport_id":           sportUUID,
+			"role_assignment_id": roleAssignmentID,
+		},
 	})
 
-	// Send welcome email with temporary password
-	go func() {
-		// Get city and sport names for email
-		var cityName, sportName string
-		s.db.GetConnection().QueryRow(context.Background(), "SELECT name FROM cities WHERE city_id = $1", cityUUID).Scan(&cityName)
-		s.db.GetConnection().QueryRow(context.Background(), "SELECT name FROM sports WHERE sport_id = $1", sportUUID).Scan(&sportName)
-
-		emailData := WelcomeEmailData{
-			FirstName:         req.FirstName,
-			LastName:          req.LastName,
-			Email:             req.Email,
-			TemporaryPassword: tempPassword,
-			LoginURL:          s.config.FrontendURL + "/sign-in",
-			SupportEmail:      s.config.SupportEmail,
-			CompanyName:       "Mowe Sport",
-			CityName:          cityName,
-			SportName:         sportName,
-			ExpirationHours:   24,
-		}
-
-		if err := s.emailService.SendWelcomeEmail(context.Background(), emailData); err != nil {
-			// Log error but don't fail the registration
-			fmt.Printf("Failed to send welcome email to %s: %v\n", req.Email, err)
-		}
-	}()
-
 	return response, nil
 }
 
@@ -387,8 +377,8 @@ func (s *AdminService) validateSecurityRequirements(ctx context.Context, req *mo
 		return fmt.Errorf("phone validation failed: %w", err)
 	}
 
-	// Validate identification format (assuming Colombia as default)
-	if err := s.securityValidator.ValidateIdentificationFormat(req.Identification, "CO"); err != nil {
+	// Validate identification format for its Colombian document type
+	if err := s.securityValidator.ValidateColombianIdentification(req.IdentificationType, req.Identification); err != nil {
 		return fmt.Errorf("identification validation failed: %w", err)
 	}
 
@@ -421,9 +411,13 @@ func (s *AdminService) validateSecurityRequirements(ctx context.Context, req *mo
 
 	suspiciousFindings := s.securityValidator.DetectSuspiciousPatterns(inputData)
 	if len(suspiciousFindings) > 0 {
-		s.securityValidator.LogSecurityEvent(ctx, "SUSPICIOUS_PATTERNS_DETECTED", "Suspicious patterns detected in admin registration", map[string]interface{}{
-			"email":    req.Email,
-			"findings": suspiciousFindings,
+		s.auditService.LogSecurityEvent(ctx, SecurityEvent{
+			EventType:   EventTypeSuspiciousActivity,
+			Description: "Suspicious patterns detected in admin registration",
+			Metadata: map[string]interface{}{
+				"email":    req.Email,
+				"findings": suspiciousFindings,
+			},
 		})
 		return fmt.Errorf("suspicious patterns detected in input data")
 	}
@@ -443,9 +437,13 @@ func (s *AdminService) ValidateEmailUniquenessWithSecurity(ctx context.Context,
 	// Rate limiting for email validation
 	clientIP := s.securityValidator.GetClientIP(ctx)
 	if err := s.securityValidator.CheckRateLimit(ctx, clientIP+":email_validation", 10, 1*time.Minute); err != nil {
-		s.securityValidator.LogSecurityEvent(ctx, "EMAIL_VALIDATION_RATE_LIMIT", "Email validation rate limit exceeded", map[string]interface{}{
-			"ip":    clientIP,
-			"email": email,
+		s.auditService.LogSecurityEvent(ctx, SecurityEvent{
+			EventType:   EventTypeRateLimitExceeded,
+			Description: "Email validation rate limit exceeded",
+			Metadata: map[string]interface{}{
+				"ip":    clientIP,
+				"email": email,
+			},
 		})
 		return nil, fmt.Errorf("email validation rate limit exceeded")
 	}
@@ -465,9 +463,13 @@ func (s *AdminService) ValidateEmailUniquenessWithSecurity(ctx context.Context,
 	// Check for suspicious patterns
 	suspiciousFindings := s.securityValidator.DetectSuspiciousPatterns(map[string]string{"email": sanitizedEmail})
 	if len(suspiciousFindings) > 0 {
-		s.securityValidator.LogSecurityEvent(ctx, "SUSPICIOUS_EMAIL_VALIDATION", "Suspicious email validation attempt", map[string]interface{}{
-			"email":    sanitizedEmail,
-			"findings": suspiciousFindings,
+		s.auditService.LogSecurityEvent(ctx, SecurityEvent{
+			EventType:   EventTypeSuspiciousActivity,
+			Description: "Suspicious email validation attempt",
+			Metadata: map[string]interface{}{
+				"email":    sanitizedEmail,
+				"findings": suspiciousFindings,
+			},
 		})
 		return &models.EmailValidationResponse{
 			IsValid:  false,
@@ -499,133 +501,99 @@ func (s *AdminService) ValidateEmailUniquenessWithSecurity(ctx context.Context,
 	}, nil
 }
 
-// GetAdminList retrieves a paginated list of administrators with filtering and search
+// adminListSorts are the sortable columns of the admin list
+var adminListSorts = map[string]listSort{
+	"first_name":    {"up.first_name", "text"},
+	"last_name":     {"up.last_name", "text"},
+	"email":         {"up.email", "text"},
+	"created_at":    {"up.created_at", "timestamptz"},
+	"last_login_at": {"COALESCE(up.last_login_at, '-infinity')", "timestamptz"},
+}
+
+// adminListColumns are the admin summary columns. City and sport come from the admin's
+// oldest active assignment, so each admin is listed once.
+const adminListColumns = `
+	up.user_id, up.email, up.first_name, up.last_name, up.phone, up.photo_url,
+	up.account_status, up.is_active, up.last_login_at, up.created_at,
+	assignment.city_name, assignment.sport_name`
+
+const adminListAssignmentJoin = `
+	LEFT JOIN LATERAL (
+		SELECT c.name AS city_name, s.name AS sport_name
+		FROM user_roles_by_city_sport ur
+		LEFT JOIN cities c ON ur.city_id = c.city_id
+		LEFT JOIN sports s ON ur.sport_id = s.sport_id
+		WHERE ur.user_id = up.user_id AND ur.is_active = true
+		ORDER BY ur.created_at
+		LIMIT 1
+	) assignment ON true`
+
+// GetAdminList retrieves a page of administrators with filtering and search, after the
+// request cursor or at the requested page number
 func (s *AdminService) GetAdminList(ctx context.Context, req *models.AdminListRequest) (*models.AdminListResponse, error) {
 	// Set defaults
-	if req.Page <= 0 {
-		req.Page = 1
-	}
 	if req.Limit <= 0 {
 		req.Limit = 20
 	}
 	if req.Limit > 100 {
 		req.Limit = 100
 	}
-	if req.SortBy == "" {
-		req.SortBy = "created_at"
-	}
-	if req.SortOrder == "" {
-		req.SortOrder = "desc"
-	}
-
-	// Calculate offset
-	offset := (req.Page - 1) * req.Limit
-
-	// Build WHERE clause
-	whereConditions := []string{"up.primary_role = $1"}
-	args := []interface{}{models.RoleCityAdmin}
-	argIndex := 2
-
-	// Add search filter
-	if req.Search != "" {
-		searchPattern := "%" + strings.ToLower(req.Search) + "%"
-		whereConditions = append(whereConditions, fmt.Sprintf("(LOWER(up.first_name) LIKE $%d OR LOWER(up.last_name) LIKE $%d OR LOWER(up.email) LIKE $%d)", argIndex, argIndex, argIndex))
-		args = append(args, searchPattern)
-		argIndex++
-	}
 
-	// Add city filter
-	if req.CityID != "" {
-		whereConditions = append(whereConditions, fmt.Sprintf("ur.city_id = $%d", argIndex))
-		args = append(args, req.CityID)
-		argIndex++
-	}
-
-	// Add sport filter
-	if req.SportID != "" {
-		whereConditions = append(whereConditions, fmt.Sprintf("ur.sport_id = $%d", argIndex))
-		args = append(args, req.SportID)
-		argIndex++
-	}
-
-	// Add status filter
-	if req.Status != "" {
-		whereConditions = append(whereConditions, fmt.Sprintf("up.account_status = $%d", argIndex))
-		args = append(args, req.Status)
-		argIndex++
-	}
-
-	whereClause := strings.Join(whereConditions, " AND ")
-
-	// Build ORDER BY clause
-	validSortFields := map[string]string{
-		"first_name":    "up.first_name",
-		"last_name":     "up.last_name",
-		"email":         "up.email",
-		"created_at":    "up.created_at",
-		"last_login_at": "up.last_login_at",
-	}
-
-	sortField, exists := validSortFields[req.SortBy]
-	if !exists {
-		sortField = "up.created_at"
-	}
-
-	sortOrder := "DESC"
-	if req.SortOrder == "asc" {
-		sortOrder = "ASC"
+	q, sort, err := adminListQuery(req)
+	if err != nil {
+		return nil, err
 	}
 
-	// Count total records
-	countQuery := fmt.Sprintf(`
-		SELECT COUNT(DISTINCT up.user_id)
-		FROM user_profiles up
-		LEFT JOIN user_roles_by_city_sport ur ON up.user_id = ur.user_id AND ur.is_active = true
-		WHERE %s
-	`, whereClause)
-
-	var total int
-	err := s.db.GetConnection().QueryRow(ctx, countQuery, args...).Scan(&total)
-	if err != nil {
-		return nil, fmt.Errorf("failed to count admins: %w", err)
+	response := &models.AdminListResponse{Admins: []models.AdminSummary{}, Limit: req.Limit}
+	offset := 0
+	if req.Cursor != "" {
+		cursor, err := decodeListCursor(req.Cursor, req.SortBy, req.SortOrder)
+		if err != nil {
+			return nil, err
+		}
+		q.after(sort, req.SortOrder, cursor)
+		response.HasPrev = true
+	} else {
+		// Count total records
+		var total int
+		err := s.db.GetConnection().QueryRow(ctx, fmt.Sprintf(`
+			SELECT COUNT(*) FROM user_profiles up WHERE %s
+		`, q.whereClause()), q.args...).Scan(&total)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count admins: %w", err)
+		}
+		response.Total = &total
+		response.TotalPages = (total + req.Limit - 1) / req.Limit
+		if req.Page > 1 {
+			offset = (req.Page - 1) * req.Limit
+			response.Page = req.Page
+			response.HasPrev = true
+		} else if req.Page == 1 {
+			response.Page = 1
+		}
 	}
 
-	// Get paginated results
+	// One extra row tells whether there is a next page
 	query := fmt.Sprintf(`
-		SELECT DISTINCT
-			up.user_id,
-			up.email,
-			up.first_name,
-			up.last_name,
-			up.phone,
-			up.photo_url,
-			up.account_status,
-			up.is_active,
-			up.last_login_at,
-			up.created_at,
-			c.name as city_name,
-			s.name as sport_name
+		SELECT %s, (%s)::text
 		FROM user_profiles up
-		LEFT JOIN user_roles_by_city_sport ur ON up.user_id = ur.user_id AND ur.is_active = true
-		LEFT JOIN cities c ON ur.city_id = c.city_id
-		LEFT JOIN sports s ON ur.sport_id = s.sport_id
+		%s
 		WHERE %s
-		ORDER BY %s %s
-		LIMIT $%d OFFSET $%d
-	`, whereClause, sortField, sortOrder, argIndex, argIndex+1)
-
-	args = append(args, req.Limit, offset)
+		ORDER BY %s
+		LIMIT %s OFFSET %s
+	`, adminListColumns, sort.expr, adminListAssignmentJoin, q.whereClause(), orderBy(sort, req.SortOrder),
+		q.arg(req.Limit+1), q.arg(offset))
 
-	rows, err := s.db.GetConnection().Query(ctx, query, args...)
+	rows, err := s.db.GetConnection().Query(ctx, query, q.args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query admins: %w", err)
 	}
 	defer rows.Close()
 
-	var admins []models.AdminSummary
+	var lastKey string
 	for rows.Next() {
 		var admin models.AdminSummary
-		var cityName, sportName *string
+		var sortKey string
 
 		err := rows.Scan(
 			&admin.UserID,
@@ -638,34 +606,114 @@ func (s *AdminService) GetAdminList(ctx context.Context, req *models.AdminListRe
 			&admin.IsActive,
 			&admin.LastLoginAt,
 			&admin.CreatedAt,
-			&cityName,
-			&sportName,
+			&admin.CityName,
+			&admin.SportName,
+			&sortKey,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan admin: %w", err)
 		}
-
-		admin.CityName = cityName
-		admin.SportName = sportName
-		admins = append(admins, admin)
+		if len(response.Admins) == req.Limit {
+			response.HasNext = true
+			break
+		}
+		response.Admins = append(response.Admins, admin)
+		lastKey = sortKey
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("error iterating over admin rows: %w", err)
 	}
 
-	// Calculate pagination info
-	totalPages := (total + req.Limit - 1) / req.Limit
-	hasNext := req.Page < totalPages
-	hasPrev := req.Page > 1
+	if response.HasNext {
+		last := response.Admins[len(response.Admins)-1]
+		response.NextCursor = encodeListCursor(listCursor{Sort: req.SortBy, Order: req.SortOrder, Key: lastKey, ID: last.UserID})
+	}
 
-	return &models.AdminListResponse{
-		Admins:     admins,
-		Total:      total,
-		Page:       req.Page,
-		Limit:      req.Limit,
-		TotalPages: totalPages,
-		HasNext:    hasNext,
-		HasPrev:    hasPrev,
-	}, nil
+	return response, nil
+}
+
+// ExportAdminList streams every administrator matching the list filters to write, in list
+// order, and audits the export. Pagination fields of the request are ignored.
+func (s *AdminService) ExportAdminList(ctx context.Context, req *models.AdminListRequest, requestedBy uuid.UUID, write func(models.AdminSummary) error) (int, error) {
+	q, sort, err := adminListQuery(req)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := s.db.GetConnection().Query(ctx, fmt.Sprintf(`
+		SELECT %s
+		FROM user_profiles up
+		%s
+		WHERE %s
+		ORDER BY %s
+	`, adminListColumns, adminListAssignmentJoin, q.whereClause(), orderBy(sort, req.SortOrder)), q.args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query admins: %w", err)
+	}
+
+	exported := 0
+	for rows.Next() {
+		var admin models.AdminSummary
+		if err := rows.Scan(
+			&admin.UserID, &admin.Email, &admin.FirstName, &admin.LastName, &admin.Phone, &admin.PhotoURL,
+			&admin.AccountStatus, &admin.IsActive, &admin.LastLoginAt, &admin.CreatedAt, &admin.CityName, &admin.SportName,
+		); err != nil {
+			rows.Close()
+			return exported, fmt.Errorf("failed to scan admin: %w", err)
+		}
+		if err := write(admin); err != nil {
+			rows.Close()
+			return exported, err
+		}
+		exported++
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return exported, fmt.Errorf("error iterating over admin rows: %w", err)
+	}
+
+	s.auditService.LogSecurityEvent(ctx, SecurityEvent{
+		EventType:   EventTypeUserListExported,
+		Description: "Admin list exported",
+		UserID:      &requestedBy,
+		Metadata: map[string]interface{}{
+			"list":    "admins",
+			"rows":    exported,
+			"filters": req,
+		},
+	})
+
+	return exported, nil
+}
+
+// adminListQuery applies the admin list filters and resolves the sort
+func adminListQuery(req *models.AdminListRequest) (*listQuery, listSort, error) {
+	if req.SortBy == "" {
+		req.SortBy = "created_at"
+	}
+	if req.SortOrder == "" {
+		req.SortOrder = "desc"
+	}
+	sort, exists := adminListSorts[req.SortBy]
+	if !exists {
+		return nil, listSort{}, fmt.Errorf("invalid sort field: %s", req.SortBy)
+	}
+
+	q := &listQuery{}
+	q.where("up.primary_role = " + q.arg(models.RoleCityAdmin))
+	if req.Search != "" {
+		q.search(req.Search)
+	}
+	q.assignment(req.CityID, req.SportID, "")
+	if req.Status != "" {
+		q.where("up.account_status = " + q.arg(req.Status))
+	}
+	if err := q.dateRange("up.created_at", req.CreatedFrom, req.CreatedTo); err != nil {
+		return nil, listSort{}, err
+	}
+	if err := q.dateRange("up.last_login_at", req.LastLoginFrom, req.LastLoginTo); err != nil {
+		return nil, listSort{}, err
+	}
+	return q, sort, nil
 }
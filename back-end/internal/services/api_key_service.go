@@ -0,0 +1,283 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"mowesport/internal/database"
+	"mowesport/internal/models"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	apiKeyPrefix            = "mws"
+	apiKeyLastUsedThrottle  = time.Minute
+	apiKeyDefaultExpiration = 90 * 24 * time.Hour
+)
+
+// APIKeyService manages scoped API keys for machine integrations
+type APIKeyService struct {
+	db           *database.Database
+	auditService *SecurityAuditService
+}
+
+// NewAPIKeyService creates a new API key service
+func NewAPIKeyService(db *database.Database) *APIKeyService {
+	return &APIKeyService{
+		db:           db,
+		auditService: NewSecurityAuditService(db),
+	}
+}
+
+// CreateAPIKey generates a new key, stores its hash and returns the raw key once
+func (s *APIKeyService) CreateAPIKey(ctx context.Context, req *models.APIKeyCreateRequest, createdBy uuid.UUID, creatorRole string) (*models.APIKeyCreateResponse, error) {
+	if req.TournamentID == nil && req.CityID == nil {
+		return nil, fmt.Errorf("api key must be scoped to a tournament or a city")
+	}
+	if req.SportID != nil && req.CityID == nil {
+		return nil, fmt.Errorf("sport scope requires a city scope")
+	}
+
+	// Resolve the effective city/sport the key will act on
+	cityID, sportID := req.CityID, req.SportID
+	if req.TournamentID != nil {
+		var tournamentCityID, tournamentSportID uuid.UUID
+		err := s.db.GetConnection().QueryRow(ctx, `
+			SELECT city_id, sport_id FROM tournaments WHERE tournament_id = $1
+		`, *req.TournamentID).Scan(&tournamentCityID, &tournamentSportID)
+		if err != nil {
+			return nil, fmt.Errorf("tournament not found")
+		}
+		if (req.CityID != nil && *req.CityID != tournamentCityID) || (req.SportID != nil && *req.SportID != tournamentSportID) {
+			return nil, fmt.Errorf("tournament does not belong to the specified city/sport")
+		}
+		cityID, sportID = &tournamentCityID, &tournamentSportID
+	}
+
+	// City admins may only issue keys inside their own city/sport
+	if creatorRole != models.RoleSuperAdmin {
+		allowed, err := s.adminCoversScope(ctx, createdBy, cityID, sportID)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, fmt.Errorf("insufficient permissions: scope outside of your city/sport")
+		}
+	}
+
+	prefix, secret, err := generateAPIKeyParts()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate api key: %w", err)
+	}
+	rawKey := fmt.Sprintf("%s_%s_%s", apiKeyPrefix, prefix, secret)
+
+	expiresAt := time.Now().Add(apiKeyDefaultExpiration)
+	if req.ExpiresInDays > 0 {
+		expiresAt = time.Now().AddDate(0, 0, req.ExpiresInDays)
+	}
+
+	var key models.APIKey
+	err = s.db.GetConnection().QueryRow(ctx, `
+		INSERT INTO api_keys (
+			name, key_prefix, key_hash, permissions, tournament_id, city_id, sport_id,
+			created_by_user_id, expires_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING api_key_id, name, key_prefix, permissions, tournament_id, city_id, sport_id,
+		          created_by_user_id, expires_at, last_used_at, revoked_at, is_active,
+		          created_at, updated_at
+	`, req.Name, prefix, hashAPIKey(rawKey), req.Permissions, req.TournamentID, req.CityID, req.SportID,
+		createdBy, expiresAt).Scan(
+		&key.APIKeyID, &key.Name, &key.KeyPrefix, &key.Permissions, &key.TournamentID,
+		&key.CityID, &key.SportID, &key.CreatedByUserID, &key.ExpiresAt, &key.LastUsedAt,
+		&key.RevokedAt, &key.IsActive, &key.CreatedAt, &key.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	s.auditService.LogSecurityEvent(ctx, SecurityEvent{
+		EventType:   "API_KEY_CREATED",
+		Description: fmt.Sprintf("API key %s created by %s", key.KeyPrefix, createdBy),
+		UserID:      &createdBy,
+		Metadata: map[string]interface{}{
+			"api_key_id":    key.APIKeyID,
+			"key_prefix":    key.KeyPrefix,
+			"permissions":   key.Permissions,
+			"tournament_id": key.TournamentID,
+			"city_id":       key.CityID,
+			"sport_id":      key.SportID,
+		},
+		Timestamp: time.Now(),
+	})
+
+	return &models.APIKeyCreateResponse{
+		APIKey:  key,
+		Key:     rawKey,
+		Message: "Store this key securely, it will not be shown again",
+	}, nil
+}
+
+// ListAPIKeys returns keys visible to the requester (super admins see all keys)
+func (s *APIKeyService) ListAPIKeys(ctx context.Context, requestedBy uuid.UUID, requesterRole string) ([]models.APIKey, error) {
+	query := `
+		SELECT api_key_id, name, key_prefix, permissions, tournament_id, city_id, sport_id,
+		       created_by_user_id, expires_at, last_used_at, revoked_at, is_active,
+		       created_at, updated_at
+		FROM api_keys`
+	args := []interface{}{}
+	if requesterRole != models.RoleSuperAdmin {
+		query += " WHERE created_by_user_id = $1"
+		args = append(args, requestedBy)
+	}
+	query += " ORDER BY created_at DESC"
+
+	rows, err := s.db.GetConnection().Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		var key models.APIKey
+		if err := rows.Scan(
+			&key.APIKeyID, &key.Name, &key.KeyPrefix, &key.Permissions, &key.TournamentID,
+			&key.CityID, &key.SportID, &key.CreatedByUserID, &key.ExpiresAt, &key.LastUsedAt,
+			&key.RevokedAt, &key.IsActive, &key.CreatedAt, &key.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan api key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// RevokeAPIKey deactivates a key; city admins may only revoke keys they created
+func (s *APIKeyService) RevokeAPIKey(ctx context.Context, apiKeyID uuid.UUID, revokedBy uuid.UUID, requesterRole string) error {
+	var createdBy uuid.UUID
+	var revokedAt *time.Time
+	err := s.db.GetConnection().QueryRow(ctx, `
+		SELECT created_by_user_id, revoked_at FROM api_keys WHERE api_key_id = $1
+	`, apiKeyID).Scan(&createdBy, &revokedAt)
+	if err != nil {
+		return fmt.Errorf("api key not found")
+	}
+	if requesterRole != models.RoleSuperAdmin && createdBy != revokedBy {
+		return fmt.Errorf("insufficient permissions: api key was created by another admin")
+	}
+	if revokedAt != nil {
+		return fmt.Errorf("api key already revoked")
+	}
+
+	_, err = s.db.GetConnection().Exec(ctx, `
+		UPDATE api_keys
+		SET is_active = false, revoked_at = NOW(), revoked_by_user_id = $2
+		WHERE api_key_id = $1
+	`, apiKeyID, revokedBy)
+	if err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+
+	s.auditService.LogSecurityEvent(ctx, SecurityEvent{
+		EventType:   "API_KEY_REVOKED",
+		Description: fmt.Sprintf("API key %s revoked by %s", apiKeyID, revokedBy),
+		UserID:      &revokedBy,
+		Metadata: map[string]interface{}{
+			"api_key_id": apiKeyID,
+		},
+		Timestamp: time.Now(),
+	})
+
+	return nil
+}
+
+// AuthenticateAPIKey validates a raw key and resolves the identity it carries
+func (s *APIKeyService) AuthenticateAPIKey(ctx context.Context, rawKey string) (*models.APIKeyIdentity, error) {
+	parts := strings.Split(rawKey, "_")
+	if len(parts) != 3 || parts[0] != apiKeyPrefix {
+		return nil, fmt.Errorf("invalid api key")
+	}
+
+	var identity models.APIKeyIdentity
+	var keyHash string
+	var expiresAt, lastUsedAt, revokedAt *time.Time
+	var isActive bool
+	err := s.db.GetConnection().QueryRow(ctx, `
+		SELECT api_key_id, name, key_hash, permissions, tournament_id, city_id, sport_id,
+		       created_by_user_id, expires_at, last_used_at, revoked_at, is_active
+		FROM api_keys
+		WHERE key_prefix = $1
+	`, parts[1]).Scan(
+		&identity.APIKeyID, &identity.Name, &keyHash, &identity.Permissions, &identity.TournamentID,
+		&identity.CityID, &identity.SportID, &identity.CreatedByUserID, &expiresAt, &lastUsedAt,
+		&revokedAt, &isActive,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid api key")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(keyHash), []byte(hashAPIKey(rawKey))) != 1 {
+		return nil, fmt.Errorf("invalid api key")
+	}
+	if revokedAt != nil || !isActive {
+		return nil, fmt.Errorf("api key revoked")
+	}
+	if expiresAt != nil && time.Now().After(*expiresAt) {
+		return nil, fmt.Errorf("api key expired")
+	}
+
+	// Throttle last-used writes so busy scoreboards don't update the row on every poll
+	if lastUsedAt == nil || time.Since(*lastUsedAt) > apiKeyLastUsedThrottle {
+		s.db.GetConnection().Exec(ctx, "UPDATE api_keys SET last_used_at = NOW() WHERE api_key_id = $1", identity.APIKeyID)
+	}
+
+	return &identity, nil
+}
+
+// Helper methods
+
+// adminCoversScope checks the admin holds an active city_admin assignment covering the scope
+func (s *APIKeyService) adminCoversScope(ctx context.Context, userID uuid.UUID, cityID, sportID *uuid.UUID) (bool, error) {
+	var allowed bool
+	err := s.db.GetConnection().QueryRow(ctx, `
+		SELECT EXISTS(
+			SELECT 1 FROM user_roles_by_city_sport
+			WHERE user_id = $1 AND role_name = $2 AND is_active = true
+			AND (city_id IS NULL OR city_id = $3)
+			AND (sport_id IS NULL OR sport_id = $4)
+		)
+	`, userID, models.RoleCityAdmin, cityID, sportID).Scan(&allowed)
+	if err != nil {
+		return false, fmt.Errorf("failed to check admin scope: %w", err)
+	}
+	return allowed, nil
+}
+
+// generateAPIKeyParts returns a short lookup prefix and a high-entropy secret
+func generateAPIKeyParts() (string, string, error) {
+	prefixBytes := make([]byte, 4)
+	if _, err := rand.Read(prefixBytes); err != nil {
+		return "", "", err
+	}
+	secretBytes := make([]byte, 32)
+	if _, err := rand.Read(secretBytes); err != nil {
+		return "", "", err
+	}
+	// RawURLEncoding may contain '_', which we use as separator
+	secret := strings.ReplaceAll(base64.RawURLEncoding.EncodeToString(secretBytes), "_", "-")
+	return hex.EncodeToString(prefixBytes), secret, nil
+}
+
+// hashAPIKey returns the SHA-256 hex digest stored at rest
+func hashAPIKey(rawKey string) string {
+	sum := sha256.Sum256([]byte(rawKey))
+	return hex.EncodeToString(sum[:])
+}
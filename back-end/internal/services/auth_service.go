@@ -5,6 +5,7 @@ import (
 	"crypto/rand"
 	"encoding/base32"
 	"fmt"
+	"mowesport/internal/config"
 	"mowesport/internal/database"
 	"mowesport/internal/models"
 	"time"
@@ -20,32 +21,59 @@ type AuthService struct {
 	db                       *database.Database
 	jwtSecret                []byte
 	temporaryPasswordService *TemporaryPasswordService
+	auditService             *SecurityAuditService
+	loginProtection          *LoginProtectionService
+	passwordPolicy           *PasswordPolicyService
+	lockoutPolicy            config.LockoutPolicyConfig
+	fieldEncryption          *FieldEncryptionService
 }
 
-func NewAuthService(db *database.Database, jwtSecret string) *AuthService {
+func NewAuthService(db *database.Database, cfg *config.Config) *AuthService {
 	return &AuthService{
 		db:                       db,
-		jwtSecret:                []byte(jwtSecret),
+		jwtSecret:                []byte(cfg.JWTSecret),
 		temporaryPasswordService: NewTemporaryPasswordService(db),
+		auditService:             NewSecurityAuditService(db),
+		loginProtection:          NewLoginProtectionService(db, cfg),
+		passwordPolicy:           NewPasswordPolicyService(db, cfg),
+		lockoutPolicy:            cfg.Security.Lockout,
+		fieldEncryption:          NewFieldEncryptionService(db, cfg),
 	}
 }
 
 // Login authenticates a user and returns JWT tokens
 func (s *AuthService) Login(ctx context.Context, req *models.LoginRequest) (*models.LoginResponse, error) {
+	// Attacks spread across accounts are detected per client network
+	clientIP := ""
+	if info, ok := RequestInfoFromContext(ctx); ok {
+		clientIP = info.IPAddress
+	}
+	assessment, err := s.loginProtection.Assess(ctx, clientIP)
+	if err != nil {
+		return nil, err
+	}
+	if assessment.Blocked {
+		return nil, fmt.Errorf("ip_blocked")
+	}
+
 	// Get user from database
 	var userProfile models.UserProfile
-	err := s.db.GetConnection().QueryRow(ctx,
+	err = s.db.GetConnection().QueryRow(ctx,
 		`SELECT user_id, email, password_hash, first_name, last_name, primary_role, 
-		 is_active, account_status, failed_login_attempts, locked_until, two_factor_enabled, two_factor_secret
+		 is_active, account_status, failed_login_attempts, locked_until, two_factor_enabled, two_factor_secret,
+		 email_verified_at, preferred_locale, password_changed_at, token_version
 		 FROM user_profiles WHERE email = $1`,
 		req.Email,
 	).Scan(&userProfile.UserID, &userProfile.Email, &userProfile.PasswordHash,
 		&userProfile.FirstName, &userProfile.LastName, &userProfile.PrimaryRole,
 		&userProfile.IsActive, &userProfile.AccountStatus,
 		&userProfile.FailedLoginAttempts, &userProfile.LockedUntil,
-		&userProfile.TwoFactorEnabled, &userProfile.TwoFactorSecret)
+		&userProfile.TwoFactorEnabled, &userProfile.TwoFactorSecret,
+		&userProfile.EmailVerifiedAt, &userProfile.PreferredLocale, &userProfile.PasswordChangedAt,
+		&userProfile.TokenVersion)
 
 	if err != nil {
+		s.loginProtection.RecordAttempt(ctx, clientIP, req.Email, nil, false, "unknown_email")
 		return nil, fmt.Errorf("user not found")
 	}
 
@@ -57,10 +85,16 @@ func (s *AuthService) Login(ctx context.Context, req *models.LoginRequest) (*mod
 	// Verify password
 	if err := bcrypt.CompareHashAndPassword([]byte(userProfile.PasswordHash), []byte(req.Password)); err != nil {
 		// Increment failed login attempts
-		s.incrementFailedAttempts(ctx, userProfile.UserID)
+		s.incrementFailedAttempts(ctx, userProfile.UserID, "invalid_password")
+		s.loginProtection.RecordAttempt(ctx, clientIP, req.Email, &userProfile.UserID, false, "invalid_password")
 		return nil, fmt.Errorf("invalid credentials")
 	}
 
+	// Self-signup accounts must prove ownership of their email first
+	if userProfile.EmailVerifiedAt == nil {
+		return nil, fmt.Errorf("email_not_verified")
+	}
+
 	// Check if password is temporary and handle accordingly
 	isTemporary, expirationDate, err := s.temporaryPasswordService.IsPasswordTemporary(ctx, userProfile.UserID)
 	if err != nil {
@@ -77,12 +111,34 @@ func (s *AuthService) Login(ctx context.Context, req *models.LoginRequest) (*mod
 			return nil, fmt.Errorf("two_factor_required")
 		}
 
-		if !s.verify2FACode(*userProfile.TwoFactorSecret, req.TwoFactorCode) {
-			s.incrementFailedAttempts(ctx, userProfile.UserID)
+		secret, err := s.fieldEncryption.Decrypt(ctx, FieldTwoFactorSecret, *userProfile.TwoFactorSecret)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read 2FA secret: %w", err)
+		}
+
+		if !s.verify2FACode(secret, req.TwoFactorCode) {
+			s.incrementFailedAttempts(ctx, userProfile.UserID, "invalid_two_factor_code")
+			s.loginProtection.RecordAttempt(ctx, clientIP, req.Email, &userProfile.UserID, false, "invalid_two_factor_code")
 			return nil, fmt.Errorf("invalid_two_factor_code")
 		}
+	} else if assessment.ChallengeRequired {
+		// Without 2FA, a correct password from a network under attack is not enough:
+		// the account owner must also enter a code sent to their email
+		if req.ChallengeCode == "" {
+			if err := s.loginProtection.IssueChallenge(ctx, &userProfile, clientIP); err != nil {
+				return nil, err
+			}
+			return nil, fmt.Errorf("step_up_required")
+		}
+
+		if err := s.loginProtection.VerifyChallenge(ctx, userProfile.UserID, req.ChallengeCode); err != nil {
+			s.loginProtection.RecordAttempt(ctx, clientIP, req.Email, &userProfile.UserID, false, "invalid_challenge_code")
+			return nil, err
+		}
 	}
 
+	s.loginProtection.RecordAttempt(ctx, clientIP, req.Email, &userProfile.UserID, true, "")
+
 	// Reset failed attempts and update last login
 	s.resetFailedAttempts(ctx, userProfile.UserID)
 
@@ -108,12 +164,23 @@ func (s *AuthService) Login(ctx context.Context, req *models.LoginRequest) (*mod
 		ExpiresIn:    3600, // 1 hour
 	}
 
+	// Limited statuses (e.g. payment_pending) sign in read-only with a notice to act on
+	capabilities := models.CapabilitiesForStatus(userProfile.AccountStatus)
+	if capabilities.ReadOnly {
+		response.AccountStatus = userProfile.AccountStatus
+		response.AccountNotice = capabilities.Notice
+	}
+
 	// Add temporary password information to response if applicable
 	if isTemporary {
 		response.RequiresPasswordChange = true
 		if expirationDate != nil {
 			response.PasswordExpiresAt = expirationDate
 		}
+	} else if expiresAt := s.passwordPolicy.ExpiresAt(userProfile.PasswordChangedAt); expiresAt != nil {
+		// The access token only allows changing the password until it is rotated
+		response.RequiresPasswordChange = s.passwordPolicy.IsExpired(userProfile.PasswordChangedAt)
+		response.PasswordExpiresAt = expiresAt
 	}
 
 	return response, nil
@@ -159,16 +226,21 @@ func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*m
 	var userProfile models.UserProfile
 	err = s.db.GetConnection().QueryRow(ctx,
 		`SELECT user_id, email, first_name, last_name, primary_role, 
-		 is_active, account_status FROM user_profiles WHERE user_id = $1`,
+		 is_active, account_status, password_changed_at, token_version FROM user_profiles WHERE user_id = $1`,
 		userID,
 	).Scan(&userProfile.UserID, &userProfile.Email, &userProfile.FirstName,
 		&userProfile.LastName, &userProfile.PrimaryRole, &userProfile.IsActive,
-		&userProfile.AccountStatus)
+		&userProfile.AccountStatus, &userProfile.PasswordChangedAt, &userProfile.TokenVersion)
 
 	if err != nil {
 		return nil, fmt.Errorf("user not found")
 	}
 
+	// Status changes revoke refresh tokens as well
+	if version, _ := claims["token_version"].(float64); int(version) != userProfile.TokenVersion {
+		return nil, fmt.Errorf("token_revoked")
+	}
+
 	// Check account status
 	if err := s.validateAccountStatus(&userProfile); err != nil {
 		return nil, err
@@ -251,14 +323,24 @@ func (s *AuthService) ResetPassword(ctx context.Context, req *models.PasswordRes
 		return fmt.Errorf("recovery token has expired")
 	}
 
+	if err := s.passwordPolicy.ValidateForUser(ctx, userID, req.NewPassword); err != nil {
+		return err
+	}
+
 	// Hash new password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
 	if err != nil {
 		return fmt.Errorf("failed to hash password: %w", err)
 	}
 
+	tx, err := s.db.GetConnection().Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
 	// Update password and clear recovery token
-	_, err = s.db.GetConnection().Exec(ctx,
+	_, err = tx.Exec(ctx,
 		`UPDATE user_profiles 
 		 SET password_hash = $1, token_recovery = NULL, token_expiration_date = NULL,
 		     failed_login_attempts = 0, locked_until = NULL, updated_at = NOW()
@@ -270,6 +352,14 @@ func (s *AuthService) ResetPassword(ctx context.Context, req *models.PasswordRes
 		return fmt.Errorf("failed to update password: %w", err)
 	}
 
+	if err := s.passwordPolicy.RecordChange(ctx, tx, userID, string(hashedPassword)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return nil
 }
 
@@ -304,10 +394,15 @@ func (s *AuthService) Setup2FA(ctx context.Context, userID uuid.UUID) (*models.S
 		return nil, fmt.Errorf("failed to generate OTP key: %w", err)
 	}
 
-	// Store secret (but don't enable 2FA yet)
+	// Store secret, encrypted (but don't enable 2FA yet)
+	encryptedSecret, err := s.fieldEncryption.Encrypt(ctx, FieldTwoFactorSecret, secretBase32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt 2FA secret: %w", err)
+	}
+
 	_, err = s.db.GetConnection().Exec(ctx,
 		"UPDATE user_profiles SET two_factor_secret = $1, updated_at = NOW() WHERE user_id = $2",
-		secretBase32, userID,
+		encryptedSecret, userID,
 	)
 
 	if err != nil {
@@ -333,6 +428,10 @@ func (s *AuthService) Verify2FA(ctx context.Context, userID uuid.UUID, req *mode
 		return fmt.Errorf("user not found or 2FA not set up")
 	}
 
+	if secret, err = s.fieldEncryption.Decrypt(ctx, FieldTwoFactorSecret, secret); err != nil {
+		return fmt.Errorf("failed to read 2FA secret: %w", err)
+	}
+
 	// Verify code
 	if !s.verify2FACode(secret, req.Code) {
 		return fmt.Errorf("invalid 2FA code")
@@ -369,6 +468,10 @@ func (s *AuthService) Disable2FA(ctx context.Context, userID uuid.UUID, req *mod
 		return fmt.Errorf("2FA is not enabled")
 	}
 
+	if secret, err = s.fieldEncryption.Decrypt(ctx, FieldTwoFactorSecret, secret); err != nil {
+		return fmt.Errorf("failed to read 2FA secret: %w", err)
+	}
+
 	// Verify code before disabling
 	if !s.verify2FACode(secret, req.Code) {
 		return fmt.Errorf("invalid 2FA code")
@@ -396,7 +499,7 @@ func (s *AuthService) validateAccountStatus(user *models.UserProfile) error {
 		return fmt.Errorf("account_inactive")
 	}
 
-	if user.AccountStatus != models.AccountStatusActive {
+	if !models.CapabilitiesForStatus(user.AccountStatus).CanLogin {
 		return fmt.Errorf("account_%s", user.AccountStatus)
 	}
 
@@ -407,7 +510,7 @@ func (s *AuthService) validateAccountStatus(user *models.UserProfile) error {
 	return nil
 }
 
-func (s *AuthService) incrementFailedAttempts(ctx context.Context, userID uuid.UUID) {
+func (s *AuthService) incrementFailedAttempts(ctx context.Context, userID uuid.UUID, reason string) {
 	// Get current failed attempts
 	var attempts int
 	s.db.GetConnection().QueryRow(ctx,
@@ -418,12 +521,9 @@ func (s *AuthService) incrementFailedAttempts(ctx context.Context, userID uuid.U
 	attempts++
 	var lockUntil *time.Time
 
-	// Progressive locking: 5 attempts = 15 min, 10 attempts = 24 hours
-	if attempts >= 10 {
-		lockTime := time.Now().Add(24 * time.Hour)
-		lockUntil = &lockTime
-	} else if attempts >= 5 {
-		lockTime := time.Now().Add(15 * time.Minute)
+	// Progressive locking driven by the configured lockout policy
+	if duration := s.lockoutPolicy.LockDuration(attempts); duration > 0 {
+		lockTime := time.Now().Add(duration)
 		lockUntil = &lockTime
 	}
 
@@ -431,6 +531,31 @@ func (s *AuthService) incrementFailedAttempts(ctx context.Context, userID uuid.U
 		"UPDATE user_profiles SET failed_login_attempts = $1, locked_until = $2 WHERE user_id = $3",
 		attempts, lockUntil, userID,
 	)
+
+	// Failed logins feed the security alert rules
+	s.auditService.LogSecurityEvent(ctx, SecurityEvent{
+		EventType:   EventTypeLoginFailed,
+		Description: "Failed login attempt",
+		UserID:      &userID,
+		Metadata: map[string]interface{}{
+			"reason":   reason,
+			"attempts": attempts,
+		},
+	})
+
+	// Locked accounts are rejected before the password is checked,
+	// so every lock applied here is a new one
+	if lockUntil != nil {
+		s.auditService.LogSecurityEvent(ctx, SecurityEvent{
+			EventType:   EventTypeAccountLocked,
+			Description: "Account locked after repeated failed logins",
+			UserID:      &userID,
+			Metadata: map[string]interface{}{
+				"attempts":     attempts,
+				"locked_until": lockUntil,
+			},
+		})
+	}
 }
 
 func (s *AuthService) resetFailedAttempts(ctx context.Context, userID uuid.UUID) {
@@ -444,14 +569,21 @@ func (s *AuthService) resetFailedAttempts(ctx context.Context, userID uuid.UUID)
 
 func (s *AuthService) generateAccessToken(user *models.UserProfile) (string, error) {
 	claims := jwt.MapClaims{
-		"user_id":      user.UserID.String(),
-		"email":        user.Email,
-		"first_name":   user.FirstName,
-		"last_name":    user.LastName,
-		"primary_role": user.PrimaryRole,
-		"type":         "access",
-		"exp":          time.Now().Add(time.Hour).Unix(),
-		"iat":          time.Now().Unix(),
+		"user_id":        user.UserID.String(),
+		"email":          user.Email,
+		"first_name":     user.FirstName,
+		"last_name":      user.LastName,
+		"primary_role":   user.PrimaryRole,
+		"account_status": user.AccountStatus,
+		"token_version":  user.TokenVersion,
+		"type":           "access",
+		"exp":            time.Now().Add(time.Hour).Unix(),
+		"iat":            time.Now().Unix(),
+	}
+
+	// Expired passwords get a token that is only accepted for changing the password
+	if s.passwordPolicy.IsExpired(user.PasswordChangedAt) {
+		claims["password_change_required"] = true
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -460,10 +592,11 @@ func (s *AuthService) generateAccessToken(user *models.UserProfile) (string, err
 
 func (s *AuthService) generateRefreshToken(user *models.UserProfile) (string, error) {
 	claims := jwt.MapClaims{
-		"user_id": user.UserID.String(),
-		"type":    "refresh",
-		"exp":     time.Now().Add(7 * 24 * time.Hour).Unix(), // 7 days
-		"iat":     time.Now().Unix(),
+		"user_id":       user.UserID.String(),
+		"token_version": user.TokenVersion,
+		"type":          "refresh",
+		"exp":           time.Now().Add(7 * 24 * time.Hour).Unix(), // 7 days
+		"iat":           time.Now().Unix(),
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -487,19 +620,19 @@ func (s *AuthService) verify2FACode(secret, code string) bool {
 func (s *AuthService) GetUserProfile(ctx context.Context, userID uuid.UUID) (*models.UserProfile, error) {
 	var userProfile models.UserProfile
 	err := s.db.GetConnection().QueryRow(ctx,
-		`SELECT user_id, email, first_name, last_name, phone, identification, 
+		`SELECT user_id, email, first_name, last_name, phone, identification, identification_type,
 		 photo_url, primary_role, is_active, account_status, 
 		 last_login_at, failed_login_attempts, locked_until, 
-		 two_factor_enabled, created_at, updated_at
+		 two_factor_enabled, preferred_locale, created_at, updated_at
 		 FROM user_profiles 
 		 WHERE user_id = $1 AND is_active = true`,
 		userID,
 	).Scan(
 		&userProfile.UserID, &userProfile.Email, &userProfile.FirstName, &userProfile.LastName,
-		&userProfile.Phone, &userProfile.Identification, &userProfile.PhotoURL, &userProfile.PrimaryRole,
+		&userProfile.Phone, &userProfile.Identification, &userProfile.IdentificationType, &userProfile.PhotoURL, &userProfile.PrimaryRole,
 		&userProfile.IsActive, &userProfile.AccountStatus, &userProfile.LastLoginAt,
 		&userProfile.FailedLoginAttempts, &userProfile.LockedUntil, &userProfile.TwoFactorEnabled,
-		&userProfile.CreatedAt, &userProfile.UpdatedAt,
+		&userProfile.PreferredLocale, &userProfile.CreatedAt, &userProfile.UpdatedAt,
 	)
 
 	if err != nil {
@@ -508,3 +641,19 @@ func (s *AuthService) GetUserProfile(ctx context.Context, userID uuid.UUID) (*mo
 
 	return &userProfile, nil
 }
+
+// UpdatePreferredLocale sets the language used for the user's emails
+func (s *AuthService) UpdatePreferredLocale(ctx context.Context, userID uuid.UUID, locale string) error {
+	result, err := s.db.GetConnection().Exec(ctx,
+		"UPDATE user_profiles SET preferred_locale = $2, updated_at = NOW() WHERE user_id = $1 AND is_active = true",
+		userID, locale,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update preferred locale: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	return nil
+}
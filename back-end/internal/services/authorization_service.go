@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mowesport/internal/database"
+	"mowesport/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ResourceScope identifies the city/sport a resource belongs to.
+// A nil field means the resource is not bound to a specific city or sport.
+// TournamentID narrows the scope to one tournament of that city/sport.
+type ResourceScope struct {
+	CityID       *uuid.UUID `json:"city_id"`
+	SportID      *uuid.UUID `json:"sport_id"`
+	TournamentID *uuid.UUID `json:"tournament_id,omitempty"`
+}
+
+// String renders the scope for cache keys and audit metadata
+func (r ResourceScope) String() string {
+	city, sport := "*", "*"
+	if r.CityID != nil {
+		city = r.CityID.String()
+	}
+	if r.SportID != nil {
+		sport = r.SportID.String()
+	}
+	if r.TournamentID != nil {
+		return city + "/" + sport + "/" + r.TournamentID.String()
+	}
+	return city + "/" + sport
+}
+
+// AuthorizationPolicy decides whether a user holding one of the given roles may act within a scope
+type AuthorizationPolicy interface {
+	Authorize(ctx context.Context, userID uuid.UUID, roles []string, scope ResourceScope) (bool, error)
+}
+
+// RoleAssignmentPolicy authorizes against active user_roles_by_city_sport assignments.
+// An assignment with a NULL city, sport or tournament covers every city, sport or
+// tournament; a tournament-bound assignment only covers that tournament. Super admins
+// are always allowed.
+type RoleAssignmentPolicy struct {
+	db *database.Database
+}
+
+// NewRoleAssignmentPolicy creates a policy backed by role assignments
+func NewRoleAssignmentPolicy(db *database.Database) *RoleAssignmentPolicy {
+	return &RoleAssignmentPolicy{db: db}
+}
+
+// Authorize checks the user has an active assignment covering the scope
+func (p *RoleAssignmentPolicy) Authorize(ctx context.Context, userID uuid.UUID, roles []string, scope ResourceScope) (bool, error) {
+	var allowed bool
+	err := p.db.GetConnection().QueryRow(ctx, `
+		SELECT EXISTS(
+			SELECT 1 FROM user_profiles
+			WHERE user_id = $1 AND primary_role = $2 AND is_active = true AND account_status = 'active'
+		) OR EXISTS(
+			SELECT 1 FROM user_roles_by_city_sport ur
+			JOIN user_profiles up ON up.user_id = ur.user_id
+			WHERE ur.user_id = $1
+			AND ur.role_name = ANY($3)
+			AND ur.is_active = true
+			AND up.is_active = true
+			AND (ur.city_id IS NULL OR ur.city_id = $4)
+			AND (ur.sport_id IS NULL OR ur.sport_id = $5)
+			AND (ur.tournament_id IS NULL OR ur.tournament_id = $6)
+		)
+	`, userID, models.RoleSuperAdmin, roles, scope.CityID, scope.SportID, scope.TournamentID).Scan(&allowed)
+	if err != nil {
+		return false, fmt.Errorf("failed to evaluate authorization policy: %w", err)
+	}
+	return allowed, nil
+}
+
+// ScopeLookupService resolves the city/sport scope of stored resources
+type ScopeLookupService struct {
+	db *database.Database
+}
+
+// NewScopeLookupService creates a new scope lookup service
+func NewScopeLookupService(db *database.Database) *ScopeLookupService {
+	return &ScopeLookupService{db: db}
+}
+
+// ScopesForUser returns the scopes of every active role assignment held by a user
+func (s *ScopeLookupService) ScopesForUser(ctx context.Context, userID uuid.UUID) ([]ResourceScope, error) {
+	var exists bool
+	if err := s.db.GetConnection().QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM user_profiles WHERE user_id = $1)", userID).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to check user existence: %w", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("user not found")
+	}
+
+	rows, err := s.db.GetConnection().Query(ctx, `
+		SELECT DISTINCT city_id, sport_id FROM user_roles_by_city_sport
+		WHERE user_id = $1 AND is_active = true
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load user scopes: %w", err)
+	}
+	defer rows.Close()
+
+	scopes := []ResourceScope{}
+	for rows.Next() {
+		var scope ResourceScope
+		if err := rows.Scan(&scope.CityID, &scope.SportID); err != nil {
+			return nil, fmt.Errorf("failed to scan user scope: %w", err)
+		}
+		scopes = append(scopes, scope)
+	}
+
+	// Users without assignments are global resources only super admins manage
+	if len(scopes) == 0 {
+		scopes = append(scopes, ResourceScope{})
+	}
+
+	return scopes, rows.Err()
+}
+
+// ScopeForMatch returns the city/sport of a match's tournament, narrowed to the tournament
+func (s *ScopeLookupService) ScopeForMatch(ctx context.Context, matchID uuid.UUID) (ResourceScope, error) {
+	var scope ResourceScope
+	err := s.db.GetConnection().QueryRow(ctx, `
+		SELECT t.city_id, t.sport_id, t.tournament_id
+		FROM matches m
+		JOIN tournaments t ON t.tournament_id = m.tournament_id
+		WHERE m.match_id = $1
+	`, matchID).Scan(&scope.CityID, &scope.SportID, &scope.TournamentID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return scope, fmt.Errorf("match not found")
+		}
+		return scope, fmt.Errorf("failed to load match scope: %w", err)
+	}
+	return scope, nil
+}
+
+// ScopeForTournament returns the city/sport of a tournament, narrowed to the tournament
+func (s *ScopeLookupService) ScopeForTournament(ctx context.Context, tournamentID uuid.UUID) (ResourceScope, error) {
+	scope := ResourceScope{TournamentID: &tournamentID}
+	err := s.db.GetConnection().QueryRow(ctx, `
+		SELECT city_id, sport_id FROM tournaments WHERE tournament_id = $1
+	`, tournamentID).Scan(&scope.CityID, &scope.SportID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return scope, fmt.Errorf("tournament not found")
+		}
+		return scope, fmt.Errorf("failed to load tournament scope: %w", err)
+	}
+	return scope, nil
+}
+
+// ScopesForPlayer returns the city/sport of every team a player is active in, plus the
+// scopes of the player's own account. Players in neither are managed by super admins only.
+func (s *ScopeLookupService) ScopesForPlayer(ctx context.Context, playerID uuid.UUID) ([]ResourceScope, error) {
+	var userProfileID *uuid.UUID
+	err := s.db.GetConnection().QueryRow(ctx, "SELECT user_profile_id FROM players WHERE player_id = $1", playerID).Scan(&userProfileID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("player not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load player: %w", err)
+	}
+
+	rows, err := s.db.GetConnection().Query(ctx, `
+		SELECT DISTINCT t.city_id, t.sport_id FROM team_players tp
+		JOIN teams t ON t.team_id = tp.team_id
+		WHERE tp.player_id = $1 AND tp.is_active = true
+	`, playerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load player scopes: %w", err)
+	}
+	defer rows.Close()
+
+	scopes := []ResourceScope{}
+	for rows.Next() {
+		var scope ResourceScope
+		if err := rows.Scan(&scope.CityID, &scope.SportID); err != nil {
+			return nil, fmt.Errorf("failed to scan player scope: %w", err)
+		}
+		scopes = append(scopes, scope)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if userProfileID != nil {
+		userScopes, err := s.ScopesForUser(ctx, *userProfileID)
+		if err != nil {
+			return nil, err
+		}
+		for _, scope := range userScopes {
+			if scope.CityID != nil || scope.SportID != nil || len(scopes) == 0 {
+				scopes = append(scopes, scope)
+			}
+		}
+	}
+
+	if len(scopes) == 0 {
+		scopes = append(scopes, ResourceScope{})
+	}
+	return scopes, nil
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"mowesport/internal/database"
+	"mowesport/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// Delegation actions
+const (
+	DelegationCreate = "create" // Register a new user holding the role
+	DelegationAssign = "assign" // Grant the role to an existing user
+	DelegationRevoke = "revoke" // Deactivate a role assignment
+)
+
+// DelegationGrant allows holders of ActorRole to perform Actions on TargetRole.
+// Outside of super admins, the actor needs an active assignment of ActorRole that
+// covers the target city/sport, and the tournament for tournament-bound roles.
+type DelegationGrant struct {
+	ActorRole  string
+	TargetRole string
+	Actions    []string
+}
+
+var allDelegationActions = []string{DelegationCreate, DelegationAssign, DelegationRevoke}
+
+// delegationMatrix is the single source of truth for who may create, assign and revoke
+// which roles. It drives the register/* and roles endpoints.
+var delegationMatrix = []DelegationGrant{
+	{ActorRole: models.RoleSuperAdmin, TargetRole: models.RoleCityAdmin, Actions: allDelegationActions},
+	{ActorRole: models.RoleSuperAdmin, TargetRole: models.RoleTournamentAdmin, Actions: allDelegationActions},
+	{ActorRole: models.RoleSuperAdmin, TargetRole: models.RoleOwner, Actions: allDelegationActions},
+	{ActorRole: models.RoleSuperAdmin, TargetRole: models.RoleReferee, Actions: allDelegationActions},
+	{ActorRole: models.RoleSuperAdmin, TargetRole: models.RoleCoach, Actions: allDelegationActions},
+	{ActorRole: models.RoleSuperAdmin, TargetRole: models.RolePlayer, Actions: allDelegationActions},
+	{ActorRole: models.RoleSuperAdmin, TargetRole: models.RoleClient, Actions: []string{DelegationAssign, DelegationRevoke}},
+
+	{ActorRole: models.RoleCityAdmin, TargetRole: models.RoleTournamentAdmin, Actions: allDelegationActions},
+	{ActorRole: models.RoleCityAdmin, TargetRole: models.RoleOwner, Actions: allDelegationActions},
+	{ActorRole: models.RoleCityAdmin, TargetRole: models.RoleReferee, Actions: allDelegationActions},
+	{ActorRole: models.RoleCityAdmin, TargetRole: models.RoleCoach, Actions: allDelegationActions},
+	{ActorRole: models.RoleCityAdmin, TargetRole: models.RolePlayer, Actions: allDelegationActions},
+	{ActorRole: models.RoleCityAdmin, TargetRole: models.RoleClient, Actions: []string{DelegationAssign, DelegationRevoke}},
+
+	// Referee assignments are not tournament-bound, so tournament admins cannot revoke them
+	{ActorRole: models.RoleTournamentAdmin, TargetRole: models.RoleReferee, Actions: []string{DelegationCreate, DelegationAssign}},
+
+	{ActorRole: models.RoleOwner, TargetRole: models.RoleCoach, Actions: allDelegationActions},
+	{ActorRole: models.RoleOwner, TargetRole: models.RolePlayer, Actions: allDelegationActions},
+}
+
+// DelegationMatrix returns a copy of the delegation grants
+func DelegationMatrix() []DelegationGrant {
+	matrix := make([]DelegationGrant, len(delegationMatrix))
+	copy(matrix, delegationMatrix)
+	return matrix
+}
+
+// DelegatorRoles returns the roles allowed to perform action on targetRole
+func DelegatorRoles(action, targetRole string) []string {
+	var roles []string
+	for _, grant := range delegationMatrix {
+		if grant.TargetRole != targetRole {
+			continue
+		}
+		for _, granted := range grant.Actions {
+			if granted == action {
+				roles = append(roles, grant.ActorRole)
+				break
+			}
+		}
+	}
+	return roles
+}
+
+// DelegatingRoles returns every role that may perform action on at least one role
+func DelegatingRoles(action string) []string {
+	seen := map[string]bool{}
+	var roles []string
+	for _, grant := range delegationMatrix {
+		for _, granted := range grant.Actions {
+			if granted == action && !seen[grant.ActorRole] {
+				seen[grant.ActorRole] = true
+				roles = append(roles, grant.ActorRole)
+			}
+		}
+	}
+	return roles
+}
+
+// IsTournamentBoundRole reports whether assignments of the role must name a tournament
+func IsTournamentBoundRole(role string) bool {
+	return role == models.RoleTournamentAdmin
+}
+
+// DelegationService checks requests to create, assign and revoke roles against the
+// delegation matrix and the actor's own city/sport/tournament assignments
+type DelegationService struct {
+	policy       AuthorizationPolicy
+	scopeLookup  *ScopeLookupService
+	auditService *SecurityAuditService
+}
+
+// NewDelegationService creates a new delegation service
+func NewDelegationService(db *database.Database) *DelegationService {
+	return &DelegationService{
+		policy:       NewRoleAssignmentPolicy(db),
+		scopeLookup:  NewScopeLookupService(db),
+		auditService: NewSecurityAuditService(db),
+	}
+}
+
+// ResolveScope builds the scope of a role assignment request. A tournament fills in the
+// city/sport, which must match any city/sport given; it is required for tournament-bound
+// roles and lets tournament admins act within their tournament for the others.
+func (s *DelegationService) ResolveScope(ctx context.Context, targetRole string, cityID, sportID, tournamentID *uuid.UUID) (ResourceScope, error) {
+	if tournamentID == nil {
+		if IsTournamentBoundRole(targetRole) {
+			return ResourceScope{}, fmt.Errorf("invalid scope: tournament_id is required for %s", targetRole)
+		}
+		return ResourceScope{CityID: cityID, SportID: sportID}, nil
+	}
+
+	scope, err := s.scopeLookup.ScopeForTournament(ctx, *tournamentID)
+	if err != nil {
+		return ResourceScope{}, err
+	}
+	if (cityID != nil && *cityID != *scope.CityID) || (sportID != nil && *sportID != *scope.SportID) {
+		return ResourceScope{}, fmt.Errorf("invalid scope: tournament does not belong to the specified city/sport")
+	}
+	return scope, nil
+}
+
+// AssignmentTournament returns the tournament a role assignment in scope is bound to, if any
+func AssignmentTournament(targetRole string, scope ResourceScope) *uuid.UUID {
+	if !IsTournamentBoundRole(targetRole) {
+		return nil
+	}
+	return scope.TournamentID
+}
+
+// Authorize checks that actorID may perform action on targetRole within scope.
+// Denials are written to the audit log.
+func (s *DelegationService) Authorize(ctx context.Context, actorID uuid.UUID, action, targetRole string, scope ResourceScope) error {
+	roles := DelegatorRoles(action, targetRole)
+	if len(roles) == 0 {
+		return fmt.Errorf("insufficient permissions: role %s cannot be delegated", targetRole)
+	}
+
+	allowed, err := s.policy.Authorize(ctx, actorID, roles, scope)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		s.auditService.LogSecurityEvent(ctx, SecurityEvent{
+			EventType:   EventTypeUnauthorizedAccess,
+			Description: fmt.Sprintf("Delegation denied: %s %s", action, targetRole),
+			UserID:      &actorID,
+			Metadata: map[string]interface{}{
+				"action":        action,
+				"target_role":   targetRole,
+				"allowed_roles": roles,
+				"city_id":       scope.CityID,
+				"sport_id":      scope.SportID,
+				"tournament_id": scope.TournamentID,
+			},
+		})
+		return fmt.Errorf("insufficient permissions: cannot %s %s in this scope", action, targetRole)
+	}
+
+	return nil
+}
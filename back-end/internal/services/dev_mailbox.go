@@ -0,0 +1,321 @@
+package services
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"mowesport/internal/models"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DevMailbox captures every email "sent" in development as an .eml file, so links in
+// password reset or invitation emails can be followed locally and integration tests
+// can read exactly what would have been delivered
+type DevMailbox struct {
+	dir string
+}
+
+// NewDevMailbox creates a mailbox stored in dir
+func NewDevMailbox(dir string) *DevMailbox {
+	return &DevMailbox{dir: dir}
+}
+
+// Store writes the raw message and returns the mailbox ID derived from its Message-ID
+func (m *DevMailbox) Store(messageID string, raw []byte) (string, error) {
+	id := strings.TrimPrefix(messageID, "<")
+	if at := strings.Index(id, "@"); at >= 0 {
+		id = id[:at]
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		id = uuid.New().String()
+	}
+
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create dev mailbox: %w", err)
+	}
+
+	// The timestamp prefix keeps files in delivery order
+	filename := fmt.Sprintf("%020d_%s.eml", time.Now().UnixNano(), id)
+	if err := os.WriteFile(filepath.Join(m.dir, filename), raw, 0o644); err != nil {
+		return "", fmt.Errorf("failed to store email in dev mailbox: %w", err)
+	}
+
+	return id, nil
+}
+
+// List returns captured messages newest first, optionally only those sent to one address
+func (m *DevMailbox) List(to string, limit int) ([]models.DevMailboxMessage, error) {
+	if limit == 0 {
+		limit = 50
+	}
+
+	files, err := m.files()
+	if err != nil {
+		return nil, err
+	}
+
+	messages := []models.DevMailboxMessage{}
+	for _, file := range files {
+		if len(messages) >= limit {
+			break
+		}
+		detail, _, err := m.read(file)
+		if err != nil {
+			continue
+		}
+		if to != "" && !strings.EqualFold(detail.To, to) {
+			continue
+		}
+		messages = append(messages, detail.DevMailboxMessage)
+	}
+
+	return messages, nil
+}
+
+// Get returns a captured message with its decoded parts
+func (m *DevMailbox) Get(id string) (*models.DevMailboxMessageDetail, error) {
+	file, err := m.find(id)
+	if err != nil {
+		return nil, err
+	}
+
+	detail, _, err := m.read(file)
+	return detail, err
+}
+
+// Latest returns the most recent message sent to an address
+func (m *DevMailbox) Latest(to string) (*models.DevMailboxMessageDetail, error) {
+	messages, err := m.List(to, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("message not found")
+	}
+
+	return m.Get(messages[0].ID)
+}
+
+// Raw returns the message exactly as it would have been sent over SMTP
+func (m *DevMailbox) Raw(id string) ([]byte, error) {
+	file, err := m.find(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return os.ReadFile(file)
+}
+
+// Attachment returns one decoded attachment of a captured message
+func (m *DevMailbox) Attachment(id string, index int) (*models.DevMailboxAttachment, []byte, error) {
+	file, err := m.find(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	detail, contents, err := m.read(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	if index < 0 || index >= len(contents) {
+		return nil, nil, fmt.Errorf("attachment not found")
+	}
+
+	return &detail.Attachments[index], contents[index], nil
+}
+
+// Clear deletes every captured message
+func (m *DevMailbox) Clear() (int, error) {
+	files, err := m.files()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, file := range files {
+		if err := os.Remove(file); err != nil {
+			return 0, fmt.Errorf("failed to delete %s: %w", filepath.Base(file), err)
+		}
+	}
+
+	return len(files), nil
+}
+
+// Helper methods
+
+// files returns the stored messages, newest first
+func (m *DevMailbox) files() ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(m.dir, "*.eml"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dev mailbox: %w", err)
+	}
+
+	sort.Sort(sort.Reverse(sort.StringSlice(files)))
+	return files, nil
+}
+
+// find locates the file of a message; IDs are UUIDs so they cannot escape the mailbox directory
+func (m *DevMailbox) find(id string) (string, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return "", fmt.Errorf("message not found")
+	}
+
+	files, err := filepath.Glob(filepath.Join(m.dir, "*_"+id+".eml"))
+	if err != nil || len(files) == 0 {
+		return "", fmt.Errorf("message not found")
+	}
+
+	return files[0], nil
+}
+
+// read parses a stored message into its headers, bodies and attachments
+func (m *DevMailbox) read(file string) (*models.DevMailboxMessageDetail, [][]byte, error) {
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read %s: %w", filepath.Base(file), err)
+	}
+
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse %s: %w", filepath.Base(file), err)
+	}
+
+	decoder := new(mime.WordDecoder)
+	subject, _ := decoder.DecodeHeader(msg.Header.Get("Subject"))
+	from, _ := decoder.DecodeHeader(msg.Header.Get("From"))
+	date, _ := msg.Header.Date()
+
+	to := msg.Header.Get("To")
+	if address, err := mail.ParseAddress(to); err == nil {
+		to = address.Address
+	}
+
+	id := strings.TrimSuffix(filepath.Base(file), ".eml")
+	if underscore := strings.Index(id, "_"); underscore >= 0 {
+		id = id[underscore+1:]
+	}
+
+	detail := &models.DevMailboxMessageDetail{
+		DevMailboxMessage: models.DevMailboxMessage{
+			ID:        id,
+			MessageID: msg.Header.Get("Message-ID"),
+			From:      from,
+			To:        to,
+			ReplyTo:   msg.Header.Get("Reply-To"),
+			Subject:   subject,
+			Date:      date,
+		},
+		Headers:     msg.Header,
+		Attachments: []models.DevMailboxAttachment{},
+	}
+
+	contents := [][]byte{}
+	err = walkMIMEParts(msg.Header.Get("Content-Type"), msg.Header.Get("Content-Transfer-Encoding"), "", msg.Body,
+		func(mediaType, filename string, body []byte) {
+			switch {
+			case filename != "":
+				detail.Attachments = append(detail.Attachments, models.DevMailboxAttachment{
+					Index:    len(contents),
+					Filename: filename,
+					MimeType: mediaType,
+					Size:     len(body),
+				})
+				contents = append(contents, body)
+			case mediaType == "text/html" && detail.HTML == "":
+				detail.HTML = string(body)
+			case mediaType == "text/plain" && detail.Text == "":
+				detail.Text = string(body)
+			}
+		})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode %s: %w", filepath.Base(file), err)
+	}
+
+	detail.AttachmentCount = len(detail.Attachments)
+	detail.Links = extractLinks(detail.HTML, detail.Text)
+
+	return detail, contents, nil
+}
+
+// walkMIMEParts decodes every leaf part of a message, descending into multiparts
+func walkMIMEParts(contentType, transferEncoding, disposition string, body io.Reader, visit func(mediaType, filename string, body []byte)) error {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = "text/plain"
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := reader.NextRawPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if err := walkMIMEParts(part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"),
+				part.Header.Get("Content-Disposition"), part, visit); err != nil {
+				return err
+			}
+		}
+	}
+
+	switch strings.ToLower(transferEncoding) {
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	}
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	filename := ""
+	if _, dispositionParams, err := mime.ParseMediaType(disposition); err == nil {
+		filename = dispositionParams["filename"]
+	}
+	visit(mediaType, filename, content)
+
+	return nil
+}
+
+var (
+	hrefPattern    = regexp.MustCompile(`(?i)href="([^"]+)"`)
+	textURLPattern = regexp.MustCompile(`https?://[^\s"'<>]+`)
+)
+
+// extractLinks returns the distinct links of a message, HTML links first
+func extractLinks(htmlBody, textBody string) []string {
+	links := []string{}
+	seen := map[string]bool{}
+	add := func(link string) {
+		link = html.UnescapeString(link)
+		if !seen[link] {
+			seen[link] = true
+			links = append(links, link)
+		}
+	}
+
+	for _, match := range hrefPattern.FindAllStringSubmatch(htmlBody, -1) {
+		add(match[1])
+	}
+	for _, match := range textURLPattern.FindAllString(textBody, -1) {
+		add(match)
+	}
+
+	return links
+}
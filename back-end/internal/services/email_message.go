@@ -0,0 +1,218 @@
+package services
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// mimeHeaders are the top-level headers of an outgoing message
+type mimeHeaders struct {
+	From      mail.Address
+	To        string
+	ReplyTo   string
+	Subject   string
+	MessageID string
+	Date      time.Time
+}
+
+// buildMIMEMessage renders a complete RFC 5322 message. HTML email is sent as
+// multipart/alternative with a plain-text part; attachments wrap the body in multipart/mixed.
+func buildMIMEMessage(h mimeHeaders, emailData EmailData) ([]byte, error) {
+	to, err := mail.ParseAddress(h.To)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recipient address %q: %w", h.To, err)
+	}
+
+	var buf bytes.Buffer
+	writeHeader(&buf, "From", h.From.String())
+	writeHeader(&buf, "To", to.String())
+	if h.ReplyTo != "" {
+		replyTo, err := mail.ParseAddress(h.ReplyTo)
+		if err != nil {
+			return nil, fmt.Errorf("invalid reply-to address %q: %w", h.ReplyTo, err)
+		}
+		writeHeader(&buf, "Reply-To", replyTo.String())
+	}
+	writeHeader(&buf, "Subject", mime.QEncoding.Encode("utf-8", h.Subject))
+	writeHeader(&buf, "Date", h.Date.Format(time.RFC1123Z))
+	writeHeader(&buf, "Message-ID", h.MessageID)
+	writeHeader(&buf, "MIME-Version", "1.0")
+
+	textBody := emailData.TextBody
+	if !emailData.IsHTML {
+		textBody = emailData.Body
+	} else if textBody == "" {
+		textBody = htmlToText(emailData.Body)
+	}
+
+	if len(emailData.Attachments) == 0 {
+		if !emailData.IsHTML {
+			writeHeader(&buf, "Content-Type", "text/plain; charset=UTF-8")
+			writeHeader(&buf, "Content-Transfer-Encoding", "quoted-printable")
+			buf.WriteString("\r\n")
+			if err := writeQuotedPrintable(&buf, textBody); err != nil {
+				return nil, err
+			}
+			return buf.Bytes(), nil
+		}
+
+		alternative := multipart.NewWriter(&buf)
+		writeHeader(&buf, "Content-Type", "multipart/alternative; boundary="+alternative.Boundary())
+		buf.WriteString("\r\n")
+		if err := writeAlternativeParts(alternative, textBody, emailData.Body); err != nil {
+			return nil, err
+		}
+		if err := alternative.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	mixed := multipart.NewWriter(&buf)
+	writeHeader(&buf, "Content-Type", "multipart/mixed; boundary="+mixed.Boundary())
+	buf.WriteString("\r\n")
+
+	if emailData.IsHTML {
+		alternativeBoundary := multipart.NewWriter(io.Discard).Boundary()
+		part, err := mixed.CreatePart(textproto.MIMEHeader{
+			"Content-Type": {"multipart/alternative; boundary=" + alternativeBoundary},
+		})
+		if err != nil {
+			return nil, err
+		}
+		alternative := multipart.NewWriter(part)
+		if err := alternative.SetBoundary(alternativeBoundary); err != nil {
+			return nil, err
+		}
+		if err := writeAlternativeParts(alternative, textBody, emailData.Body); err != nil {
+			return nil, err
+		}
+		if err := alternative.Close(); err != nil {
+			return nil, err
+		}
+	} else if err := writeTextPart(mixed, "text/plain; charset=UTF-8", textBody); err != nil {
+		return nil, err
+	}
+
+	for _, attachment := range emailData.Attachments {
+		if err := writeAttachmentPart(mixed, attachment); err != nil {
+			return nil, err
+		}
+	}
+	if err := mixed.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// writeHeader writes a single header line
+func writeHeader(buf *bytes.Buffer, name, value string) {
+	buf.WriteString(name)
+	buf.WriteString(": ")
+	buf.WriteString(value)
+	buf.WriteString("\r\n")
+}
+
+// writeAlternativeParts writes the plain-text part first so clients prefer the HTML one
+func writeAlternativeParts(w *multipart.Writer, textBody, htmlBody string) error {
+	if err := writeTextPart(w, "text/plain; charset=UTF-8", textBody); err != nil {
+		return err
+	}
+	return writeTextPart(w, "text/html; charset=UTF-8", htmlBody)
+}
+
+// writeTextPart adds a quoted-printable text part
+func writeTextPart(w *multipart.Writer, contentType, body string) error {
+	part, err := w.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {contentType},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return err
+	}
+	return writeQuotedPrintable(part, body)
+}
+
+// writeQuotedPrintable encodes a UTF-8 body with CRLF line endings
+func writeQuotedPrintable(w io.Writer, body string) error {
+	qp := quotedprintable.NewWriter(w)
+	body = strings.ReplaceAll(strings.ReplaceAll(body, "\r\n", "\n"), "\n", "\r\n")
+	if _, err := qp.Write([]byte(body)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+// writeAttachmentPart adds a base64 attachment part, wrapped at 76 characters
+func writeAttachmentPart(w *multipart.Writer, attachment EmailAttachment) error {
+	mimeType := attachment.MimeType
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+
+	part, err := w.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {mime.FormatMediaType(mimeType, map[string]string{"name": attachment.Filename})},
+		"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename})},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return err
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(attachment.Content)
+	for len(encoded) > 76 {
+		if _, err := io.WriteString(part, encoded[:76]+"\r\n"); err != nil {
+			return err
+		}
+		encoded = encoded[76:]
+	}
+	_, err = io.WriteString(part, encoded+"\r\n")
+	return err
+}
+
+var (
+	htmlLinkPattern        = regexp.MustCompile(`(?is)<a\s[^>]*href="([^"]*)"[^>]*>(.*?)</a>`)
+	htmlBlockPattern       = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|h[1-6]|li|tr)>`)
+	htmlHeadPattern        = regexp.MustCompile(`(?is)<(head|style|script)[^>]*>.*?</(head|style|script)>`)
+	htmlTagPattern         = regexp.MustCompile(`(?s)<[^>]+>`)
+	blankLinesPattern      = regexp.MustCompile(`\n{3,}`)
+	horizontalSpacePattern = regexp.MustCompile(`[ \t]+`)
+)
+
+// htmlToText derives a readable plain-text alternative from an HTML body;
+// links keep their target so they still work in text-only clients
+func htmlToText(body string) string {
+	text := htmlHeadPattern.ReplaceAllString(body, "")
+	text = htmlLinkPattern.ReplaceAllStringFunc(text, func(link string) string {
+		match := htmlLinkPattern.FindStringSubmatch(link)
+		label := strings.TrimSpace(htmlTagPattern.ReplaceAllString(match[2], ""))
+		if label == "" || label == match[1] {
+			return match[1]
+		}
+		return label + ": " + match[1]
+	})
+	text = htmlBlockPattern.ReplaceAllString(text, "\n")
+	text = htmlTagPattern.ReplaceAllString(text, "")
+	text = html.UnescapeString(text)
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(horizontalSpacePattern.ReplaceAllString(line, " "))
+	}
+	text = strings.Join(lines, "\n")
+	text = blankLinesPattern.ReplaceAllString(text, "\n\n")
+
+	return strings.TrimSpace(text) + "\n"
+}
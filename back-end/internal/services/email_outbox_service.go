@@ -0,0 +1,287 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"mowesport/internal/config"
+	"mowesport/internal/database"
+	"mowesport/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// emailOutboxStaleClaim is how long a claimed message may stay in "sending" before
+// another worker pass assumes the previous worker died and picks it up again
+const emailOutboxStaleClaim = 10 * time.Minute
+
+// EmailOutboxService delivers queued email in the background and manages dead letters.
+// The worker should run on its own database connection, separate from request handling.
+type EmailOutboxService struct {
+	db           *database.Database
+	config       config.EmailOutboxConfig
+	emailService *EmailService
+	auditService *SecurityAuditService
+}
+
+// NewEmailOutboxService creates a new email outbox service
+func NewEmailOutboxService(db *database.Database, cfg *config.Config) *EmailOutboxService {
+	auditService := NewSecurityAuditService(db)
+
+	return &EmailOutboxService{
+		db:           db,
+		config:       cfg.EmailOutbox,
+		emailService: NewEmailService(cfg, auditService),
+		auditService: auditService,
+	}
+}
+
+// Run polls the outbox until the context is cancelled
+func (s *EmailOutboxService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Drain full batches before waiting for the next tick
+		for {
+			processed, err := s.ProcessBatch(ctx)
+			if err != nil {
+				slog.ErrorContext(ctx, "failed to process email outbox batch", "component", "email_outbox", "error", err)
+				break
+			}
+			if processed < s.config.BatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+type outboxDelivery struct {
+	messageID   uuid.UUID
+	email       EmailData
+	attempts    int
+	maxAttempts int
+}
+
+// ProcessBatch claims due messages, attempts delivery and records the outcome
+func (s *EmailOutboxService) ProcessBatch(ctx context.Context) (int, error) {
+	rows, err := s.db.GetConnection().Query(ctx, `
+		UPDATE email_outbox
+		SET status = $1, locked_at = NOW(), attempts = attempts + 1
+		WHERE message_id IN (
+			SELECT message_id FROM email_outbox
+			WHERE (status = $2 AND next_attempt_at <= NOW())
+			   OR (status = $1 AND locked_at < NOW() - $3 * INTERVAL '1 second')
+			ORDER BY next_attempt_at
+			LIMIT $4
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING message_id, to_email, subject, body, COALESCE(text_body, ''), is_html, COALESCE(reply_to, ''),
+		          attachments, attempts, max_attempts
+	`, models.EmailStatusSending, models.EmailStatusPending, int(emailOutboxStaleClaim.Seconds()), s.config.BatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim outbox messages: %w", err)
+	}
+
+	deliveries := []outboxDelivery{}
+	for rows.Next() {
+		var delivery outboxDelivery
+		var attachments []byte
+		if err := rows.Scan(
+			&delivery.messageID, &delivery.email.To, &delivery.email.Subject, &delivery.email.Body, &delivery.email.TextBody,
+			&delivery.email.IsHTML, &delivery.email.ReplyTo, &attachments, &delivery.attempts, &delivery.maxAttempts,
+		); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan outbox message: %w", err)
+		}
+		if err := json.Unmarshal(attachments, &delivery.email.Attachments); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to decode attachments of %s: %w", delivery.messageID, err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating over outbox messages: %w", err)
+	}
+
+	// Rows are fully read before sending so the connection is free for status updates
+	for _, delivery := range deliveries {
+		providerMessageID, sendErr := s.emailService.DeliverNow(ctx, delivery.email)
+		if err := s.recordOutcome(ctx, delivery, providerMessageID, sendErr); err != nil {
+			return len(deliveries), err
+		}
+	}
+
+	return len(deliveries), nil
+}
+
+// ListMessages returns outbox messages, failed (dead-lettered) ones by default
+func (s *EmailOutboxService) ListMessages(ctx context.Context, req *models.EmailOutboxListRequest) ([]models.EmailOutboxMessage, error) {
+	status := req.Status
+	if status == "" {
+		status = models.EmailStatusFailed
+	}
+	limit := req.Limit
+	if limit == 0 {
+		limit = 50
+	}
+
+	rows, err := s.db.GetConnection().Query(ctx, `
+		SELECT message_id, to_email, subject, status, attempts, max_attempts, next_attempt_at,
+		       last_error, provider_message_id, sent_at, failed_at, created_at, updated_at
+		FROM email_outbox
+		WHERE status = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list outbox messages: %w", err)
+	}
+	defer rows.Close()
+
+	messages := []models.EmailOutboxMessage{}
+	for rows.Next() {
+		var message models.EmailOutboxMessage
+		if err := rows.Scan(
+			&message.MessageID, &message.ToEmail, &message.Subject, &message.Status, &message.Attempts,
+			&message.MaxAttempts, &message.NextAttemptAt, &message.LastError, &message.ProviderMessageID,
+			&message.SentAt, &message.FailedAt, &message.CreatedAt, &message.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan outbox message: %w", err)
+		}
+		messages = append(messages, message)
+	}
+
+	return messages, rows.Err()
+}
+
+// RetryMessage puts a failed message back in the queue with a fresh attempt budget
+func (s *EmailOutboxService) RetryMessage(ctx context.Context, messageID, requestedBy uuid.UUID) error {
+	result, err := s.db.GetConnection().Exec(ctx, `
+		UPDATE email_outbox
+		SET status = $2, attempts = 0, next_attempt_at = NOW(), failed_at = NULL
+		WHERE message_id = $1 AND status = $3
+	`, messageID, models.EmailStatusPending, models.EmailStatusFailed)
+	if err != nil {
+		return fmt.Errorf("failed to requeue message: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		var exists bool
+		s.db.GetConnection().QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM email_outbox WHERE message_id = $1)", messageID).Scan(&exists)
+		if !exists {
+			return fmt.Errorf("message not found")
+		}
+		return fmt.Errorf("only failed messages can be retried")
+	}
+
+	s.auditService.LogSecurityEvent(ctx, SecurityEvent{
+		EventType:   "EMAIL_RETRY_REQUESTED",
+		Description: fmt.Sprintf("Failed email %s requeued by %s", messageID, requestedBy),
+		UserID:      &requestedBy,
+		Metadata: map[string]interface{}{
+			"message_id": messageID,
+		},
+		Timestamp: time.Now(),
+	})
+
+	return nil
+}
+
+// RetryAllFailed requeues every dead-lettered message
+func (s *EmailOutboxService) RetryAllFailed(ctx context.Context, requestedBy uuid.UUID) (int64, error) {
+	result, err := s.db.GetConnection().Exec(ctx, `
+		UPDATE email_outbox
+		SET status = $1, attempts = 0, next_attempt_at = NOW(), failed_at = NULL
+		WHERE status = $2
+	`, models.EmailStatusPending, models.EmailStatusFailed)
+	if err != nil {
+		return 0, fmt.Errorf("failed to requeue messages: %w", err)
+	}
+	count := result.RowsAffected()
+
+	s.auditService.LogSecurityEvent(ctx, SecurityEvent{
+		EventType:   "EMAIL_RETRY_REQUESTED",
+		Description: fmt.Sprintf("%d failed emails requeued by %s", count, requestedBy),
+		UserID:      &requestedBy,
+		Metadata: map[string]interface{}{
+			"count": count,
+		},
+		Timestamp: time.Now(),
+	})
+
+	return count, nil
+}
+
+// Helper methods
+
+// recordOutcome marks a delivery sent, schedules the next attempt, or dead-letters it
+func (s *EmailOutboxService) recordOutcome(ctx context.Context, delivery outboxDelivery, providerMessageID string, sendErr error) error {
+	if sendErr == nil {
+		_, err := s.db.GetConnection().Exec(ctx, `
+			UPDATE email_outbox
+			SET status = $2, provider_message_id = $3, sent_at = NOW(), locked_at = NULL, last_error = NULL
+			WHERE message_id = $1
+		`, delivery.messageID, models.EmailStatusSent, providerMessageID)
+		if err != nil {
+			return fmt.Errorf("failed to mark message %s sent: %w", delivery.messageID, err)
+		}
+		return nil
+	}
+
+	if delivery.attempts >= delivery.maxAttempts {
+		_, err := s.db.GetConnection().Exec(ctx, `
+			UPDATE email_outbox
+			SET status = $2, failed_at = NOW(), locked_at = NULL, last_error = $3
+			WHERE message_id = $1
+		`, delivery.messageID, models.EmailStatusFailed, sendErr.Error())
+		if err != nil {
+			return fmt.Errorf("failed to dead-letter message %s: %w", delivery.messageID, err)
+		}
+
+		s.auditService.LogSecurityEvent(ctx, SecurityEvent{
+			EventType:   "EMAIL_SEND_FAILED",
+			Description: fmt.Sprintf("Email dead-lettered after %d attempts", delivery.attempts),
+			IPAddress:   "127.0.0.1",
+			UserAgent:   "System",
+			Metadata: map[string]interface{}{
+				"message_id": delivery.messageID,
+				"recipient":  delivery.email.To,
+				"subject":    delivery.email.Subject,
+				"error":      sendErr.Error(),
+			},
+			Timestamp: time.Now(),
+		})
+		return nil
+	}
+
+	_, err := s.db.GetConnection().Exec(ctx, `
+		UPDATE email_outbox
+		SET status = $2, next_attempt_at = $3, locked_at = NULL, last_error = $4
+		WHERE message_id = $1
+	`, delivery.messageID, models.EmailStatusPending, time.Now().Add(s.backoff(delivery.attempts)), sendErr.Error())
+	if err != nil {
+		return fmt.Errorf("failed to reschedule message %s: %w", delivery.messageID, err)
+	}
+	return nil
+}
+
+// backoff doubles the wait after every failed attempt, capped at MaxBackoff
+func (s *EmailOutboxService) backoff(attempts int) time.Duration {
+	wait := s.config.BaseBackoff
+	for i := 1; i < attempts && wait < s.config.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > s.config.MaxBackoff {
+		wait = s.config.MaxBackoff
+	}
+	return wait
+}
@@ -1,26 +1,37 @@
 package services
 
 import (
-	"bytes"
 	"context"
+	"crypto/tls"
+	"encoding/json"
 	"fmt"
-	"html/template"
+	"log/slog"
 	"mowesport/internal/config"
+	"mowesport/internal/models"
+	"net"
+	"net/mail"
 	"net/smtp"
+	"net/url"
 	"strings"
 	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
 type EmailService struct {
 	config       *config.Config
 	auditService *SecurityAuditService
+	templates    *EmailTemplates
 }
 
 type EmailData struct {
 	To          string
 	Subject     string
 	Body        string
+	TextBody    string // plain-text alternative for HTML bodies; derived from Body when empty
 	IsHTML      bool
+	ReplyTo     string // overrides REPLY_TO_EMAIL for this message
 	Attachments []EmailAttachment
 }
 
@@ -30,523 +41,372 @@ type EmailAttachment struct {
 	MimeType string
 }
 
-type WelcomeEmailData struct {
-	FirstName         string
-	LastName          string
-	Email             string
-	TemporaryPassword string
-	LoginURL          string
-	SupportEmail      string
-	CompanyName       string
-	CityName          string
-	SportName         string
-	ExpirationHours   int
+type InvitationEmailData struct {
+	FirstName       string
+	Email           string
+	RoleName        string
+	InviterName     string
+	CityName        string
+	SportName       string
+	AcceptURL       string
+	ExpirationHours int
+	Locale          string
+}
+
+// GuardianMatchEmailData describes a match of a guardian's minor player
+type GuardianMatchEmailData struct {
+	Email          string
+	FirstName      string
+	PlayerID       string
+	PlayerName     string
+	HomeTeam       string
+	AwayTeam       string
+	TournamentName string
+	MatchDate      string
+	MatchTime      string
+	Venue          string
+	Status         string
+	Locale         string
 }
 
 func NewEmailService(cfg *config.Config, auditService *SecurityAuditService) *EmailService {
 	return &EmailService{
 		config:       cfg,
 		auditService: auditService,
+		templates:    NewEmailTemplates(cfg.EmailTemplatesDir),
 	}
 }
 
-// SendWelcomeEmail sends a welcome email to newly registered administrators
-func (s *EmailService) SendWelcomeEmail(ctx context.Context, data WelcomeEmailData) error {
-	// Generate HTML content from template
-	htmlBody, err := s.generateWelcomeEmailHTML(data)
-	if err != nil {
-		return fmt.Errorf("failed to generate email HTML: %w", err)
-	}
+// SendPasswordResetEmail sends a password reset email
+func (s *EmailService) SendPasswordResetEmail(ctx context.Context, q dbExecutor, email, resetToken, firstName, locale string, expiresIn time.Duration) error {
+	return s.enqueueTemplate(ctx, q, email, EmailTemplatePasswordReset, locale, map[string]interface{}{
+		"FirstName":         firstName,
+		"ResetURL":          fmt.Sprintf("%s/reset-password?token=%s", s.config.FrontendURL, url.QueryEscape(resetToken)),
+		"ExpirationMinutes": int(expiresIn.Minutes()),
+	})
+}
 
-	// Generate plain text version (for future use)
-	_ = s.generateWelcomeEmailText(data)
+// SendVerificationEmail sends the signup email verification link
+func (s *EmailService) SendVerificationEmail(ctx context.Context, q dbExecutor, email, firstName, verifyURL, locale string, expirationHours int) error {
+	return s.enqueueTemplate(ctx, q, email, EmailTemplateVerification, locale, map[string]interface{}{
+		"FirstName":       firstName,
+		"VerifyURL":       verifyURL,
+		"ExpirationHours": expirationHours,
+	})
+}
 
-	// Create email data
-	emailData := EmailData{
-		To:      data.Email,
-		Subject: fmt.Sprintf("Bienvenido a %s - Credenciales de Administrador", data.CompanyName),
-		Body:    htmlBody,
-		IsHTML:  true,
-	}
+// SendEmailChangeConfirmation asks one of the addresses involved in an email change to confirm it.
+// The current address is told which new address was requested so an unexpected change can be stopped.
+func (s *EmailService) SendEmailChangeConfirmation(ctx context.Context, q dbExecutor, to, firstName, newEmail, confirmURL, locale string, isCurrentAddress bool) error {
+	return s.enqueueTemplate(ctx, q, to, EmailTemplateEmailChange, locale, map[string]interface{}{
+		"FirstName":        firstName,
+		"NewEmail":         newEmail,
+		"ConfirmURL":       confirmURL,
+		"IsCurrentAddress": isCurrentAddress,
+	})
+}
 
-	// Send email with retry logic
-	err = s.sendEmailWithRetry(ctx, emailData, 3)
-	if err != nil {
-		// Log failed email attempt
-		s.auditService.LogSecurityEvent(ctx, SecurityEvent{
-			EventType:   "EMAIL_SEND_FAILED",
-			Description: "Failed to send welcome email",
-			IPAddress:   "127.0.0.1",
-			UserAgent:   "System",
-			Metadata: map[string]interface{}{
-				"recipient": data.Email,
-				"error":     err.Error(),
-			},
-		})
-		return fmt.Errorf("failed to send welcome email: %w", err)
-	}
+// SendInvitationEmail sends an account invitation link; the invitee chooses their own password
+func (s *EmailService) SendInvitationEmail(ctx context.Context, q dbExecutor, data InvitationEmailData) error {
+	return s.enqueueTemplate(ctx, q, data.Email, EmailTemplateInvitation, data.Locale, map[string]interface{}{
+		"FirstName":       data.FirstName,
+		"InviterName":     data.InviterName,
+		"RoleName":        data.RoleName,
+		"CityName":        data.CityName,
+		"SportName":       data.SportName,
+		"AcceptURL":       data.AcceptURL,
+		"ExpirationHours": data.ExpirationHours,
+	})
+}
 
-	// Log successful email
-	s.auditService.LogSecurityEvent(ctx, SecurityEvent{
-		EventType:   "WELCOME_EMAIL_SENT",
-		Description: "Welcome email sent successfully",
-		IPAddress:   "127.0.0.1",
-		UserAgent:   "System",
-		Metadata: map[string]interface{}{
-			"recipient":  data.Email,
-			"admin_name": fmt.Sprintf("%s %s", data.FirstName, data.LastName),
-			"city":       data.CityName,
-			"sport":      data.SportName,
-		},
+// SendLoginChallengeEmail queues the one-time code required to finish a login from a suspicious network
+func (s *EmailService) SendLoginChallengeEmail(ctx context.Context, q dbExecutor, email, firstName, code, locale string, expiresIn time.Duration) error {
+	return s.enqueueTemplate(ctx, q, email, EmailTemplateLoginChallenge, locale, map[string]interface{}{
+		"FirstName":         firstName,
+		"Code":              code,
+		"ExpirationMinutes": int(expiresIn.Minutes()),
 	})
+}
 
-	return nil
+// SendReactivationEmail tells a user their account was reactivated by a scheduled status change
+func (s *EmailService) SendReactivationEmail(ctx context.Context, q dbExecutor, email, firstName, reason, locale string) error {
+	return s.enqueueTemplate(ctx, q, email, EmailTemplateReactivation, locale, map[string]interface{}{
+		"FirstName": firstName,
+		"Reason":    reason,
+		"LoginURL":  fmt.Sprintf("%s/login", s.config.FrontendURL),
+	})
 }
 
-// SendPasswordResetEmail sends a password reset email
-func (s *EmailService) SendPasswordResetEmail(ctx context.Context, email, resetToken, firstName string) error {
-	resetURL := fmt.Sprintf("%s/reset-password?token=%s", s.config.FrontendURL, resetToken)
-
-	htmlBody := fmt.Sprintf(`
-		<html>
-		<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
-			<div style="max-width: 600px; margin: 0 auto; padding: 20px;">
-				<h2 style="color: #2c5aa0;">Recuperación de Contraseña</h2>
-				<p>Hola %s,</p>
-				<p>Has solicitado restablecer tu contraseña. Haz clic en el siguiente enlace para crear una nueva contraseña:</p>
-				<p style="text-align: center; margin: 30px 0;">
-					<a href="%s" style="background-color: #2c5aa0; color: white; padding: 12px 24px; text-decoration: none; border-radius: 5px; display: inline-block;">
-						Restablecer Contraseña
-					</a>
-				</p>
-				<p><strong>Este enlace expirará en 1 hora.</strong></p>
-				<p>Si no solicitaste este cambio, puedes ignorar este email.</p>
-				<hr style="margin: 30px 0; border: none; border-top: 1px solid #eee;">
-				<p style="font-size: 12px; color: #666;">
-					Este es un email automático, por favor no respondas a este mensaje.
-				</p>
-			</div>
-		</body>
-		</html>
-	`, firstName, resetURL)
-
-	emailData := EmailData{
-		To:      email,
-		Subject: "Recuperación de Contraseña - Mowe Sport",
-		Body:    htmlBody,
-		IsHTML:  true,
+// SendDataDeletionScheduledEmail queues the notice that an approved deletion request will be applied
+func (s *EmailService) SendDataDeletionScheduledEmail(ctx context.Context, q dbExecutor, email, firstName string, scheduledFor time.Time, locale string) error {
+	return s.enqueueTemplate(ctx, q, email, EmailTemplateDataDeletion, locale, map[string]interface{}{
+		"FirstName":    firstName,
+		"ScheduledFor": scheduledFor.UTC().Format("2006-01-02"),
+		"ProfileURL":   fmt.Sprintf("%s/profile/data", s.config.FrontendURL),
+	})
+}
+
+// SendGuardianConsentRequestEmail tells a guardian they were linked to a minor and asks for consent
+func (s *EmailService) SendGuardianConsentRequestEmail(ctx context.Context, q dbExecutor, email, firstName, playerID, playerName, linkedByName, documentVersion, locale string) error {
+	return s.enqueueTemplate(ctx, q, email, EmailTemplateGuardianConsent, locale, map[string]interface{}{
+		"FirstName":       firstName,
+		"PlayerName":      playerName,
+		"LinkedByName":    linkedByName,
+		"DocumentVersion": documentVersion,
+		"ConsentURL":      fmt.Sprintf("%s/guardian/players/%s", s.config.FrontendURL, url.PathEscape(playerID)),
+	})
+}
+
+// SendGuardianMatchEmail queues a match reminder, or with update set a schedule change, to a guardian
+func (s *EmailService) SendGuardianMatchEmail(ctx context.Context, q dbExecutor, data GuardianMatchEmailData, update bool) error {
+	name := EmailTemplateGuardianMatch
+	if update {
+		name = EmailTemplateGuardianUpdate
 	}
+	return s.enqueueTemplate(ctx, q, data.Email, name, data.Locale, map[string]interface{}{
+		"FirstName":      data.FirstName,
+		"PlayerName":     data.PlayerName,
+		"HomeTeam":       data.HomeTeam,
+		"AwayTeam":       data.AwayTeam,
+		"TournamentName": data.TournamentName,
+		"MatchDate":      data.MatchDate,
+		"MatchTime":      data.MatchTime,
+		"Venue":          data.Venue,
+		"Status":         data.Status,
+		"PlayerURL":      fmt.Sprintf("%s/guardian/players/%s", s.config.FrontendURL, url.PathEscape(data.PlayerID)),
+	})
+}
 
-	return s.sendEmailWithRetry(ctx, emailData, 3)
+// SendSecurityAlertEmail queues the notification of a security alert
+func (s *EmailService) SendSecurityAlertEmail(ctx context.Context, q dbExecutor, to string, alert *models.SecurityAlert, cooldown time.Duration) error {
+	return s.enqueueTemplate(ctx, q, to, EmailTemplateSecurityAlert, DefaultEmailLocale, map[string]interface{}{
+		"Title":           alert.Title,
+		"Description":     alert.Description,
+		"Severity":        alert.Severity,
+		"EventCount":      alert.EventCount,
+		"FirstEventAt":    alert.FirstEventAt.UTC().Format("2006-01-02 15:04:05 MST"),
+		"LastEventAt":     alert.LastEventAt.UTC().Format("2006-01-02 15:04:05 MST"),
+		"IPAddresses":     strings.Join(alert.IPAddresses, ", "),
+		"CooldownMinutes": int(cooldown.Minutes()),
+	})
 }
 
-// generateWelcomeEmailHTML generates the HTML template for welcome emails
-func (s *EmailService) generateWelcomeEmailHTML(data WelcomeEmailData) (string, error) {
-	tmpl := `
-<!DOCTYPE html>
-<html lang="es">
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>Bienvenido a {{.CompanyName}}</title>
-    <style>
-        body {
-            font-family: 'Segoe UI', Tahoma, Geneva, Verdana, sans-serif;
-            line-height: 1.6;
-            color: #333;
-            margin: 0;
-            padding: 0;
-            background-color: #f4f4f4;
-        }
-        .container {
-            max-width: 600px;
-            margin: 0 auto;
-            background-color: #ffffff;
-            border-radius: 10px;
-            overflow: hidden;
-            box-shadow: 0 0 20px rgba(0,0,0,0.1);
-        }
-        .header {
-            background: linear-gradient(135deg, #667eea 0%, #764ba2 100%);
-            color: white;
-            padding: 30px;
-            text-align: center;
-        }
-        .header h1 {
-            margin: 0;
-            font-size: 28px;
-            font-weight: 300;
-        }
-        .content {
-            padding: 40px 30px;
-        }
-        .welcome-box {
-            background-color: #f8f9ff;
-            border-left: 4px solid #667eea;
-            padding: 20px;
-            margin: 20px 0;
-            border-radius: 5px;
-        }
-        .credentials-box {
-            background-color: #fff3cd;
-            border: 1px solid #ffeaa7;
-            padding: 20px;
-            margin: 20px 0;
-            border-radius: 5px;
-        }
-        .credentials-box h3 {
-            color: #856404;
-            margin-top: 0;
-        }
-        .credential-item {
-            display: flex;
-            justify-content: space-between;
-            margin: 10px 0;
-            padding: 8px 0;
-            border-bottom: 1px solid #f0f0f0;
-        }
-        .credential-label {
-            font-weight: bold;
-            color: #555;
-        }
-        .credential-value {
-            font-family: 'Courier New', monospace;
-            background-color: #f8f9fa;
-            padding: 4px 8px;
-            border-radius: 3px;
-            font-size: 14px;
-        }
-        .cta-button {
-            display: inline-block;
-            background: linear-gradient(135deg, #667eea 0%, #764ba2 100%);
-            color: white;
-            padding: 15px 30px;
-            text-decoration: none;
-            border-radius: 25px;
-            font-weight: bold;
-            text-align: center;
-            margin: 20px 0;
-            transition: transform 0.2s;
-        }
-        .cta-button:hover {
-            transform: translateY(-2px);
-        }
-        .info-section {
-            background-color: #e8f4fd;
-            border-left: 4px solid #3498db;
-            padding: 20px;
-            margin: 20px 0;
-            border-radius: 5px;
-        }
-        .warning-section {
-            background-color: #ffeaa7;
-            border-left: 4px solid #f39c12;
-            padding: 20px;
-            margin: 20px 0;
-            border-radius: 5px;
-        }
-        .footer {
-            background-color: #2c3e50;
-            color: #ecf0f1;
-            padding: 30px;
-            text-align: center;
-            font-size: 14px;
-        }
-        .footer a {
-            color: #3498db;
-            text-decoration: none;
-        }
-        .steps-list {
-            counter-reset: step-counter;
-            list-style: none;
-            padding: 0;
-        }
-        .steps-list li {
-            counter-increment: step-counter;
-            margin: 15px 0;
-            padding: 15px;
-            background-color: #f8f9fa;
-            border-radius: 5px;
-            position: relative;
-            padding-left: 60px;
-        }
-        .steps-list li::before {
-            content: counter(step-counter);
-            position: absolute;
-            left: 20px;
-            top: 15px;
-            background: linear-gradient(135deg, #667eea 0%, #764ba2 100%);
-            color: white;
-            width: 25px;
-            height: 25px;
-            border-radius: 50%;
-            display: flex;
-            align-items: center;
-            justify-content: center;
-            font-weight: bold;
-            font-size: 12px;
-        }
-    </style>
-</head>
-<body>
-    <div class="container">
-        <div class="header">
-            <h1>¡Bienvenido a {{.CompanyName}}!</h1>
-            <p>Tu cuenta de administrador ha sido creada exitosamente</p>
-        </div>
-        
-        <div class="content">
-            <div class="welcome-box">
-                <h2>Hola {{.FirstName}} {{.LastName}},</h2>
-                <p>Te damos la bienvenida como <strong>Administrador de {{.CityName}}</strong> para el deporte de <strong>{{.SportName}}</strong>.</p>
-                <p>Tu cuenta ha sido configurada y ya puedes acceder a la plataforma para gestionar torneos, equipos y jugadores en tu jurisdicción.</p>
-            </div>
-
-            <div class="credentials-box">
-                <h3>🔐 Credenciales de Acceso</h3>
-                <div class="credential-item">
-                    <span class="credential-label">Email:</span>
-                    <span class="credential-value">{{.Email}}</span>
-                </div>
-                <div class="credential-item">
-                    <span class="credential-label">Contraseña Temporal:</span>
-                    <span class="credential-value">{{.TemporaryPassword}}</span>
-                </div>
-            </div>
-
-            <div style="text-align: center;">
-                <a href="{{.LoginURL}}" class="cta-button">Acceder a la Plataforma</a>
-            </div>
-
-            <div class="warning-section">
-                <h3>⚠️ Importante - Seguridad</h3>
-                <ul>
-                    <li><strong>Cambia tu contraseña inmediatamente</strong> después del primer acceso</li>
-                    <li>Esta contraseña temporal expirará en <strong>{{.ExpirationHours}} horas</strong></li>
-                    <li>No compartas estas credenciales con nadie</li>
-                    <li>Usa una contraseña segura con al menos 8 caracteres, incluyendo mayúsculas, minúsculas, números y símbolos</li>
-                </ul>
-            </div>
-
-            <div class="info-section">
-                <h3>📋 Próximos Pasos</h3>
-                <ol class="steps-list">
-                    <li><strong>Inicia sesión</strong> con las credenciales proporcionadas</li>
-                    <li><strong>Cambia tu contraseña</strong> por una segura y personal</li>
-                    <li><strong>Completa tu perfil</strong> con información adicional</li>
-                    <li><strong>Explora la plataforma</strong> y familiarízate con las funciones disponibles</li>
-                    <li><strong>Comienza a gestionar</strong> torneos y equipos en tu ciudad</li>
-                </ol>
-            </div>
-
-            <div class="info-section">
-                <h3>🎯 Tus Responsabilidades</h3>
-                <p>Como administrador de <strong>{{.CityName}}</strong> para <strong>{{.SportName}}</strong>, podrás:</p>
-                <ul>
-                    <li>✅ Aprobar y gestionar torneos en tu ciudad</li>
-                    <li>✅ Supervisar el registro de equipos y jugadores</li>
-                    <li>✅ Gestionar árbitros y personal de apoyo</li>
-                    <li>✅ Generar reportes y estadísticas</li>
-                    <li>✅ Mantener la integridad de los datos deportivos</li>
-                </ul>
-            </div>
-
-            <div class="info-section">
-                <h3>📞 Soporte y Ayuda</h3>
-                <p>Si tienes preguntas o necesitas ayuda, no dudes en contactarnos:</p>
-                <ul>
-                    <li><strong>Email de Soporte:</strong> <a href="mailto:{{.SupportEmail}}">{{.SupportEmail}}</a></li>
-                    <li><strong>Documentación:</strong> Disponible en la plataforma una vez que inicies sesión</li>
-                    <li><strong>Video Tutoriales:</strong> Accesibles desde tu panel de administración</li>
-                </ul>
-            </div>
-        </div>
-
-        <div class="footer">
-            <p><strong>{{.CompanyName}}</strong></p>
-            <p>Transformando el deporte local con tecnología</p>
-            <p style="font-size: 12px; margin-top: 20px;">
-                Este es un email automático. Por favor no respondas a este mensaje.<br>
-                Si tienes problemas, contacta a <a href="mailto:{{.SupportEmail}}">{{.SupportEmail}}</a>
-            </p>
-        </div>
-    </div>
-</body>
-</html>
-	`
-
-	t, err := template.New("welcome").Parse(tmpl)
-	if err != nil {
-		return "", err
+// RenderTemplate renders an email template with the fields every template can use
+func (s *EmailService) RenderTemplate(name, locale string, data map[string]interface{}) (*RenderedEmail, error) {
+	values := map[string]interface{}{
+		"SupportEmail": s.config.SupportEmail,
+		"FrontendURL":  s.config.FrontendURL,
 	}
+	for key, value := range data {
+		values[key] = value
+	}
+
+	return s.templates.Render(name, locale, values)
+}
+
+// TemplateNames returns the available email templates
+func (s *EmailService) TemplateNames() ([]string, error) {
+	return s.templates.Names()
+}
 
-	var buf bytes.Buffer
-	err = t.Execute(&buf, data)
+// enqueueTemplate renders a template in the recipient's locale and queues it
+func (s *EmailService) enqueueTemplate(ctx context.Context, q dbExecutor, to, name, locale string, data map[string]interface{}) error {
+	rendered, err := s.RenderTemplate(name, locale, data)
 	if err != nil {
-		return "", err
+		return err
 	}
 
-	return buf.String(), nil
+	return s.enqueue(ctx, q, EmailData{
+		To:       to,
+		Subject:  rendered.Subject,
+		Body:     rendered.HTML,
+		TextBody: rendered.Text,
+		IsHTML:   true,
+	})
 }
 
-// generateWelcomeEmailText generates plain text version of welcome email
-func (s *EmailService) generateWelcomeEmailText(data WelcomeEmailData) string {
-	return fmt.Sprintf(`
-¡Bienvenido a %s!
-
-Hola %s %s,
-
-Te damos la bienvenida como Administrador de %s para el deporte de %s.
-
-CREDENCIALES DE ACCESO:
-Email: %s
-Contraseña Temporal: %s
-
-IMPORTANTE - SEGURIDAD:
-- Cambia tu contraseña inmediatamente después del primer acceso
-- Esta contraseña temporal expirará en %d horas
-- No compartas estas credenciales con nadie
-
-ACCESO A LA PLATAFORMA:
-%s
-
-PRÓXIMOS PASOS:
-1. Inicia sesión con las credenciales proporcionadas
-2. Cambia tu contraseña por una segura y personal
-3. Completa tu perfil con información adicional
-4. Explora la plataforma y familiarízate con las funciones
-5. Comienza a gestionar torneos y equipos en tu ciudad
-
-SOPORTE:
-Si necesitas ayuda, contacta a: %s
-
-Saludos,
-Equipo de %s
-
----
-Este es un email automático. Por favor no respondas a este mensaje.
-	`,
-		data.CompanyName,
-		data.FirstName, data.LastName,
-		data.CityName, data.SportName,
-		data.Email, data.TemporaryPassword,
-		data.ExpirationHours,
-		data.LoginURL,
-		data.SupportEmail,
-		data.CompanyName,
-	)
+// dbExecutor is satisfied by both *pgx.Conn and pgx.Tx, so outgoing email can be
+// queued inside the transaction of the change that triggers it
+type dbExecutor interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
 }
 
-// sendEmailWithRetry sends email with retry logic
-func (s *EmailService) sendEmailWithRetry(ctx context.Context, emailData EmailData, maxRetries int) error {
-	var lastErr error
-
-	for attempt := 1; attempt <= maxRetries; attempt++ {
-		err := s.sendEmail(ctx, emailData)
-		if err == nil {
-			return nil
-		}
+// enqueue writes the email to the outbox on the caller's executor, so it is only
+// delivered if the surrounding transaction commits. EmailOutboxService delivers it.
+func (s *EmailService) enqueue(ctx context.Context, q dbExecutor, emailData EmailData) error {
+	attachments, err := json.Marshal(emailData.Attachments)
+	if err != nil {
+		return fmt.Errorf("failed to encode attachments: %w", err)
+	}
+	if emailData.Attachments == nil {
+		attachments = []byte("[]")
+	}
 
-		lastErr = err
-		if attempt < maxRetries {
-			// Wait before retry (exponential backoff)
-			waitTime := time.Duration(attempt*attempt) * time.Second
-			select {
-			case <-ctx.Done():
-				return ctx.Err()
-			case <-time.After(waitTime):
-				continue
-			}
-		}
+	var messageID uuid.UUID
+	err = q.QueryRow(ctx, `
+		INSERT INTO email_outbox (to_email, subject, body, text_body, is_html, reply_to, attachments, max_attempts)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, NULLIF($6, ''), $7, $8)
+		RETURNING message_id
+	`, emailData.To, emailData.Subject, emailData.Body, emailData.TextBody, emailData.IsHTML, emailData.ReplyTo,
+		attachments, s.config.EmailOutbox.MaxAttempts).Scan(&messageID)
+	if err != nil {
+		return fmt.Errorf("failed to queue email: %w", err)
 	}
 
-	return fmt.Errorf("failed to send email after %d attempts: %w", maxRetries, lastErr)
+	return nil
 }
 
-// sendEmail sends the actual email using SMTP
-func (s *EmailService) sendEmail(ctx context.Context, emailData EmailData) error {
-	// For development, we'll use a simple SMTP configuration
-	// In production, this should use proper SMTP credentials from config
+// DeliverNow sends the email immediately, bypassing the outbox, and returns the
+// provider message ID. Regular code paths should queue email instead.
+func (s *EmailService) DeliverNow(ctx context.Context, emailData EmailData) (string, error) {
+	messageID := s.newMessageID()
 
-	// Mock email sending for development
+	// Development email goes to the local mailbox (/api/dev/mailbox)
 	if s.config.Environment == "development" {
-		return s.mockEmailSend(emailData)
+		return messageID, s.captureDevEmail(emailData, messageID)
 	}
 
 	// Production SMTP implementation
-	return s.sendSMTPEmail(emailData)
+	return messageID, s.sendSMTPEmail(ctx, emailData, messageID)
+}
+
+// newMessageID generates a globally unique Message-ID for the sender's domain
+func (s *EmailService) newMessageID() string {
+	domain := "mowesport.com"
+	if at := strings.LastIndex(s.config.FromEmail, "@"); at >= 0 {
+		domain = s.config.FromEmail[at+1:]
+	}
+	return fmt.Sprintf("<%s@%s>", uuid.New().String(), domain)
 }
 
-// mockEmailSend simulates email sending for development
-func (s *EmailService) mockEmailSend(emailData EmailData) error {
-	fmt.Printf("\n=== MOCK EMAIL SENT ===\n")
-	fmt.Printf("To: %s\n", emailData.To)
-	fmt.Printf("Subject: %s\n", emailData.Subject)
-	fmt.Printf("Is HTML: %t\n", emailData.IsHTML)
-	fmt.Printf("Body Length: %d characters\n", len(emailData.Body))
-	fmt.Printf("========================\n\n")
-
-	// In development, we can also save the email to a file for inspection
-	if s.config.Debug {
-		filename := fmt.Sprintf("email_%s_%d.html",
-			strings.ReplaceAll(emailData.To, "@", "_at_"),
-			time.Now().Unix())
-		// Note: In a real implementation, you'd save this to a temp directory
-		fmt.Printf("Email content would be saved to: %s\n", filename)
+// captureDevEmail stores the fully built message in the development mailbox instead of sending it
+func (s *EmailService) captureDevEmail(emailData EmailData, messageID string) error {
+	message, err := s.buildEmailMessage(emailData, messageID)
+	if err != nil {
+		return err
+	}
+
+	id, err := NewDevMailbox(s.config.DevMailboxDir).Store(messageID, message)
+	if err != nil {
+		return err
 	}
 
+	slog.Info("development email captured", "component", "dev_mailbox", "subject", emailData.Subject, "to", emailData.To,
+		"url", "/api/dev/mailbox/"+id+"/html")
 	return nil
 }
 
-// sendSMTPEmail sends email using SMTP (production implementation)
-func (s *EmailService) sendSMTPEmail(emailData EmailData) error {
-	// This is a basic SMTP implementation
-	// In production, you should use a proper email service like SendGrid, AWS SES, etc.
-
+// sendSMTPEmail sends email over SMTP using the configured TLS mode
+func (s *EmailService) sendSMTPEmail(ctx context.Context, emailData EmailData, messageID string) error {
 	smtpHost := s.config.SMTPHost
-	smtpPort := s.config.SMTPPort
-	smtpUser := s.config.SMTPUser
-	smtpPass := s.config.SMTPPassword
-
 	if smtpHost == "" {
 		return fmt.Errorf("SMTP configuration not provided")
 	}
 
-	// Create message
-	message := s.buildEmailMessage(emailData)
+	message, err := s.buildEmailMessage(emailData, messageID)
+	if err != nil {
+		return err
+	}
+
+	client, err := s.dialSMTP(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if s.config.SMTPUser != "" {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return fmt.Errorf("SMTP server does not support authentication")
+		}
+		if err := client.Auth(smtp.PlainAuth("", s.config.SMTPUser, s.config.SMTPPassword, smtpHost)); err != nil {
+			return fmt.Errorf("SMTP authentication failed: %w", err)
+		}
+	}
 
-	// SMTP authentication
-	auth := smtp.PlainAuth("", smtpUser, smtpPass, smtpHost)
+	// The envelope sender matches the From header so bounces reach the sending domain
+	if err := client.Mail(s.config.FromEmail); err != nil {
+		return fmt.Errorf("SMTP MAIL FROM rejected: %w", err)
+	}
+	if err := client.Rcpt(emailData.To); err != nil {
+		return fmt.Errorf("SMTP RCPT TO rejected: %w", err)
+	}
 
-	// Send email
-	addr := fmt.Sprintf("%s:%s", smtpHost, smtpPort)
-	err := smtp.SendMail(addr, auth, smtpUser, []string{emailData.To}, []byte(message))
+	writer, err := client.Data()
 	if err != nil {
+		return fmt.Errorf("SMTP DATA rejected: %w", err)
+	}
+	if _, err := writer.Write(message); err != nil {
+		writer.Close()
+		return fmt.Errorf("failed to write SMTP message: %w", err)
+	}
+	if err := writer.Close(); err != nil {
 		return fmt.Errorf("failed to send SMTP email: %w", err)
 	}
 
-	return nil
+	return client.Quit()
 }
 
-// buildEmailMessage builds the email message with proper headers
-func (s *EmailService) buildEmailMessage(emailData EmailData) string {
-	var message strings.Builder
+// dialSMTP connects to the SMTP server and negotiates TLS according to SMTP_TLS_MODE
+func (s *EmailService) dialSMTP(ctx context.Context) (*smtp.Client, error) {
+	smtpHost := s.config.SMTPHost
+	addr := net.JoinHostPort(smtpHost, s.config.SMTPPort)
+	tlsConfig := &tls.Config{ServerName: smtpHost, MinVersion: tls.VersionTLS12}
+	dialer := &net.Dialer{Timeout: s.config.SMTPTimeout}
+
+	var conn net.Conn
+	var err error
+	switch s.config.SMTPTLSMode {
+	case config.SMTPTLSModeImplicit:
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	case config.SMTPTLSModeStartTLS, config.SMTPTLSModeNone:
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	default:
+		return nil, fmt.Errorf("unsupported SMTP TLS mode %q", s.config.SMTPTLSMode)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+
+	// Bound the whole conversation so a stalled server cannot block the outbox worker
+	if s.config.SMTPTimeout > 0 {
+		conn.SetDeadline(time.Now().Add(s.config.SMTPTimeout))
+	}
 
-	// Headers
-	message.WriteString(fmt.Sprintf("To: %s\r\n", emailData.To))
-	message.WriteString(fmt.Sprintf("Subject: %s\r\n", emailData.Subject))
+	client, err := smtp.NewClient(conn, smtpHost)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to start SMTP session: %w", err)
+	}
 
-	if emailData.IsHTML {
-		message.WriteString("MIME-Version: 1.0\r\n")
-		message.WriteString("Content-Type: text/html; charset=UTF-8\r\n")
-	} else {
-		message.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	if s.config.SMTPTLSMode == config.SMTPTLSModeStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			client.Close()
+			return nil, fmt.Errorf("SMTP server does not support STARTTLS")
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("SMTP STARTTLS failed: %w", err)
+		}
 	}
 
-	message.WriteString("\r\n")
-	message.WriteString(emailData.Body)
+	return client, nil
+}
+
+// buildEmailMessage builds the MIME message with sender, date and reply-to headers
+func (s *EmailService) buildEmailMessage(emailData EmailData, messageID string) ([]byte, error) {
+	replyTo := emailData.ReplyTo
+	if replyTo == "" {
+		replyTo = s.config.ReplyToEmail
+	}
 
-	return message.String()
+	return buildMIMEMessage(mimeHeaders{
+		From:      mail.Address{Name: s.config.FromName, Address: s.config.FromEmail},
+		To:        emailData.To,
+		ReplyTo:   replyTo,
+		Subject:   emailData.Subject,
+		MessageID: messageID,
+		Date:      time.Now(),
+	}, emailData)
 }
 
 // TestEmailConfiguration tests the email configuration
@@ -558,5 +418,6 @@ func (s *EmailService) TestEmailConfiguration(ctx context.Context) error {
 		IsHTML:  false,
 	}
 
-	return s.sendEmail(ctx, testEmail)
+	_, err := s.DeliverNow(ctx, testEmail)
+	return err
 }
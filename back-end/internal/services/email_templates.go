@@ -0,0 +1,356 @@
+package services
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"html"
+	"html/template"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Email template names; each one has a <locale>/<name>.html file under templates/email
+const (
+	EmailTemplatePasswordReset   = "password_reset"
+	EmailTemplateVerification    = "verification"
+	EmailTemplateEmailChange     = "email_change"
+	EmailTemplateInvitation      = "invitation"
+	EmailTemplateSecurityAlert   = "security_alert"
+	EmailTemplateLoginChallenge  = "login_challenge"
+	EmailTemplateReactivation    = "account_reactivated"
+	EmailTemplateDataDeletion    = "data_deletion_scheduled"
+	EmailTemplateGuardianConsent = "guardian_consent_request"
+	EmailTemplateGuardianMatch   = "guardian_match_reminder"
+	EmailTemplateGuardianUpdate  = "guardian_match_update"
+)
+
+// Supported email locales. DefaultEmailLocale is used when a user has no
+// preference or their preference has no template variant.
+const (
+	DefaultEmailLocale = "es-CO"
+	EmailLocaleEnglish = "en"
+)
+
+// SupportedEmailLocales lists every locale that ships a full set of templates
+var SupportedEmailLocales = []string{DefaultEmailLocale, EmailLocaleEnglish}
+
+//go:embed templates/email
+var embeddedEmailTemplates embed.FS
+
+// roleDisplayNames are the role labels shown to users per locale, kept in sync with the frontend
+var roleDisplayNames = map[string]map[string]string{
+	DefaultEmailLocale: {
+		"super_admin":      "Super Administrador",
+		"city_admin":       "Administrador de Ciudad",
+		"tournament_admin": "Administrador de Torneo",
+		"owner":            "Propietario",
+		"coach":            "Entrenador",
+		"referee":          "Árbitro",
+		"player":           "Jugador",
+		"client":           "Cliente",
+	},
+	EmailLocaleEnglish: {
+		"super_admin":      "Super Administrator",
+		"city_admin":       "City Administrator",
+		"tournament_admin": "Tournament Administrator",
+		"owner":            "Team Owner",
+		"coach":            "Coach",
+		"referee":          "Referee",
+		"player":           "Player",
+		"client":           "Client",
+	},
+}
+
+// RenderedEmail is a template rendered for one locale
+type RenderedEmail struct {
+	Template string `json:"template"`
+	Locale   string `json:"locale"`
+	Subject  string `json:"subject"`
+	HTML     string `json:"html"`
+	Text     string `json:"text"`
+}
+
+// EmailTemplates renders email from a shared layout and per-locale content files.
+// Templates are embedded in the binary; when a directory is configured, files found
+// there take precedence and are re-read on every render so copy can be edited live.
+type EmailTemplates struct {
+	fsys   fs.FS
+	reload bool
+
+	mu    sync.Mutex
+	cache map[string]*template.Template
+}
+
+// NewEmailTemplates creates a renderer; dir may be empty to use only the embedded templates
+func NewEmailTemplates(dir string) *EmailTemplates {
+	embedded, _ := fs.Sub(embeddedEmailTemplates, "templates/email")
+
+	templates := &EmailTemplates{
+		fsys:  embedded,
+		cache: make(map[string]*template.Template),
+	}
+	if dir != "" {
+		templates.fsys = overlayFS{primary: os.DirFS(dir), fallback: embedded}
+		templates.reload = true
+	}
+
+	return templates
+}
+
+// NormalizeEmailLocale maps a user preference such as "en-US" or "es" to a supported locale
+func NormalizeEmailLocale(locale string) string {
+	locale = strings.TrimSpace(locale)
+	for _, supported := range SupportedEmailLocales {
+		if strings.EqualFold(locale, supported) {
+			return supported
+		}
+	}
+
+	language := strings.ToLower(strings.SplitN(strings.ReplaceAll(locale, "_", "-"), "-", 2)[0])
+	for _, supported := range SupportedEmailLocales {
+		if strings.HasPrefix(strings.ToLower(supported), language) && language != "" {
+			return supported
+		}
+	}
+
+	return DefaultEmailLocale
+}
+
+// Names returns the available template names
+func (t *EmailTemplates) Names() ([]string, error) {
+	entries, err := fs.ReadDir(t.fsys, DefaultEmailLocale)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list email templates: %w", err)
+	}
+
+	names := []string{}
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), ".html")
+		if entry.IsDir() || name == entry.Name() || name == "common" {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// Render executes a template for the given locale, falling back to the default locale
+func (t *EmailTemplates) Render(name, locale string, data map[string]interface{}) (*RenderedEmail, error) {
+	locale = NormalizeEmailLocale(locale)
+
+	tmpl, err := t.load(name, locale)
+	if errors.Is(err, fs.ErrNotExist) && locale != DefaultEmailLocale {
+		locale = DefaultEmailLocale
+		tmpl, err = t.load(name, locale)
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("email template not found: %s", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]interface{}{"Locale": locale}
+	for key, value := range data {
+		values[key] = value
+	}
+
+	var subject, body bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&subject, "subject", values); err != nil {
+		return nil, fmt.Errorf("failed to render subject of %s: %w", name, err)
+	}
+	if err := tmpl.ExecuteTemplate(&body, "layout", values); err != nil {
+		return nil, fmt.Errorf("failed to render %s: %w", name, err)
+	}
+
+	rendered := &RenderedEmail{
+		Template: name,
+		Locale:   locale,
+		// Subjects are plain text; undo the HTML escaping applied by html/template
+		Subject: html.UnescapeString(strings.TrimSpace(subject.String())),
+		HTML:    body.String(),
+	}
+
+	// A template may provide its own plain-text version; otherwise it is derived from the HTML
+	if text := tmpl.Lookup("text"); text != nil {
+		var textBody bytes.Buffer
+		if err := text.Execute(&textBody, values); err != nil {
+			return nil, fmt.Errorf("failed to render text of %s: %w", name, err)
+		}
+		rendered.Text = html.UnescapeString(textBody.String())
+	} else {
+		rendered.Text = htmlToText(rendered.HTML)
+	}
+
+	return rendered, nil
+}
+
+// load parses the layout, the locale's shared partials and the template itself
+func (t *EmailTemplates) load(name, locale string) (*template.Template, error) {
+	key := locale + "/" + name
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if tmpl, ok := t.cache[key]; ok && !t.reload {
+		return tmpl, nil
+	}
+
+	if _, err := fs.Stat(t.fsys, key+".html"); err != nil {
+		return nil, err
+	}
+
+	tmpl, err := template.New(name).Funcs(template.FuncMap{
+		"dict":     templateDict,
+		"roleName": func(role string) string { return roleDisplayName(role, locale) },
+	}).ParseFS(t.fsys, "layout.html", locale+"/common.html", key+".html")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse email template %s: %w", key, err)
+	}
+
+	t.cache[key] = tmpl
+	return tmpl, nil
+}
+
+// templateDict builds a map from key/value pairs so partials can take several arguments
+func templateDict(pairs ...interface{}) (map[string]interface{}, error) {
+	if len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("dict requires key/value pairs")
+	}
+
+	values := make(map[string]interface{}, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("dict keys must be strings")
+		}
+		values[key] = pairs[i+1]
+	}
+
+	return values, nil
+}
+
+// roleDisplayName returns the localized label of a role, or the role itself when unknown
+func roleDisplayName(role, locale string) string {
+	if name := roleDisplayNames[locale][role]; name != "" {
+		return name
+	}
+	if name := roleDisplayNames[DefaultEmailLocale][role]; name != "" {
+		return name
+	}
+	return role
+}
+
+// overlayFS reads from primary and falls back to fallback for files it does not have
+type overlayFS struct {
+	primary  fs.FS
+	fallback fs.FS
+}
+
+func (o overlayFS) Open(name string) (fs.File, error) {
+	file, err := o.primary.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return o.fallback.Open(name)
+	}
+	return file, err
+}
+
+// emailTemplateSamples is the sample data used to preview each template
+var emailTemplateSamples = map[string]map[string]interface{}{
+	EmailTemplatePasswordReset: {
+		"FirstName":         "Ana",
+		"ResetURL":          "https://mowesport.com/reset-password?token=sample",
+		"ExpirationMinutes": 10,
+	},
+	EmailTemplateVerification: {
+		"FirstName":       "Ana",
+		"VerifyURL":       "https://mowesport.com/verify-email?token=sample",
+		"ExpirationHours": 24,
+	},
+	EmailTemplateEmailChange: {
+		"FirstName":        "Ana",
+		"NewEmail":         "ana.nueva@example.com",
+		"ConfirmURL":       "https://mowesport.com/confirm-email-change?token=sample",
+		"IsCurrentAddress": true,
+	},
+	EmailTemplateInvitation: {
+		"FirstName":       "Ana",
+		"InviterName":     "Carlos Gómez",
+		"RoleName":        "referee",
+		"CityName":        "Medellín",
+		"SportName":       "Fútbol",
+		"AcceptURL":       "https://mowesport.com/accept-invitation?token=sample",
+		"ExpirationHours": 72,
+	},
+	EmailTemplateLoginChallenge: {
+		"FirstName":         "Ana",
+		"Code":              "482915",
+		"ExpirationMinutes": 10,
+	},
+	EmailTemplateReactivation: {
+		"FirstName": "Ana",
+		"Reason":    "Pago de la suscripción recibido",
+		"LoginURL":  "https://mowesport.com/login",
+	},
+	EmailTemplateDataDeletion: {
+		"FirstName":    "Ana",
+		"ScheduledFor": "2024-03-01",
+		"ProfileURL":   "https://mowesport.com/profile/data",
+	},
+	EmailTemplateGuardianConsent: {
+		"FirstName":       "Ana",
+		"PlayerName":      "Tomás Restrepo",
+		"LinkedByName":    "Carlos Gómez",
+		"DocumentVersion": "2026-01",
+		"ConsentURL":      "https://mowesport.com/guardian/players/sample",
+	},
+	EmailTemplateGuardianMatch: {
+		"FirstName":      "Ana",
+		"PlayerName":     "Tomás Restrepo",
+		"HomeTeam":       "Los Halcones",
+		"AwayTeam":       "Deportivo Norte",
+		"TournamentName": "Copa Infantil Medellín",
+		"MatchDate":      "2024-03-02",
+		"MatchTime":      "09:30",
+		"Venue":          "Cancha Marte 1",
+		"PlayerURL":      "https://mowesport.com/guardian/players/sample",
+	},
+	EmailTemplateGuardianUpdate: {
+		"FirstName":      "Ana",
+		"PlayerName":     "Tomás Restrepo",
+		"HomeTeam":       "Los Halcones",
+		"AwayTeam":       "Deportivo Norte",
+		"TournamentName": "Copa Infantil Medellín",
+		"MatchDate":      "2024-03-03",
+		"MatchTime":      "10:00",
+		"Venue":          "Cancha Marte 1",
+		"Status":         "scheduled",
+		"PlayerURL":      "https://mowesport.com/guardian/players/sample",
+	},
+	EmailTemplateSecurityAlert: {
+		"Title":           "Repeated failed logins for one account",
+		"Description":     "5 LOGIN_FAILED events for account ana@example.com within 10 minutes",
+		"Severity":        "HIGH",
+		"EventCount":      5,
+		"FirstEventAt":    "2024-01-31 14:02:11 UTC",
+		"LastEventAt":     "2024-01-31 14:09:45 UTC",
+		"IPAddresses":     "203.0.113.7, 198.51.100.23",
+		"CooldownMinutes": 30,
+	},
+}
+
+// EmailTemplateSample returns a copy of the preview data of a template
+func EmailTemplateSample(name string) map[string]interface{} {
+	data := map[string]interface{}{}
+	for key, value := range emailTemplateSamples[name] {
+		data[key] = value
+	}
+	return data
+}
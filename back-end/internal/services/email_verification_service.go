@@ -0,0 +1,489 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"mowesport/internal/config"
+	"mowesport/internal/database"
+	"mowesport/internal/models"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Verification token purposes
+const (
+	VerificationPurposeSignup         = "signup"
+	VerificationPurposeEmailChangeOld = "email_change_old"
+	VerificationPurposeEmailChangeNew = "email_change_new"
+)
+
+const (
+	verificationTokenTTL   = 24 * time.Hour
+	emailChangeTTL         = 24 * time.Hour
+	verificationResendWait = time.Minute
+	verificationDailyLimit = 5
+)
+
+// EmailVerificationService handles signed single-use email verification links
+type EmailVerificationService struct {
+	db           *database.Database
+	config       *config.Config
+	emailService *EmailService
+	auditService *SecurityAuditService
+	signingKey   []byte
+}
+
+// NewEmailVerificationService creates a new email verification service
+func NewEmailVerificationService(db *database.Database, cfg *config.Config) *EmailVerificationService {
+	auditService := NewSecurityAuditService(db)
+
+	return &EmailVerificationService{
+		db:           db,
+		config:       cfg,
+		emailService: NewEmailService(cfg, auditService),
+		auditService: auditService,
+		signingKey:   []byte(cfg.JWTSecret),
+	}
+}
+
+// SendSignupVerification issues a verification link for an unverified account
+func (s *EmailVerificationService) SendSignupVerification(ctx context.Context, userID uuid.UUID) error {
+	var email, firstName, locale string
+	var verifiedAt *time.Time
+	err := s.db.GetConnection().QueryRow(ctx,
+		"SELECT email, first_name, preferred_locale, email_verified_at FROM user_profiles WHERE user_id = $1",
+		userID,
+	).Scan(&email, &firstName, &locale, &verifiedAt)
+	if err != nil {
+		return fmt.Errorf("user not found")
+	}
+	if verifiedAt != nil {
+		return fmt.Errorf("email already verified")
+	}
+
+	if err := s.checkResendThrottle(ctx, userID, VerificationPurposeSignup); err != nil {
+		return err
+	}
+
+	tx, err := s.db.GetConnection().Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	token, err := s.issueToken(ctx, tx, userID, VerificationPurposeSignup, email, nil, time.Now().Add(verificationTokenTTL))
+	if err != nil {
+		return err
+	}
+
+	verifyURL := fmt.Sprintf("%s/verify-email?token=%s", s.config.FrontendURL, url.QueryEscape(token))
+	if err := s.emailService.SendVerificationEmail(ctx, tx, email, firstName, verifyURL, locale, int(verificationTokenTTL.Hours())); err != nil {
+		return fmt.Errorf("failed to send verification email: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// ResendSignupVerification resends the link by email address. Unknown or already
+// verified addresses succeed silently so the endpoint cannot be used to probe accounts.
+func (s *EmailVerificationService) ResendSignupVerification(ctx context.Context, email string) error {
+	var userID uuid.UUID
+	err := s.db.GetConnection().QueryRow(ctx,
+		"SELECT user_id FROM user_profiles WHERE LOWER(email) = LOWER($1) AND email_verified_at IS NULL",
+		strings.TrimSpace(email),
+	).Scan(&userID)
+	if err != nil {
+		return nil
+	}
+
+	return s.SendSignupVerification(ctx, userID)
+}
+
+// VerifyEmail consumes a signup verification link and marks the email verified
+func (s *EmailVerificationService) VerifyEmail(ctx context.Context, token string) error {
+	tx, err := s.db.GetConnection().Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	record, err := s.consumeToken(ctx, tx, token, VerificationPurposeSignup)
+	if err != nil {
+		return err
+	}
+
+	// The link is only valid for the address it was sent to
+	result, err := tx.Exec(ctx, `
+		UPDATE user_profiles SET email_verified_at = NOW(), updated_at = NOW()
+		WHERE user_id = $1 AND email = $2 AND email_verified_at IS NULL
+	`, record.userID, record.email)
+	if err != nil {
+		return fmt.Errorf("failed to verify email: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("invalid or expired token")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.auditService.LogSecurityEvent(ctx, SecurityEvent{
+		EventType:   "EMAIL_VERIFIED",
+		Description: "Email address verified",
+		UserID:      &record.userID,
+		Metadata: map[string]interface{}{
+			"email": record.email,
+		},
+		Timestamp: time.Now(),
+	})
+
+	return nil
+}
+
+// RequestEmailChange starts an email change that both addresses must confirm
+func (s *EmailVerificationService) RequestEmailChange(ctx context.Context, userID uuid.UUID, req *models.EmailChangeRequest) (*models.EmailChangeStatusResponse, error) {
+	newEmail := strings.ToLower(strings.TrimSpace(req.NewEmail))
+
+	var currentEmail, firstName, locale, passwordHash string
+	err := s.db.GetConnection().QueryRow(ctx,
+		"SELECT email, first_name, preferred_locale, password_hash FROM user_profiles WHERE user_id = $1 AND is_active = true",
+		userID,
+	).Scan(&currentEmail, &firstName, &locale, &passwordHash)
+	if err != nil {
+		return nil, fmt.Errorf("user not found")
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(req.Password)); err != nil {
+		return nil, fmt.Errorf("invalid credentials")
+	}
+	if strings.EqualFold(currentEmail, newEmail) {
+		return nil, fmt.Errorf("new email must be different from the current email")
+	}
+
+	var emailTaken bool
+	if err := s.db.GetConnection().QueryRow(ctx,
+		"SELECT EXISTS(SELECT 1 FROM user_profiles WHERE LOWER(email) = $1)", newEmail,
+	).Scan(&emailTaken); err != nil {
+		return nil, fmt.Errorf("failed to check email uniqueness: %w", err)
+	}
+	if emailTaken {
+		return nil, fmt.Errorf("email already exists")
+	}
+
+	if err := s.checkResendThrottle(ctx, userID, VerificationPurposeEmailChangeNew); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.GetConnection().Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Only one change may be pending at a time
+	if _, err := tx.Exec(ctx, `
+		UPDATE email_change_requests SET cancelled_at = NOW()
+		WHERE user_id = $1 AND completed_at IS NULL AND cancelled_at IS NULL
+	`, userID); err != nil {
+		return nil, fmt.Errorf("failed to cancel pending email changes: %w", err)
+	}
+
+	expiresAt := time.Now().Add(emailChangeTTL)
+	var requestID uuid.UUID
+	err = tx.QueryRow(ctx, `
+		INSERT INTO email_change_requests (user_id, old_email, new_email, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING request_id
+	`, userID, currentEmail, newEmail, expiresAt).Scan(&requestID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create email change request: %w", err)
+	}
+
+	oldToken, err := s.issueToken(ctx, tx, userID, VerificationPurposeEmailChangeOld, currentEmail, &requestID, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	newToken, err := s.issueToken(ctx, tx, userID, VerificationPurposeEmailChangeNew, newEmail, &requestID, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	oldURL := fmt.Sprintf("%s/confirm-email-change?token=%s", s.config.FrontendURL, url.QueryEscape(oldToken))
+	newURL := fmt.Sprintf("%s/confirm-email-change?token=%s", s.config.FrontendURL, url.QueryEscape(newToken))
+	if err := s.emailService.SendEmailChangeConfirmation(ctx, tx, currentEmail, firstName, newEmail, oldURL, locale, true); err != nil {
+		return nil, fmt.Errorf("failed to send confirmation to current email: %w", err)
+	}
+	if err := s.emailService.SendEmailChangeConfirmation(ctx, tx, newEmail, firstName, newEmail, newURL, locale, false); err != nil {
+		return nil, fmt.Errorf("failed to send confirmation to new email: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.auditService.LogSecurityEvent(ctx, SecurityEvent{
+		EventType:   "EMAIL_CHANGE_REQUESTED",
+		Description: "Email change requested",
+		UserID:      &userID,
+		Metadata: map[string]interface{}{
+			"request_id": requestID,
+			"old_email":  currentEmail,
+			"new_email":  newEmail,
+		},
+		Timestamp: time.Now(),
+	})
+
+	return &models.EmailChangeStatusResponse{
+		RequestID: requestID,
+		NewEmail:  newEmail,
+		ExpiresAt: expiresAt,
+		Message:   "Confirmation links were sent to your current and new email addresses",
+	}, nil
+}
+
+// ConfirmEmailChange consumes one side of an email change and applies it once both sides confirmed
+func (s *EmailVerificationService) ConfirmEmailChange(ctx context.Context, token string) (*models.EmailChangeStatusResponse, error) {
+	purpose, err := s.tokenPurpose(token)
+	if err != nil {
+		return nil, err
+	}
+	if purpose != VerificationPurposeEmailChangeOld && purpose != VerificationPurposeEmailChangeNew {
+		return nil, fmt.Errorf("invalid or expired token")
+	}
+
+	tx, err := s.db.GetConnection().Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	record, err := s.consumeToken(ctx, tx, token, purpose)
+	if err != nil {
+		return nil, err
+	}
+	if record.changeRequestID == nil {
+		return nil, fmt.Errorf("invalid or expired token")
+	}
+
+	column := "new_confirmed_at"
+	if purpose == VerificationPurposeEmailChangeOld {
+		column = "old_confirmed_at"
+	}
+
+	var status models.EmailChangeStatusResponse
+	var oldEmail string
+	var oldConfirmedAt, newConfirmedAt *time.Time
+	err = tx.QueryRow(ctx, fmt.Sprintf(`
+		UPDATE email_change_requests SET %s = COALESCE(%s, NOW())
+		WHERE request_id = $1 AND completed_at IS NULL AND cancelled_at IS NULL AND expires_at > NOW()
+		RETURNING request_id, old_email, new_email, old_confirmed_at, new_confirmed_at, expires_at
+	`, column, column), *record.changeRequestID).Scan(
+		&status.RequestID, &oldEmail, &status.NewEmail, &oldConfirmedAt, &newConfirmedAt, &status.ExpiresAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid or expired token")
+	}
+	status.OldConfirmed = oldConfirmedAt != nil
+	status.NewConfirmed = newConfirmedAt != nil
+
+	if status.OldConfirmed && status.NewConfirmed {
+		// Re-check uniqueness, the address may have been taken since the request
+		result, err := tx.Exec(ctx, `
+			UPDATE user_profiles SET email = $2, email_verified_at = NOW(), updated_at = NOW()
+			WHERE user_id = $1 AND email = $3
+			AND NOT EXISTS (SELECT 1 FROM user_profiles WHERE LOWER(email) = LOWER($2))
+		`, record.userID, status.NewEmail, oldEmail)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update email: %w", err)
+		}
+		if result.RowsAffected() == 0 {
+			return nil, fmt.Errorf("email already exists")
+		}
+
+		now := time.Now()
+		if _, err := tx.Exec(ctx, "UPDATE email_change_requests SET completed_at = $2 WHERE request_id = $1", status.RequestID, now); err != nil {
+			return nil, fmt.Errorf("failed to complete email change: %w", err)
+		}
+		status.Completed = true
+		status.CompletedAt = &now
+		status.Message = "Email updated successfully"
+	} else if status.OldConfirmed {
+		status.Message = "Current address confirmed, waiting for the new address"
+	} else {
+		status.Message = "New address confirmed, waiting for the current address"
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	if status.Completed {
+		s.auditService.LogSecurityEvent(ctx, SecurityEvent{
+			EventType:   "EMAIL_CHANGED",
+			Description: "Email address changed after confirmation from both addresses",
+			UserID:      &record.userID,
+			Metadata: map[string]interface{}{
+				"request_id": status.RequestID,
+				"old_email":  oldEmail,
+				"new_email":  status.NewEmail,
+			},
+			Timestamp: time.Now(),
+		})
+	}
+
+	return &status, nil
+}
+
+// Helper methods
+
+type verificationTokenRecord struct {
+	userID          uuid.UUID
+	email           string
+	changeRequestID *uuid.UUID
+}
+
+// checkResendThrottle limits how often verification links are sent to a user
+func (s *EmailVerificationService) checkResendThrottle(ctx context.Context, userID uuid.UUID, purpose string) error {
+	var lastSent *time.Time
+	var sentToday int
+	err := s.db.GetConnection().QueryRow(ctx, `
+		SELECT MAX(created_at), COUNT(*) FILTER (WHERE created_at > NOW() - INTERVAL '24 hours')
+		FROM email_verification_tokens
+		WHERE user_id = $1 AND purpose = $2
+	`, userID, purpose).Scan(&lastSent, &sentToday)
+	if err != nil {
+		return fmt.Errorf("failed to check resend throttle: %w", err)
+	}
+
+	if lastSent != nil && time.Since(*lastSent) < verificationResendWait {
+		return fmt.Errorf("resend throttled: wait before requesting another email")
+	}
+	if sentToday >= verificationDailyLimit {
+		return fmt.Errorf("resend throttled: daily limit reached")
+	}
+	return nil
+}
+
+// issueToken stores a new token and returns the signed link value "<id>.<secret>.<signature>"
+func (s *EmailVerificationService) issueToken(ctx context.Context, q dbExecutor, userID uuid.UUID, purpose, email string, changeRequestID *uuid.UUID, expiresAt time.Time) (string, error) {
+	secret, secretHash, err := newTokenSecret()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	var tokenID uuid.UUID
+	err = q.QueryRow(ctx, `
+		INSERT INTO email_verification_tokens (user_id, purpose, email, token_hash, change_request_id, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING token_id
+	`, userID, purpose, email, secretHash, changeRequestID, expiresAt).Scan(&tokenID)
+	if err != nil {
+		return "", fmt.Errorf("failed to store verification token: %w", err)
+	}
+
+	return buildSignedToken(s.signingKey, tokenID, secret, purpose), nil
+}
+
+// consumeToken verifies signature, purpose, expiry and single use, then marks the token used
+func (s *EmailVerificationService) consumeToken(ctx context.Context, tx pgx.Tx, token, purpose string) (*verificationTokenRecord, error) {
+	tokenID, secret, ok := parseSignedToken(s.signingKey, token, purpose)
+	if !ok {
+		return nil, fmt.Errorf("invalid or expired token")
+	}
+
+	var record verificationTokenRecord
+	var tokenHash string
+	err := tx.QueryRow(ctx, `
+		UPDATE email_verification_tokens SET used_at = NOW()
+		WHERE token_id = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING user_id, email, change_request_id, token_hash
+	`, tokenID, purpose).Scan(&record.userID, &record.email, &record.changeRequestID, &tokenHash)
+	if err != nil {
+		return nil, fmt.Errorf("invalid or expired token")
+	}
+
+	if !tokenSecretMatches(tokenHash, secret) {
+		return nil, fmt.Errorf("invalid or expired token")
+	}
+
+	return &record, nil
+}
+
+// tokenPurpose finds which email change purpose a token was signed for
+func (s *EmailVerificationService) tokenPurpose(token string) (string, error) {
+	for _, purpose := range []string{VerificationPurposeEmailChangeOld, VerificationPurposeEmailChangeNew, VerificationPurposeSignup} {
+		if _, _, ok := parseSignedToken(s.signingKey, token, purpose); ok {
+			return purpose, nil
+		}
+	}
+	return "", fmt.Errorf("invalid or expired token")
+}
+
+// Signed link tokens, shared with invitations
+
+// newTokenSecret returns a random link secret and the SHA-256 hex digest stored at rest
+func newTokenSecret() (string, string, error) {
+	secretBytes := make([]byte, 32)
+	if _, err := rand.Read(secretBytes); err != nil {
+		return "", "", err
+	}
+	secret := base64.RawURLEncoding.EncodeToString(secretBytes)
+	return secret, hashTokenSecret(secret), nil
+}
+
+// buildSignedToken returns the link value "<id>.<secret>.<signature>"
+func buildSignedToken(key []byte, id uuid.UUID, secret, purpose string) string {
+	payload := id.String() + "." + secret
+	return payload + "." + signToken(key, payload, purpose)
+}
+
+// parseSignedToken checks the signature for a purpose and returns the token ID and secret
+func parseSignedToken(key []byte, token, purpose string) (uuid.UUID, string, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return uuid.Nil, "", false
+	}
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(signToken(key, payload, purpose))) {
+		return uuid.Nil, "", false
+	}
+	id, err := uuid.Parse(parts[0])
+	if err != nil {
+		return uuid.Nil, "", false
+	}
+	return id, parts[1], true
+}
+
+// tokenSecretMatches compares a presented secret with the stored digest in constant time
+func tokenSecretMatches(storedHash, secret string) bool {
+	return subtle.ConstantTimeCompare([]byte(storedHash), []byte(hashTokenSecret(secret))) == 1
+}
+
+// hashTokenSecret returns the SHA-256 hex digest of a link secret
+func hashTokenSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// signToken computes the HMAC signature binding a token to its purpose
+func signToken(key []byte, payload, purpose string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(purpose + ":" + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,554 @@
+package services
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mowesport/internal/config"
+	"mowesport/internal/database"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Encrypted fields. The name is bound to each ciphertext, so a value cannot be moved to
+// another column and still decrypt.
+const (
+	FieldPlayerIdentification   = "players.identification"
+	FieldPlayerBloodType        = "players.blood_type"
+	FieldPlayerEmergencyContact = "players.emergency_contact"
+	FieldPlayerMedicalInfo      = "players.medical_info"
+	FieldTwoFactorSecret        = "user_profiles.two_factor_secret"
+)
+
+// Key purposes in encryption_keys
+const (
+	EncryptionKeyPurposeData       = "data"
+	EncryptionKeyPurposeBlindIndex = "blind_index"
+)
+
+const (
+	encryptedValuePrefix = "enc:v1:"
+	masterKeySize        = 32
+
+	// Rotations done by another process are picked up within this delay
+	activeKeyRefreshInterval = time.Minute
+)
+
+// developmentMasterKey is used outside production when no master key is configured.
+// It is public, so data encrypted with it is not protected.
+var developmentMasterKey = sha256.Sum256([]byte("mowesport-development-field-encryption"))
+
+var developmentKeyWarning sync.Once
+
+type masterKey struct {
+	id  string
+	key []byte
+}
+
+// FieldEncryptionService encrypts sensitive fields with envelope encryption: values are
+// sealed with AES-256-GCM data keys, stored in encryption_keys wrapped by a master key that
+// never reaches the database. Ciphertexts look like "enc:v1:<key_id>:<base64>"; values
+// without the prefix are plaintext written before encryption was enabled and are returned
+// as is until the re-encryption job converts them.
+type FieldEncryptionService struct {
+	db         *database.Database
+	config     config.EncryptionConfig
+	masterKeys []masterKey // Current first
+	configErr  error
+
+	auditService *SecurityAuditService
+
+	mu              sync.RWMutex
+	dataKeys        map[int][]byte
+	activeKeyID     int
+	activeCheckedAt time.Time
+	blindIndexKey   []byte
+}
+
+// NewFieldEncryptionService creates a new field encryption service. Configuration problems
+// are reported by the first operation that needs a key.
+func NewFieldEncryptionService(db *database.Database, cfg *config.Config) *FieldEncryptionService {
+	s := &FieldEncryptionService{
+		db:           db,
+		config:       cfg.Encryption,
+		auditService: NewSecurityAuditService(db),
+		dataKeys:     map[int][]byte{},
+	}
+	s.masterKeys, s.configErr = loadMasterKeys(cfg)
+	return s
+}
+
+func loadMasterKeys(cfg *config.Config) ([]masterKey, error) {
+	encoded := []string{}
+	if cfg.Encryption.MasterKey != "" {
+		encoded = append(encoded, cfg.Encryption.MasterKey)
+	} else if cfg.Encryption.MasterKeyFile != "" {
+		content, err := os.ReadFile(cfg.Encryption.MasterKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read master keyfile: %w", err)
+		}
+		for _, line := range strings.Split(string(content), "\n") {
+			if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+				encoded = append(encoded, line)
+			}
+		}
+	}
+	encoded = append(encoded, cfg.Encryption.PreviousMasterKeys...)
+
+	if len(encoded) == 0 {
+		if cfg.Environment == "production" {
+			return nil, fmt.Errorf("field encryption is not configured: set FIELD_ENCRYPTION_MASTER_KEY or FIELD_ENCRYPTION_MASTER_KEY_FILE")
+		}
+		developmentKeyWarning.Do(func() {
+			slog.Warn("no master key configured, using the insecure development key", "component", "field_encryption")
+		})
+		return []masterKey{newMasterKey(developmentMasterKey[:])}, nil
+	}
+
+	keys := make([]masterKey, 0, len(encoded))
+	for i, value := range encoded {
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+		if err != nil || len(key) != masterKeySize {
+			return nil, fmt.Errorf("master key %d must be %d bytes encoded in base64", i+1, masterKeySize)
+		}
+		keys = append(keys, newMasterKey(key))
+	}
+	return keys, nil
+}
+
+func newMasterKey(key []byte) masterKey {
+	fingerprint := sha256.Sum256(key)
+	return masterKey{id: hex.EncodeToString(fingerprint[:8]), key: key}
+}
+
+// GenerateMasterKey returns a new random master key, base64 encoded
+func GenerateMasterKey() (string, error) {
+	key := make([]byte, masterKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("failed to generate key: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// CurrentMasterKeyID returns the fingerprint of the master key used to wrap new data keys
+func (s *FieldEncryptionService) CurrentMasterKeyID() (string, error) {
+	if s.configErr != nil {
+		return "", s.configErr
+	}
+	return s.masterKeys[0].id, nil
+}
+
+// IsEncrypted reports whether a stored value is a ciphertext
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, encryptedValuePrefix)
+}
+
+// Encrypt seals a value of field with the active data key
+func (s *FieldEncryptionService) Encrypt(ctx context.Context, field, plaintext string) (string, error) {
+	keyID, key, err := s.activeDataKey(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	sealed, err := seal(key, []byte(plaintext), []byte(field))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s%d:%s", encryptedValuePrefix, keyID, base64.RawStdEncoding.EncodeToString(sealed)), nil
+}
+
+// EncryptOptional encrypts a nullable value; nil and empty values stay nil
+func (s *FieldEncryptionService) EncryptOptional(ctx context.Context, field string, plaintext *string) (*string, error) {
+	if plaintext == nil || *plaintext == "" {
+		return nil, nil
+	}
+	value, err := s.Encrypt(ctx, field, *plaintext)
+	if err != nil {
+		return nil, err
+	}
+	return &value, nil
+}
+
+// Decrypt opens a value of field. Plaintext values (not yet encrypted) are returned unchanged.
+func (s *FieldEncryptionService) Decrypt(ctx context.Context, field, value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+
+	rest := strings.TrimPrefix(value, encryptedValuePrefix)
+	separator := strings.IndexByte(rest, ':')
+	if separator < 0 {
+		return "", fmt.Errorf("malformed encrypted value in %s", field)
+	}
+	keyID, err := strconv.Atoi(rest[:separator])
+	if err != nil {
+		return "", fmt.Errorf("malformed encrypted value in %s", field)
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(rest[separator+1:])
+	if err != nil {
+		return "", fmt.Errorf("malformed encrypted value in %s", field)
+	}
+
+	key, err := s.dataKey(ctx, keyID)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := open(key, sealed, []byte(field))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt %s: %w", field, err)
+	}
+	return string(plaintext), nil
+}
+
+// DecryptOptional decrypts a nullable value
+func (s *FieldEncryptionService) DecryptOptional(ctx context.Context, field string, value *string) (*string, error) {
+	if value == nil {
+		return nil, nil
+	}
+	plaintext, err := s.Decrypt(ctx, field, *value)
+	if err != nil {
+		return nil, err
+	}
+	return &plaintext, nil
+}
+
+// PlayerSensitiveFields are the encrypted columns of a players row
+type PlayerSensitiveFields struct {
+	Identification      *string
+	IdentificationIndex *string // Blind index, set by EncryptPlayerFields
+	BloodType           *string
+	EmergencyContact    *string
+	MedicalInfo         *string
+}
+
+func (f *PlayerSensitiveFields) optional() []struct {
+	name  string
+	value **string
+} {
+	return []struct {
+		name  string
+		value **string
+	}{
+		{FieldPlayerBloodType, &f.BloodType},
+		{FieldPlayerEmergencyContact, &f.EmergencyContact},
+		{FieldPlayerMedicalInfo, &f.MedicalInfo},
+	}
+}
+
+// EncryptPlayerFields replaces the plaintext fields with ciphertexts and sets the blind index
+func (s *FieldEncryptionService) EncryptPlayerFields(ctx context.Context, fields *PlayerSensitiveFields) error {
+	if fields.Identification != nil {
+		index, err := s.BlindIndex(ctx, *fields.Identification)
+		if err != nil {
+			return err
+		}
+		encrypted, err := s.Encrypt(ctx, FieldPlayerIdentification, *fields.Identification)
+		if err != nil {
+			return err
+		}
+		fields.Identification, fields.IdentificationIndex = &encrypted, &index
+	}
+
+	for _, field := range fields.optional() {
+		encrypted, err := s.EncryptOptional(ctx, field.name, *field.value)
+		if err != nil {
+			return err
+		}
+		*field.value = encrypted
+	}
+	return nil
+}
+
+// DecryptPlayerFields replaces the ciphertexts with their plaintext
+func (s *FieldEncryptionService) DecryptPlayerFields(ctx context.Context, fields *PlayerSensitiveFields) error {
+	identification, err := s.DecryptOptional(ctx, FieldPlayerIdentification, fields.Identification)
+	if err != nil {
+		return err
+	}
+	fields.Identification = identification
+
+	for _, field := range fields.optional() {
+		decrypted, err := s.DecryptOptional(ctx, field.name, *field.value)
+		if err != nil {
+			return err
+		}
+		*field.value = decrypted
+	}
+	return nil
+}
+
+// NormalizeIdentification removes the formatting people add to document numbers
+// ("1.023.456-7" and "10234567" are the same identification)
+func NormalizeIdentification(identification string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '.', '-', '\t':
+			return -1
+		}
+		return r
+	}, strings.ToUpper(strings.TrimSpace(identification)))
+}
+
+// BlindIndex returns the HMAC of a normalized identification, used for lookups and
+// uniqueness without decrypting. The blind index key is never rotated, since every stored
+// index would have to be recomputed.
+func (s *FieldEncryptionService) BlindIndex(ctx context.Context, identification string) (string, error) {
+	key, err := s.loadBlindIndexKey(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(NormalizeIdentification(identification)))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// BlindIndexes maps the blind index of each identification to the identification
+func (s *FieldEncryptionService) BlindIndexes(ctx context.Context, identifications []string) (map[string]string, error) {
+	indexes := make(map[string]string, len(identifications))
+	for _, identification := range identifications {
+		index, err := s.BlindIndex(ctx, identification)
+		if err != nil {
+			return nil, err
+		}
+		indexes[index] = identification
+	}
+	return indexes, nil
+}
+
+func (s *FieldEncryptionService) activeDataKey(ctx context.Context) (int, []byte, error) {
+	s.mu.RLock()
+	keyID, checkedAt := s.activeKeyID, s.activeCheckedAt
+	key := s.dataKeys[keyID]
+	s.mu.RUnlock()
+	if keyID != 0 && time.Since(checkedAt) < activeKeyRefreshInterval {
+		return keyID, key, nil
+	}
+
+	keyID, key, err := s.loadActiveKey(ctx, EncryptionKeyPurposeData)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	s.mu.Lock()
+	s.dataKeys[keyID] = key
+	s.activeKeyID = keyID
+	s.activeCheckedAt = time.Now()
+	s.mu.Unlock()
+	return keyID, key, nil
+}
+
+// ActiveDataKeyID returns the data key new values are encrypted with
+func (s *FieldEncryptionService) ActiveDataKeyID(ctx context.Context) (int, error) {
+	s.mu.Lock()
+	s.activeCheckedAt = time.Time{}
+	s.mu.Unlock()
+
+	keyID, _, err := s.activeDataKey(ctx)
+	return keyID, err
+}
+
+func (s *FieldEncryptionService) dataKey(ctx context.Context, keyID int) ([]byte, error) {
+	s.mu.RLock()
+	key, ok := s.dataKeys[keyID]
+	s.mu.RUnlock()
+	if ok {
+		return key, nil
+	}
+
+	var wrapped, masterKeyID, purpose string
+	err := s.db.GetConnection().QueryRow(ctx,
+		"SELECT wrapped_key, master_key_id, purpose FROM encryption_keys WHERE key_id = $1", keyID,
+	).Scan(&wrapped, &masterKeyID, &purpose)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && purpose != EncryptionKeyPurposeData) {
+		return nil, fmt.Errorf("data key %d not found", keyID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load data key: %w", err)
+	}
+
+	key, err = s.unwrap(wrapped, masterKeyID, purpose)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.dataKeys[keyID] = key
+	s.mu.Unlock()
+	return key, nil
+}
+
+func (s *FieldEncryptionService) loadBlindIndexKey(ctx context.Context) ([]byte, error) {
+	s.mu.RLock()
+	key := s.blindIndexKey
+	s.mu.RUnlock()
+	if key != nil {
+		return key, nil
+	}
+
+	_, key, err := s.loadActiveKey(ctx, EncryptionKeyPurposeBlindIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.blindIndexKey = key
+	s.mu.Unlock()
+	return key, nil
+}
+
+// loadActiveKey returns the active key of a purpose, creating the first one on demand
+func (s *FieldEncryptionService) loadActiveKey(ctx context.Context, purpose string) (int, []byte, error) {
+	if s.configErr != nil {
+		return 0, nil, s.configErr
+	}
+
+	for attempt := 0; attempt < 2; attempt++ {
+		var keyID int
+		var wrapped, masterKeyID string
+		err := s.db.GetConnection().QueryRow(ctx,
+			"SELECT key_id, wrapped_key, master_key_id FROM encryption_keys WHERE purpose = $1 AND status = 'active'",
+			purpose,
+		).Scan(&keyID, &wrapped, &masterKeyID)
+		if err == nil {
+			key, err := s.unwrap(wrapped, masterKeyID, purpose)
+			return keyID, key, err
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return 0, nil, fmt.Errorf("failed to load %s key: %w", purpose, err)
+		}
+
+		// Concurrent first uses race on the active index; the loser reads the winner's key
+		if _, err := s.createKey(ctx, s.db.GetConnection(), purpose, true); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	return 0, nil, fmt.Errorf("failed to create %s key", purpose)
+}
+
+// keyExecutor is satisfied by *pgx.Conn and pgx.Tx
+type keyExecutor interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// createKey generates a key, wraps it with the current master key and stores it as active.
+// With ignoreConflict, an existing active key wins and 0 is returned.
+func (s *FieldEncryptionService) createKey(ctx context.Context, q keyExecutor, purpose string, ignoreConflict bool) (int, error) {
+	key := make([]byte, masterKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return 0, fmt.Errorf("failed to generate %s key: %w", purpose, err)
+	}
+	wrapped, err := s.wrap(key, purpose)
+	if err != nil {
+		return 0, err
+	}
+
+	query := `
+		INSERT INTO encryption_keys (purpose, wrapped_key, master_key_id)
+		VALUES ($1, $2, $3)
+		RETURNING key_id
+	`
+	if ignoreConflict {
+		query = `
+			INSERT INTO encryption_keys (purpose, wrapped_key, master_key_id)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (purpose) WHERE status = 'active' DO NOTHING
+			RETURNING key_id
+		`
+	}
+
+	var keyID int
+	err = q.QueryRow(ctx, query, purpose, wrapped, s.masterKeys[0].id).Scan(&keyID)
+	if ignoreConflict && errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to store %s key: %w", purpose, err)
+	}
+	return keyID, nil
+}
+
+func (s *FieldEncryptionService) masterKey(id string) ([]byte, error) {
+	if s.configErr != nil {
+		return nil, s.configErr
+	}
+	for _, master := range s.masterKeys {
+		if master.id == id {
+			return master.key, nil
+		}
+	}
+	return nil, fmt.Errorf("master key %s is not configured", id)
+}
+
+func (s *FieldEncryptionService) wrap(key []byte, purpose string) (string, error) {
+	if s.configErr != nil {
+		return "", s.configErr
+	}
+	sealed, err := seal(s.masterKeys[0].key, key, []byte("encryption_keys."+purpose))
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (s *FieldEncryptionService) unwrap(wrapped, masterKeyID, purpose string) ([]byte, error) {
+	master, err := s.masterKey(masterKeyID)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := base64.StdEncoding.DecodeString(wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("malformed wrapped %s key", purpose)
+	}
+	key, err := open(master, sealed, []byte("encryption_keys."+purpose))
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap %s key with master key %s: %w", purpose, masterKeyID, err)
+	}
+	return key, nil
+}
+
+// seal encrypts with AES-256-GCM and returns nonce || ciphertext
+func seal(key, plaintext, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return gcm.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func open(key, sealed, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	return gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], additionalData)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
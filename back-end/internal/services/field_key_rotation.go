@@ -0,0 +1,431 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// FieldEncryptionKey describes a key in encryption_keys, without key material
+type FieldEncryptionKey struct {
+	KeyID       int        `json:"key_id"`
+	Purpose     string     `json:"purpose"`
+	Status      string     `json:"status"`
+	MasterKeyID string     `json:"master_key_id"`
+	CreatedAt   time.Time  `json:"created_at"`
+	RetiredAt   *time.Time `json:"retired_at,omitempty"`
+}
+
+// FieldEncryptionStatus summarizes the keys and the values still waiting for re-encryption
+type FieldEncryptionStatus struct {
+	CurrentMasterKeyID string               `json:"current_master_key_id"`
+	ActiveDataKeyID    int                  `json:"active_data_key_id"`
+	Keys               []FieldEncryptionKey `json:"keys"`
+	PendingRewrap      int                  `json:"pending_rewrap"`
+	PendingPlayers     int                  `json:"pending_players"`
+	PendingTwoFactor   int                  `json:"pending_two_factor"`
+}
+
+// FieldReencryptionResult counts the rows rewritten by a re-encryption or decryption run
+type FieldReencryptionResult struct {
+	Players          int `json:"players"`
+	TwoFactorSecrets int `json:"two_factor_secrets"`
+}
+
+// playerFieldsPending matches players with a field not encrypted under the active data key
+// ($1 is "enc:v1:<active key>:%") or without a blind index
+const playerFieldsPending = `(
+	identification NOT LIKE $1 OR identification_bidx IS NULL
+	OR (blood_type IS NOT NULL AND blood_type NOT LIKE $1)
+	OR (emergency_contact IS NOT NULL AND emergency_contact NOT LIKE $1)
+	OR (medical_info IS NOT NULL AND medical_info NOT LIKE $1)
+)`
+
+// playerFieldsEncrypted matches players with any encrypted field ($1 is "enc:%")
+const playerFieldsEncrypted = `(
+	identification LIKE $1 OR blood_type LIKE $1 OR emergency_contact LIKE $1 OR medical_info LIKE $1
+)`
+
+// Status reports the keys and how many rows the re-encryption job still has to convert
+func (s *FieldEncryptionService) Status(ctx context.Context) (*FieldEncryptionStatus, error) {
+	currentMasterKeyID, err := s.CurrentMasterKeyID()
+	if err != nil {
+		return nil, err
+	}
+	activeKeyID, err := s.ActiveDataKeyID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &FieldEncryptionStatus{
+		CurrentMasterKeyID: currentMasterKeyID,
+		ActiveDataKeyID:    activeKeyID,
+		Keys:               []FieldEncryptionKey{},
+	}
+
+	rows, err := s.db.GetConnection().Query(ctx, `
+		SELECT key_id, purpose, status, master_key_id, created_at, retired_at
+		FROM encryption_keys ORDER BY key_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query encryption keys: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key FieldEncryptionKey
+		if err := rows.Scan(&key.KeyID, &key.Purpose, &key.Status, &key.MasterKeyID, &key.CreatedAt, &key.RetiredAt); err != nil {
+			return nil, fmt.Errorf("failed to scan encryption key: %w", err)
+		}
+		if key.MasterKeyID != currentMasterKeyID {
+			status.PendingRewrap++
+		}
+		status.Keys = append(status.Keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over encryption keys: %w", err)
+	}
+
+	prefix := activeKeyPattern(activeKeyID)
+	err = s.db.GetConnection().QueryRow(ctx,
+		"SELECT COUNT(*) FROM players WHERE "+playerFieldsPending, prefix,
+	).Scan(&status.PendingPlayers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count pending players: %w", err)
+	}
+	err = s.db.GetConnection().QueryRow(ctx,
+		"SELECT COUNT(*) FROM user_profiles WHERE two_factor_secret IS NOT NULL AND two_factor_secret NOT LIKE $1", prefix,
+	).Scan(&status.PendingTwoFactor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count pending 2FA secrets: %w", err)
+	}
+
+	return status, nil
+}
+
+// RotateDataKey retires the active data key and creates a new one. Values encrypted with
+// retired keys stay readable; Reencrypt moves them to the new key.
+func (s *FieldEncryptionService) RotateDataKey(ctx context.Context, rotatedBy *uuid.UUID) (int, error) {
+	if s.configErr != nil {
+		return 0, s.configErr
+	}
+
+	tx, err := s.db.GetConnection().Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var retiredKeyID int
+	err = tx.QueryRow(ctx, `
+		UPDATE encryption_keys SET status = 'retired', retired_at = NOW()
+		WHERE purpose = $1 AND status = 'active'
+		RETURNING key_id
+	`, EncryptionKeyPurposeData).Scan(&retiredKeyID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return 0, fmt.Errorf("failed to retire data key: %w", err)
+	}
+
+	keyID, err := s.createKey(ctx, tx, EncryptionKeyPurposeData, false)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.mu.Lock()
+	s.activeCheckedAt = time.Time{}
+	s.mu.Unlock()
+
+	s.auditService.LogSecurityEvent(ctx, SecurityEvent{
+		EventType:   EventTypeEncryptionKeyRotated,
+		Description: "Field encryption data key rotated",
+		UserID:      rotatedBy,
+		Metadata: map[string]interface{}{
+			"new_key_id":     keyID,
+			"retired_key_id": retiredKeyID,
+		},
+	})
+
+	return keyID, nil
+}
+
+// RewrapKeys wraps every key still wrapped by a previous master key with the current one.
+// Run it after changing the master key, keeping the old key configured as a previous key
+// until it completes.
+func (s *FieldEncryptionService) RewrapKeys(ctx context.Context, rotatedBy *uuid.UUID) (int, error) {
+	currentMasterKeyID, err := s.CurrentMasterKeyID()
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := s.db.GetConnection().Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		SELECT key_id, purpose, wrapped_key, master_key_id FROM encryption_keys
+		WHERE master_key_id <> $1
+		ORDER BY key_id
+		FOR UPDATE
+	`, currentMasterKeyID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query keys to rewrap: %w", err)
+	}
+
+	type staleKey struct {
+		keyID                         int
+		purpose, wrapped, masterKeyID string
+	}
+	stale := []staleKey{}
+	for rows.Next() {
+		var key staleKey
+		if err := rows.Scan(&key.keyID, &key.purpose, &key.wrapped, &key.masterKeyID); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan key: %w", err)
+		}
+		stale = append(stale, key)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating over keys: %w", err)
+	}
+
+	previousMasterKeys := map[string]bool{}
+	for _, key := range stale {
+		plain, err := s.unwrap(key.wrapped, key.masterKeyID, key.purpose)
+		if err != nil {
+			return 0, err
+		}
+		wrapped, err := s.wrap(plain, key.purpose)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := tx.Exec(ctx,
+			"UPDATE encryption_keys SET wrapped_key = $2, master_key_id = $3, rewrapped_at = NOW() WHERE key_id = $1",
+			key.keyID, wrapped, currentMasterKeyID,
+		); err != nil {
+			return 0, fmt.Errorf("failed to rewrap key %d: %w", key.keyID, err)
+		}
+		previousMasterKeys[key.masterKeyID] = true
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	if len(stale) > 0 {
+		retired := make([]string, 0, len(previousMasterKeys))
+		for id := range previousMasterKeys {
+			retired = append(retired, id)
+		}
+		s.auditService.LogSecurityEvent(ctx, SecurityEvent{
+			EventType:   EventTypeEncryptionKeyRotated,
+			Description: "Field encryption keys rewrapped with a new master key",
+			UserID:      rotatedBy,
+			Metadata: map[string]interface{}{
+				"master_key_id":        currentMasterKeyID,
+				"previous_master_keys": retired,
+				"rewrapped_keys":       len(stale),
+			},
+		})
+	}
+
+	return len(stale), nil
+}
+
+// Reencrypt converts plaintext values and values under retired data keys to the active data
+// key, and fills missing blind indexes. It runs in batches and can be resumed at any time.
+func (s *FieldEncryptionService) Reencrypt(ctx context.Context) (*FieldReencryptionResult, error) {
+	activeKeyID, err := s.ActiveDataKeyID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.convert(ctx, activeKeyPattern(activeKeyID), true)
+}
+
+// DecryptAll writes every encrypted value back as plaintext. It is only meant to prepare a
+// rollback of migration 023.
+func (s *FieldEncryptionService) DecryptAll(ctx context.Context) (*FieldReencryptionResult, error) {
+	return s.convert(ctx, encryptedValuePrefix+"%", false)
+}
+
+func activeKeyPattern(keyID int) string {
+	return fmt.Sprintf("%s%d:%%", encryptedValuePrefix, keyID)
+}
+
+func (s *FieldEncryptionService) convert(ctx context.Context, pattern string, encrypt bool) (*FieldReencryptionResult, error) {
+	result := &FieldReencryptionResult{}
+	batchSize := s.config.ReencryptBatchSize
+	if batchSize <= 0 {
+		batchSize = 200
+	}
+
+	for {
+		converted, err := s.convertPlayers(ctx, pattern, encrypt, batchSize)
+		if err != nil {
+			return result, err
+		}
+		result.Players += converted
+		if converted < batchSize {
+			break
+		}
+	}
+
+	for {
+		converted, err := s.convertTwoFactorSecrets(ctx, pattern, encrypt, batchSize)
+		if err != nil {
+			return result, err
+		}
+		result.TwoFactorSecrets += converted
+		if converted < batchSize {
+			break
+		}
+	}
+
+	return result, nil
+}
+
+func (s *FieldEncryptionService) convertPlayers(ctx context.Context, pattern string, encrypt bool, batchSize int) (int, error) {
+	condition := playerFieldsPending
+	if !encrypt {
+		condition = playerFieldsEncrypted
+	}
+
+	tx, err := s.db.GetConnection().Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		SELECT player_id, identification, blood_type, emergency_contact, medical_info
+		FROM players WHERE `+condition+`
+		ORDER BY player_id
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED
+	`, pattern, batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query players: %w", err)
+	}
+
+	players := map[uuid.UUID]*PlayerSensitiveFields{}
+	for rows.Next() {
+		var playerID uuid.UUID
+		fields := &PlayerSensitiveFields{}
+		if err := rows.Scan(&playerID, &fields.Identification, &fields.BloodType, &fields.EmergencyContact, &fields.MedicalInfo); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan player: %w", err)
+		}
+		players[playerID] = fields
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating over players: %w", err)
+	}
+
+	for playerID, fields := range players {
+		if err := s.DecryptPlayerFields(ctx, fields); err != nil {
+			return 0, fmt.Errorf("player %s: %w", playerID, err)
+		}
+
+		if !encrypt {
+			_, err = tx.Exec(ctx, `
+				UPDATE players SET identification = $2, blood_type = $3, emergency_contact = $4, medical_info = $5
+				WHERE player_id = $1
+			`, playerID, fields.Identification, fields.BloodType, fields.EmergencyContact, fields.MedicalInfo)
+			if err != nil {
+				return 0, fmt.Errorf("failed to decrypt player %s: %w", playerID, err)
+			}
+			continue
+		}
+
+		if err := s.EncryptPlayerFields(ctx, fields); err != nil {
+			return 0, err
+		}
+		_, err = tx.Exec(ctx, `
+			UPDATE players SET identification = $2, identification_bidx = $3, blood_type = $4,
+			       emergency_contact = $5, medical_info = $6
+			WHERE player_id = $1
+		`, playerID, fields.Identification, fields.IdentificationIndex, fields.BloodType, fields.EmergencyContact, fields.MedicalInfo)
+		if err != nil {
+			if strings.Contains(err.Error(), "idx_players_identification_bidx") {
+				return 0, fmt.Errorf("player %s has the same identification as another player; merge or correct them first", playerID)
+			}
+			return 0, fmt.Errorf("failed to re-encrypt player %s: %w", playerID, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return len(players), nil
+}
+
+func (s *FieldEncryptionService) convertTwoFactorSecrets(ctx context.Context, pattern string, encrypt bool, batchSize int) (int, error) {
+	condition := "two_factor_secret NOT LIKE $1"
+	if !encrypt {
+		condition = "two_factor_secret LIKE $1"
+	}
+
+	tx, err := s.db.GetConnection().Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		SELECT user_id, two_factor_secret FROM user_profiles
+		WHERE two_factor_secret IS NOT NULL AND `+condition+`
+		ORDER BY user_id
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED
+	`, pattern, batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query 2FA secrets: %w", err)
+	}
+
+	secrets := map[uuid.UUID]string{}
+	for rows.Next() {
+		var userID uuid.UUID
+		var secret string
+		if err := rows.Scan(&userID, &secret); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan 2FA secret: %w", err)
+		}
+		secrets[userID] = secret
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating over 2FA secrets: %w", err)
+	}
+
+	for userID, secret := range secrets {
+		value, err := s.Decrypt(ctx, FieldTwoFactorSecret, secret)
+		if err != nil {
+			return 0, fmt.Errorf("user %s: %w", userID, err)
+		}
+		if encrypt {
+			if value, err = s.Encrypt(ctx, FieldTwoFactorSecret, value); err != nil {
+				return 0, err
+			}
+		}
+		if _, err := tx.Exec(ctx, "UPDATE user_profiles SET two_factor_secret = $2 WHERE user_id = $1", userID, value); err != nil {
+			return 0, fmt.Errorf("failed to rewrite 2FA secret of %s: %w", userID, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return len(secrets), nil
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const guardianNotificationBatchSize = 100
+
+// guardianMatchNotice is a match to email to one guardian of a rostered minor
+type guardianMatchNotice struct {
+	matchID    uuid.UUID
+	guardianID uuid.UUID
+	playerID   uuid.UUID
+	matchDate  time.Time
+	matchTime  string
+	status     string
+	update     bool
+	email      GuardianMatchEmailData
+}
+
+// Run sends guardian match notifications until ctx is cancelled
+func (s *GuardianService) Run(ctx context.Context) {
+	interval := s.config.NotificationInterval
+	if interval <= 0 {
+		interval = 15 * time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.ProcessMatchNotifications(ctx); err != nil {
+			slog.ErrorContext(ctx, "failed to send guardian match notifications", "component", "guardians", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessMatchNotifications emails guardians of minors on a tournament roster about matches
+// starting within the reminder lead, and again when a notified match is rescheduled,
+// postponed or cancelled. It returns how many emails were queued. Match dates and times
+// are local, as stored.
+func (s *GuardianService) ProcessMatchNotifications(ctx context.Context) (int, error) {
+	rows, err := s.db.GetConnection().Query(ctx, `
+		SELECT m.match_id, g.guardian_user_id, p.player_id, m.match_date, m.match_time::text, m.status,
+		       n.match_id IS NOT NULL, up.email, up.first_name, up.preferred_locale,
+		       p.first_name || ' ' || p.last_name, home.name, away.name, t.name, COALESCE(m.venue, '')
+		FROM matches m
+		JOIN tournaments t ON t.tournament_id = m.tournament_id
+		JOIN teams home ON home.team_id = m.home_team_id
+		JOIN teams away ON away.team_id = m.away_team_id
+		JOIN tournament_teams tt ON tt.tournament_id = m.tournament_id AND tt.team_id IN (m.home_team_id, m.away_team_id)
+		JOIN tournament_team_players ttp ON ttp.tournament_team_id = tt.tournament_team_id
+		JOIN players p ON p.player_id = ttp.player_id AND p.anonymized_at IS NULL
+		JOIN player_guardians g ON g.player_id = p.player_id AND g.revoked_at IS NULL AND g.notify_matches = true
+		JOIN user_profiles up ON up.user_id = g.guardian_user_id AND up.is_active = true AND up.anonymized_at IS NULL
+		LEFT JOIN guardian_match_notifications n
+			ON n.match_id = m.match_id AND n.guardian_user_id = g.guardian_user_id AND n.player_id = p.player_id
+		WHERE m.match_date + m.match_time >= LOCALTIMESTAMP
+		  AND p.date_of_birth > m.match_date - make_interval(years => $1)
+		  AND (
+			(n.match_id IS NULL AND m.status = 'scheduled'
+			 AND m.match_date + m.match_time <= LOCALTIMESTAMP + make_interval(secs => $2))
+			OR (n.match_id IS NOT NULL AND m.status IN ('scheduled', 'postponed', 'cancelled')
+			 AND (n.match_date, n.match_time, n.match_status) IS DISTINCT FROM (m.match_date, m.match_time, m.status))
+		  )
+		ORDER BY m.match_date, m.match_time
+		LIMIT $3
+	`, GuardianAgeOfMajority, s.config.MatchReminderLead.Seconds(), guardianNotificationBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query match notifications: %w", err)
+	}
+
+	notices := []guardianMatchNotice{}
+	for rows.Next() {
+		var notice guardianMatchNotice
+		if err := rows.Scan(
+			&notice.matchID, &notice.guardianID, &notice.playerID, &notice.matchDate, &notice.matchTime, &notice.status,
+			&notice.update, &notice.email.Email, &notice.email.FirstName, &notice.email.Locale, &notice.email.PlayerName,
+			&notice.email.HomeTeam, &notice.email.AwayTeam, &notice.email.TournamentName, &notice.email.Venue,
+		); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan match notification: %w", err)
+		}
+		notice.email.PlayerID = notice.playerID.String()
+		notice.email.MatchDate = notice.matchDate.Format("2006-01-02")
+		notice.email.MatchTime = notice.matchTime
+		if len(notice.matchTime) >= 5 {
+			notice.email.MatchTime = notice.matchTime[:5]
+		}
+		notice.email.Status = notice.status
+		notices = append(notices, notice)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating over match notifications: %w", err)
+	}
+
+	sent := 0
+	for _, notice := range notices {
+		if err := s.sendMatchNotice(ctx, notice); err != nil {
+			return sent, err
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// sendMatchNotice queues the email and records the schedule it announced, together
+func (s *GuardianService) sendMatchNotice(ctx context.Context, notice guardianMatchNotice) error {
+	tx, err := s.db.GetConnection().Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := s.emailService.SendGuardianMatchEmail(ctx, tx, notice.email, notice.update); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO guardian_match_notifications (match_id, guardian_user_id, player_id, match_date, match_time, match_status)
+		VALUES ($1, $2, $3, $4, $5::time, $6)
+		ON CONFLICT (match_id, guardian_user_id, player_id) DO UPDATE SET
+			match_date = EXCLUDED.match_date, match_time = EXCLUDED.match_time,
+			match_status = EXCLUDED.match_status, notified_at = NOW()
+	`, notice.matchID, notice.guardianID, notice.playerID, notice.matchDate, notice.matchTime, notice.status)
+	if err != nil {
+		return fmt.Errorf("failed to record match notification: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
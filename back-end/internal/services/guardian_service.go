@@ -0,0 +1,734 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mowesport/internal/config"
+	"mowesport/internal/database"
+	"mowesport/internal/models"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// GuardianAgeOfMajority is the age from which a player no longer needs a guardian.
+// The roster consent trigger of migration 024 uses the same age.
+const GuardianAgeOfMajority = 18
+
+// DataAccessPurposeGuardian is recorded when a guardian reads their minor's sensitive fields
+const DataAccessPurposeGuardian = "guardian_of_minor"
+
+// IsMinor reports whether someone born on dateOfBirth is under age at the given time
+func IsMinor(dateOfBirth, at time.Time) bool {
+	return at.Before(dateOfBirth.AddDate(GuardianAgeOfMajority, 0, 0))
+}
+
+// GuardianService links guardian (parent) accounts to minor players, records their consent
+// for tournament participation and lets them edit the minor's profile. Minors registered
+// through it have no login of their own.
+type GuardianService struct {
+	db                *database.Database
+	config            config.GuardianConfig
+	emailService      *EmailService
+	auditService      *SecurityAuditService
+	invitationService *InvitationService
+	delegation        *DelegationService
+	scopeLookup       *ScopeLookupService
+	fieldEncryption   *FieldEncryptionService
+}
+
+// NewGuardianService creates a new guardian service
+func NewGuardianService(db *database.Database, cfg *config.Config) *GuardianService {
+	auditService := NewSecurityAuditService(db)
+
+	return &GuardianService{
+		db:                db,
+		config:            cfg.Guardian,
+		emailService:      NewEmailService(cfg, auditService),
+		auditService:      auditService,
+		invitationService: NewInvitationService(db, cfg),
+		delegation:        NewDelegationService(db),
+		scopeLookup:       NewScopeLookupService(db),
+		fieldEncryption:   NewFieldEncryptionService(db, cfg),
+	}
+}
+
+// RegisterMinorPlayer creates a player record without an account, adds it to a team and
+// links the guardian who will act on the player's behalf. The requester needs the
+// player creation grant in the team's city/sport.
+func (s *GuardianService) RegisterMinorPlayer(ctx context.Context, req *models.MinorPlayerRegistrationRequest, requesterID uuid.UUID) (*models.MinorPlayerRegistration, error) {
+	dateOfBirth, err := time.Parse("2006-01-02", req.DateOfBirth)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date of birth format")
+	}
+	if !IsMinor(dateOfBirth, time.Now()) {
+		return nil, fmt.Errorf("player is not a minor: register them with their own account")
+	}
+
+	teamID, err := uuid.Parse(req.TeamID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid team ID format")
+	}
+	var scope ResourceScope
+	err = s.db.GetConnection().QueryRow(ctx,
+		"SELECT city_id, sport_id FROM teams WHERE team_id = $1 AND is_active = true", teamID,
+	).Scan(&scope.CityID, &scope.SportID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("team not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load team: %w", err)
+	}
+
+	if err := s.delegation.Authorize(ctx, requesterID, DelegationCreate, models.RolePlayer, scope); err != nil {
+		return nil, err
+	}
+
+	emergencyContact, err := jsonObjectField("emergency_contact", req.EmergencyContact)
+	if err != nil {
+		return nil, err
+	}
+	medicalInfo, err := jsonObjectField("medical_info", req.MedicalInfo)
+	if err != nil {
+		return nil, err
+	}
+	sensitive := PlayerSensitiveFields{
+		Identification:   &req.Identification,
+		BloodType:        optionalString(req.BloodType),
+		EmergencyContact: emergencyContact,
+		MedicalInfo:      medicalInfo,
+	}
+	if err := s.fieldEncryption.EncryptPlayerFields(ctx, &sensitive); err != nil {
+		return nil, fmt.Errorf("failed to encrypt player record: %w", err)
+	}
+
+	tx, err := s.db.GetConnection().Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var playerID uuid.UUID
+	err = tx.QueryRow(ctx, `
+		INSERT INTO players (player_id, user_profile_id, first_name, last_name, date_of_birth,
+		 identification, identification_bidx, identification_type, blood_type, gender, emergency_contact, medical_info,
+		 preferred_position, is_active, created_at, updated_at)
+		VALUES (gen_random_uuid(), NULL, $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, true, NOW(), NOW())
+		RETURNING player_id
+	`, req.FirstName, req.LastName, dateOfBirth, sensitive.Identification, sensitive.IdentificationIndex,
+		req.IdentificationType, sensitive.BloodType, optionalString(req.Gender), sensitive.EmergencyContact, sensitive.MedicalInfo,
+		optionalString(req.Position)).Scan(&playerID)
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			return nil, fmt.Errorf("identification is already registered")
+		}
+		return nil, fmt.Errorf("failed to create player record: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO team_players (team_id, player_id, position, jersey_number, registered_by_user_id)
+		VALUES ($1, $2, $3, $4, $5)
+	`, teamID, playerID, optionalString(req.Position), req.JerseyNumber, requesterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add player to team: %w", err)
+	}
+
+	playerName := req.FirstName + " " + req.LastName
+	guardian, err := s.linkGuardian(ctx, tx, playerID, playerName, nil, &req.Guardian, requesterID, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.logLinked(ctx, guardian, requesterID, true)
+
+	return &models.MinorPlayerRegistration{
+		PlayerID:  playerID,
+		TeamID:    teamID,
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+		Guardian:  *guardian,
+	}, nil
+}
+
+// LinkGuardian links a guardian to an existing minor player. Scope checks are done by the
+// caller, since they depend on the player's teams.
+func (s *GuardianService) LinkGuardian(ctx context.Context, playerID uuid.UUID, contact *models.GuardianContact, requesterID uuid.UUID) (*models.PlayerGuardian, error) {
+	var firstName, lastName string
+	var dateOfBirth time.Time
+	var userProfileID *uuid.UUID
+	var anonymizedAt *time.Time
+	err := s.db.GetConnection().QueryRow(ctx, `
+		SELECT first_name, last_name, date_of_birth, user_profile_id, anonymized_at
+		FROM players WHERE player_id = $1
+	`, playerID).Scan(&firstName, &lastName, &dateOfBirth, &userProfileID, &anonymizedAt)
+	if errors.Is(err, pgx.ErrNoRows) || anonymizedAt != nil {
+		return nil, fmt.Errorf("player not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load player: %w", err)
+	}
+	if !IsMinor(dateOfBirth, time.Now()) {
+		return nil, fmt.Errorf("player is not a minor")
+	}
+
+	// Invitations of new guardian accounts carry the city/sport of one of the player's teams
+	var scope ResourceScope
+	scopes, err := s.scopeLookup.ScopesForPlayer(ctx, playerID)
+	if err != nil {
+		return nil, err
+	}
+	if len(scopes) > 0 {
+		scope = scopes[0]
+	}
+
+	tx, err := s.db.GetConnection().Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	guardian, err := s.linkGuardian(ctx, tx, playerID, firstName+" "+lastName, userProfileID, contact, requesterID, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.logLinked(ctx, guardian, requesterID, false)
+	return guardian, nil
+}
+
+// linkGuardian links the guardian account with the contact's email to the player on tx,
+// creating and inviting the account when the email is not registered, and queues the
+// consent request
+func (s *GuardianService) linkGuardian(ctx context.Context, tx pgx.Tx, playerID uuid.UUID, playerName string, playerUserID *uuid.UUID, contact *models.GuardianContact, requesterID uuid.UUID, scope ResourceScope) (*models.PlayerGuardian, error) {
+	email := strings.ToLower(strings.TrimSpace(contact.Email))
+	guardian := &models.PlayerGuardian{
+		PlayerID:       playerID,
+		Relationship:   contact.Relationship,
+		NotifyMatches:  true,
+		LinkedByUserID: &requesterID,
+	}
+
+	var locale string
+	var isActive bool
+	var anonymizedAt *time.Time
+	err := tx.QueryRow(ctx, `
+		SELECT user_id, email, first_name, last_name, preferred_locale, is_active, anonymized_at
+		FROM user_profiles WHERE lower(email) = $1
+	`, email).Scan(&guardian.GuardianUserID, &guardian.Email, &guardian.FirstName, &guardian.LastName, &locale, &isActive, &anonymizedAt)
+	switch {
+	case err == nil:
+		if !isActive || anonymizedAt != nil {
+			return nil, fmt.Errorf("guardian account is not active")
+		}
+		if playerUserID != nil && *playerUserID == guardian.GuardianUserID {
+			return nil, fmt.Errorf("a player cannot be their own guardian")
+		}
+	case errors.Is(err, pgx.ErrNoRows):
+		if contact.FirstName == "" || contact.LastName == "" {
+			return nil, fmt.Errorf("guardian first_name and last_name are required for a new account")
+		}
+		guardian.Email, guardian.FirstName, guardian.LastName = email, contact.FirstName, contact.LastName
+		locale = DefaultEmailLocale
+
+		// The password is chosen by the guardian when accepting the invitation
+		err = tx.QueryRow(ctx, `
+			INSERT INTO user_profiles (user_id, email, password_hash, first_name, last_name, phone,
+			 primary_role, is_active, account_status, failed_login_attempts, two_factor_enabled, created_at, updated_at)
+			VALUES (gen_random_uuid(), $1, $2, $3, $4, $5, $6, true, $7, 0, false, NOW(), NOW())
+			RETURNING user_id
+		`, email, InvitationPendingPasswordHash, contact.FirstName, contact.LastName, optionalString(contact.Phone),
+			models.RoleClient, models.AccountStatusActive).Scan(&guardian.GuardianUserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create guardian account: %w", err)
+		}
+
+		invitation, token, err := s.invitationService.CreateInvitation(ctx, tx, guardian.GuardianUserID, email, models.RoleClient, scope.CityID, scope.SportID, requesterID)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.invitationService.QueueInvitation(ctx, tx, invitation.InvitationID, token); err != nil {
+			return nil, err
+		}
+		guardian.InvitationSent = true
+	default:
+		return nil, fmt.Errorf("failed to look up guardian account: %w", err)
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO player_guardians (player_id, guardian_user_id, relationship, linked_by_user_id)
+		VALUES ($1, $2, $3, $4)
+		RETURNING guardian_link_id, created_at
+	`, playerID, guardian.GuardianUserID, contact.Relationship, requesterID).Scan(&guardian.GuardianLinkID, &guardian.CreatedAt)
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			return nil, fmt.Errorf("guardian already linked to this player")
+		}
+		return nil, fmt.Errorf("failed to link guardian: %w", err)
+	}
+
+	var linkedByName string
+	if err := tx.QueryRow(ctx,
+		"SELECT first_name || ' ' || last_name FROM user_profiles WHERE user_id = $1", requesterID,
+	).Scan(&linkedByName); err != nil {
+		return nil, fmt.Errorf("failed to load requester: %w", err)
+	}
+
+	if err := s.emailService.SendGuardianConsentRequestEmail(ctx, tx, guardian.Email, guardian.FirstName, playerID.String(),
+		playerName, linkedByName, s.config.ConsentDocumentVersion, locale); err != nil {
+		return nil, err
+	}
+
+	return guardian, nil
+}
+
+func (s *GuardianService) logLinked(ctx context.Context, guardian *models.PlayerGuardian, requesterID uuid.UUID, newPlayer bool) {
+	s.auditService.LogSecurityEvent(ctx, SecurityEvent{
+		EventType:   EventTypeGuardianLinked,
+		Description: "Guardian linked to a minor player",
+		UserID:      &requesterID,
+		Metadata: map[string]interface{}{
+			"player_id":        guardian.PlayerID,
+			"guardian_user_id": guardian.GuardianUserID,
+			"guardian_link_id": guardian.GuardianLinkID,
+			"relationship":     guardian.Relationship,
+			"invitation_sent":  guardian.InvitationSent,
+			"new_player":       newPlayer,
+		},
+	})
+}
+
+// UnlinkGuardian ends a guardian link. The guardian's consents for the player end with it.
+func (s *GuardianService) UnlinkGuardian(ctx context.Context, playerID, linkID, requesterID uuid.UUID) error {
+	tx, err := s.db.GetConnection().Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var guardianID uuid.UUID
+	err = tx.QueryRow(ctx, `
+		UPDATE player_guardians SET revoked_at = NOW(), revoked_by_user_id = $3
+		WHERE guardian_link_id = $1 AND player_id = $2 AND revoked_at IS NULL
+		RETURNING guardian_user_id
+	`, linkID, playerID, requesterID).Scan(&guardianID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("guardian link not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to unlink guardian: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx,
+		"UPDATE guardian_consents SET revoked_at = NOW() WHERE player_id = $1 AND guardian_user_id = $2 AND revoked_at IS NULL",
+		playerID, guardianID,
+	); err != nil {
+		return fmt.Errorf("failed to revoke consents: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.auditService.LogSecurityEvent(ctx, SecurityEvent{
+		EventType:   EventTypeGuardianUnlinked,
+		Description: "Guardian unlinked from a minor player",
+		UserID:      &requesterID,
+		Metadata: map[string]interface{}{
+			"player_id":        playerID,
+			"guardian_user_id": guardianID,
+			"guardian_link_id": linkID,
+		},
+	})
+	return nil
+}
+
+// ListGuardians returns the active guardians of a player with their latest consent
+func (s *GuardianService) ListGuardians(ctx context.Context, playerID uuid.UUID) ([]models.PlayerGuardian, error) {
+	rows, err := s.db.GetConnection().Query(ctx, `
+		SELECT g.guardian_link_id, g.player_id, g.guardian_user_id, up.email, up.first_name, up.last_name,
+		       g.relationship, g.notify_matches, g.linked_by_user_id, g.created_at,
+		       c.consent_id, c.document_version, c.consented_at
+		FROM player_guardians g
+		JOIN user_profiles up ON up.user_id = g.guardian_user_id
+		LEFT JOIN LATERAL (
+			SELECT consent_id, document_version, consented_at FROM guardian_consents
+			WHERE player_id = g.player_id AND guardian_user_id = g.guardian_user_id AND revoked_at IS NULL
+			ORDER BY consented_at DESC LIMIT 1
+		) c ON true
+		WHERE g.player_id = $1 AND g.revoked_at IS NULL
+		ORDER BY g.created_at
+	`, playerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load guardians: %w", err)
+	}
+	defer rows.Close()
+
+	guardians := []models.PlayerGuardian{}
+	for rows.Next() {
+		var guardian models.PlayerGuardian
+		var consentID *uuid.UUID
+		var documentVersion *string
+		var consentedAt *time.Time
+		if err := rows.Scan(
+			&guardian.GuardianLinkID, &guardian.PlayerID, &guardian.GuardianUserID, &guardian.Email, &guardian.FirstName,
+			&guardian.LastName, &guardian.Relationship, &guardian.NotifyMatches, &guardian.LinkedByUserID, &guardian.CreatedAt,
+			&consentID, &documentVersion, &consentedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan guardian: %w", err)
+		}
+		guardian.Consent = s.consent(consentID, guardian.PlayerID, guardian.GuardianUserID, documentVersion, consentedAt)
+		guardians = append(guardians, guardian)
+	}
+	return guardians, rows.Err()
+}
+
+// ListGuardedPlayers returns the minors a guardian is linked to, without sensitive fields
+func (s *GuardianService) ListGuardedPlayers(ctx context.Context, guardianID uuid.UUID) ([]models.GuardedPlayer, error) {
+	return s.queryGuardedPlayers(ctx, guardianID, nil, false)
+}
+
+// GetGuardedPlayer returns one of the guardian's minors including the decrypted sensitive
+// fields. The read is audited.
+func (s *GuardianService) GetGuardedPlayer(ctx context.Context, guardianID, playerID uuid.UUID) (*models.GuardedPlayer, error) {
+	players, err := s.queryGuardedPlayers(ctx, guardianID, &playerID, true)
+	if err != nil {
+		return nil, err
+	}
+	if len(players) == 0 {
+		return nil, fmt.Errorf("insufficient permissions: not a guardian of this player")
+	}
+
+	s.auditService.LogSecurityEvent(ctx, SecurityEvent{
+		EventType:   EventTypeSensitiveDataAccessed,
+		Description: fmt.Sprintf("Sensitive player fields read (%s)", DataAccessPurposeGuardian),
+		UserID:      &guardianID,
+		Metadata: map[string]interface{}{
+			"player_ids": []uuid.UUID{playerID},
+			"fields":     encryptedPlayerFields,
+			"purpose":    DataAccessPurposeGuardian,
+		},
+	})
+	return &players[0], nil
+}
+
+func (s *GuardianService) queryGuardedPlayers(ctx context.Context, guardianID uuid.UUID, playerID *uuid.UUID, revealSensitive bool) ([]models.GuardedPlayer, error) {
+	rows, err := s.db.GetConnection().Query(ctx, `
+		SELECT p.player_id, p.first_name, p.last_name, p.date_of_birth, p.email, p.phone, p.photo_url,
+		       p.height_cm, p.weight_kg::float8, p.preferred_position, p.dominant_foot,
+		       p.blood_type, p.emergency_contact, p.medical_info, g.relationship, g.notify_matches,
+		       c.consent_id, c.document_version, c.consented_at
+		FROM player_guardians g
+		JOIN players p ON p.player_id = g.player_id
+		LEFT JOIN LATERAL (
+			SELECT consent_id, document_version, consented_at FROM guardian_consents
+			WHERE player_id = g.player_id AND guardian_user_id = g.guardian_user_id AND revoked_at IS NULL
+			ORDER BY consented_at DESC LIMIT 1
+		) c ON true
+		WHERE g.guardian_user_id = $1 AND g.revoked_at IS NULL AND p.anonymized_at IS NULL
+		  AND ($2::uuid IS NULL OR p.player_id = $2)
+		ORDER BY p.first_name, p.last_name
+	`, guardianID, playerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load players: %w", err)
+	}
+	defer rows.Close()
+
+	players := []models.GuardedPlayer{}
+	for rows.Next() {
+		player := models.GuardedPlayer{ConsentDocumentVersion: s.config.ConsentDocumentVersion}
+		var sensitive PlayerSensitiveFields
+		var consentID *uuid.UUID
+		var documentVersion *string
+		var consentedAt *time.Time
+		if err := rows.Scan(
+			&player.PlayerID, &player.FirstName, &player.LastName, &player.DateOfBirth, &player.Email, &player.Phone,
+			&player.PhotoURL, &player.HeightCM, &player.WeightKG, &player.PreferredPosition, &player.DominantFoot,
+			&sensitive.BloodType, &sensitive.EmergencyContact, &sensitive.MedicalInfo, &player.Relationship,
+			&player.NotifyMatches, &consentID, &documentVersion, &consentedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan player: %w", err)
+		}
+		player.Consent = s.consent(consentID, player.PlayerID, guardianID, documentVersion, consentedAt)
+
+		if revealSensitive {
+			if err := s.fieldEncryption.DecryptPlayerFields(ctx, &sensitive); err != nil {
+				return nil, fmt.Errorf("failed to decrypt player %s: %w", player.PlayerID, err)
+			}
+			player.BloodType = sensitive.BloodType
+			if sensitive.EmergencyContact != nil {
+				player.EmergencyContact = json.RawMessage(*sensitive.EmergencyContact)
+			}
+			if sensitive.MedicalInfo != nil {
+				player.MedicalInfo = json.RawMessage(*sensitive.MedicalInfo)
+			}
+		}
+		players = append(players, player)
+	}
+	return players, rows.Err()
+}
+
+// UpdateGuardedPlayer changes the profile of a minor on their guardian's behalf
+func (s *GuardianService) UpdateGuardedPlayer(ctx context.Context, guardianID, playerID uuid.UUID, req *models.GuardedPlayerUpdateRequest) (*models.GuardedPlayer, error) {
+	if err := s.requireGuardian(ctx, guardianID, playerID); err != nil {
+		return nil, err
+	}
+
+	setClauses := []string{}
+	args := []interface{}{playerID}
+	fields := []string{}
+	set := func(column string, value interface{}) {
+		args = append(args, value)
+		setClauses = append(setClauses, fmt.Sprintf("%s = $%d", column, len(args)))
+		fields = append(fields, column)
+	}
+
+	if req.Email != nil {
+		set("email", optionalString(*req.Email))
+	}
+	if req.Phone != nil {
+		set("phone", optionalString(*req.Phone))
+	}
+	if req.PhotoURL != nil {
+		set("photo_url", optionalString(*req.PhotoURL))
+	}
+	if req.HeightCM != nil {
+		set("height_cm", *req.HeightCM)
+	}
+	if req.WeightKG != nil {
+		set("weight_kg", *req.WeightKG)
+	}
+	if req.PreferredPosition != nil {
+		set("preferred_position", optionalString(*req.PreferredPosition))
+	}
+	if req.DominantFoot != nil {
+		set("dominant_foot", optionalString(*req.DominantFoot))
+	}
+
+	// Sensitive fields are stored encrypted like at registration
+	type encryptedColumn struct {
+		column string
+		field  string
+		value  *string
+	}
+	encrypted := []encryptedColumn{}
+	if req.BloodType != nil {
+		encrypted = append(encrypted, encryptedColumn{"blood_type", FieldPlayerBloodType, optionalString(*req.BloodType)})
+	}
+	if req.EmergencyContact != nil {
+		value, err := jsonObjectField("emergency_contact", req.EmergencyContact)
+		if err != nil {
+			return nil, err
+		}
+		encrypted = append(encrypted, encryptedColumn{"emergency_contact", FieldPlayerEmergencyContact, value})
+	}
+	if req.MedicalInfo != nil {
+		value, err := jsonObjectField("medical_info", req.MedicalInfo)
+		if err != nil {
+			return nil, err
+		}
+		encrypted = append(encrypted, encryptedColumn{"medical_info", FieldPlayerMedicalInfo, value})
+	}
+	for _, column := range encrypted {
+		value, err := s.fieldEncryption.EncryptOptional(ctx, column.field, column.value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encrypt %s: %w", column.column, err)
+		}
+		set(column.column, value)
+	}
+
+	if len(setClauses) == 0 {
+		return nil, fmt.Errorf("no fields to update")
+	}
+
+	_, err := s.db.GetConnection().Exec(ctx,
+		fmt.Sprintf("UPDATE players SET %s, updated_at = NOW() WHERE player_id = $1", strings.Join(setClauses, ", ")),
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update player: %w", err)
+	}
+
+	s.auditService.LogSecurityEvent(ctx, SecurityEvent{
+		EventType:   EventTypeGuardianProfileUpdated,
+		Description: "Minor player profile updated by their guardian",
+		UserID:      &guardianID,
+		Metadata: map[string]interface{}{
+			"player_id": playerID,
+			"fields":    fields,
+		},
+	})
+
+	players, err := s.queryGuardedPlayers(ctx, guardianID, &playerID, false)
+	if err != nil || len(players) == 0 {
+		return nil, fmt.Errorf("failed to reload player: %w", err)
+	}
+	return &players[0], nil
+}
+
+// RecordConsent stores the guardian's consent to the current consent document for the
+// player, replacing any earlier consent of the same guardian
+func (s *GuardianService) RecordConsent(ctx context.Context, guardianID, playerID uuid.UUID, documentVersion string) (*models.GuardianConsent, error) {
+	if err := s.requireGuardian(ctx, guardianID, playerID); err != nil {
+		return nil, err
+	}
+	if documentVersion != s.config.ConsentDocumentVersion {
+		return nil, fmt.Errorf("consent document version is outdated: the current version is %s", s.config.ConsentDocumentVersion)
+	}
+
+	var ipAddress, userAgent *string
+	if info, ok := RequestInfoFromContext(ctx); ok {
+		ipAddress, userAgent = optionalString(info.IPAddress), optionalString(info.UserAgent)
+	}
+
+	tx, err := s.db.GetConnection().Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx,
+		"UPDATE guardian_consents SET revoked_at = NOW() WHERE player_id = $1 AND guardian_user_id = $2 AND revoked_at IS NULL",
+		playerID, guardianID,
+	); err != nil {
+		return nil, fmt.Errorf("failed to replace previous consent: %w", err)
+	}
+
+	consent := &models.GuardianConsent{PlayerID: playerID, GuardianUserID: guardianID, DocumentVersion: documentVersion, IsCurrent: true}
+	err = tx.QueryRow(ctx, `
+		INSERT INTO guardian_consents (player_id, guardian_user_id, document_version, ip_address, user_agent)
+		VALUES ($1, $2, $3, $4::inet, $5)
+		RETURNING consent_id, consented_at
+	`, playerID, guardianID, documentVersion, ipAddress, userAgent).Scan(&consent.ConsentID, &consent.ConsentedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record consent: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.auditService.LogSecurityEvent(ctx, SecurityEvent{
+		EventType:   EventTypeGuardianConsentGiven,
+		Description: "Guardian consent recorded for a minor player",
+		UserID:      &guardianID,
+		Metadata: map[string]interface{}{
+			"player_id":        playerID,
+			"consent_id":       consent.ConsentID,
+			"document_version": documentVersion,
+		},
+	})
+	return consent, nil
+}
+
+// RevokeConsent withdraws the guardian's consent. The player stays on rosters they are
+// already on, but cannot be added to new ones without another guardian's consent.
+func (s *GuardianService) RevokeConsent(ctx context.Context, guardianID, playerID uuid.UUID) error {
+	if err := s.requireGuardian(ctx, guardianID, playerID); err != nil {
+		return err
+	}
+
+	result, err := s.db.GetConnection().Exec(ctx,
+		"UPDATE guardian_consents SET revoked_at = NOW() WHERE player_id = $1 AND guardian_user_id = $2 AND revoked_at IS NULL",
+		playerID, guardianID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to revoke consent: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("no active consent")
+	}
+
+	s.auditService.LogSecurityEvent(ctx, SecurityEvent{
+		EventType:   EventTypeGuardianConsentRevoked,
+		Description: "Guardian consent revoked for a minor player",
+		UserID:      &guardianID,
+		Metadata: map[string]interface{}{
+			"player_id": playerID,
+		},
+	})
+	return nil
+}
+
+// SetMatchNotifications turns the guardian's match emails for the player on or off
+func (s *GuardianService) SetMatchNotifications(ctx context.Context, guardianID, playerID uuid.UUID, enabled bool) error {
+	result, err := s.db.GetConnection().Exec(ctx, `
+		UPDATE player_guardians SET notify_matches = $3
+		WHERE guardian_user_id = $1 AND player_id = $2 AND revoked_at IS NULL
+	`, guardianID, playerID, enabled)
+	if err != nil {
+		return fmt.Errorf("failed to update notifications: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("insufficient permissions: not a guardian of this player")
+	}
+	return nil
+}
+
+// requireGuardian checks guardianID may act for playerID: an active link to a player who
+// is still a minor
+func (s *GuardianService) requireGuardian(ctx context.Context, guardianID, playerID uuid.UUID) error {
+	var dateOfBirth time.Time
+	err := s.db.GetConnection().QueryRow(ctx, `
+		SELECT p.date_of_birth FROM player_guardians g
+		JOIN players p ON p.player_id = g.player_id
+		WHERE g.guardian_user_id = $1 AND g.player_id = $2 AND g.revoked_at IS NULL AND p.anonymized_at IS NULL
+	`, guardianID, playerID).Scan(&dateOfBirth)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("insufficient permissions: not a guardian of this player")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check guardian link: %w", err)
+	}
+	if !IsMinor(dateOfBirth, time.Now()) {
+		return fmt.Errorf("insufficient permissions: player is no longer a minor")
+	}
+	return nil
+}
+
+func (s *GuardianService) consent(consentID *uuid.UUID, playerID, guardianID uuid.UUID, documentVersion *string, consentedAt *time.Time) *models.GuardianConsent {
+	if consentID == nil {
+		return nil
+	}
+	return &models.GuardianConsent{
+		ConsentID:       *consentID,
+		PlayerID:        playerID,
+		GuardianUserID:  guardianID,
+		DocumentVersion: *documentVersion,
+		ConsentedAt:     *consentedAt,
+		IsCurrent:       *documentVersion == s.config.ConsentDocumentVersion,
+	}
+}
+
+// jsonObjectField returns raw as a string when it holds a JSON object; empty and null
+// values clear the field
+func jsonObjectField(name string, raw json.RawMessage) (*string, error) {
+	trimmed := strings.TrimSpace(string(raw))
+	if trimmed == "" || trimmed == "null" {
+		return nil, nil
+	}
+	var object map[string]interface{}
+	if err := json.Unmarshal(raw, &object); err != nil {
+		return nil, fmt.Errorf("invalid %s: must be a JSON object", name)
+	}
+	return &trimmed, nil
+}
+
+func optionalString(value string) *string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	return &value
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"mowesport/internal/config"
+	"mowesport/internal/database"
+
+	"github.com/google/uuid"
+)
+
+const identificationBackfillBatchSize = 500
+
+// IdentificationTypeService records the document type of identifications stored before
+// types were (migration 025)
+type IdentificationTypeService struct {
+	db                *database.Database
+	fieldEncryption   *FieldEncryptionService
+	securityValidator *SecurityValidationService
+}
+
+// IdentificationTypeStatus counts the identifications still stored without a type
+type IdentificationTypeStatus struct {
+	PendingUsers   int
+	PendingPlayers int
+}
+
+// IdentificationTypeBackfill is the outcome of a backfill run. Identifications whose type
+// could not be inferred are listed for manual review (set identification_type through the
+// user update endpoint or SQL).
+type IdentificationTypeBackfill struct {
+	Users         int
+	Players       int
+	ReviewUsers   []uuid.UUID
+	ReviewPlayers []uuid.UUID
+}
+
+// untypedIdentification is a stored identification without a document type
+type untypedIdentification struct {
+	id             uuid.UUID
+	identification string
+	dateOfBirth    *time.Time
+}
+
+// NewIdentificationTypeService creates a new identification type service
+func NewIdentificationTypeService(db *database.Database, cfg *config.Config) *IdentificationTypeService {
+	return &IdentificationTypeService{
+		db:                db,
+		fieldEncryption:   NewFieldEncryptionService(db, cfg),
+		securityValidator: NewSecurityValidationService(),
+	}
+}
+
+// Status counts the identifications waiting for a document type
+func (s *IdentificationTypeService) Status(ctx context.Context) (*IdentificationTypeStatus, error) {
+	status := &IdentificationTypeStatus{}
+	err := s.db.GetConnection().QueryRow(ctx, `
+		SELECT
+			(SELECT COUNT(*) FROM user_profiles WHERE identification IS NOT NULL AND identification_type IS NULL),
+			(SELECT COUNT(*) FROM players WHERE identification IS NOT NULL AND identification_type IS NULL AND anonymized_at IS NULL)
+	`).Scan(&status.PendingUsers, &status.PendingPlayers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count untyped identifications: %w", err)
+	}
+	return status, nil
+}
+
+// Backfill infers the document type of every untyped identification. Before types were
+// recorded every identification was validated as a cédula, so numbers are classified as
+// CC, or as TI or RC when the holder's date of birth (from their player record) says so.
+// Anything else is left untyped and reported. Safe to run again.
+func (s *IdentificationTypeService) Backfill(ctx context.Context) (*IdentificationTypeBackfill, error) {
+	result := &IdentificationTypeBackfill{ReviewUsers: []uuid.UUID{}, ReviewPlayers: []uuid.UUID{}}
+
+	// Users without a player record have no date of birth and can only be CC
+	userQuery := `
+		SELECT u.user_id, u.identification,
+		       (SELECT p.date_of_birth FROM players p WHERE p.user_profile_id = u.user_id ORDER BY p.created_at LIMIT 1)
+		FROM user_profiles u
+		WHERE u.identification IS NOT NULL AND u.identification_type IS NULL AND u.user_id > $1
+		ORDER BY u.user_id
+		LIMIT $2`
+	err := s.backfillTable(ctx, userQuery, "UPDATE user_profiles SET identification_type = $2 WHERE user_id = $1",
+		false, &result.Users, &result.ReviewUsers)
+	if err != nil {
+		return result, err
+	}
+
+	playerQuery := `
+		SELECT player_id, identification, date_of_birth
+		FROM players
+		WHERE identification IS NOT NULL AND identification_type IS NULL AND anonymized_at IS NULL AND player_id > $1
+		ORDER BY player_id
+		LIMIT $2`
+	err = s.backfillTable(ctx, playerQuery, "UPDATE players SET identification_type = $2 WHERE player_id = $1",
+		true, &result.Players, &result.ReviewPlayers)
+	return result, err
+}
+
+// backfillTable walks the untyped rows of one table by ID, so rows left for review are not
+// read again
+func (s *IdentificationTypeService) backfillTable(ctx context.Context, query, update string, encrypted bool, typed *int, review *[]uuid.UUID) error {
+	after := uuid.Nil
+	for {
+		batch, err := s.untypedBatch(ctx, query, after)
+		if err != nil {
+			return err
+		}
+
+		for _, row := range batch {
+			identification := row.identification
+			if encrypted {
+				identification, err = s.fieldEncryption.Decrypt(ctx, FieldPlayerIdentification, identification)
+				if err != nil {
+					return fmt.Errorf("player %s: %w", row.id, err)
+				}
+			}
+
+			identificationType := s.securityValidator.InferIdentificationType(identification, row.dateOfBirth, time.Now())
+			if identificationType == "" {
+				*review = append(*review, row.id)
+				continue
+			}
+			if _, err := s.db.GetConnection().Exec(ctx, update, row.id, identificationType); err != nil {
+				return fmt.Errorf("failed to set identification type of %s: %w", row.id, err)
+			}
+			*typed++
+		}
+
+		if len(batch) < identificationBackfillBatchSize {
+			return nil
+		}
+		after = batch[len(batch)-1].id
+	}
+}
+
+func (s *IdentificationTypeService) untypedBatch(ctx context.Context, query string, after uuid.UUID) ([]untypedIdentification, error) {
+	rows, err := s.db.GetConnection().Query(ctx, query, after, identificationBackfillBatchSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query untyped identifications: %w", err)
+	}
+	defer rows.Close()
+
+	batch := []untypedIdentification{}
+	for rows.Next() {
+		var row untypedIdentification
+		if err := rows.Scan(&row.id, &row.identification, &row.dateOfBirth); err != nil {
+			return nil, fmt.Errorf("failed to scan identification: %w", err)
+		}
+		batch = append(batch, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over identifications: %w", err)
+	}
+	return batch, nil
+}
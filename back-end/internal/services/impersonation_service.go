@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mowesport/internal/config"
+	"mowesport/internal/database"
+	"mowesport/internal/models"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// impersonationMaxTTL bounds impersonation tokens regardless of configuration
+const impersonationMaxTTL = time.Hour
+
+// ImpersonationService lets super admins see the platform as another user. Sessions
+// are short-lived access tokens flagged with the actor and subject; the JWT middleware
+// blocks credential and role changes for them and audits every request under the actor.
+type ImpersonationService struct {
+	db           *database.Database
+	config       config.ImpersonationConfig
+	auditService *SecurityAuditService
+	jwtSecret    []byte
+}
+
+// NewImpersonationService creates a new impersonation service
+func NewImpersonationService(db *database.Database, cfg *config.Config) *ImpersonationService {
+	return &ImpersonationService{
+		db:           db,
+		config:       cfg.Security.Impersonation,
+		auditService: NewSecurityAuditService(db),
+		jwtSecret:    []byte(cfg.JWTSecret),
+	}
+}
+
+// Start issues an impersonation token for subjectID on behalf of actorID
+func (s *ImpersonationService) Start(ctx context.Context, actorID, subjectID uuid.UUID, reason string) (*models.ImpersonationResponse, error) {
+	if !s.config.Enabled {
+		return nil, fmt.Errorf("impersonation disabled")
+	}
+	if actorID == subjectID {
+		return nil, fmt.Errorf("cannot impersonate yourself")
+	}
+
+	var subject models.UserProfile
+	err := s.db.GetConnection().QueryRow(ctx, `
+		SELECT user_id, email, first_name, last_name, primary_role, is_active, account_status, token_version
+		FROM user_profiles WHERE user_id = $1
+	`, subjectID).Scan(&subject.UserID, &subject.Email, &subject.FirstName,
+		&subject.LastName, &subject.PrimaryRole, &subject.IsActive, &subject.AccountStatus, &subject.TokenVersion)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("user not found")
+		}
+		return nil, fmt.Errorf("failed to load user: %w", err)
+	}
+
+	// Acting as another super admin would hand out the same powers without the restrictions
+	if subject.PrimaryRole == models.RoleSuperAdmin {
+		return nil, fmt.Errorf("cannot impersonate a super admin")
+	}
+	if !subject.IsActive {
+		return nil, fmt.Errorf("cannot impersonate an inactive account")
+	}
+
+	ttl := s.config.TokenTTL
+	if ttl <= 0 || ttl > impersonationMaxTTL {
+		ttl = 15 * time.Minute
+	}
+	sessionID := uuid.New()
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+
+	// Same shape as a regular access token so every handler sees the subject,
+	// plus the actor and session used by the middleware
+	claims := jwt.MapClaims{
+		"user_id":                  subject.UserID.String(),
+		"email":                    subject.Email,
+		"first_name":               subject.FirstName,
+		"last_name":                subject.LastName,
+		"primary_role":             subject.PrimaryRole,
+		"account_status":           subject.AccountStatus,
+		"token_version":            subject.TokenVersion,
+		"type":                     "access",
+		"impersonation":            true,
+		"impersonated_by":          actorID.String(),
+		"impersonation_session_id": sessionID.String(),
+		"exp":                      expiresAt.Unix(),
+		"iat":                      now.Unix(),
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.jwtSecret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate impersonation token: %w", err)
+	}
+
+	s.auditService.LogSecurityEvent(ctx, SecurityEvent{
+		EventType:   EventTypeImpersonationStarted,
+		Description: fmt.Sprintf("Super admin %s started impersonating user %s", actorID, subjectID),
+		UserID:      &actorID,
+		Metadata: map[string]interface{}{
+			"subject_user_id":          subjectID,
+			"subject_role":             subject.PrimaryRole,
+			"impersonation_session_id": sessionID,
+			"expires_at":               expiresAt,
+			"reason":                   reason,
+		},
+	})
+
+	return &models.ImpersonationResponse{
+		SessionID:   sessionID,
+		ActorID:     actorID,
+		SubjectID:   subject.UserID,
+		Email:       subject.Email,
+		FirstName:   subject.FirstName,
+		LastName:    subject.LastName,
+		PrimaryRole: subject.PrimaryRole,
+		Token:       token,
+		ExpiresIn:   int(ttl.Seconds()),
+		ExpiresAt:   expiresAt,
+	}, nil
+}
+
+// LogImpersonatedRequest records a request made with an impersonation token under the real actor
+func (s *ImpersonationService) LogImpersonatedRequest(ctx context.Context, impersonation Impersonation, method, path string, status int) {
+	s.auditService.LogSecurityEvent(ctx, SecurityEvent{
+		EventType:   EventTypeImpersonatedRequest,
+		Description: fmt.Sprintf("%s %s as user %s", method, path, impersonation.SubjectID),
+		UserID:      &impersonation.ActorID,
+		Metadata: map[string]interface{}{
+			"subject_user_id": impersonation.SubjectID,
+			"method":          method,
+			"path":            path,
+			"status":          status,
+		},
+	})
+}
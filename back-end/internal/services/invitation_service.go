@@ -0,0 +1,476 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"mowesport/internal/config"
+	"mowesport/internal/database"
+	"mowesport/internal/models"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// InvitationPendingPasswordHash is stored for invited accounts until they accept.
+// It is not a bcrypt hash, so no password can ever match it.
+const InvitationPendingPasswordHash = "!invitation-pending"
+
+const (
+	invitationTokenPurpose = "invitation"
+	invitationTTL          = 72 * time.Hour
+	invitationResendWait   = time.Minute
+)
+
+// InvitationService issues and redeems single-use account invitation links
+type InvitationService struct {
+	db             *database.Database
+	config         *config.Config
+	emailService   *EmailService
+	auditService   *SecurityAuditService
+	authService    *AuthService
+	passwordPolicy *PasswordPolicyService
+	signingKey     []byte
+}
+
+// NewInvitationService creates a new invitation service
+func NewInvitationService(db *database.Database, cfg *config.Config) *InvitationService {
+	auditService := NewSecurityAuditService(db)
+
+	return &InvitationService{
+		db:             db,
+		config:         cfg,
+		emailService:   NewEmailService(cfg, auditService),
+		auditService:   auditService,
+		authService:    NewAuthService(db, cfg),
+		passwordPolicy: NewPasswordPolicyService(db, cfg),
+		signingKey:     []byte(cfg.JWTSecret),
+	}
+}
+
+// CreateInvitation stores a pending invitation for a freshly registered user and returns
+// the link token. It runs on the caller's executor so it commits with the registration.
+func (s *InvitationService) CreateInvitation(ctx context.Context, q dbExecutor, userID uuid.UUID, email, roleName string, cityID, sportID *uuid.UUID, invitedBy uuid.UUID) (*models.Invitation, string, error) {
+	secret, secretHash, err := newTokenSecret()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate invitation token: %w", err)
+	}
+
+	invitation := &models.Invitation{
+		UserID:          userID,
+		Email:           email,
+		RoleName:        roleName,
+		CityID:          cityID,
+		SportID:         sportID,
+		InvitedByUserID: invitedBy,
+		Status:          models.InvitationStatusPending,
+		ExpiresAt:       time.Now().Add(invitationTTL),
+		SendCount:       1,
+	}
+
+	err = q.QueryRow(ctx, `
+		INSERT INTO user_invitations (user_id, email, role_name, city_id, sport_id, token_hash, invited_by_user_id, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING invitation_id, last_sent_at, created_at
+	`, userID, email, roleName, cityID, sportID, secretHash, invitedBy, invitation.ExpiresAt).Scan(
+		&invitation.InvitationID, &invitation.LastSentAt, &invitation.CreatedAt,
+	)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create invitation: %w", err)
+	}
+
+	return invitation, buildSignedToken(s.signingKey, invitation.InvitationID, secret, invitationTokenPurpose), nil
+}
+
+// QueueInvitation queues the invitation email on the caller's executor
+func (s *InvitationService) QueueInvitation(ctx context.Context, q dbExecutor, invitationID uuid.UUID, token string) error {
+	var data InvitationEmailData
+	var cityName, sportName *string
+	var expiresAt time.Time
+	err := q.QueryRow(ctx, `
+		SELECT i.email, u.first_name, u.preferred_locale, i.role_name, inviter.first_name || ' ' || inviter.last_name,
+		       c.name, sp.name, i.expires_at
+		FROM user_invitations i
+		JOIN user_profiles u ON u.user_id = i.user_id
+		JOIN user_profiles inviter ON inviter.user_id = i.invited_by_user_id
+		LEFT JOIN cities c ON c.city_id = i.city_id
+		LEFT JOIN sports sp ON sp.sport_id = i.sport_id
+		WHERE i.invitation_id = $1
+	`, invitationID).Scan(&data.Email, &data.FirstName, &data.Locale, &data.RoleName, &data.InviterName, &cityName, &sportName, &expiresAt)
+	if err != nil {
+		return fmt.Errorf("invitation not found")
+	}
+
+	if cityName != nil {
+		data.CityName = *cityName
+	}
+	if sportName != nil {
+		data.SportName = *sportName
+	}
+	data.AcceptURL = fmt.Sprintf("%s/accept-invitation?token=%s", s.config.FrontendURL, url.QueryEscape(token))
+	data.ExpirationHours = int(time.Until(expiresAt).Round(time.Hour).Hours())
+
+	if err := s.emailService.SendInvitationEmail(ctx, q, data); err != nil {
+		return fmt.Errorf("failed to queue invitation email: %w", err)
+	}
+
+	return nil
+}
+
+// GetInvitation returns what the invitee is shown before accepting
+func (s *InvitationService) GetInvitation(ctx context.Context, token string) (*models.InvitationDetailsResponse, error) {
+	invitationID, secret, ok := parseSignedToken(s.signingKey, token, invitationTokenPurpose)
+	if !ok {
+		return nil, fmt.Errorf("invalid or expired invitation")
+	}
+
+	var details models.InvitationDetailsResponse
+	var tokenHash string
+	err := s.db.GetConnection().QueryRow(ctx, `
+		SELECT i.email, u.first_name, u.last_name, i.role_name, c.name, sp.name, i.expires_at, i.token_hash
+		FROM user_invitations i
+		JOIN user_profiles u ON u.user_id = i.user_id
+		LEFT JOIN cities c ON c.city_id = i.city_id
+		LEFT JOIN sports sp ON sp.sport_id = i.sport_id
+		WHERE i.invitation_id = $1 AND i.accepted_at IS NULL AND i.revoked_at IS NULL AND i.expires_at > NOW()
+	`, invitationID).Scan(
+		&details.Email, &details.FirstName, &details.LastName, &details.RoleName,
+		&details.CityName, &details.SportName, &details.ExpiresAt, &tokenHash,
+	)
+	if err != nil || !tokenSecretMatches(tokenHash, secret) {
+		return nil, fmt.Errorf("invalid or expired invitation")
+	}
+
+	return &details, nil
+}
+
+// AcceptInvitation consumes the link, sets the invitee's password and marks the email verified.
+// When requested, a 2FA secret is generated; it is enabled once the code is confirmed via /auth/2fa/verify.
+func (s *InvitationService) AcceptInvitation(ctx context.Context, req *models.AcceptInvitationRequest) (*models.AcceptInvitationResponse, error) {
+	if err := s.passwordPolicy.Validate(req.Password); err != nil {
+		return nil, err
+	}
+
+	invitationID, secret, ok := parseSignedToken(s.signingKey, req.Token, invitationTokenPurpose)
+	if !ok {
+		return nil, fmt.Errorf("invalid or expired invitation")
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	tx, err := s.db.GetConnection().Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var userID uuid.UUID
+	var email, tokenHash string
+	err = tx.QueryRow(ctx, `
+		UPDATE user_invitations SET accepted_at = NOW()
+		WHERE invitation_id = $1 AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > NOW()
+		RETURNING user_id, email, token_hash
+	`, invitationID).Scan(&userID, &email, &tokenHash)
+	if err != nil || !tokenSecretMatches(tokenHash, secret) {
+		return nil, fmt.Errorf("invalid or expired invitation")
+	}
+
+	// The invitation proves ownership of the address it was sent to
+	result, err := tx.Exec(ctx, `
+		UPDATE user_profiles
+		SET password_hash = $2, email_verified_at = NOW(), failed_login_attempts = 0,
+		    locked_until = NULL, updated_at = NOW()
+		WHERE user_id = $1 AND email = $3 AND password_hash = $4
+	`, userID, string(hashedPassword), email, InvitationPendingPasswordHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set password: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return nil, fmt.Errorf("invalid or expired invitation")
+	}
+
+	if err := s.passwordPolicy.RecordChange(ctx, tx, userID, string(hashedPassword)); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.auditService.LogSecurityEvent(ctx, SecurityEvent{
+		EventType:   "INVITATION_ACCEPTED",
+		Description: "Invitation accepted and password set",
+		UserID:      &userID,
+		Metadata: map[string]interface{}{
+			"invitation_id": invitationID,
+			"email":         email,
+		},
+		Timestamp: time.Now(),
+	})
+
+	response := &models.AcceptInvitationResponse{
+		UserID:  userID,
+		Email:   email,
+		Message: "Invitation accepted. You can now sign in",
+	}
+
+	if req.EnableTwoFactor {
+		setup, err := s.authService.Setup2FA(ctx, userID)
+		if err != nil {
+			// The account is usable; 2FA can still be set up from the profile
+			response.Message = "Invitation accepted. Two-factor setup failed, enable it from your profile after signing in"
+			return response, nil
+		}
+		response.TwoFactorSetup = setup
+		response.Message = "Invitation accepted. Sign in and confirm a code from your authenticator app to enable two-factor authentication"
+	}
+
+	return response, nil
+}
+
+// ListInvitations returns invitations visible to the requester. Super admins see all of them;
+// other admins see invitations they sent or that fall within their city_admin scope.
+func (s *InvitationService) ListInvitations(ctx context.Context, req *models.InvitationListRequest, requestedBy uuid.UUID, requesterRole string) ([]models.Invitation, error) {
+	whereConditions := []string{}
+	args := []interface{}{}
+	argIndex := 1
+
+	if requesterRole != models.RoleSuperAdmin {
+		whereConditions = append(whereConditions, fmt.Sprintf(`(i.invited_by_user_id = $%d OR EXISTS (
+			SELECT 1 FROM user_roles_by_city_sport r
+			WHERE r.user_id = $%d AND r.role_name = '%s' AND r.is_active = true
+			AND (r.city_id IS NULL OR r.city_id = i.city_id)
+			AND (r.sport_id IS NULL OR r.sport_id = i.sport_id)
+		))`, argIndex, argIndex, models.RoleCityAdmin))
+		args = append(args, requestedBy)
+		argIndex++
+	}
+
+	switch req.Status {
+	case models.InvitationStatusPending:
+		whereConditions = append(whereConditions, "i.accepted_at IS NULL AND i.revoked_at IS NULL AND i.expires_at > NOW()")
+	case models.InvitationStatusExpired:
+		whereConditions = append(whereConditions, "i.accepted_at IS NULL AND i.revoked_at IS NULL AND i.expires_at <= NOW()")
+	case models.InvitationStatusAccepted:
+		whereConditions = append(whereConditions, "i.accepted_at IS NOT NULL")
+	case models.InvitationStatusRevoked:
+		whereConditions = append(whereConditions, "i.revoked_at IS NOT NULL")
+	}
+
+	query := `
+		SELECT i.invitation_id, i.user_id, i.email, u.first_name, u.last_name, i.role_name,
+		       i.city_id, i.sport_id, i.invited_by_user_id, i.expires_at, i.accepted_at,
+		       i.revoked_at, i.send_count, i.last_sent_at, i.created_at
+		FROM user_invitations i
+		JOIN user_profiles u ON u.user_id = i.user_id`
+	if len(whereConditions) > 0 {
+		query += " WHERE " + strings.Join(whereConditions, " AND ")
+	}
+	query += " ORDER BY i.created_at DESC"
+
+	rows, err := s.db.GetConnection().Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invitations: %w", err)
+	}
+	defer rows.Close()
+
+	invitations := []models.Invitation{}
+	for rows.Next() {
+		var invitation models.Invitation
+		if err := rows.Scan(
+			&invitation.InvitationID, &invitation.UserID, &invitation.Email, &invitation.FirstName,
+			&invitation.LastName, &invitation.RoleName, &invitation.CityID, &invitation.SportID,
+			&invitation.InvitedByUserID, &invitation.ExpiresAt, &invitation.AcceptedAt,
+			&invitation.RevokedAt, &invitation.SendCount, &invitation.LastSentAt, &invitation.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan invitation: %w", err)
+		}
+		invitation.Status = invitationStatus(&invitation)
+		invitations = append(invitations, invitation)
+	}
+
+	return invitations, rows.Err()
+}
+
+// ResendInvitation rotates the link secret, extends the expiry and emails the new link.
+// Links sent earlier stop working.
+func (s *InvitationService) ResendInvitation(ctx context.Context, invitationID, requestedBy uuid.UUID, requesterRole string) (*models.Invitation, error) {
+	invitation, err := s.getManageableInvitation(ctx, invitationID, requestedBy, requesterRole)
+	if err != nil {
+		return nil, err
+	}
+	if time.Since(invitation.LastSentAt) < invitationResendWait {
+		return nil, fmt.Errorf("resend throttled: wait before sending the invitation again")
+	}
+
+	secret, secretHash, err := newTokenSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate invitation token: %w", err)
+	}
+
+	tx, err := s.db.GetConnection().Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		UPDATE user_invitations
+		SET token_hash = $2, expires_at = $3, send_count = send_count + 1, last_sent_at = NOW()
+		WHERE invitation_id = $1 AND accepted_at IS NULL AND revoked_at IS NULL
+		RETURNING expires_at, send_count, last_sent_at
+	`, invitationID, secretHash, time.Now().Add(invitationTTL)).Scan(&invitation.ExpiresAt, &invitation.SendCount, &invitation.LastSentAt)
+	if err != nil {
+		return nil, fmt.Errorf("invitation is no longer pending")
+	}
+	invitation.Status = models.InvitationStatusPending
+
+	token := buildSignedToken(s.signingKey, invitationID, secret, invitationTokenPurpose)
+	if err := s.QueueInvitation(ctx, tx, invitationID, token); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.auditService.LogSecurityEvent(ctx, SecurityEvent{
+		EventType:   "INVITATION_RESENT",
+		Description: fmt.Sprintf("Invitation %s resent by %s", invitationID, requestedBy),
+		UserID:      &requestedBy,
+		Metadata: map[string]interface{}{
+			"invitation_id": invitationID,
+			"email":         invitation.Email,
+			"send_count":    invitation.SendCount,
+		},
+		Timestamp: time.Now(),
+	})
+
+	return invitation, nil
+}
+
+// RevokeInvitation cancels a pending invitation and disables the account that was created for it
+func (s *InvitationService) RevokeInvitation(ctx context.Context, invitationID, revokedBy uuid.UUID, requesterRole string) error {
+	invitation, err := s.getManageableInvitation(ctx, invitationID, revokedBy, requesterRole)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.GetConnection().Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `
+		UPDATE user_invitations SET revoked_at = NOW(), revoked_by_user_id = $2
+		WHERE invitation_id = $1 AND accepted_at IS NULL AND revoked_at IS NULL
+	`, invitationID, revokedBy)
+	if err != nil {
+		return fmt.Errorf("failed to revoke invitation: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("invitation is no longer pending")
+	}
+
+	// The account never had a usable password; keep it for history but make sure it stays closed
+	if _, err := tx.Exec(ctx, `
+		UPDATE user_profiles SET is_active = false, account_status = $2, updated_at = NOW()
+		WHERE user_id = $1 AND password_hash = $3
+	`, invitation.UserID, models.AccountStatusDisabled, InvitationPendingPasswordHash); err != nil {
+		return fmt.Errorf("failed to disable invited account: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `
+		UPDATE user_roles_by_city_sport SET is_active = false WHERE user_id = $1
+	`, invitation.UserID); err != nil {
+		return fmt.Errorf("failed to deactivate role assignments: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.auditService.LogSecurityEvent(ctx, SecurityEvent{
+		EventType:   "INVITATION_REVOKED",
+		Description: fmt.Sprintf("Invitation %s revoked by %s", invitationID, revokedBy),
+		UserID:      &revokedBy,
+		Metadata: map[string]interface{}{
+			"invitation_id": invitationID,
+			"email":         invitation.Email,
+			"invited_user":  invitation.UserID,
+		},
+		Timestamp: time.Now(),
+	})
+
+	return nil
+}
+
+// Helper methods
+
+// getManageableInvitation loads an open invitation the requester may resend or revoke
+func (s *InvitationService) getManageableInvitation(ctx context.Context, invitationID, requestedBy uuid.UUID, requesterRole string) (*models.Invitation, error) {
+	var invitation models.Invitation
+	err := s.db.GetConnection().QueryRow(ctx, `
+		SELECT i.invitation_id, i.user_id, i.email, u.first_name, u.last_name, i.role_name,
+		       i.city_id, i.sport_id, i.invited_by_user_id, i.expires_at, i.accepted_at,
+		       i.revoked_at, i.send_count, i.last_sent_at, i.created_at
+		FROM user_invitations i
+		JOIN user_profiles u ON u.user_id = i.user_id
+		WHERE i.invitation_id = $1
+	`, invitationID).Scan(
+		&invitation.InvitationID, &invitation.UserID, &invitation.Email, &invitation.FirstName,
+		&invitation.LastName, &invitation.RoleName, &invitation.CityID, &invitation.SportID,
+		&invitation.InvitedByUserID, &invitation.ExpiresAt, &invitation.AcceptedAt,
+		&invitation.RevokedAt, &invitation.SendCount, &invitation.LastSentAt, &invitation.CreatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invitation not found")
+	}
+	invitation.Status = invitationStatus(&invitation)
+
+	if requesterRole != models.RoleSuperAdmin && invitation.InvitedByUserID != requestedBy {
+		var allowed bool
+		err := s.db.GetConnection().QueryRow(ctx, `
+			SELECT EXISTS(
+				SELECT 1 FROM user_roles_by_city_sport
+				WHERE user_id = $1 AND role_name = $2 AND is_active = true
+				AND (city_id IS NULL OR city_id = $3)
+				AND (sport_id IS NULL OR sport_id = $4)
+			)
+		`, requestedBy, models.RoleCityAdmin, invitation.CityID, invitation.SportID).Scan(&allowed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check admin scope: %w", err)
+		}
+		if !allowed {
+			return nil, fmt.Errorf("insufficient permissions: invitation is outside your city/sport")
+		}
+	}
+
+	switch invitation.Status {
+	case models.InvitationStatusAccepted:
+		return nil, fmt.Errorf("invitation already accepted")
+	case models.InvitationStatusRevoked:
+		return nil, fmt.Errorf("invitation already revoked")
+	}
+
+	return &invitation, nil
+}
+
+// invitationStatus derives the lifecycle state from the timestamps
+func invitationStatus(invitation *models.Invitation) string {
+	switch {
+	case invitation.AcceptedAt != nil:
+		return models.InvitationStatusAccepted
+	case invitation.RevokedAt != nil:
+		return models.InvitationStatusRevoked
+	case time.Now().After(invitation.ExpiresAt):
+		return models.InvitationStatusExpired
+	default:
+		return models.InvitationStatusPending
+	}
+}
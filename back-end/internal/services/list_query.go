@@ -0,0 +1,141 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// userSearchDocument is the accent and case insensitive text searched in user lists. It
+// matches the trigram index of migration 026, so it must not change without it.
+const userSearchDocument = "public.f_unaccent(lower(up.first_name || ' ' || up.last_name || ' ' || up.email))"
+
+// listSort is a sortable column of a list: the SQL expression, never NULL, and its type
+// for casting cursor keys back
+type listSort struct {
+	expr    string
+	sqlType string
+}
+
+// listCursor marks the last row of a page for keyset pagination. The key is the sort
+// expression as text, so it round-trips exactly.
+type listCursor struct {
+	Sort  string    `json:"s"`
+	Order string    `json:"o"`
+	Key   string    `json:"k"`
+	ID    uuid.UUID `json:"id"`
+}
+
+// listQuery accumulates the conditions and arguments of a list query on user_profiles up
+type listQuery struct {
+	conditions []string
+	args       []interface{}
+}
+
+// arg binds a value and returns its placeholder
+func (q *listQuery) arg(value interface{}) string {
+	q.args = append(q.args, value)
+	return fmt.Sprintf("$%d", len(q.args))
+}
+
+func (q *listQuery) where(condition string) {
+	q.conditions = append(q.conditions, condition)
+}
+
+func (q *listQuery) whereClause() string {
+	if len(q.conditions) == 0 {
+		return "TRUE"
+	}
+	return strings.Join(q.conditions, " AND ")
+}
+
+// search requires every word of the search text in the user's names or email, ignoring
+// accents and case, so "gomez" finds "Gómez"
+func (q *listQuery) search(text string) {
+	escaper := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+	for _, term := range strings.Fields(text) {
+		pattern := q.arg("%" + escaper.Replace(term) + "%")
+		q.where(fmt.Sprintf("%s LIKE public.f_unaccent(lower(%s))", userSearchDocument, pattern))
+	}
+}
+
+// dateRange limits a timestamp column to dates (YYYY-MM-DD, both inclusive)
+func (q *listQuery) dateRange(column, from, to string) error {
+	if from != "" {
+		date, err := time.Parse("2006-01-02", from)
+		if err != nil {
+			return fmt.Errorf("invalid date: %s", from)
+		}
+		q.where(fmt.Sprintf("%s >= %s", column, q.arg(date)))
+	}
+	if to != "" {
+		date, err := time.Parse("2006-01-02", to)
+		if err != nil {
+			return fmt.Errorf("invalid date: %s", to)
+		}
+		q.where(fmt.Sprintf("%s < %s", column, q.arg(date.AddDate(0, 0, 1))))
+	}
+	return nil
+}
+
+// assignment keeps users with an active role assignment matching every given filter
+func (q *listQuery) assignment(cityID, sportID, roleName string) {
+	if cityID == "" && sportID == "" && roleName == "" {
+		return
+	}
+	conditions := []string{"ra.user_id = up.user_id", "ra.is_active = true"}
+	if cityID != "" {
+		conditions = append(conditions, "ra.city_id = "+q.arg(cityID))
+	}
+	if sportID != "" {
+		conditions = append(conditions, "ra.sport_id = "+q.arg(sportID))
+	}
+	if roleName != "" {
+		conditions = append(conditions, "ra.role_name = "+q.arg(roleName))
+	}
+	q.where("EXISTS (SELECT 1 FROM user_roles_by_city_sport ra WHERE " + strings.Join(conditions, " AND ") + ")")
+}
+
+// after continues a keyset page after the cursor row
+func (q *listQuery) after(sort listSort, order string, cursor *listCursor) {
+	operator := "<"
+	if order == "asc" {
+		operator = ">"
+	}
+	q.where(fmt.Sprintf("(%s, up.user_id) %s (%s::text::%s, %s)",
+		sort.expr, operator, q.arg(cursor.Key), sort.sqlType, q.arg(cursor.ID)))
+}
+
+// orderBy sorts by the expression and breaks ties by user ID, as keyset pagination needs
+func orderBy(sort listSort, order string) string {
+	direction := "DESC"
+	if order == "asc" {
+		direction = "ASC"
+	}
+	return fmt.Sprintf("%s %s, up.user_id %s", sort.expr, direction, direction)
+}
+
+func encodeListCursor(cursor listCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeListCursor reads a cursor issued for the same sort and order
+func decodeListCursor(value, sortBy, order string) (*listCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	var cursor listCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == uuid.Nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	if cursor.Sort != sortBy || cursor.Order != order {
+		return nil, fmt.Errorf("invalid cursor: issued for a different sort order")
+	}
+	return &cursor, nil
+}
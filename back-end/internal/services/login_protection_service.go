@@ -0,0 +1,420 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"mowesport/internal/config"
+	"mowesport/internal/database"
+	"mowesport/internal/models"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// loginChallengeMaxAttempts is how many codes may be tried against one challenge
+	loginChallengeMaxAttempts = 5
+	// loginChallengeResendInterval keeps repeated logins from flooding the inbox with codes
+	loginChallengeResendInterval = time.Minute
+)
+
+// LoginAssessment is the protection applied to a login before credentials are checked
+type LoginAssessment struct {
+	Blocked           bool
+	BlockedUntil      time.Time
+	ChallengeRequired bool
+	Reason            string
+}
+
+// LoginProtectionService detects brute-force and credential-stuffing attacks spread
+// across accounts. Every login outcome is recorded; failures are counted per IP, per
+// subnet and platform-wide, and crossing the SuspiciousActivityConfig thresholds first
+// requires an emailed code to finish logging in and then blocks the IP or subnet.
+type LoginProtectionService struct {
+	db           *database.Database
+	config       config.SuspiciousActivityConfig
+	emailService *EmailService
+	auditService *SecurityAuditService
+	signingKey   []byte
+}
+
+// NewLoginProtectionService creates a new login protection service
+func NewLoginProtectionService(db *database.Database, cfg *config.Config) *LoginProtectionService {
+	auditService := NewSecurityAuditService(db)
+
+	return &LoginProtectionService{
+		db:           db,
+		config:       cfg.Security.SuspiciousActivityDetection,
+		emailService: NewEmailService(cfg, auditService),
+		auditService: auditService,
+		signingKey:   []byte(cfg.JWTSecret),
+	}
+}
+
+// loginFailureCounts are the failed logins within the detection window
+type loginFailureCounts struct {
+	ip               int
+	ipDistinctEmails int
+	subnet           int
+	global           int
+}
+
+// Assess reports whether logins from ip are blocked or need a step-up challenge
+func (s *LoginProtectionService) Assess(ctx context.Context, ip string) (*LoginAssessment, error) {
+	assessment := &LoginAssessment{}
+	subnet, ok := loginSubnet(ip)
+	if !s.config.Enabled || !ok {
+		return assessment, nil
+	}
+
+	err := s.db.GetConnection().QueryRow(ctx, `
+		SELECT expires_at FROM ip_blocks
+		WHERE lifted_at IS NULL AND expires_at > NOW() AND network >>= $1::inet
+		ORDER BY expires_at DESC
+		LIMIT 1
+	`, ip).Scan(&assessment.BlockedUntil)
+	if err == nil {
+		assessment.Blocked = true
+		assessment.Reason = "ip_blocked"
+		return assessment, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to check IP blocks: %w", err)
+	}
+
+	counts, err := s.failureCounts(ctx, ip, subnet)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case reached(counts.ip, s.config.IPChallengeThreshold):
+		assessment.Reason = "ip_failures"
+	case reached(counts.subnet, s.config.SubnetChallengeThreshold):
+		assessment.Reason = "subnet_failures"
+	case reached(counts.global, s.config.GlobalChallengeThreshold):
+		assessment.Reason = "global_failures"
+	}
+	assessment.ChallengeRequired = assessment.Reason != ""
+
+	return assessment, nil
+}
+
+// RecordAttempt stores a login outcome and escalates when a failure crosses a threshold.
+// Detection problems are logged and never fail the login itself.
+func (s *LoginProtectionService) RecordAttempt(ctx context.Context, ip, email string, userID *uuid.UUID, success bool, reason string) {
+	subnet, ok := loginSubnet(ip)
+	if !s.config.Enabled || !ok {
+		return
+	}
+
+	_, err := s.db.GetConnection().Exec(ctx, `
+		INSERT INTO login_attempts (ip_address, subnet, email, user_id, success, failure_reason)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''))
+	`, ip, subnet, strings.ToLower(strings.TrimSpace(email)), userID, success, reason)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to record login attempt", "component", "login_protection", "error", err)
+		return
+	}
+	if success {
+		return
+	}
+
+	counts, err := s.failureCounts(ctx, ip, subnet)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to count login failures", "component", "login_protection", "error", err)
+		return
+	}
+
+	switch {
+	case reached(counts.ipDistinctEmails, s.config.IPDistinctAccountsThreshold):
+		s.block(ctx, ip, "password_spraying", counts.ip, counts)
+	case reached(counts.ip, s.config.IPBlockThreshold):
+		s.block(ctx, ip, "ip_failures", counts.ip, counts)
+	case reached(counts.subnet, s.config.SubnetBlockThreshold):
+		s.block(ctx, subnet, "subnet_failures", counts.subnet, counts)
+	}
+
+	// Report each challenge level once, when the failure that crosses it is recorded
+	switch {
+	case counts.ip == s.config.IPChallengeThreshold:
+		s.logEscalation(ctx, "Login challenges required for IP "+ip, "ip_failures", ip, counts)
+	case counts.subnet == s.config.SubnetChallengeThreshold:
+		s.logEscalation(ctx, "Login challenges required for subnet "+subnet, "subnet_failures", subnet, counts)
+	case counts.global == s.config.GlobalChallengeThreshold:
+		s.logEscalation(ctx, "Login challenges required platform-wide", "global_failures", "", counts)
+	}
+}
+
+// IssueChallenge emails a one-time code the user must add to the login request.
+// A code sent within the last minute is not replaced.
+func (s *LoginProtectionService) IssueChallenge(ctx context.Context, user *models.UserProfile, ip string) error {
+	conn := s.db.GetConnection()
+
+	var recent bool
+	err := conn.QueryRow(ctx, `
+		SELECT EXISTS(
+			SELECT 1 FROM login_challenges
+			WHERE user_id = $1 AND consumed_at IS NULL AND expires_at > NOW()
+			  AND created_at > NOW() - $2 * INTERVAL '1 second'
+		)
+	`, user.UserID, int(loginChallengeResendInterval.Seconds())).Scan(&recent)
+	if err != nil {
+		return fmt.Errorf("failed to check login challenges: %w", err)
+	}
+	if recent {
+		return nil
+	}
+
+	code, err := generateChallengeCode()
+	if err != nil {
+		return err
+	}
+	challengeID := uuid.New()
+	var ipAddress *string
+	if net.ParseIP(ip) != nil {
+		ipAddress = &ip
+	}
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Only the newest code is valid
+	if _, err := tx.Exec(ctx,
+		"UPDATE login_challenges SET consumed_at = NOW() WHERE user_id = $1 AND consumed_at IS NULL",
+		user.UserID); err != nil {
+		return fmt.Errorf("failed to invalidate previous challenges: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO login_challenges (challenge_id, user_id, code_hash, ip_address, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, challengeID, user.UserID, s.hashCode(challengeID, code), ipAddress,
+		time.Now().Add(s.config.ChallengeExpiration)); err != nil {
+		return fmt.Errorf("failed to create login challenge: %w", err)
+	}
+
+	if err := s.emailService.SendLoginChallengeEmail(ctx, tx, user.Email, user.FirstName, code,
+		user.PreferredLocale, s.config.ChallengeExpiration); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// VerifyChallenge checks a code against the user's current challenge and consumes it
+func (s *LoginProtectionService) VerifyChallenge(ctx context.Context, userID uuid.UUID, code string) error {
+	conn := s.db.GetConnection()
+
+	var challengeID uuid.UUID
+	var codeHash string
+	var attempts int
+	err := conn.QueryRow(ctx, `
+		UPDATE login_challenges
+		SET attempts = attempts + 1
+		WHERE challenge_id = (
+			SELECT challenge_id FROM login_challenges
+			WHERE user_id = $1 AND consumed_at IS NULL AND expires_at > NOW()
+			ORDER BY created_at DESC
+			LIMIT 1
+		)
+		RETURNING challenge_id, code_hash, attempts
+	`, userID).Scan(&challengeID, &codeHash, &attempts)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("invalid_challenge_code")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to verify login challenge: %w", err)
+	}
+
+	valid := subtle.ConstantTimeCompare([]byte(codeHash), []byte(s.hashCode(challengeID, strings.TrimSpace(code)))) == 1
+	if valid || attempts >= loginChallengeMaxAttempts {
+		conn.Exec(ctx, "UPDATE login_challenges SET consumed_at = NOW() WHERE challenge_id = $1", challengeID)
+	}
+	if !valid {
+		return fmt.Errorf("invalid_challenge_code")
+	}
+
+	return nil
+}
+
+// ListBlocks returns IP blocks newest first; activeOnly hides expired and lifted blocks
+func (s *LoginProtectionService) ListBlocks(ctx context.Context, activeOnly bool, limit int) ([]models.IPBlock, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+
+	rows, err := s.db.GetConnection().Query(ctx, `
+		SELECT block_id, network::text, reason, failure_count, expires_at, lifted_by, lifted_at, created_at
+		FROM ip_blocks
+		WHERE NOT $1 OR (lifted_at IS NULL AND expires_at > NOW())
+		ORDER BY created_at DESC
+		LIMIT $2
+	`, activeOnly, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list IP blocks: %w", err)
+	}
+	defer rows.Close()
+
+	blocks := []models.IPBlock{}
+	for rows.Next() {
+		var block models.IPBlock
+		if err := rows.Scan(&block.BlockID, &block.Network, &block.Reason, &block.FailureCount,
+			&block.ExpiresAt, &block.LiftedBy, &block.LiftedAt, &block.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan IP block: %w", err)
+		}
+		block.Active = block.LiftedAt == nil && block.ExpiresAt.After(time.Now())
+		blocks = append(blocks, block)
+	}
+
+	return blocks, rows.Err()
+}
+
+// LiftBlock ends an active block before it expires
+func (s *LoginProtectionService) LiftBlock(ctx context.Context, blockID, liftedBy uuid.UUID) error {
+	var network string
+	err := s.db.GetConnection().QueryRow(ctx, `
+		UPDATE ip_blocks SET lifted_by = $2, lifted_at = NOW()
+		WHERE block_id = $1 AND lifted_at IS NULL AND expires_at > NOW()
+		RETURNING network::text
+	`, blockID, liftedBy).Scan(&network)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("active block not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lift IP block: %w", err)
+	}
+
+	s.auditService.LogSecurityEvent(ctx, SecurityEvent{
+		EventType:   EventTypeSuspiciousActivity,
+		Description: "IP block lifted for " + network,
+		UserID:      &liftedBy,
+		Severity:    SeverityLow,
+		Metadata: map[string]interface{}{
+			"block_id": blockID,
+			"network":  network,
+		},
+	})
+
+	return nil
+}
+
+// Helper methods
+
+func (s *LoginProtectionService) failureCounts(ctx context.Context, ip, subnet string) (*loginFailureCounts, error) {
+	var counts loginFailureCounts
+	err := s.db.GetConnection().QueryRow(ctx, `
+		SELECT COUNT(*) FILTER (WHERE ip_address = $1::inet),
+		       COUNT(DISTINCT email) FILTER (WHERE ip_address = $1::inet),
+		       COUNT(*) FILTER (WHERE subnet = $2::cidr),
+		       COUNT(*)
+		FROM login_attempts
+		WHERE NOT success AND created_at >= NOW() - $3 * INTERVAL '1 second'
+	`, ip, subnet, int(s.config.LoginWindow.Seconds())).Scan(
+		&counts.ip, &counts.ipDistinctEmails, &counts.subnet, &counts.global)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count login failures: %w", err)
+	}
+
+	return &counts, nil
+}
+
+// block inserts a temporary block for network unless one is already active
+func (s *LoginProtectionService) block(ctx context.Context, network, reason string, failures int, counts *loginFailureCounts) {
+	var expiresAt time.Time
+	err := s.db.GetConnection().QueryRow(ctx, `
+		INSERT INTO ip_blocks (network, reason, failure_count, expires_at)
+		SELECT $1::cidr, $2, $3, NOW() + $4 * INTERVAL '1 second'
+		WHERE NOT EXISTS (
+			SELECT 1 FROM ip_blocks
+			WHERE lifted_at IS NULL AND expires_at > NOW() AND network >>= $1::cidr
+		)
+		RETURNING expires_at
+	`, network, reason, failures, int(s.config.IPBlockDuration.Seconds())).Scan(&expiresAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to block network", "component", "login_protection", "network", network, "error", err)
+		return
+	}
+
+	s.auditService.LogSecurityEvent(ctx, SecurityEvent{
+		EventType:   EventTypeSuspiciousActivity,
+		Description: fmt.Sprintf("Logins from %s blocked until %s (%s)", network, expiresAt.UTC().Format(time.RFC3339), reason),
+		Severity:    SeverityHigh,
+		Metadata: map[string]interface{}{
+			"action":            "ip_blocked",
+			"reason":            reason,
+			"network":           network,
+			"expires_at":        expiresAt,
+			"ip_failures":       counts.ip,
+			"distinct_accounts": counts.ipDistinctEmails,
+			"subnet_failures":   counts.subnet,
+			"global_failures":   counts.global,
+		},
+	})
+}
+
+func (s *LoginProtectionService) logEscalation(ctx context.Context, description, reason, network string, counts *loginFailureCounts) {
+	s.auditService.LogSecurityEvent(ctx, SecurityEvent{
+		EventType:   EventTypeSuspiciousActivity,
+		Description: description,
+		Severity:    SeverityMedium,
+		Metadata: map[string]interface{}{
+			"action":            "login_challenge_required",
+			"reason":            reason,
+			"network":           network,
+			"ip_failures":       counts.ip,
+			"distinct_accounts": counts.ipDistinctEmails,
+			"subnet_failures":   counts.subnet,
+			"global_failures":   counts.global,
+		},
+	})
+}
+
+func (s *LoginProtectionService) hashCode(challengeID uuid.UUID, code string) string {
+	return signToken(s.signingKey, challengeID.String()+":"+code, "login_challenge")
+}
+
+// reached reports whether count met a threshold; zero disables the threshold
+func reached(count, threshold int) bool {
+	return threshold > 0 && count >= threshold
+}
+
+// loginSubnet returns the /24 (IPv4) or /64 (IPv6) network of an address
+func loginSubnet(ip string) (string, bool) {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return "", false
+	}
+
+	if v4 := parsed.To4(); v4 != nil {
+		return (&net.IPNet{IP: v4.Mask(net.CIDRMask(24, 32)), Mask: net.CIDRMask(24, 32)}).String(), true
+	}
+	return (&net.IPNet{IP: parsed.Mask(net.CIDRMask(64, 128)), Mask: net.CIDRMask(64, 128)}).String(), true
+}
+
+// generateChallengeCode returns a random six-digit code
+func generateChallengeCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", fmt.Errorf("failed to generate challenge code: %w", err)
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
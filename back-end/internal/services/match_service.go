@@ -0,0 +1,253 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mowesport/internal/database"
+	"mowesport/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const matchListDefaultLimit = 50
+
+// matchColumns are the columns scanned by scanMatch
+const matchColumns = `
+	m.match_id, m.tournament_id, t.name, t.city_id, m.sport_id, m.home_team_id, home.name,
+	m.away_team_id, away.name, m.match_date, m.match_time::text, m.venue, m.home_team_score,
+	m.away_team_score, m.status, m.actual_start_time, m.actual_end_time, m.updated_at`
+
+// matchJoins resolve the tournament and team names of matchColumns
+const matchJoins = `
+	FROM matches m
+	JOIN tournaments t ON t.tournament_id = m.tournament_id
+	JOIN teams home ON home.team_id = m.home_team_id
+	JOIN teams away ON away.team_id = m.away_team_id`
+
+// MatchService serves match data and events to machine integrations
+type MatchService struct {
+	db *database.Database
+}
+
+// NewMatchService creates a new match service
+func NewMatchService(db *database.Database) *MatchService {
+	return &MatchService{db: db}
+}
+
+// ListMatches returns the matches within the API key's scope, most recent first
+func (s *MatchService) ListMatches(ctx context.Context, req *models.MatchListRequest, key *models.APIKeyIdentity) ([]models.Match, error) {
+	q := &listQuery{}
+	if key.TournamentID != nil {
+		q.where("m.tournament_id = " + q.arg(*key.TournamentID))
+	}
+	if key.CityID != nil {
+		q.where("t.city_id = " + q.arg(*key.CityID))
+	}
+	if key.SportID != nil {
+		q.where("t.sport_id = " + q.arg(*key.SportID))
+	}
+	if len(q.conditions) == 0 {
+		// Keys always carry a scope (api_keys_scope_check); never list every match
+		return nil, fmt.Errorf("invalid api key scope")
+	}
+
+	if req.TournamentID != "" {
+		tournamentID, err := uuid.Parse(req.TournamentID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tournament_id")
+		}
+		q.where("m.tournament_id = " + q.arg(tournamentID))
+	}
+	if req.Status != "" {
+		q.where("m.status = " + q.arg(req.Status))
+	}
+	if req.Date != "" {
+		q.where("m.match_date = " + q.arg(req.Date) + "::date")
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = matchListDefaultLimit
+	}
+
+	rows, err := s.db.GetConnection().Query(ctx, fmt.Sprintf(`
+		SELECT %s %s
+		WHERE %s
+		ORDER BY m.match_date DESC, m.match_time DESC
+		LIMIT %s
+	`, matchColumns, matchJoins, q.whereClause(), q.arg(limit)), q.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list matches: %w", err)
+	}
+	defer rows.Close()
+
+	matches := []models.Match{}
+	for rows.Next() {
+		match, err := scanMatch(rows)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, *match)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over matches: %w", err)
+	}
+	return matches, nil
+}
+
+// GetMatch returns a match with its events in the order they happened
+func (s *MatchService) GetMatch(ctx context.Context, matchID uuid.UUID) (*models.Match, error) {
+	row := s.db.GetConnection().QueryRow(ctx, fmt.Sprintf(`SELECT %s %s WHERE m.match_id = $1`, matchColumns, matchJoins), matchID)
+	match, err := scanMatch(row)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.GetConnection().Query(ctx, `
+		SELECT event_id, match_id, team_id, player_id, related_player_id, event_type, event_minute,
+		       additional_time, description, created_at
+		FROM match_events
+		WHERE match_id = $1 AND is_deleted = false
+		ORDER BY event_minute, additional_time, created_at
+	`, matchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load match events: %w", err)
+	}
+	defer rows.Close()
+
+	match.Events = []models.MatchEvent{}
+	for rows.Next() {
+		var event models.MatchEvent
+		if err := rows.Scan(
+			&event.EventID, &event.MatchID, &event.TeamID, &event.PlayerID, &event.RelatedPlayerID,
+			&event.EventType, &event.EventMinute, &event.AdditionalTime, &event.Description, &event.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan match event: %w", err)
+		}
+		match.Events = append(match.Events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over match events: %w", err)
+	}
+	return match, nil
+}
+
+// RecordEvent stores an event posted by a scorer's API key and updates the score for
+// goals. Events can only be recorded while the match is live or at half time. The
+// event is attributed to the key in event_data, since keys are not users.
+func (s *MatchService) RecordEvent(ctx context.Context, matchID uuid.UUID, req *models.MatchEventCreateRequest, key *models.APIKeyIdentity) (*models.MatchEvent, error) {
+	tx, err := s.db.GetConnection().Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var homeTeamID, awayTeamID uuid.UUID
+	var status string
+	err = tx.QueryRow(ctx, `
+		SELECT home_team_id, away_team_id, status FROM matches WHERE match_id = $1 FOR UPDATE
+	`, matchID).Scan(&homeTeamID, &awayTeamID, &status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("match not found")
+		}
+		return nil, fmt.Errorf("failed to load match: %w", err)
+	}
+	if status != models.MatchStatusLive && status != models.MatchStatusHalfTime {
+		return nil, fmt.Errorf("match is not in progress")
+	}
+	if req.TeamID != homeTeamID && req.TeamID != awayTeamID {
+		return nil, fmt.Errorf("invalid team_id: team does not play this match")
+	}
+
+	for _, playerID := range []*uuid.UUID{req.PlayerID, req.RelatedPlayerID} {
+		if playerID == nil {
+			continue
+		}
+		var onTeam bool
+		err := tx.QueryRow(ctx, `
+			SELECT EXISTS(SELECT 1 FROM team_players WHERE team_id = $1 AND player_id = $2 AND is_active = true)
+		`, req.TeamID, *playerID).Scan(&onTeam)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check player: %w", err)
+		}
+		if !onTeam {
+			return nil, fmt.Errorf("invalid player_id: player is not on the team")
+		}
+	}
+
+	var description *string
+	if req.Description != "" {
+		description = &req.Description
+	}
+
+	event := models.MatchEvent{
+		MatchID:         matchID,
+		TeamID:          req.TeamID,
+		PlayerID:        req.PlayerID,
+		RelatedPlayerID: req.RelatedPlayerID,
+		EventType:       req.EventType,
+		EventMinute:     req.EventMinute,
+		AdditionalTime:  req.AdditionalTime,
+		Description:     description,
+	}
+	err = tx.QueryRow(ctx, `
+		INSERT INTO match_events (
+			match_id, team_id, player_id, related_player_id, event_type, event_minute,
+			additional_time, description, event_data
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, jsonb_build_object('api_key_id', $9::text))
+		RETURNING event_id, created_at
+	`, matchID, req.TeamID, req.PlayerID, req.RelatedPlayerID, req.EventType, req.EventMinute,
+		req.AdditionalTime, description, key.APIKeyID.String()).Scan(&event.EventID, &event.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record match event: %w", err)
+	}
+
+	// Own goals count for the other team
+	scoringTeam := uuid.Nil
+	switch req.EventType {
+	case "goal", "penalty_goal":
+		scoringTeam = req.TeamID
+	case "own_goal":
+		scoringTeam = homeTeamID
+		if req.TeamID == homeTeamID {
+			scoringTeam = awayTeamID
+		}
+	}
+	if scoringTeam != uuid.Nil {
+		_, err = tx.Exec(ctx, `
+			UPDATE matches
+			SET home_team_score = home_team_score + CASE WHEN home_team_id = $2 THEN 1 ELSE 0 END,
+			    away_team_score = away_team_score + CASE WHEN away_team_id = $2 THEN 1 ELSE 0 END
+			WHERE match_id = $1
+		`, matchID, scoringTeam)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update score: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return &event, nil
+}
+
+// scanMatch reads a row of matchColumns
+func scanMatch(row pgx.Row) (*models.Match, error) {
+	var match models.Match
+	err := row.Scan(
+		&match.MatchID, &match.TournamentID, &match.TournamentName, &match.CityID, &match.SportID,
+		&match.HomeTeamID, &match.HomeTeamName, &match.AwayTeamID, &match.AwayTeamName, &match.MatchDate,
+		&match.MatchTime, &match.Venue, &match.HomeTeamScore, &match.AwayTeamScore, &match.Status,
+		&match.ActualStartTime, &match.ActualEndTime, &match.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("match not found")
+		}
+		return nil, fmt.Errorf("failed to scan match: %w", err)
+	}
+	return &match, nil
+}
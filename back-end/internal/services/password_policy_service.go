@@ -0,0 +1,215 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	_ "embed"
+	"fmt"
+	"mowesport/internal/config"
+	"mowesport/internal/database"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// commonPasswordList is shipped with the binary so breached and easily guessed
+// passwords are rejected without calling an external service
+//
+//go:embed passwords/common_passwords.txt
+var commonPasswordList string
+
+var commonPasswords = loadCommonPasswords(commonPasswordList)
+
+// dbExecer is satisfied by both *pgx.Conn and pgx.Tx for statements without results
+type dbExecer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// PasswordPolicyService enforces PasswordPolicyConfig wherever a user chooses a password:
+// signup, change-password, reset-password and invitation acceptance. It checks the
+// configured rules and the bundled common password list, rejects reuse of the last
+// HistoryCount passwords and reports when a password is older than ExpirationDays.
+type PasswordPolicyService struct {
+	db     *database.Database
+	policy config.PasswordPolicyConfig
+}
+
+// NewPasswordPolicyService creates a new password policy service
+func NewPasswordPolicyService(db *database.Database, cfg *config.Config) *PasswordPolicyService {
+	return &PasswordPolicyService{
+		db:     db,
+		policy: cfg.Security.PasswordPolicy,
+	}
+}
+
+// Validate checks a new password against the configured rules and the common password list
+func (s *PasswordPolicyService) Validate(password string) error {
+	length := utf8.RuneCountInString(password)
+	if length < s.policy.MinLength {
+		return fmt.Errorf("weak password: must be at least %d characters long", s.policy.MinLength)
+	}
+	if s.policy.MaxLength > 0 && length > s.policy.MaxLength {
+		return fmt.Errorf("weak password: must not exceed %d characters", s.policy.MaxLength)
+	}
+
+	var hasUpper, hasLower, hasNumber, hasSpecial bool
+	for _, char := range password {
+		switch {
+		case unicode.IsUpper(char):
+			hasUpper = true
+		case unicode.IsLower(char):
+			hasLower = true
+		case unicode.IsNumber(char):
+			hasNumber = true
+		case unicode.IsPunct(char) || unicode.IsSymbol(char):
+			hasSpecial = true
+		}
+	}
+
+	if s.policy.RequireUppercase && !hasUpper {
+		return fmt.Errorf("weak password: must contain at least one uppercase letter")
+	}
+	if s.policy.RequireLowercase && !hasLower {
+		return fmt.Errorf("weak password: must contain at least one lowercase letter")
+	}
+	if s.policy.RequireNumbers && !hasNumber {
+		return fmt.Errorf("weak password: must contain at least one number")
+	}
+	if s.policy.RequireSpecial && !hasSpecial {
+		return fmt.Errorf("weak password: must contain at least one special character")
+	}
+
+	if s.policy.RejectBreached && isCommonPassword(password) {
+		return fmt.Errorf("breached password: this password is too common or has appeared in a data breach")
+	}
+
+	return nil
+}
+
+// ValidateForUser runs Validate and then rejects the user's current password or any
+// of their last HistoryCount passwords
+func (s *PasswordPolicyService) ValidateForUser(ctx context.Context, userID uuid.UUID, password string) error {
+	if err := s.Validate(password); err != nil {
+		return err
+	}
+	if s.policy.HistoryCount <= 0 {
+		return nil
+	}
+
+	// The current hash is checked too, for accounts created before history was kept
+	rows, err := s.db.GetConnection().Query(ctx, `
+		SELECT password_hash FROM user_profiles WHERE user_id = $1
+		UNION ALL
+		(SELECT password_hash FROM password_history WHERE user_id = $1
+		 ORDER BY created_at DESC LIMIT $2)
+	`, userID, s.policy.HistoryCount)
+	if err != nil {
+		return fmt.Errorf("failed to load password history: %w", err)
+	}
+	var hashes []string
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan password history: %w", err)
+		}
+		hashes = append(hashes, hash)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to load password history: %w", err)
+	}
+
+	for _, hash := range hashes {
+		if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil {
+			return fmt.Errorf("password reused: must differ from your last %d passwords", s.policy.HistoryCount)
+		}
+	}
+
+	return nil
+}
+
+// RecordChange stores the new hash in the user's history, prunes entries beyond
+// HistoryCount and restarts the expiration period. It runs on the caller's executor
+// so it commits together with the password update.
+func (s *PasswordPolicyService) RecordChange(ctx context.Context, q dbExecer, userID uuid.UUID, passwordHash string) error {
+	if _, err := q.Exec(ctx,
+		"UPDATE user_profiles SET password_changed_at = NOW() WHERE user_id = $1",
+		userID,
+	); err != nil {
+		return fmt.Errorf("failed to update password change time: %w", err)
+	}
+
+	if s.policy.HistoryCount > 0 {
+		if _, err := q.Exec(ctx,
+			"INSERT INTO password_history (user_id, password_hash) VALUES ($1, $2)",
+			userID, passwordHash,
+		); err != nil {
+			return fmt.Errorf("failed to record password history: %w", err)
+		}
+	}
+
+	if _, err := q.Exec(ctx, `
+		DELETE FROM password_history
+		WHERE user_id = $1 AND history_id NOT IN (
+			SELECT history_id FROM password_history WHERE user_id = $1
+			ORDER BY created_at DESC LIMIT $2
+		)
+	`, userID, s.policy.HistoryCount); err != nil {
+		return fmt.Errorf("failed to prune password history: %w", err)
+	}
+
+	return nil
+}
+
+// ExpiresAt returns when a password set at changedAt must be changed, or nil when
+// forced rotation is disabled
+func (s *PasswordPolicyService) ExpiresAt(changedAt time.Time) *time.Time {
+	if s.policy.ExpirationDays <= 0 || changedAt.IsZero() {
+		return nil
+	}
+	expiresAt := changedAt.AddDate(0, 0, s.policy.ExpirationDays)
+	return &expiresAt
+}
+
+// IsExpired reports whether a password set at changedAt is past ExpirationDays
+func (s *PasswordPolicyService) IsExpired(changedAt time.Time) bool {
+	expiresAt := s.ExpiresAt(changedAt)
+	return expiresAt != nil && time.Now().After(*expiresAt)
+}
+
+// isCommonPassword matches the lowercased password, and the same value with leading
+// and trailing digits and symbols removed, against the common password list
+func isCommonPassword(password string) bool {
+	candidate := strings.ToLower(password)
+	if _, ok := commonPasswords[candidate]; ok {
+		return true
+	}
+
+	base := strings.TrimFunc(candidate, func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	if base == "" {
+		return false
+	}
+	_, ok := commonPasswords[base]
+	return ok
+}
+
+func loadCommonPasswords(list string) map[string]struct{} {
+	passwords := make(map[string]struct{})
+	scanner := bufio.NewScanner(strings.NewReader(list))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		passwords[strings.ToLower(line)] = struct{}{}
+	}
+	return passwords
+}
@@ -0,0 +1,753 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mowesport/internal/config"
+	"mowesport/internal/database"
+	"mowesport/internal/models"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Purposes recorded when sensitive player fields are read
+const (
+	DataAccessPurposeSelfExport  = "data_subject_export"
+	DataAccessPurposeAdminExport = "admin_data_subject_request"
+)
+
+// SensitivePlayerFields are the player fields whose every read is audited
+var SensitivePlayerFields = []string{"identification", "date_of_birth", "blood_type", "medical_info", "emergency_contact"}
+
+// encryptedPlayerFields are only returned decrypted to the player and to holders of the
+// medical data permission
+var encryptedPlayerFields = []string{"identification", "blood_type", "medical_info", "emergency_contact"}
+
+const dataDeletionBatchSize = 20
+
+// anonymizedAuditKeys are removed from the metadata of the user's audit events
+var anonymizedAuditKeys = []string{"email", "old_email", "new_email", "phone", "identification", "first_name", "last_name"}
+
+// PersonalDataService answers data-subject requests under Ley 1581 de 2012 (Habeas Data):
+// exports of everything stored about a user, and deletion requests that anonymize personal
+// fields after admin approval and a grace period while keeping match statistics.
+type PersonalDataService struct {
+	db              *database.Database
+	config          config.PrivacyConfig
+	emailService    *EmailService
+	auditService    *SecurityAuditService
+	fieldEncryption *FieldEncryptionService
+	viewPermissions *ViewPermissionService
+}
+
+// NewPersonalDataService creates a new personal data service
+func NewPersonalDataService(db *database.Database, cfg *config.Config) *PersonalDataService {
+	auditService := NewSecurityAuditService(db)
+
+	return &PersonalDataService{
+		db:              db,
+		config:          cfg.Privacy,
+		emailService:    NewEmailService(cfg, auditService),
+		auditService:    auditService,
+		fieldEncryption: NewFieldEncryptionService(db, cfg),
+		viewPermissions: NewViewPermissionService(db),
+	}
+}
+
+// Export collects the personal data of userID. Encrypted player fields are decrypted for
+// the user themselves and for requesters with the medical data permission, and withheld
+// otherwise. Reading the linked player records is logged as sensitive data access on
+// behalf of requestedBy.
+func (s *PersonalDataService) Export(ctx context.Context, userID, requestedBy uuid.UUID, purpose string) (*models.PersonalDataExport, error) {
+	conn := s.db.GetConnection()
+	export := &models.PersonalDataExport{
+		GeneratedAt:      time.Now(),
+		Players:          []models.PersonalDataPlayer{},
+		TeamMemberships:  []models.PersonalDataTeamMembership{},
+		Roles:            []models.PersonalDataRole{},
+		Statistics:       []models.PersonalDataStatistic{},
+		AuditEntries:     []models.PersonalDataAuditEntry{},
+		DeletionRequests: []models.DataDeletionRequest{},
+	}
+
+	p := &export.Profile
+	err := conn.QueryRow(ctx, `
+		SELECT user_id, email, first_name, last_name, phone, identification, identification_type, photo_url, primary_role,
+		       account_status, preferred_locale, two_factor_enabled, email_verified_at, last_login_at,
+		       created_at, updated_at
+		FROM user_profiles WHERE user_id = $1
+	`, userID).Scan(
+		&p.UserID, &p.Email, &p.FirstName, &p.LastName, &p.Phone, &p.Identification, &p.IdentificationType, &p.PhotoURL, &p.PrimaryRole,
+		&p.AccountStatus, &p.PreferredLocale, &p.TwoFactorEnabled, &p.EmailVerifiedAt, &p.LastLoginAt,
+		&p.CreatedAt, &p.UpdatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("user not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load profile: %w", err)
+	}
+
+	revealSensitive := requestedBy == userID
+	if !revealSensitive {
+		if revealSensitive, err = s.viewPermissions.HasViewPermission(ctx, requestedBy, ViewMedicalData); err != nil {
+			return nil, fmt.Errorf("failed to check medical data permission: %w", err)
+		}
+	}
+
+	if err := s.loadPlayers(ctx, userID, revealSensitive, export); err != nil {
+		return nil, err
+	}
+	if err := s.loadTeamMemberships(ctx, userID, export); err != nil {
+		return nil, err
+	}
+	if err := s.loadRoles(ctx, userID, export); err != nil {
+		return nil, err
+	}
+	if err := s.loadStatistics(ctx, userID, export); err != nil {
+		return nil, err
+	}
+	if err := s.loadAuditEntries(ctx, userID, export); err != nil {
+		return nil, err
+	}
+	if export.DeletionRequests, err = s.GetDeletionRequests(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	if len(export.Players) > 0 {
+		playerIDs := make([]uuid.UUID, 0, len(export.Players))
+		for _, player := range export.Players {
+			playerIDs = append(playerIDs, player.PlayerID)
+		}
+		fields := SensitivePlayerFields
+		if !revealSensitive {
+			fields = []string{"date_of_birth"}
+		}
+		s.LogSensitiveAccess(ctx, requestedBy, userID, playerIDs, fields, purpose)
+	}
+
+	s.auditService.LogSecurityEvent(ctx, SecurityEvent{
+		EventType:   EventTypePersonalDataExported,
+		Description: "Personal data exported",
+		UserID:      &userID,
+		Metadata: map[string]interface{}{
+			"requested_by": requestedBy,
+			"purpose":      purpose,
+			"players":      len(export.Players),
+		},
+	})
+
+	return export, nil
+}
+
+func (s *PersonalDataService) loadPlayers(ctx context.Context, userID uuid.UUID, revealSensitive bool, export *models.PersonalDataExport) error {
+	rows, err := s.db.GetConnection().Query(ctx, `
+		SELECT player_id, first_name, last_name, date_of_birth, identification, identification_type, blood_type, gender,
+		       nationality, email, phone, photo_url, height_cm, weight_kg::float8, emergency_contact,
+		       medical_info, preferred_position, dominant_foot, created_at
+		FROM players WHERE user_profile_id = $1
+		ORDER BY created_at
+	`, userID)
+	if err != nil {
+		return fmt.Errorf("failed to load players: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var player models.PersonalDataPlayer
+		var sensitive PlayerSensitiveFields
+		if err := rows.Scan(
+			&player.PlayerID, &player.FirstName, &player.LastName, &player.DateOfBirth, &sensitive.Identification,
+			&player.IdentificationType, &sensitive.BloodType, &player.Gender, &player.Nationality, &player.Email, &player.Phone, &player.PhotoURL,
+			&player.HeightCM, &player.WeightKG, &sensitive.EmergencyContact, &sensitive.MedicalInfo, &player.PreferredPosition,
+			&player.DominantFoot, &player.CreatedAt,
+		); err != nil {
+			return fmt.Errorf("failed to scan player: %w", err)
+		}
+
+		if !revealSensitive {
+			player.RedactedFields = encryptedPlayerFields
+			export.Players = append(export.Players, player)
+			continue
+		}
+
+		if err := s.fieldEncryption.DecryptPlayerFields(ctx, &sensitive); err != nil {
+			return fmt.Errorf("failed to decrypt player %s: %w", player.PlayerID, err)
+		}
+		player.Identification = sensitive.Identification
+		player.BloodType = sensitive.BloodType
+		if sensitive.EmergencyContact != nil {
+			player.EmergencyContact = json.RawMessage(*sensitive.EmergencyContact)
+		}
+		if sensitive.MedicalInfo != nil {
+			player.MedicalInfo = json.RawMessage(*sensitive.MedicalInfo)
+		}
+		export.Players = append(export.Players, player)
+	}
+	return rows.Err()
+}
+
+func (s *PersonalDataService) loadTeamMemberships(ctx context.Context, userID uuid.UUID, export *models.PersonalDataExport) error {
+	rows, err := s.db.GetConnection().Query(ctx, `
+		SELECT t.team_id, t.name, tp.player_id, tp.join_date, tp.leave_date, tp.position, tp.jersey_number, tp.is_active
+		FROM team_players tp
+		JOIN players p ON p.player_id = tp.player_id
+		JOIN teams t ON t.team_id = tp.team_id
+		WHERE p.user_profile_id = $1
+		ORDER BY tp.join_date
+	`, userID)
+	if err != nil {
+		return fmt.Errorf("failed to load team memberships: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var m models.PersonalDataTeamMembership
+		if err := rows.Scan(&m.TeamID, &m.TeamName, &m.PlayerID, &m.JoinDate, &m.LeaveDate, &m.Position, &m.JerseyNumber, &m.IsActive); err != nil {
+			return fmt.Errorf("failed to scan team membership: %w", err)
+		}
+		export.TeamMemberships = append(export.TeamMemberships, m)
+	}
+	return rows.Err()
+}
+
+func (s *PersonalDataService) loadRoles(ctx context.Context, userID uuid.UUID, export *models.PersonalDataExport) error {
+	rows, err := s.db.GetConnection().Query(ctx, `
+		SELECT r.role_name, c.name, sp.name, r.tournament_id, r.is_active, r.created_at
+		FROM user_roles_by_city_sport r
+		LEFT JOIN cities c ON c.city_id = r.city_id
+		LEFT JOIN sports sp ON sp.sport_id = r.sport_id
+		WHERE r.user_id = $1
+		ORDER BY r.created_at
+	`, userID)
+	if err != nil {
+		return fmt.Errorf("failed to load roles: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var role models.PersonalDataRole
+		if err := rows.Scan(&role.RoleName, &role.CityName, &role.SportName, &role.TournamentID, &role.IsActive, &role.CreatedAt); err != nil {
+			return fmt.Errorf("failed to scan role: %w", err)
+		}
+		export.Roles = append(export.Roles, role)
+	}
+	return rows.Err()
+}
+
+func (s *PersonalDataService) loadStatistics(ctx context.Context, userID uuid.UUID, export *models.PersonalDataExport) error {
+	rows, err := s.db.GetConnection().Query(ctx, `
+		SELECT ps.player_id, tr.name, t.name, ps.matches_played, ps.minutes_played, ps.goals_scored,
+		       ps.assists, ps.yellow_cards, ps.red_cards, ps.wins, ps.draws, ps.losses
+		FROM player_statistics ps
+		JOIN players p ON p.player_id = ps.player_id
+		JOIN tournaments tr ON tr.tournament_id = ps.tournament_id
+		JOIN teams t ON t.team_id = ps.team_id
+		WHERE p.user_profile_id = $1
+		ORDER BY tr.start_date
+	`, userID)
+	if err != nil {
+		return fmt.Errorf("failed to load statistics: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var st models.PersonalDataStatistic
+		if err := rows.Scan(
+			&st.PlayerID, &st.TournamentName, &st.TeamName, &st.MatchesPlayed, &st.MinutesPlayed, &st.GoalsScored,
+			&st.Assists, &st.YellowCards, &st.RedCards, &st.Wins, &st.Draws, &st.Losses,
+		); err != nil {
+			return fmt.Errorf("failed to scan statistics: %w", err)
+		}
+		export.Statistics = append(export.Statistics, st)
+	}
+	return rows.Err()
+}
+
+func (s *PersonalDataService) loadAuditEntries(ctx context.Context, userID uuid.UUID, export *models.PersonalDataExport) error {
+	rows, err := s.db.GetConnection().Query(ctx, `
+		SELECT event_type, description, host(ip_address), user_agent, timestamp
+		FROM security_audit_log WHERE user_id = $1
+		ORDER BY timestamp DESC
+	`, userID)
+	if err != nil {
+		return fmt.Errorf("failed to load audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entry models.PersonalDataAuditEntry
+		if err := rows.Scan(&entry.EventType, &entry.Description, &entry.IPAddress, &entry.UserAgent, &entry.Timestamp); err != nil {
+			return fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		export.AuditEntries = append(export.AuditEntries, entry)
+	}
+	return rows.Err()
+}
+
+// ExportArchive packs an export as a ZIP with one JSON file per section
+func ExportArchive(export *models.PersonalDataExport) ([]byte, error) {
+	sections := []struct {
+		name string
+		data interface{}
+	}{
+		{"profile.json", export.Profile},
+		{"players.json", export.Players},
+		{"team_memberships.json", export.TeamMemberships},
+		{"roles.json", export.Roles},
+		{"statistics.json", export.Statistics},
+		{"audit_entries.json", export.AuditEntries},
+		{"deletion_requests.json", export.DeletionRequests},
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, section := range sections {
+		file, err := archive.CreateHeader(&zip.FileHeader{Name: section.name, Method: zip.Deflate, Modified: export.GeneratedAt})
+		if err != nil {
+			return nil, fmt.Errorf("failed to add %s: %w", section.name, err)
+		}
+		encoder := json.NewEncoder(file)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(section.data); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", section.name, err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish archive: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// LogSensitiveAccess audits a read of sensitive player fields. Every code path returning
+// those fields must call it.
+func (s *PersonalDataService) LogSensitiveAccess(ctx context.Context, actorID, subjectUserID uuid.UUID, playerIDs []uuid.UUID, fields []string, purpose string) {
+	s.auditService.LogSecurityEvent(ctx, SecurityEvent{
+		EventType:   EventTypeSensitiveDataAccessed,
+		Description: fmt.Sprintf("Sensitive player fields read (%s)", purpose),
+		UserID:      &actorID,
+		Metadata: map[string]interface{}{
+			"subject_user_id": subjectUserID,
+			"player_ids":      playerIDs,
+			"fields":          fields,
+			"purpose":         purpose,
+			"self_access":     actorID == subjectUserID,
+		},
+	})
+}
+
+// RequestDeletion opens a deletion request for the user; an admin has to approve it
+func (s *PersonalDataService) RequestDeletion(ctx context.Context, userID uuid.UUID, reason string) (*models.DataDeletionRequest, error) {
+	var anonymizedAt *time.Time
+	var role string
+	err := s.db.GetConnection().QueryRow(ctx,
+		"SELECT anonymized_at, primary_role FROM user_profiles WHERE user_id = $1", userID,
+	).Scan(&anonymizedAt, &role)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("user not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load user: %w", err)
+	}
+	if anonymizedAt != nil {
+		return nil, fmt.Errorf("personal data already anonymized")
+	}
+	if role == models.RoleSuperAdmin {
+		return nil, fmt.Errorf("super admin accounts must be handed over before deletion")
+	}
+
+	var reasonValue *string
+	if reason = strings.TrimSpace(reason); reason != "" {
+		reasonValue = &reason
+	}
+
+	request := &models.DataDeletionRequest{UserID: userID, Reason: reasonValue, Status: models.DataDeletionStatusPending}
+	err = s.db.GetConnection().QueryRow(ctx, `
+		INSERT INTO data_deletion_requests (user_id, reason)
+		VALUES ($1, $2)
+		RETURNING request_id, requested_at
+	`, userID, reasonValue).Scan(&request.RequestID, &request.RequestedAt)
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			return nil, fmt.Errorf("deletion request already open")
+		}
+		return nil, fmt.Errorf("failed to create deletion request: %w", err)
+	}
+
+	s.auditService.LogSecurityEvent(ctx, SecurityEvent{
+		EventType:   EventTypeDataDeletionRequested,
+		Description: "Personal data deletion requested",
+		UserID:      &userID,
+		Metadata: map[string]interface{}{
+			"request_id": request.RequestID,
+		},
+	})
+
+	return request, nil
+}
+
+// GetDeletionRequests returns the deletion requests of a user, newest first
+func (s *PersonalDataService) GetDeletionRequests(ctx context.Context, userID uuid.UUID) ([]models.DataDeletionRequest, error) {
+	return s.queryDeletionRequests(ctx, "WHERE r.user_id = $1", userID)
+}
+
+// CancelDeletion withdraws the user's open request, as long as the grace period has not ended
+func (s *PersonalDataService) CancelDeletion(ctx context.Context, userID uuid.UUID) error {
+	result, err := s.db.GetConnection().Exec(ctx, `
+		UPDATE data_deletion_requests SET status = $2
+		WHERE user_id = $1 AND (status = $3 OR (status = $4 AND scheduled_for > NOW()))
+	`, userID, models.DataDeletionStatusCancelled, models.DataDeletionStatusPending, models.DataDeletionStatusApproved)
+	if err != nil {
+		return fmt.Errorf("failed to cancel deletion request: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("no open deletion request")
+	}
+
+	s.auditService.LogSecurityEvent(ctx, SecurityEvent{
+		EventType:   EventTypeDataDeletionRequested,
+		Description: "Personal data deletion request cancelled by the user",
+		UserID:      &userID,
+		Metadata: map[string]interface{}{
+			"status": models.DataDeletionStatusCancelled,
+		},
+	})
+
+	return nil
+}
+
+// ListDeletionRequests returns deletion requests for admin review, optionally filtered by status
+func (s *PersonalDataService) ListDeletionRequests(ctx context.Context, req *models.DataDeletionListRequest) ([]models.DataDeletionRequest, error) {
+	if req.Status != "" {
+		return s.queryDeletionRequests(ctx, "WHERE r.status = $1", req.Status)
+	}
+	return s.queryDeletionRequests(ctx, "")
+}
+
+func (s *PersonalDataService) queryDeletionRequests(ctx context.Context, where string, args ...interface{}) ([]models.DataDeletionRequest, error) {
+	rows, err := s.db.GetConnection().Query(ctx, `
+		SELECT r.request_id, r.user_id, u.email, u.first_name, u.last_name, r.reason, r.status, r.requested_at,
+		       r.reviewed_by_user_id, r.reviewed_at, r.review_notes, r.scheduled_for, r.completed_at, r.last_error
+		FROM data_deletion_requests r
+		JOIN user_profiles u ON u.user_id = r.user_id
+		`+where+`
+		ORDER BY r.requested_at DESC
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query deletion requests: %w", err)
+	}
+	defer rows.Close()
+
+	requests := []models.DataDeletionRequest{}
+	for rows.Next() {
+		var r models.DataDeletionRequest
+		if err := rows.Scan(
+			&r.RequestID, &r.UserID, &r.Email, &r.FirstName, &r.LastName, &r.Reason, &r.Status, &r.RequestedAt,
+			&r.ReviewedByUserID, &r.ReviewedAt, &r.ReviewNotes, &r.ScheduledFor, &r.CompletedAt, &r.LastError,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan deletion request: %w", err)
+		}
+		requests = append(requests, r)
+	}
+	return requests, rows.Err()
+}
+
+// ApproveDeletion schedules anonymization at the end of the grace period and emails the user
+func (s *PersonalDataService) ApproveDeletion(ctx context.Context, requestID, reviewerID uuid.UUID, notes string) (*models.DataDeletionRequest, error) {
+	return s.reviewDeletion(ctx, requestID, reviewerID, notes, true)
+}
+
+// RejectDeletion refuses a pending deletion request; the notes explain why to the user
+func (s *PersonalDataService) RejectDeletion(ctx context.Context, requestID, reviewerID uuid.UUID, notes string) (*models.DataDeletionRequest, error) {
+	if strings.TrimSpace(notes) == "" {
+		return nil, fmt.Errorf("review notes are required to reject a deletion request")
+	}
+	return s.reviewDeletion(ctx, requestID, reviewerID, notes, false)
+}
+
+func (s *PersonalDataService) reviewDeletion(ctx context.Context, requestID, reviewerID uuid.UUID, notes string, approve bool) (*models.DataDeletionRequest, error) {
+	tx, err := s.db.GetConnection().Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var userID uuid.UUID
+	var status, email, firstName, locale string
+	err = tx.QueryRow(ctx, `
+		SELECT r.user_id, r.status, u.email, u.first_name, u.preferred_locale
+		FROM data_deletion_requests r
+		JOIN user_profiles u ON u.user_id = r.user_id
+		WHERE r.request_id = $1
+		FOR UPDATE OF r
+	`, requestID).Scan(&userID, &status, &email, &firstName, &locale)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("deletion request not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load deletion request: %w", err)
+	}
+	if status != models.DataDeletionStatusPending {
+		return nil, fmt.Errorf("deletion request is not pending")
+	}
+	if userID == reviewerID {
+		return nil, fmt.Errorf("insufficient permissions: cannot review your own deletion request")
+	}
+
+	var notesValue *string
+	if notes = strings.TrimSpace(notes); notes != "" {
+		notesValue = &notes
+	}
+
+	newStatus := models.DataDeletionStatusRejected
+	var scheduledFor *time.Time
+	if approve {
+		newStatus = models.DataDeletionStatusApproved
+		at := time.Now().Add(s.config.DeletionGracePeriod)
+		scheduledFor = &at
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE data_deletion_requests
+		SET status = $2, reviewed_by_user_id = $3, reviewed_at = NOW(), review_notes = $4, scheduled_for = $5
+		WHERE request_id = $1
+	`, requestID, newStatus, reviewerID, notesValue, scheduledFor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to review deletion request: %w", err)
+	}
+
+	if approve {
+		if err := s.emailService.SendDataDeletionScheduledEmail(ctx, tx, email, firstName, *scheduledFor, locale); err != nil {
+			return nil, fmt.Errorf("failed to queue deletion notice: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.auditService.LogSecurityEvent(ctx, SecurityEvent{
+		EventType:   EventTypeDataDeletionReviewed,
+		Description: fmt.Sprintf("Personal data deletion request %s", newStatus),
+		UserID:      &userID,
+		Metadata: map[string]interface{}{
+			"request_id":    requestID,
+			"reviewed_by":   reviewerID,
+			"status":        newStatus,
+			"scheduled_for": scheduledFor,
+		},
+	})
+
+	requests, err := s.queryDeletionRequests(ctx, "WHERE r.request_id = $1", requestID)
+	if err != nil || len(requests) == 0 {
+		return nil, fmt.Errorf("failed to reload deletion request: %w", err)
+	}
+	return &requests[0], nil
+}
+
+// Run anonymizes approved requests whose grace period ended until ctx is cancelled
+func (s *PersonalDataService) Run(ctx context.Context) {
+	interval := s.config.DeletionWorkerInterval
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.ProcessDue(ctx); err != nil {
+			slog.ErrorContext(ctx, "failed to process data deletion requests", "component", "privacy", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessDue anonymizes the users of due approved requests and returns how many were completed
+func (s *PersonalDataService) ProcessDue(ctx context.Context) (int, error) {
+	rows, err := s.db.GetConnection().Query(ctx, `
+		SELECT request_id FROM data_deletion_requests
+		WHERE status = $1 AND scheduled_for <= NOW()
+		ORDER BY scheduled_for
+		LIMIT $2
+	`, models.DataDeletionStatusApproved, dataDeletionBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query due deletion requests: %w", err)
+	}
+	requestIDs := []uuid.UUID{}
+	for rows.Next() {
+		var requestID uuid.UUID
+		if err := rows.Scan(&requestID); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan due deletion request: %w", err)
+		}
+		requestIDs = append(requestIDs, requestID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating over due deletion requests: %w", err)
+	}
+
+	completed := 0
+	for _, requestID := range requestIDs {
+		ok, err := s.completeDeletion(ctx, requestID)
+		if err != nil {
+			// Failed requests stay visible to admins instead of being retried forever
+			if _, markErr := s.db.GetConnection().Exec(ctx,
+				"UPDATE data_deletion_requests SET status = $2, last_error = $3 WHERE request_id = $1",
+				requestID, models.DataDeletionStatusFailed, err.Error(),
+			); markErr != nil {
+				return completed, fmt.Errorf("failed to record failure of %s: %w", requestID, markErr)
+			}
+			continue
+		}
+		if ok {
+			completed++
+		}
+	}
+
+	return completed, nil
+}
+
+// completeDeletion anonymizes the user of one approved request. It returns false when the
+// request was cancelled or completed meanwhile.
+func (s *PersonalDataService) completeDeletion(ctx context.Context, requestID uuid.UUID) (bool, error) {
+	tx, err := s.db.GetConnection().Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var userID uuid.UUID
+	err = tx.QueryRow(ctx, `
+		SELECT user_id FROM data_deletion_requests
+		WHERE request_id = $1 AND status = $2 AND scheduled_for <= NOW()
+		FOR UPDATE SKIP LOCKED
+	`, requestID, models.DataDeletionStatusApproved).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to claim deletion request: %w", err)
+	}
+
+	players, err := s.Anonymize(ctx, tx, userID)
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := tx.Exec(ctx,
+		"UPDATE data_deletion_requests SET status = $2, completed_at = NOW(), reason = NULL WHERE request_id = $1",
+		requestID, models.DataDeletionStatusCompleted,
+	); err != nil {
+		return false, fmt.Errorf("failed to complete deletion request: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.auditService.LogSecurityEvent(ctx, SecurityEvent{
+		EventType:   EventTypePersonalDataAnonymized,
+		Description: "Personal data anonymized after an approved deletion request",
+		UserID:      &userID,
+		Metadata: map[string]interface{}{
+			"request_id": requestID,
+			"players":    players,
+		},
+	})
+	return true, nil
+}
+
+// Anonymize replaces the personal fields of a user and their player records on tx and
+// returns how many player records were anonymized. Player IDs, team memberships and
+// statistics are kept, so tournament results stay intact. The account is disabled and its
+// tokens revoked.
+func (s *PersonalDataService) Anonymize(ctx context.Context, tx pgx.Tx, userID uuid.UUID) (int, error) {
+	var email string
+	var anonymizedAt *time.Time
+	err := tx.QueryRow(ctx,
+		"SELECT email, anonymized_at FROM user_profiles WHERE user_id = $1 FOR UPDATE", userID,
+	).Scan(&email, &anonymizedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, fmt.Errorf("user not found")
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to load user: %w", err)
+	}
+	if anonymizedAt != nil {
+		return 0, nil
+	}
+
+	anonymousEmail := fmt.Sprintf("deleted-%s@anonymized.invalid", userID)
+
+	_, err = tx.Exec(ctx, `
+		UPDATE user_profiles SET
+			email = $2, password_hash = '!anonymized', first_name = 'Deleted', last_name = 'User',
+			phone = NULL, identification = NULL, identification_type = NULL, photo_url = NULL,
+			two_factor_secret = NULL, two_factor_enabled = false, token_recovery = NULL, token_expiration_date = NULL,
+			is_active = false, account_status = $3, account_status_reason = 'data_deletion',
+			account_status_changed_at = NOW(), token_version = token_version + 1,
+			anonymized_at = NOW(), updated_at = NOW()
+		WHERE user_id = $1
+	`, userID, anonymousEmail, models.AccountStatusDisabled)
+	if err != nil {
+		return 0, fmt.Errorf("failed to anonymize profile: %w", err)
+	}
+
+	// Only the birth year is kept, for age categories in past tournaments
+	result, err := tx.Exec(ctx, `
+		UPDATE players SET
+			first_name = 'Anonymized', last_name = 'Player', identification = 'anon-' || player_id::text,
+			identification_bidx = NULL, identification_type = NULL,
+			date_of_birth = make_date(EXTRACT(YEAR FROM date_of_birth)::int, 1, 1),
+			blood_type = NULL, gender = NULL, nationality = NULL, email = NULL, phone = NULL, photo_url = NULL,
+			height_cm = NULL, weight_kg = NULL, emergency_contact = NULL, medical_info = NULL,
+			anonymized_at = NOW(), updated_at = NOW()
+		WHERE user_profile_id = $1 AND anonymized_at IS NULL
+	`, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to anonymize players: %w", err)
+	}
+	players := int(result.RowsAffected())
+
+	statements := []struct {
+		description string
+		sql         string
+		args        []interface{}
+	}{
+		{"deactivate roles", "UPDATE user_roles_by_city_sport SET is_active = false WHERE user_id = $1", []interface{}{userID}},
+		{"anonymize invitations", "UPDATE user_invitations SET email = $2, revoked_at = COALESCE(revoked_at, NOW()) WHERE user_id = $1", []interface{}{userID, anonymousEmail}},
+		{"anonymize import rows", "UPDATE user_import_rows SET email = $2 WHERE user_id = $1", []interface{}{userID, anonymousEmail}},
+		{"anonymize login attempts", "UPDATE login_attempts SET email = $2 WHERE user_id = $1 OR email = lower($3)", []interface{}{userID, anonymousEmail, email}},
+		{"delete queued email", "DELETE FROM email_outbox WHERE lower(to_email) = lower($1)", []interface{}{email}},
+		{"delete email changes", "DELETE FROM email_change_requests WHERE user_id = $1", []interface{}{userID}},
+		{"delete verification tokens", "DELETE FROM email_verification_tokens WHERE user_id = $1", []interface{}{userID}},
+		{"delete login challenges", "DELETE FROM login_challenges WHERE user_id = $1", []interface{}{userID}},
+		{"delete password history", "DELETE FROM password_history WHERE user_id = $1", []interface{}{userID}},
+		{"revoke guardianships", "UPDATE player_guardians SET revoked_at = COALESCE(revoked_at, NOW()) WHERE guardian_user_id = $1", []interface{}{userID}},
+		{"delete guardian match notifications", "DELETE FROM guardian_match_notifications WHERE guardian_user_id = $1", []interface{}{userID}},
+		{"cancel scheduled status changes", "UPDATE account_status_schedules SET status = 'cancelled' WHERE user_id = $1 AND status = 'pending'", []interface{}{userID}},
+		{"redact audit metadata", "UPDATE security_audit_log SET metadata = metadata - $2::text[] WHERE user_id = $1 OR metadata->>'email' = lower($3)", []interface{}{userID, anonymizedAuditKeys, email}},
+	}
+	for _, statement := range statements {
+		if _, err := tx.Exec(ctx, statement.sql, statement.args...); err != nil {
+			return 0, fmt.Errorf("failed to %s: %w", statement.description, err)
+		}
+	}
+
+	return players, nil
+}
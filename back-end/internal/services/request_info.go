@@ -0,0 +1,47 @@
+package services
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// RequestInfo identifies the HTTP request a service call is made for
+type RequestInfo struct {
+	IPAddress string
+	UserAgent string
+	RequestID string
+}
+
+type requestInfoKey struct{}
+
+// WithRequestInfo returns a context carrying the request's client information
+func WithRequestInfo(ctx context.Context, info RequestInfo) context.Context {
+	return context.WithValue(ctx, requestInfoKey{}, info)
+}
+
+// RequestInfoFromContext returns the request information set by the RequestContext middleware
+func RequestInfoFromContext(ctx context.Context) (RequestInfo, bool) {
+	info, ok := ctx.Value(requestInfoKey{}).(RequestInfo)
+	return info, ok
+}
+
+// Impersonation identifies the super admin acting through an impersonation token
+type Impersonation struct {
+	ActorID   uuid.UUID
+	SubjectID uuid.UUID
+	SessionID uuid.UUID
+}
+
+type impersonationKey struct{}
+
+// WithImpersonation returns a context marking the request as made by an impersonating super admin
+func WithImpersonation(ctx context.Context, impersonation Impersonation) context.Context {
+	return context.WithValue(ctx, impersonationKey{}, impersonation)
+}
+
+// ImpersonationFromContext returns the impersonation set by the JWT middleware, if any
+func ImpersonationFromContext(ctx context.Context) (Impersonation, bool) {
+	impersonation, ok := ctx.Value(impersonationKey{}).(Impersonation)
+	return impersonation, ok
+}
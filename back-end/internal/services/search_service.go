@@ -0,0 +1,522 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"mowesport/internal/database"
+	"mowesport/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// searchConfig is the text search configuration of the search_vector columns (migration 027)
+const searchConfig = "public.spanish_unaccent"
+
+const searchDefaultLimit = 20
+
+// searchTypes lists every result type
+var searchTypes = []string{models.SearchTypeTeam, models.SearchTypePlayer, models.SearchTypeTournament, models.SearchTypeUser}
+
+// searchTypeWords are words of the search text that select the result types
+var searchTypeWords = map[string]string{
+	"team": models.SearchTypeTeam, "teams": models.SearchTypeTeam,
+	"equipo": models.SearchTypeTeam, "equipos": models.SearchTypeTeam,
+	"player": models.SearchTypePlayer, "players": models.SearchTypePlayer,
+	"jugador": models.SearchTypePlayer, "jugadores": models.SearchTypePlayer,
+	"jugadora": models.SearchTypePlayer, "jugadoras": models.SearchTypePlayer,
+	"tournament": models.SearchTypeTournament, "tournaments": models.SearchTypeTournament,
+	"torneo": models.SearchTypeTournament, "torneos": models.SearchTypeTournament,
+	"user": models.SearchTypeUser, "users": models.SearchTypeUser,
+	"usuario": models.SearchTypeUser, "usuarios": models.SearchTypeUser,
+}
+
+// searchFillerWords join the words of a search sentence without naming anything. Spanish
+// stop words ("en", "de") are already dropped by the text search configuration.
+var searchFillerWords = map[string]bool{
+	"in": true, "named": true, "called": true, "from": true, "of": true,
+	"llamado": true, "llamada": true, "llamados": true, "llamadas": true,
+}
+
+// searchAgeCategory matches age categories such as "sub-17", "sub17" or "under-17"
+var searchAgeCategory = regexp.MustCompile(`^(?:sub|under|u)-?(\d{1,2})$`)
+
+// SearchService runs the full-text search across teams, players, tournaments and users
+type SearchService struct {
+	db              *database.Database
+	viewPermissions *ViewPermissionService
+}
+
+// searchPlace is a city or sport whose name may appear in the search text
+type searchPlace struct {
+	id    uuid.UUID
+	name  string
+	words []string // Name without accents, lower case
+}
+
+// searchQuery builds the per-type queries of one search. Placeholders are empty when
+// the corresponding filter or the caller is absent; Postgres rejects parameters no query
+// uses, so super admins, whose queries never refer to themselves, get no caller.
+type searchQuery struct {
+	args       *listQuery
+	tsquery    string
+	caller     string
+	superAdmin bool
+	views      map[string]bool
+	city       string
+	sport      string
+	maxAge     *int // Bound by players(), the only query using it
+}
+
+// NewSearchService creates a new search service
+func NewSearchService(db *database.Database) *SearchService {
+	return &SearchService{
+		db:              db,
+		viewPermissions: NewViewPermissionService(db),
+	}
+}
+
+// Search finds teams, players, tournaments and users matching the search text, best
+// matches first. callerID is nil for anonymous callers, who only get public results:
+// public tournaments, active teams and active adult players. Signed-in callers also get
+// what their views and role assignments let them see.
+func (s *SearchService) Search(ctx context.Context, req *models.SearchRequest, callerID *uuid.UUID) (*models.SearchResponse, error) {
+	limit := req.Limit
+	if limit == 0 {
+		limit = searchDefaultLimit
+	}
+
+	q := &searchQuery{args: &listQuery{}}
+	primaryRole := ""
+	if callerID != nil {
+		permissions, err := s.viewPermissions.GetEffectivePermissions(ctx, *callerID)
+		if err != nil {
+			return nil, err
+		}
+		q.views = permissions.Views
+		primaryRole = permissions.PrimaryRole
+		q.superAdmin = primaryRole == models.RoleSuperAdmin
+		if !q.superAdmin {
+			q.caller = q.args.arg(*callerID)
+		}
+	}
+
+	interpreted, terms, err := s.interpret(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if len(terms) == 0 && interpreted.CityID == nil && interpreted.SportID == nil && interpreted.MaxAge == nil {
+		return nil, fmt.Errorf("search text or a filter is required")
+	}
+
+	// Users are only searchable by the admins who can list them
+	canSearchUsers := q.superAdmin || (primaryRole == models.RoleCityAdmin && q.views[ViewUsers])
+	visibleTypes := []string{}
+	for _, searchType := range interpreted.Types {
+		if searchType != models.SearchTypeUser || canSearchUsers {
+			visibleTypes = append(visibleTypes, searchType)
+		}
+	}
+	interpreted.Types = visibleTypes
+
+	response := &models.SearchResponse{
+		Results:     []models.SearchResult{},
+		Interpreted: *interpreted,
+		PublicOnly:  callerID == nil,
+	}
+	if len(visibleTypes) == 0 {
+		return response, nil
+	}
+
+	if len(terms) > 0 {
+		q.tsquery = fmt.Sprintf("to_tsquery('%s', %s)", searchConfig, q.args.arg(strings.Join(terms, ":* & ")+":*"))
+	}
+	if interpreted.CityID != nil {
+		q.city = q.args.arg(*interpreted.CityID)
+	}
+	if interpreted.SportID != nil {
+		q.sport = q.args.arg(*interpreted.SportID)
+	}
+	q.maxAge = interpreted.MaxAge
+
+	parts := []string{}
+	for _, searchType := range visibleTypes {
+		switch searchType {
+		case models.SearchTypeTeam:
+			parts = append(parts, q.teams())
+		case models.SearchTypePlayer:
+			parts = append(parts, q.players())
+		case models.SearchTypeTournament:
+			parts = append(parts, q.tournaments())
+		case models.SearchTypeUser:
+			parts = append(parts, q.users())
+		}
+	}
+
+	rows, err := s.db.GetConnection().Query(ctx, fmt.Sprintf(`
+		SELECT type, id, title, subtitle, city_id, city_name, sport_id, sport_name, rank
+		FROM (%s) results
+		ORDER BY rank DESC, title
+		LIMIT %s
+	`, strings.Join(parts, " UNION ALL "), q.args.arg(limit)), q.args.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var result models.SearchResult
+		if err := rows.Scan(
+			&result.Type, &result.ID, &result.Title, &result.Subtitle, &result.CityID, &result.CityName,
+			&result.SportID, &result.SportName, &result.Rank,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan search result: %w", err)
+		}
+		response.Results = append(response.Results, result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over search results: %w", err)
+	}
+
+	return response, nil
+}
+
+// interpret reads filters out of the search text: result type words ("jugadores"), a
+// city or sport name ("Medellín") and an age category ("sub-17"). Filters of the request
+// take precedence. The remaining words are returned as to_tsquery prefix terms.
+func (s *SearchService) interpret(ctx context.Context, req *models.SearchRequest) (*models.SearchInterpretation, []string, error) {
+	interpreted := &models.SearchInterpretation{Types: []string{}}
+
+	var normalized string
+	if err := s.db.GetConnection().QueryRow(ctx, "SELECT public.f_unaccent(lower($1))", req.Query).Scan(&normalized); err != nil {
+		return nil, nil, fmt.Errorf("failed to normalize search text: %w", err)
+	}
+	words := strings.FieldsFunc(normalized, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-'
+	})
+
+	cities, err := s.places(ctx, "SELECT city_id, name, public.f_unaccent(lower(name)) FROM cities WHERE is_active = true")
+	if err != nil {
+		return nil, nil, err
+	}
+	sports, err := s.places(ctx, "SELECT sport_id, name, public.f_unaccent(lower(name)) FROM sports WHERE is_active = true")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if req.CityID != "" {
+		cityID, err := uuid.Parse(req.CityID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid city ID")
+		}
+		interpreted.CityID, interpreted.CityName = &cityID, placeName(cities, cityID)
+	} else if city, rest := matchPlace(cities, words); city != nil {
+		interpreted.CityID, interpreted.CityName, words = &city.id, &city.name, rest
+	}
+	if req.SportID != "" {
+		sportID, err := uuid.Parse(req.SportID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid sport ID")
+		}
+		interpreted.SportID, interpreted.SportName = &sportID, placeName(sports, sportID)
+	} else if sport, rest := matchPlace(sports, words); sport != nil {
+		interpreted.SportID, interpreted.SportName, words = &sport.id, &sport.name, rest
+	}
+	if req.MaxAge > 0 {
+		maxAge := req.MaxAge
+		interpreted.MaxAge = &maxAge
+	}
+
+	requestedTypes := map[string]bool{}
+	for _, searchType := range strings.Split(req.Types, ",") {
+		searchType = strings.TrimSpace(searchType)
+		if searchType == "" {
+			continue
+		}
+		if !roleInList(searchType, searchTypes) {
+			return nil, nil, fmt.Errorf("invalid search type: %s", searchType)
+		}
+		requestedTypes[searchType] = true
+	}
+	typedInText := map[string]bool{}
+
+	terms := []string{}
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		if searchType, ok := searchTypeWords[word]; ok {
+			typedInText[searchType] = true
+			continue
+		}
+		if searchFillerWords[word] {
+			continue
+		}
+		age := searchAgeCategory.FindStringSubmatch(word)
+		if age == nil && (word == "sub" || word == "under") && i+1 < len(words) {
+			if age = searchAgeCategory.FindStringSubmatch(word + words[i+1]); age != nil {
+				i++
+			}
+		}
+		if age != nil {
+			if interpreted.MaxAge == nil {
+				maxAge, _ := strconv.Atoi(age[1])
+				interpreted.MaxAge = &maxAge
+			}
+			continue
+		}
+		for _, term := range strings.Split(word, "-") {
+			if term != "" {
+				terms = append(terms, term)
+			}
+		}
+	}
+
+	if len(requestedTypes) == 0 {
+		requestedTypes = typedInText
+	}
+	for _, searchType := range searchTypes {
+		if len(requestedTypes) == 0 || requestedTypes[searchType] {
+			interpreted.Types = append(interpreted.Types, searchType)
+		}
+	}
+	interpreted.Text = strings.Join(terms, " ")
+
+	return interpreted, terms, nil
+}
+
+// places loads the cities or sports that may be named in the search text
+func (s *SearchService) places(ctx context.Context, query string) ([]searchPlace, error) {
+	rows, err := s.db.GetConnection().Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load search places: %w", err)
+	}
+	defer rows.Close()
+
+	places := []searchPlace{}
+	for rows.Next() {
+		var place searchPlace
+		var normalized string
+		if err := rows.Scan(&place.id, &place.name, &normalized); err != nil {
+			return nil, fmt.Errorf("failed to scan search place: %w", err)
+		}
+		place.words = strings.FieldsFunc(normalized, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		if len(place.words) > 0 {
+			places = append(places, place)
+		}
+	}
+	return places, rows.Err()
+}
+
+// matchPlace finds the place with the longest name among the words and returns the words
+// without it
+func matchPlace(places []searchPlace, words []string) (*searchPlace, []string) {
+	var match *searchPlace
+	at := 0
+	for i := range places {
+		place := &places[i]
+		if match != nil && len(place.words) <= len(match.words) {
+			continue
+		}
+		for start := 0; start+len(place.words) <= len(words); start++ {
+			if strings.Join(words[start:start+len(place.words)], " ") == strings.Join(place.words, " ") {
+				match, at = place, start
+				break
+			}
+		}
+	}
+	if match == nil {
+		return nil, words
+	}
+
+	rest := append([]string{}, words[:at]...)
+	return match, append(rest, words[at+len(match.words):]...)
+}
+
+func placeName(places []searchPlace, id uuid.UUID) *string {
+	for _, place := range places {
+		if place.id == id {
+			return &place.name
+		}
+	}
+	return nil
+}
+
+// searchSelect is the result row shared by every per-type query
+func searchSelect(resultType, id, title, subtitle, cityID, cityName, sportID, sportName, rank string) string {
+	return fmt.Sprintf(`SELECT '%s'::text AS type, %s AS id, (%s)::text AS title, (%s)::text AS subtitle,
+		%s AS city_id, (%s)::text AS city_name, %s AS sport_id, (%s)::text AS sport_name, (%s)::real AS rank`,
+		resultType, id, title, subtitle, cityID, cityName, sportID, sportName, rank)
+}
+
+// match requires the search text in the table's search_vector
+func (q *searchQuery) match(alias string) string {
+	if q.tsquery == "" {
+		return "TRUE"
+	}
+	return fmt.Sprintf("%s.search_vector @@ %s", alias, q.tsquery)
+}
+
+// rank scores a match; every row of a filter-only search ranks the same
+func (q *searchQuery) rank(alias string) string {
+	if q.tsquery == "" {
+		return "0"
+	}
+	return fmt.Sprintf("ts_rank(%s.search_vector, %s)", alias, q.tsquery)
+}
+
+// visibility is what anyone may see, plus the caller's own rows and, with the view, rows
+// within the caller's role assignments. Super admins see everything.
+func (q *searchQuery) visibility(view, public, own, scoped string) string {
+	if q.superAdmin {
+		return "TRUE"
+	}
+	if q.caller == "" {
+		return public
+	}
+	conditions := []string{public, own}
+	if q.views[view] {
+		conditions = append(conditions, scoped)
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")"
+}
+
+// scope matches a city/sport covered by one of the caller's active role assignments.
+// Tournament-bound assignments only cover their tournament.
+func (q *searchQuery) scope(city, sport, tournament string) string {
+	tournamentCondition := "mine.tournament_id IS NULL"
+	if tournament != "" {
+		tournamentCondition = fmt.Sprintf("(mine.tournament_id IS NULL OR mine.tournament_id = %s)", tournament)
+	}
+	return fmt.Sprintf(`EXISTS (
+		SELECT 1 FROM user_roles_by_city_sport mine
+		WHERE mine.user_id = %s AND mine.is_active = true AND %s
+		  AND (mine.city_id IS NULL OR mine.city_id = %s)
+		  AND (mine.sport_id IS NULL OR mine.sport_id = %s))`, q.caller, tournamentCondition, city, sport)
+}
+
+func (q *searchQuery) teams() string {
+	conditions := []string{
+		q.match("tm"),
+		q.visibility(ViewTeams, "tm.is_active = true", "tm.owner_user_id = "+q.caller, q.scope("tm.city_id", "tm.sport_id", "")),
+	}
+	if q.city != "" {
+		conditions = append(conditions, "tm.city_id = "+q.city)
+	}
+	if q.sport != "" {
+		conditions = append(conditions, "tm.sport_id = "+q.sport)
+	}
+
+	return searchSelect(models.SearchTypeTeam, "tm.team_id", "tm.name", "tm.short_name",
+		"c.city_id", "c.name", "s.sport_id", "s.name", q.rank("tm")) + `
+		FROM teams tm
+		JOIN cities c ON c.city_id = tm.city_id
+		JOIN sports s ON s.sport_id = tm.sport_id
+		WHERE ` + strings.Join(conditions, " AND ")
+}
+
+// players are shown with their latest active team, which also places them in a city and
+// sport. Minors are not public: only the player, their guardians and callers with the
+// players view within their assignments find them.
+func (q *searchQuery) players() string {
+	public := fmt.Sprintf("(p.is_active = true AND p.date_of_birth <= CURRENT_DATE - make_interval(years => %d))", GuardianAgeOfMajority)
+	own := fmt.Sprintf(`(p.user_profile_id = %s OR EXISTS (
+		SELECT 1 FROM player_guardians g
+		WHERE g.player_id = p.player_id AND g.guardian_user_id = %s AND g.revoked_at IS NULL))`, q.caller, q.caller)
+	scoped := `EXISTS (
+		SELECT 1 FROM team_players stp
+		JOIN teams stm ON stm.team_id = stp.team_id
+		WHERE stp.player_id = p.player_id AND stp.is_active = true AND ` + q.scope("stm.city_id", "stm.sport_id", "") + `)`
+
+	conditions := []string{"p.anonymized_at IS NULL", q.match("p"), q.visibility(ViewPlayers, public, own, scoped)}
+	if q.maxAge != nil {
+		conditions = append(conditions, fmt.Sprintf("p.date_of_birth > CURRENT_DATE - make_interval(years => %s + 1)", q.args.arg(*q.maxAge)))
+	}
+
+	// With a city or sport filter, players need an active team there
+	teamConditions := []string{"tp.player_id = p.player_id", "tp.is_active = true"}
+	join := "LEFT JOIN LATERAL"
+	if q.city != "" {
+		teamConditions = append(teamConditions, "team.city_id = "+q.city)
+		join = "JOIN LATERAL"
+	}
+	if q.sport != "" {
+		teamConditions = append(teamConditions, "team.sport_id = "+q.sport)
+		join = "JOIN LATERAL"
+	}
+
+	return searchSelect(models.SearchTypePlayer, "p.player_id", "p.first_name || ' ' || p.last_name", "current_team.name",
+		"c.city_id", "c.name", "s.sport_id", "s.name", q.rank("p")) + `
+		FROM players p
+		` + join + ` (
+			SELECT team.name, team.city_id, team.sport_id
+			FROM team_players tp
+			JOIN teams team ON team.team_id = tp.team_id
+			WHERE ` + strings.Join(teamConditions, " AND ") + `
+			ORDER BY tp.join_date DESC
+			LIMIT 1
+		) current_team ON true
+		LEFT JOIN cities c ON c.city_id = current_team.city_id
+		LEFT JOIN sports s ON s.sport_id = current_team.sport_id
+		WHERE ` + strings.Join(conditions, " AND ")
+}
+
+func (q *searchQuery) tournaments() string {
+	conditions := []string{
+		q.match("t"),
+		q.visibility(ViewTournaments,
+			"(t.is_public = true AND t.status IN ('approved', 'active', 'completed') AND t.on_hold_since IS NULL)",
+			"t.admin_user_id = "+q.caller,
+			q.scope("t.city_id", "t.sport_id", "t.tournament_id")),
+	}
+	if q.city != "" {
+		conditions = append(conditions, "t.city_id = "+q.city)
+	}
+	if q.sport != "" {
+		conditions = append(conditions, "t.sport_id = "+q.sport)
+	}
+
+	return searchSelect(models.SearchTypeTournament, "t.tournament_id", "t.name", "t.location",
+		"c.city_id", "c.name", "s.sport_id", "s.name", q.rank("t")) + `
+		FROM tournaments t
+		JOIN cities c ON c.city_id = t.city_id
+		JOIN sports s ON s.sport_id = t.sport_id
+		WHERE ` + strings.Join(conditions, " AND ")
+}
+
+// users are limited like the user list: city admins only find users assigned within
+// their own city/sport
+func (q *searchQuery) users() string {
+	conditions := []string{"up.anonymized_at IS NULL", q.match("up")}
+
+	assignment := []string{"ra.user_id = up.user_id", "ra.is_active = true"}
+	if q.city != "" {
+		assignment = append(assignment, "ra.city_id = "+q.city)
+	}
+	if q.sport != "" {
+		assignment = append(assignment, "ra.sport_id = "+q.sport)
+	}
+	if len(assignment) > 2 {
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM user_roles_by_city_sport ra WHERE "+strings.Join(assignment, " AND ")+")")
+	}
+
+	if !q.superAdmin {
+		conditions = append(conditions, fmt.Sprintf(`up.user_id IN (
+			SELECT target.user_id FROM user_roles_by_city_sport target
+			JOIN user_roles_by_city_sport mine ON mine.user_id = %s
+			 AND mine.role_name = '%s' AND mine.is_active = true
+			 AND (mine.city_id IS NULL OR mine.city_id = target.city_id)
+			 AND (mine.sport_id IS NULL OR mine.sport_id = target.sport_id)
+			WHERE target.is_active = true
+		)`, q.caller, models.RoleCityAdmin))
+	}
+
+	return searchSelect(models.SearchTypeUser, "up.user_id", "up.first_name || ' ' || up.last_name", "up.email",
+		"NULL::uuid", "NULL", "NULL::uuid", "NULL", q.rank("up")) + `
+		FROM user_profiles up
+		WHERE ` + strings.Join(conditions, " AND ")
+}
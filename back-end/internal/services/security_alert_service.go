@@ -0,0 +1,445 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mowesport/internal/config"
+	"mowesport/internal/database"
+	"mowesport/internal/models"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// AlertChannel delivers a raised security alert. Alerts are always stored for the
+// in-app list; channels are the additional notifications.
+type AlertChannel interface {
+	Name() string
+	Notify(ctx context.Context, alert *models.SecurityAlert) error
+}
+
+// SecurityAlertService evaluates alert rules against the security audit log and
+// notifies super admins. Events are grouped per rule (by account, IP or event type);
+// an incident produces one alert, and repeat notifications are held back until the
+// cooldown has passed. The worker should run on its own database connection.
+type SecurityAlertService struct {
+	db       *database.Database
+	config   config.AlertConfig
+	rules    []config.AlertRuleConfig
+	channels []AlertChannel
+}
+
+// NewSecurityAlertService creates a new security alert service with the configured channels
+func NewSecurityAlertService(db *database.Database, cfg *config.Config) *SecurityAlertService {
+	auditConfig := cfg.Security.AuditLogging
+	rules := append([]config.AlertRuleConfig{}, auditConfig.Alerts.Rules...)
+	if auditConfig.CriticalEventNotification {
+		rules = append(rules, config.AlertRuleConfig{
+			Name:        "critical_event",
+			Title:       "Critical security event",
+			MinSeverity: SeverityCritical,
+			GroupBy:     "event_type",
+			Threshold:   1,
+			Window:      10 * time.Minute,
+			Severity:    SeverityCritical,
+		})
+	}
+
+	service := &SecurityAlertService{
+		db:     db,
+		config: auditConfig.Alerts,
+		rules:  rules,
+	}
+
+	for _, name := range auditConfig.Alerts.Channels {
+		switch name {
+		case config.AlertChannelEmail:
+			if auditConfig.Alerts.EmailTo == "" {
+				slog.Warn("email alert channel disabled: no SECURITY_ALERT_EMAIL or SUPPORT_EMAIL", "component", "security_alert")
+				continue
+			}
+			service.channels = append(service.channels, &emailAlertChannel{
+				db:           db,
+				emailService: NewEmailService(cfg, NewSecurityAuditService(db)),
+				to:           auditConfig.Alerts.EmailTo,
+				cooldown:     auditConfig.Alerts.Cooldown,
+			})
+		case config.AlertChannelWebhook:
+			if auditConfig.Alerts.WebhookURL == "" {
+				slog.Warn("webhook alert channel disabled: SECURITY_ALERT_WEBHOOK_URL is not set", "component", "security_alert")
+				continue
+			}
+			service.channels = append(service.channels, &webhookAlertChannel{
+				url:    auditConfig.Alerts.WebhookURL,
+				client: &http.Client{Timeout: 10 * time.Second},
+			})
+		default:
+			slog.Warn("unknown alert channel ignored", "component", "security_alert", "channel", name)
+		}
+	}
+
+	return service
+}
+
+// Run evaluates the alert rules until the context is cancelled
+func (s *SecurityAlertService) Run(ctx context.Context) {
+	interval := s.config.PollInterval
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.Evaluate(ctx); err != nil {
+			slog.ErrorContext(ctx, "failed to evaluate alert rules", "component", "security_alert", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Evaluate runs every rule once and returns the number of notifications sent
+func (s *SecurityAlertService) Evaluate(ctx context.Context) (int, error) {
+	notified := 0
+	for _, rule := range s.rules {
+		count, err := s.evaluateRule(ctx, rule)
+		if err != nil {
+			return notified, fmt.Errorf("rule %s: %w", rule.Name, err)
+		}
+		notified += count
+	}
+
+	return notified, nil
+}
+
+// alertGroup is one set of events that crossed a rule's threshold
+type alertGroup struct {
+	key          string
+	eventCount   int
+	firstEventAt time.Time
+	lastEventAt  time.Time
+	ipAddresses  []string
+	userID       *uuid.UUID
+	userEmail    string
+}
+
+// alertGroupColumns maps a rule's GroupBy to the audit log column it groups on
+var alertGroupColumns = map[string]string{
+	"user_id":    "user_id::text",
+	"ip_address": "host(ip_address)",
+	"event_type": "event_type",
+}
+
+func (s *SecurityAlertService) evaluateRule(ctx context.Context, rule config.AlertRuleConfig) (int, error) {
+	column, ok := alertGroupColumns[rule.GroupBy]
+	if !ok {
+		return 0, fmt.Errorf("unsupported group_by %q", rule.GroupBy)
+	}
+	threshold := rule.Threshold
+	if threshold < 1 {
+		threshold = 1
+	}
+
+	conditions := fmt.Sprintf("timestamp >= NOW() - $1 * INTERVAL '1 second' AND %s IS NOT NULL", column)
+	args := []interface{}{int(rule.Window.Seconds()), threshold}
+	argIndex := 3
+
+	if len(rule.EventTypes) > 0 {
+		conditions += fmt.Sprintf(" AND event_type = ANY($%d)", argIndex)
+		args = append(args, rule.EventTypes)
+		argIndex++
+	}
+
+	if rule.MinSeverity != "" {
+		conditions += fmt.Sprintf(" AND severity = ANY($%d)", argIndex)
+		args = append(args, severitiesFrom(rule.MinSeverity))
+		argIndex++
+	}
+
+	rows, err := s.db.GetConnection().Query(ctx, fmt.Sprintf(`
+		SELECT l.group_key, l.event_count, l.first_event_at, l.last_event_at, l.ip_addresses,
+		       l.user_id, COALESCE(u.email, '')
+		FROM (
+			SELECT %s AS group_key, COUNT(*) AS event_count, MIN(timestamp) AS first_event_at,
+			       MAX(timestamp) AS last_event_at,
+			       COALESCE(array_agg(DISTINCT host(ip_address)) FILTER (WHERE ip_address IS NOT NULL), '{}') AS ip_addresses,
+			       (array_agg(user_id ORDER BY timestamp DESC) FILTER (WHERE user_id IS NOT NULL))[1] AS user_id
+			FROM security_audit_log
+			WHERE %s
+			GROUP BY 1
+			HAVING COUNT(*) >= $2
+		) l
+		LEFT JOIN user_profiles u ON u.user_id = l.user_id
+	`, column, conditions), args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query audit events: %w", err)
+	}
+
+	groups := []alertGroup{}
+	for rows.Next() {
+		var group alertGroup
+		if err := rows.Scan(&group.key, &group.eventCount, &group.firstEventAt, &group.lastEventAt,
+			&group.ipAddresses, &group.userID, &group.userEmail); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan audit events: %w", err)
+		}
+		groups = append(groups, group)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to query audit events: %w", err)
+	}
+
+	notified := 0
+	for _, group := range groups {
+		alert, notify, err := s.recordAlert(ctx, rule, group)
+		if err != nil {
+			return notified, err
+		}
+		if notify {
+			s.notify(ctx, alert)
+			notified++
+		}
+	}
+
+	return notified, nil
+}
+
+// recordAlert creates or updates the alert of a group and reports whether channels
+// should be notified. Events already covered by an alert never notify twice, and an
+// open alert notifies again only once its cooldown has passed.
+func (s *SecurityAlertService) recordAlert(ctx context.Context, rule config.AlertRuleConfig, group alertGroup) (*models.SecurityAlert, bool, error) {
+	conn := s.db.GetConnection()
+	fingerprint := rule.Name + ":" + group.key
+	description := s.describe(rule, group)
+
+	var existingID uuid.UUID
+	var status string
+	var lastEventAt time.Time
+	var lastNotifiedAt *time.Time
+	err := conn.QueryRow(ctx, `
+		SELECT alert_id, status, last_event_at, last_notified_at
+		FROM security_alerts
+		WHERE fingerprint = $1
+		ORDER BY created_at DESC
+		LIMIT 1
+	`, fingerprint).Scan(&existingID, &status, &lastEventAt, &lastNotifiedAt)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, false, fmt.Errorf("failed to look up alert: %w", err)
+	}
+
+	// Nothing new since the alert was raised
+	if err == nil && !group.lastEventAt.After(lastEventAt) {
+		return nil, false, nil
+	}
+
+	if err == nil && status == models.SecurityAlertStatusOpen {
+		notify := lastNotifiedAt == nil || time.Since(*lastNotifiedAt) >= s.config.Cooldown
+		alert, err := s.scanAlert(conn.QueryRow(ctx, `
+			UPDATE security_alerts
+			SET description = $2, event_count = GREATEST(event_count, $3), last_event_at = $4,
+			    ip_addresses = ARRAY(SELECT DISTINCT unnest(ip_addresses || $5::text[])),
+			    suppressed_count = suppressed_count + CASE WHEN $6 THEN 0 ELSE 1 END,
+			    last_notified_at = CASE WHEN $6 THEN NOW() ELSE last_notified_at END
+			WHERE alert_id = $1
+			RETURNING `+securityAlertColumns,
+			existingID, description, group.eventCount, group.lastEventAt, group.ipAddresses, notify))
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to update alert: %w", err)
+		}
+		return alert, notify, nil
+	}
+
+	// First occurrence, or new events after the previous alert was acknowledged
+	alert, err := s.scanAlert(conn.QueryRow(ctx, `
+		INSERT INTO security_alerts (
+			rule_name, fingerprint, severity, title, description, user_id, ip_addresses,
+			event_count, first_event_at, last_event_at, last_notified_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW())
+		RETURNING `+securityAlertColumns,
+		rule.Name, fingerprint, rule.Severity, rule.Title, description, group.userID, group.ipAddresses,
+		group.eventCount, group.firstEventAt, group.lastEventAt))
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create alert: %w", err)
+	}
+
+	return alert, true, nil
+}
+
+func (s *SecurityAlertService) describe(rule config.AlertRuleConfig, group alertGroup) string {
+	window := fmt.Sprintf("%d minutes", int(rule.Window.Minutes()))
+	subject := group.key
+	switch rule.GroupBy {
+	case "user_id":
+		if group.userEmail != "" {
+			subject = group.userEmail
+		}
+		subject = "account " + subject
+	case "ip_address":
+		subject = "IP address " + subject
+	case "event_type":
+		return fmt.Sprintf("%d %s events within %s", group.eventCount, group.key, window)
+	}
+
+	return fmt.Sprintf("%d %s events for %s within %s", group.eventCount, strings.Join(rule.EventTypes, "/"), subject, window)
+}
+
+// notify delivers an alert to every channel; a failing channel does not stop the others
+func (s *SecurityAlertService) notify(ctx context.Context, alert *models.SecurityAlert) {
+	slog.WarnContext(ctx, "security alert raised", "component", "security_alert", "alert_id", alert.AlertID,
+		"title", alert.Title, "severity", alert.Severity, "description", alert.Description)
+
+	for _, channel := range s.channels {
+		if err := channel.Notify(ctx, alert); err != nil {
+			slog.ErrorContext(ctx, "failed to notify alert channel", "component", "security_alert", "channel", channel.Name(),
+				"alert_id", alert.AlertID, "error", err)
+		}
+	}
+}
+
+// ListAlerts returns alerts newest first, optionally filtered by status
+func (s *SecurityAlertService) ListAlerts(ctx context.Context, status string, limit int) ([]models.SecurityAlert, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+
+	rows, err := s.db.GetConnection().Query(ctx, `
+		SELECT `+securityAlertColumns+`
+		FROM security_alerts
+		WHERE ($1 = '' OR status = $1)
+		ORDER BY created_at DESC
+		LIMIT $2
+	`, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list security alerts: %w", err)
+	}
+	defer rows.Close()
+
+	alerts := []models.SecurityAlert{}
+	for rows.Next() {
+		alert, err := s.scanAlert(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan security alert: %w", err)
+		}
+		alerts = append(alerts, *alert)
+	}
+
+	return alerts, rows.Err()
+}
+
+// AcknowledgeAlert marks an open alert as handled; later events open a new alert
+func (s *SecurityAlertService) AcknowledgeAlert(ctx context.Context, alertID, acknowledgedBy uuid.UUID) (*models.SecurityAlert, error) {
+	alert, err := s.scanAlert(s.db.GetConnection().QueryRow(ctx, `
+		UPDATE security_alerts
+		SET status = 'acknowledged', acknowledged_by = $2, acknowledged_at = NOW()
+		WHERE alert_id = $1 AND status = 'open'
+		RETURNING `+securityAlertColumns,
+		alertID, acknowledgedBy))
+	if errors.Is(err, pgx.ErrNoRows) {
+		var exists bool
+		s.db.GetConnection().QueryRow(ctx,
+			"SELECT EXISTS(SELECT 1 FROM security_alerts WHERE alert_id = $1)", alertID).Scan(&exists)
+		if exists {
+			return nil, fmt.Errorf("alert already acknowledged")
+		}
+		return nil, fmt.Errorf("alert not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to acknowledge alert: %w", err)
+	}
+
+	return alert, nil
+}
+
+// Helper methods
+
+const securityAlertColumns = `alert_id, rule_name, severity, title, description, user_id, ip_addresses,
+	event_count, suppressed_count, first_event_at, last_event_at, last_notified_at, status,
+	acknowledged_by, acknowledged_at, created_at`
+
+func (s *SecurityAlertService) scanAlert(row pgx.Row) (*models.SecurityAlert, error) {
+	var alert models.SecurityAlert
+	err := row.Scan(&alert.AlertID, &alert.RuleName, &alert.Severity, &alert.Title, &alert.Description,
+		&alert.UserID, &alert.IPAddresses, &alert.EventCount, &alert.SuppressedCount,
+		&alert.FirstEventAt, &alert.LastEventAt, &alert.LastNotifiedAt, &alert.Status,
+		&alert.AcknowledgedBy, &alert.AcknowledgedAt, &alert.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &alert, nil
+}
+
+// severitiesFrom returns the severity levels at or above min
+func severitiesFrom(min string) []string {
+	levels := []string{SeverityLow, SeverityMedium, SeverityHigh, SeverityCritical}
+	for i, level := range levels {
+		if level == min {
+			return levels[i:]
+		}
+	}
+	return levels
+}
+
+// emailAlertChannel queues the alert to the security contact through the email outbox
+type emailAlertChannel struct {
+	db           *database.Database
+	emailService *EmailService
+	to           string
+	cooldown     time.Duration
+}
+
+func (c *emailAlertChannel) Name() string { return config.AlertChannelEmail }
+
+func (c *emailAlertChannel) Notify(ctx context.Context, alert *models.SecurityAlert) error {
+	return c.emailService.SendSecurityAlertEmail(ctx, c.db.GetConnection(), c.to, alert, c.cooldown)
+}
+
+// webhookAlertChannel posts the alert as JSON, e.g. to a chat or incident tool
+type webhookAlertChannel struct {
+	url    string
+	client *http.Client
+}
+
+func (c *webhookAlertChannel) Name() string { return config.AlertChannelWebhook }
+
+func (c *webhookAlertChannel) Notify(ctx context.Context, alert *models.SecurityAlert) error {
+	payload, err := json.Marshal(map[string]interface{}{
+		"type":  "security_alert",
+		"alert": alert,
+		"text":  fmt.Sprintf("[%s] %s: %s", alert.Severity, alert.Title, alert.Description),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode alert: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
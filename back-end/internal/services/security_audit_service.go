@@ -2,9 +2,15 @@ package services
 
 import (
 	"context"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"io"
+	"log/slog"
 	"mowesport/internal/database"
+	"mowesport/internal/logging"
+	"net"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -30,6 +36,7 @@ type SecurityEvent struct {
 	UserID      *uuid.UUID             `json:"user_id,omitempty"`
 	IPAddress   string                 `json:"ip_address"`
 	UserAgent   string                 `json:"user_agent"`
+	RequestID   string                 `json:"request_id,omitempty"`
 	Metadata    map[string]interface{} `json:"metadata"`
 	Timestamp   time.Time              `json:"timestamp"`
 	Severity    string                 `json:"severity"`
@@ -51,6 +58,25 @@ const (
 	EventTypeAccountLocked           = "ACCOUNT_LOCKED"
 	EventTypeAccountUnlocked         = "ACCOUNT_UNLOCKED"
 	EventTypePermissionDenied        = "PERMISSION_DENIED"
+	EventTypeAuditLogPurged          = "AUDIT_LOG_PURGED"
+	EventTypeAuditLogExported        = "AUDIT_LOG_EXPORTED"
+	EventTypeImpersonationStarted    = "IMPERSONATION_STARTED"
+	EventTypeImpersonatedRequest     = "IMPERSONATED_REQUEST"
+	EventTypeAccountStatusChanged    = "ACCOUNT_STATUS_CHANGED"
+	EventTypeAccountStatusScheduled  = "ACCOUNT_STATUS_SCHEDULED"
+	EventTypeUserImport              = "USER_IMPORT"
+	EventTypeSensitiveDataAccessed   = "SENSITIVE_DATA_ACCESSED"
+	EventTypePersonalDataExported    = "PERSONAL_DATA_EXPORTED"
+	EventTypeDataDeletionRequested   = "DATA_DELETION_REQUESTED"
+	EventTypeDataDeletionReviewed    = "DATA_DELETION_REVIEWED"
+	EventTypePersonalDataAnonymized  = "PERSONAL_DATA_ANONYMIZED"
+	EventTypeEncryptionKeyRotated    = "ENCRYPTION_KEY_ROTATED"
+	EventTypeGuardianLinked          = "GUARDIAN_LINKED"
+	EventTypeGuardianUnlinked        = "GUARDIAN_UNLINKED"
+	EventTypeGuardianConsentGiven    = "GUARDIAN_CONSENT_GIVEN"
+	EventTypeGuardianConsentRevoked  = "GUARDIAN_CONSENT_REVOKED"
+	EventTypeGuardianProfileUpdated  = "GUARDIAN_PROFILE_UPDATED"
+	EventTypeUserListExported        = "USER_LIST_EXPORTED"
 )
 
 // Severity levels
@@ -61,7 +87,8 @@ const (
 	SeverityCritical = "CRITICAL"
 )
 
-// LogSecurityEvent logs a security event to the database
+// LogSecurityEvent logs a security event to the database. The client IP, user agent
+// and request ID are taken from the request context when the event does not set them.
 func (s *SecurityAuditService) LogSecurityEvent(ctx context.Context, event SecurityEvent) error {
 	// Ensure event has required fields
 	if event.EventID == uuid.Nil {
@@ -73,36 +100,69 @@ func (s *SecurityAuditService) LogSecurityEvent(ctx context.Context, event Secur
 	if event.Severity == "" {
 		event.Severity = s.determineSeverity(event.EventType)
 	}
+	if info, ok := RequestInfoFromContext(ctx); ok {
+		if event.IPAddress == "" || event.IPAddress == "unknown" {
+			event.IPAddress = info.IPAddress
+		}
+		if event.UserAgent == "" {
+			event.UserAgent = info.UserAgent
+		}
+		if event.RequestID == "" {
+			event.RequestID = info.RequestID
+		}
+	}
 
 	// Convert metadata to JSON
+	if event.Metadata == nil {
+		event.Metadata = map[string]interface{}{}
+	}
+	// Anything done through an impersonation token is attributed to the real actor as well
+	if impersonation, ok := ImpersonationFromContext(ctx); ok {
+		event.Metadata["impersonated_by"] = impersonation.ActorID
+		event.Metadata["impersonation_session_id"] = impersonation.SessionID
+	}
 	metadataJSON, err := json.Marshal(event.Metadata)
 	if err != nil {
 		return fmt.Errorf("failed to marshal metadata: %w", err)
 	}
 
-	// Insert into audit log table
-	_, err = s.db.GetConnection().Exec(ctx, `
+	// ip_address is INET; anything that is not an address is stored as NULL
+	var ipAddress *string
+	if net.ParseIP(event.IPAddress) != nil {
+		ipAddress = &event.IPAddress
+	}
+
+	// The event is still recorded when the request that triggered it is cancelled
+	_, err = s.db.GetConnection().Exec(context.WithoutCancel(ctx), `
 		INSERT INTO security_audit_log (
-			event_id, event_type, description, user_id, ip_address, 
-			user_agent, metadata, timestamp, severity
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			event_id, event_type, description, user_id, ip_address,
+			user_agent, request_id, metadata, timestamp, severity
+		) VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), $8, $9, $10)
 	`,
 		event.EventID, event.EventType, event.Description, event.UserID,
-		event.IPAddress, event.UserAgent, string(metadataJSON),
+		ipAddress, event.UserAgent, event.RequestID, string(metadataJSON),
 		event.Timestamp, event.Severity,
 	)
 
 	if err != nil {
-		// If audit logging fails, at least log to console
-		fmt.Printf("[AUDIT_ERROR] Failed to log security event: %v\n", err)
-		fmt.Printf("[AUDIT_FALLBACK] %+v\n", event)
+		// If audit logging fails, keep the event in the application log
+		slog.ErrorContext(ctx, "failed to record security event", "component", "audit", "error", err,
+			"event_id", event.EventID, "event_type", event.EventType, "severity", event.Severity,
+			"description", event.Description, "user_id", event.UserID, "ip_address", event.IPAddress,
+			"metadata", event.Metadata)
 		return fmt.Errorf("failed to log security event: %w", err)
 	}
 
-	// For critical events, also log to console for immediate visibility
+	// The request's log record lists the events, linking errors to the audit trail
+	logging.AddAuditEvent(ctx, event.EventID.String())
+
+	// Critical events are also logged for immediate visibility
 	if event.Severity == SeverityCritical {
-		fmt.Printf("[CRITICAL_SECURITY_EVENT] %s: %s - %v\n",
-			event.EventType, event.Description, event.Metadata)
+		slog.WarnContext(ctx, "critical security event", "component", "audit", "event_id", event.EventID,
+			"event_type", event.EventType, "description", event.Description, "metadata", event.Metadata)
+	} else {
+		slog.DebugContext(ctx, "security event recorded", "component", "audit", "event_id", event.EventID,
+			"event_type", event.EventType)
 	}
 
 	return nil
@@ -189,58 +249,21 @@ func (s *SecurityAuditService) LogUnauthorizedAccess(ctx context.Context, resour
 
 // GetSecurityEvents retrieves security events with filtering
 func (s *SecurityAuditService) GetSecurityEvents(ctx context.Context, filters SecurityEventFilters) ([]SecurityEvent, error) {
+	whereClause, args := filters.where()
 	query := `
-		SELECT event_id, event_type, description, user_id, ip_address, 
-		       user_agent, metadata, timestamp, severity
+		SELECT event_id, event_type, description, user_id, COALESCE(host(ip_address), ''),
+		       COALESCE(user_agent, ''), COALESCE(request_id, ''), COALESCE(metadata, '{}'::jsonb)::text,
+		       timestamp, severity
 		FROM security_audit_log
-		WHERE 1=1
-	`
-	args := []interface{}{}
-	argCount := 0
-
-	// Apply filters
-	if filters.EventType != "" {
-		argCount++
-		query += fmt.Sprintf(" AND event_type = $%d", argCount)
-		args = append(args, filters.EventType)
-	}
-
-	if filters.UserID != nil {
-		argCount++
-		query += fmt.Sprintf(" AND user_id = $%d", argCount)
-		args = append(args, *filters.UserID)
-	}
-
-	if filters.IPAddress != "" {
-		argCount++
-		query += fmt.Sprintf(" AND ip_address = $%d", argCount)
-		args = append(args, filters.IPAddress)
-	}
-
-	if filters.Severity != "" {
-		argCount++
-		query += fmt.Sprintf(" AND severity = $%d", argCount)
-		args = append(args, filters.Severity)
-	}
-
-	if !filters.StartTime.IsZero() {
-		argCount++
-		query += fmt.Sprintf(" AND timestamp >= $%d", argCount)
-		args = append(args, filters.StartTime)
-	}
+	` + whereClause + " ORDER BY timestamp DESC"
 
-	if !filters.EndTime.IsZero() {
-		argCount++
-		query += fmt.Sprintf(" AND timestamp <= $%d", argCount)
-		args = append(args, filters.EndTime)
-	}
-
-	// Add ordering and limit
-	query += " ORDER BY timestamp DESC"
 	if filters.Limit > 0 {
-		argCount++
-		query += fmt.Sprintf(" LIMIT $%d", argCount)
 		args = append(args, filters.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	if filters.Offset > 0 {
+		args = append(args, filters.Offset)
+		query += fmt.Sprintf(" OFFSET $%d", len(args))
 	}
 
 	rows, err := s.db.GetConnection().Query(ctx, query, args...)
@@ -249,14 +272,14 @@ func (s *SecurityAuditService) GetSecurityEvents(ctx context.Context, filters Se
 	}
 	defer rows.Close()
 
-	var events []SecurityEvent
+	events := []SecurityEvent{}
 	for rows.Next() {
 		var event SecurityEvent
 		var metadataJSON string
 
 		err := rows.Scan(
 			&event.EventID, &event.EventType, &event.Description,
-			&event.UserID, &event.IPAddress, &event.UserAgent,
+			&event.UserID, &event.IPAddress, &event.UserAgent, &event.RequestID,
 			&metadataJSON, &event.Timestamp, &event.Severity,
 		)
 		if err != nil {
@@ -274,7 +297,172 @@ func (s *SecurityAuditService) GetSecurityEvents(ctx context.Context, filters Se
 		events = append(events, event)
 	}
 
-	return events, nil
+	return events, rows.Err()
+}
+
+// SecurityEventPage is one page of security events
+type SecurityEventPage struct {
+	Events     []SecurityEvent `json:"events"`
+	Total      int             `json:"total"`
+	Page       int             `json:"page"`
+	Limit      int             `json:"limit"`
+	TotalPages int             `json:"total_pages"`
+	HasNext    bool            `json:"has_next"`
+	HasPrev    bool            `json:"has_prev"`
+}
+
+// ListSecurityEvents returns a page of security events, newest first
+func (s *SecurityAuditService) ListSecurityEvents(ctx context.Context, filters SecurityEventFilters, page, limit int) (*SecurityEventPage, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = 50
+	}
+
+	whereClause, args := filters.where()
+	var total int
+	if err := s.db.GetConnection().QueryRow(ctx, "SELECT COUNT(*) FROM security_audit_log "+whereClause, args...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("failed to count security events: %w", err)
+	}
+
+	filters.Limit = limit
+	filters.Offset = (page - 1) * limit
+	events, err := s.GetSecurityEvents(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := (total + limit - 1) / limit
+	return &SecurityEventPage{
+		Events:     events,
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+		TotalPages: totalPages,
+		HasNext:    page < totalPages,
+		HasPrev:    page > 1,
+	}, nil
+}
+
+// auditExportMaxRows caps a single CSV export
+const auditExportMaxRows = 100000
+
+// ExportSecurityEventsCSV streams the filtered events as CSV, newest first, and returns the row count
+func (s *SecurityAuditService) ExportSecurityEventsCSV(ctx context.Context, filters SecurityEventFilters, w io.Writer) (int, error) {
+	whereClause, args := filters.where()
+	args = append(args, auditExportMaxRows)
+	rows, err := s.db.GetConnection().Query(ctx, `
+		SELECT event_id, timestamp, event_type, severity, COALESCE(user_id::text, ''), COALESCE(host(ip_address), ''),
+		       COALESCE(user_agent, ''), COALESCE(request_id, ''), description, COALESCE(metadata, '{}'::jsonb)::text
+		FROM security_audit_log
+	`+whereClause+fmt.Sprintf(" ORDER BY timestamp DESC LIMIT $%d", len(args)), args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query security events: %w", err)
+	}
+	defer rows.Close()
+
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"event_id", "timestamp", "event_type", "severity", "user_id", "ip_address", "user_agent", "request_id", "description", "metadata"})
+
+	count := 0
+	for rows.Next() {
+		var eventID uuid.UUID
+		var timestamp time.Time
+		var eventType, severity, userID, ipAddress, userAgent, requestID, description, metadata string
+		if err := rows.Scan(&eventID, &timestamp, &eventType, &severity, &userID, &ipAddress, &userAgent, &requestID, &description, &metadata); err != nil {
+			return count, fmt.Errorf("failed to scan security event: %w", err)
+		}
+
+		if err := writer.Write([]string{
+			eventID.String(), timestamp.UTC().Format(time.RFC3339), eventType, severity, userID,
+			ipAddress, userAgent, requestID, description, metadata,
+		}); err != nil {
+			return count, fmt.Errorf("failed to write CSV: %w", err)
+		}
+		count++
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return count, fmt.Errorf("failed to write CSV: %w", err)
+	}
+
+	return count, rows.Err()
+}
+
+// PurgeExpiredEvents deletes audit entries older than the retention period from the
+// unified log and the legacy audit_logs table it mirrors, along with acknowledged alerts,
+// login attempts and spent login challenges
+func (s *SecurityAuditService) PurgeExpiredEvents(ctx context.Context, retentionDays int) (int64, error) {
+	if retentionDays <= 0 {
+		return 0, fmt.Errorf("retention period must be positive")
+	}
+
+	result, err := s.db.GetConnection().Exec(ctx,
+		"DELETE FROM security_audit_log WHERE timestamp < NOW() - $1 * INTERVAL '1 day'", retentionDays)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge security audit log: %w", err)
+	}
+	purged := result.RowsAffected()
+
+	if _, err := s.db.GetConnection().Exec(ctx,
+		"DELETE FROM audit_logs WHERE created_at < NOW() - $1 * INTERVAL '1 day'", retentionDays); err != nil {
+		return purged, fmt.Errorf("failed to purge legacy audit logs: %w", err)
+	}
+
+	if _, err := s.db.GetConnection().Exec(ctx,
+		"DELETE FROM security_alerts WHERE status = 'acknowledged' AND last_event_at < NOW() - $1 * INTERVAL '1 day'", retentionDays); err != nil {
+		return purged, fmt.Errorf("failed to purge acknowledged security alerts: %w", err)
+	}
+
+	if _, err := s.db.GetConnection().Exec(ctx,
+		"DELETE FROM login_attempts WHERE created_at < NOW() - $1 * INTERVAL '1 day'", retentionDays); err != nil {
+		return purged, fmt.Errorf("failed to purge login attempts: %w", err)
+	}
+
+	if _, err := s.db.GetConnection().Exec(ctx,
+		"DELETE FROM login_challenges WHERE expires_at < NOW() - INTERVAL '1 day'"); err != nil {
+		return purged, fmt.Errorf("failed to purge login challenges: %w", err)
+	}
+
+	if purged > 0 {
+		s.LogSecurityEvent(ctx, SecurityEvent{
+			EventType:   EventTypeAuditLogPurged,
+			Description: fmt.Sprintf("%d audit events older than %d days purged", purged, retentionDays),
+			IPAddress:   "127.0.0.1",
+			UserAgent:   "System",
+			Metadata: map[string]interface{}{
+				"purged":         purged,
+				"retention_days": retentionDays,
+			},
+		})
+	}
+
+	return purged, nil
+}
+
+// RunRetentionPurge purges expired events immediately and then at every interval until ctx is cancelled
+func (s *SecurityAuditService) RunRetentionPurge(ctx context.Context, retentionDays int, interval time.Duration) {
+	if interval <= 0 {
+		interval = 24 * time.Hour
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if purged, err := s.PurgeExpiredEvents(ctx, retentionDays); err != nil {
+			slog.ErrorContext(ctx, "failed to purge audit log", "component", "audit_retention", "error", err)
+		} else if purged > 0 {
+			slog.InfoContext(ctx, "purged expired audit events", "component", "audit_retention", "purged", purged, "retention_days", retentionDays)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
 }
 
 // SecurityEventFilters for filtering security events
@@ -283,15 +471,71 @@ type SecurityEventFilters struct {
 	UserID    *uuid.UUID
 	IPAddress string
 	Severity  string
+	RequestID string
 	StartTime time.Time
 	EndTime   time.Time
 	Limit     int
+	Offset    int
+}
+
+// where builds the WHERE clause and arguments shared by listing, counting and export
+func (f SecurityEventFilters) where() (string, []interface{}) {
+	conditions := []string{}
+	args := []interface{}{}
+	argIndex := 1
+
+	if f.EventType != "" {
+		conditions = append(conditions, fmt.Sprintf("event_type = $%d", argIndex))
+		args = append(args, f.EventType)
+		argIndex++
+	}
+
+	if f.UserID != nil {
+		conditions = append(conditions, fmt.Sprintf("user_id = $%d", argIndex))
+		args = append(args, *f.UserID)
+		argIndex++
+	}
+
+	if f.IPAddress != "" {
+		conditions = append(conditions, fmt.Sprintf("ip_address = $%d::inet", argIndex))
+		args = append(args, f.IPAddress)
+		argIndex++
+	}
+
+	if f.Severity != "" {
+		conditions = append(conditions, fmt.Sprintf("severity = $%d", argIndex))
+		args = append(args, f.Severity)
+		argIndex++
+	}
+
+	if f.RequestID != "" {
+		conditions = append(conditions, fmt.Sprintf("request_id = $%d", argIndex))
+		args = append(args, f.RequestID)
+		argIndex++
+	}
+
+	if !f.StartTime.IsZero() {
+		conditions = append(conditions, fmt.Sprintf("timestamp >= $%d", argIndex))
+		args = append(args, f.StartTime)
+		argIndex++
+	}
+
+	if !f.EndTime.IsZero() {
+		conditions = append(conditions, fmt.Sprintf("timestamp <= $%d", argIndex))
+		args = append(args, f.EndTime)
+		argIndex++
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
 }
 
 // determineSeverity determines the severity level based on event type
 func (s *SecurityAuditService) determineSeverity(eventType string) string {
 	switch eventType {
-	case EventTypeLogin, EventTypeLogout:
+	case EventTypeLogin, EventTypeLogout, EventTypeImpersonatedRequest:
 		return SeverityLow
 	case EventTypeAdminRegistration, EventTypePasswordReset:
 		return SeverityMedium
@@ -299,7 +543,7 @@ func (s *SecurityAuditService) determineSeverity(eventType string) string {
 		return SeverityMedium
 	case EventTypeSuspiciousActivity, EventTypeUnauthorizedAccess, EventTypePermissionDenied:
 		return SeverityHigh
-	case EventTypeSecurityViolation, EventTypeAdminRegistrationFailed:
+	case EventTypeSecurityViolation, EventTypeAdminRegistrationFailed, EventTypeImpersonationStarted, EventTypeEncryptionKeyRotated:
 		return SeverityHigh
 	case EventTypeAccountLocked:
 		return SeverityCritical
@@ -307,35 +551,3 @@ func (s *SecurityAuditService) determineSeverity(eventType string) string {
 		return SeverityMedium
 	}
 }
-
-// CreateAuditLogTable creates the security audit log table if it doesn't exist
-func (s *SecurityAuditService) CreateAuditLogTable(ctx context.Context) error {
-	query := `
-		CREATE TABLE IF NOT EXISTS security_audit_log (
-			event_id UUID PRIMARY KEY,
-			event_type VARCHAR(50) NOT NULL,
-			description TEXT NOT NULL,
-			user_id UUID REFERENCES user_profiles(user_id),
-			ip_address INET,
-			user_agent TEXT,
-			metadata JSONB,
-			timestamp TIMESTAMP WITH TIME ZONE NOT NULL,
-			severity VARCHAR(20) NOT NULL,
-			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
-		);
-
-		-- Create indexes for better query performance
-		CREATE INDEX IF NOT EXISTS idx_security_audit_event_type ON security_audit_log(event_type);
-		CREATE INDEX IF NOT EXISTS idx_security_audit_user_id ON security_audit_log(user_id);
-		CREATE INDEX IF NOT EXISTS idx_security_audit_timestamp ON security_audit_log(timestamp);
-		CREATE INDEX IF NOT EXISTS idx_security_audit_severity ON security_audit_log(severity);
-		CREATE INDEX IF NOT EXISTS idx_security_audit_ip_address ON security_audit_log(ip_address);
-	`
-
-	_, err := s.db.GetConnection().Exec(ctx, query)
-	if err != nil {
-		return fmt.Errorf("failed to create audit log table: %w", err)
-	}
-
-	return nil
-}
@@ -8,6 +8,8 @@ import (
 	"time"
 	"unicode"
 
+	"mowesport/internal/models"
+
 	"github.com/google/uuid"
 )
 
@@ -178,7 +180,7 @@ func (s *SecurityValidationService) ValidateIdentificationFormat(identification,
 	// Country-specific validation
 	switch strings.ToUpper(country) {
 	case "CO", "COLOMBIA":
-		return s.validateColombianID(identification)
+		return s.ValidateColombianIdentification(models.IdentificationTypeCC, identification)
 	case "US", "USA", "UNITED STATES":
 		return s.validateUSSSN(identification)
 	case "MX", "MEXICO":
@@ -189,16 +191,166 @@ func (s *SecurityValidationService) ValidateIdentificationFormat(identification,
 	}
 }
 
-// validateColombianID validates Colombian cédula format
-func (s *SecurityValidationService) validateColombianID(id string) error {
-	// Colombian cédula: 8-10 digits
-	idRegex := regexp.MustCompile(`^\d{8,10}$`)
-	if !idRegex.MatchString(id) {
-		return fmt.Errorf("Colombian cédula must be 8-10 digits")
+// IdentificationTypes are the accepted identification document types
+var IdentificationTypes = []string{
+	models.IdentificationTypeCC, models.IdentificationTypeTI, models.IdentificationTypeRC,
+	models.IdentificationTypeCE, models.IdentificationTypePEP, models.IdentificationTypePPT,
+	models.IdentificationTypePA, models.IdentificationTypeNIT,
+}
+
+// colombianIDFormats are the number formats of each document type, matched after removing
+// spaces and dots. NIT numbers are checked by ValidateNIT.
+var colombianIDFormats = map[string]struct {
+	pattern *regexp.Regexp
+	message string
+}{
+	models.IdentificationTypeCC:  {regexp.MustCompile(`^\d{6,10}$`), "cédula de ciudadanía must be 6-10 digits"},
+	models.IdentificationTypeTI:  {regexp.MustCompile(`^\d{10,11}$`), "tarjeta de identidad must be 10-11 digits"},
+	models.IdentificationTypeRC:  {regexp.MustCompile(`^\d{10,11}$`), "registro civil (NUIP) must be 10-11 digits"},
+	models.IdentificationTypeCE:  {regexp.MustCompile(`^\d{6,10}$`), "cédula de extranjería must be 6-10 digits"},
+	models.IdentificationTypePEP: {regexp.MustCompile(`^\d{15}$`), "PEP must be 15 digits"},
+	models.IdentificationTypePPT: {regexp.MustCompile(`^\d{6,10}$`), "PPT must be 6-10 digits"},
+	models.IdentificationTypePA:  {regexp.MustCompile(`^[A-Z0-9]{5,20}$`), "passport must be 5-20 letters or digits"},
+}
+
+// identificationAges are the ages at which national documents are held: from (inclusive)
+// and until (exclusive, 0 for no limit)
+var identificationAges = map[string]struct{ from, until int }{
+	models.IdentificationTypeCC: {18, 0},
+	models.IdentificationTypeTI: {7, 18},
+	models.IdentificationTypeRC: {0, 7},
+}
+
+var nitRegex = regexp.MustCompile(`^(\d{5,15})-?(\d)$`)
+
+// nitWeights are the DIAN prime weights, applied from the rightmost digit
+var nitWeights = []int{3, 7, 13, 17, 19, 23, 29, 37, 41, 43, 47, 53, 59, 67, 71}
+
+// ValidateColombianIdentification validates a Colombian identification number for its
+// document type. An empty type means CC, the only document accepted before types were
+// recorded.
+func (s *SecurityValidationService) ValidateColombianIdentification(identificationType, identification string) error {
+	identification = strings.TrimSpace(identification)
+	if identification == "" {
+		return nil // Identification is optional
+	}
+	if identificationType == "" {
+		identificationType = models.IdentificationTypeCC
+	}
+
+	if identificationType == models.IdentificationTypeNIT {
+		return s.ValidateNIT(identification)
+	}
+	format, ok := colombianIDFormats[identificationType]
+	if !ok {
+		return fmt.Errorf("identification_type must be one of %s", strings.Join(IdentificationTypes, ", "))
+	}
+	number := strings.ToUpper(strings.NewReplacer(" ", "", ".", "").Replace(identification))
+	if !format.pattern.MatchString(number) {
+		return fmt.Errorf("%s", format.message)
 	}
 	return nil
 }
 
+// ValidateNIT validates a NIT and its check digit (DIAN modulo 11), e.g. 900.123.456-8
+func (s *SecurityValidationService) ValidateNIT(nit string) error {
+	parts := nitRegex.FindStringSubmatch(strings.NewReplacer(" ", "", ".", "").Replace(nit))
+	if parts == nil {
+		return fmt.Errorf("NIT must be 5-15 digits followed by its check digit (900123456-8)")
+	}
+	if NITCheckDigit(parts[1]) != int(parts[2][0]-'0') {
+		return fmt.Errorf("NIT check digit is invalid")
+	}
+	return nil
+}
+
+// NITCheckDigit computes the check digit of a NIT given without it
+func NITCheckDigit(nit string) int {
+	sum := 0
+	for i := 0; i < len(nit) && i < len(nitWeights); i++ {
+		sum += int(nit[len(nit)-1-i]-'0') * nitWeights[i]
+	}
+	if remainder := sum % 11; remainder > 1 {
+		return 11 - remainder
+	}
+	return sum % 11
+}
+
+// ValidateIdentificationAge checks a national document type matches the holder's age on
+// the given date: cédula de ciudadanía from 18, tarjeta de identidad from 7 to 17 and
+// registro civil under 7. Other types are held at any age.
+func (s *SecurityValidationService) ValidateIdentificationAge(identificationType string, dateOfBirth, on time.Time) error {
+	if identificationType == "" {
+		identificationType = models.IdentificationTypeCC
+	}
+	ages, ok := identificationAges[identificationType]
+	if !ok {
+		return nil
+	}
+	if on.Before(dateOfBirth.AddDate(ages.from, 0, 0)) {
+		return fmt.Errorf("%s is only issued from age %d", identificationType, ages.from)
+	}
+	if ages.until > 0 && !on.Before(dateOfBirth.AddDate(ages.until, 0, 0)) {
+		return fmt.Errorf("%s is only held under age %d", identificationType, ages.until)
+	}
+	return nil
+}
+
+// ValidatePlayerIdentification validates the identification of a player record, including
+// the age rules of national documents. Players cannot be identified by a NIT.
+func (s *SecurityValidationService) ValidatePlayerIdentification(identificationType, identification string, dateOfBirth, on time.Time) error {
+	if identificationType == models.IdentificationTypeNIT {
+		return fmt.Errorf("NIT is not a valid identification for a player")
+	}
+	if err := s.ValidateColombianIdentification(identificationType, identification); err != nil {
+		return err
+	}
+	if strings.TrimSpace(identification) == "" {
+		return nil
+	}
+	return s.ValidateIdentificationAge(identificationType, dateOfBirth, on)
+}
+
+// InferIdentificationType guesses the national document type of an identification stored
+// without one, or returns "" when it is ambiguous or matches none. Without a date of birth
+// only CC is considered.
+func (s *SecurityValidationService) InferIdentificationType(identification string, dateOfBirth *time.Time, on time.Time) string {
+	if strings.TrimSpace(identification) == "" {
+		return ""
+	}
+	candidates := []string{models.IdentificationTypeCC}
+	if dateOfBirth != nil {
+		candidates = append(candidates, models.IdentificationTypeTI, models.IdentificationTypeRC)
+	}
+
+	inferred := ""
+	for _, candidate := range candidates {
+		if s.ValidateColombianIdentification(candidate, identification) != nil {
+			continue
+		}
+		if dateOfBirth != nil && s.ValidateIdentificationAge(candidate, *dateOfBirth, on) != nil {
+			continue
+		}
+		if inferred != "" {
+			return ""
+		}
+		inferred = candidate
+	}
+	return inferred
+}
+
+// IdentificationTypeFor is the document type to store with an identification: nil without
+// one, CC when none was given
+func IdentificationTypeFor(identificationType, identification string) *string {
+	if strings.TrimSpace(identification) == "" {
+		return nil
+	}
+	if identificationType == "" {
+		identificationType = models.IdentificationTypeCC
+	}
+	return &identificationType
+}
+
 // validateUSSSN validates US Social Security Number format
 func (s *SecurityValidationService) validateUSSSN(ssn string) error {
 	// SSN format: XXX-XX-XXXX or XXXXXXXXX
@@ -376,30 +528,30 @@ func (s *SecurityValidationService) ValidateAllowedEmailDomains(email string, al
 	return fmt.Errorf("email domain '%s' is not allowed", domain)
 }
 
-// LogSecurityEvent logs security-related events for auditing
-func (s *SecurityValidationService) LogSecurityEvent(ctx context.Context, eventType, description string, metadata map[string]interface{}) {
-	// In production, this should write to a proper logging system
-	// For now, we'll just print to console
-	fmt.Printf("[SECURITY] %s: %s - %v\n", eventType, description, metadata)
-
-	// TODO: Implement proper security logging to database or external service
-	// This should include:
-	// - Timestamp
-	// - Event type
-	// - User ID (if available)
-	// - IP address
-	// - User agent
-	// - Description
-	// - Metadata
-}
-
-// GetClientIP extracts client IP from request context
+// GetClientIP returns the client IP recorded on the request context, or "unknown"
+// outside of an HTTP request
 func (s *SecurityValidationService) GetClientIP(ctx context.Context) string {
-	// This would typically extract IP from HTTP headers
-	// For now, return a placeholder
+	if info, ok := RequestInfoFromContext(ctx); ok && info.IPAddress != "" {
+		return info.IPAddress
+	}
 	return "unknown"
 }
 
+// ValidateRegistrationFields validates the contact fields of a user registered by an admin.
+// Phone and identification are optional; the identification type defaults to CC.
+func (s *SecurityValidationService) ValidateRegistrationFields(email, phone, identificationType, identification string) error {
+	if err := s.ValidateEmailRFC5322(email); err != nil {
+		return fmt.Errorf("email validation failed: %w", err)
+	}
+	if err := s.ValidateInternationalPhone(phone); err != nil {
+		return fmt.Errorf("phone validation failed: %w", err)
+	}
+	if err := s.ValidateColombianIdentification(identificationType, identification); err != nil {
+		return fmt.Errorf("identification validation failed: %w", err)
+	}
+	return nil
+}
+
 // IsValidUUID validates UUID format
 func (s *SecurityValidationService) IsValidUUID(uuidStr string) error {
 	_, err := uuid.Parse(uuidStr)
@@ -408,49 +560,3 @@ func (s *SecurityValidationService) IsValidUUID(uuidStr string) error {
 	}
 	return nil
 }
-
-// ValidatePasswordStrength validates password strength
-func (s *SecurityValidationService) ValidatePasswordStrength(password string) error {
-	if len(password) < 8 {
-		return fmt.Errorf("password must be at least 8 characters long")
-	}
-
-	if len(password) > 128 {
-		return fmt.Errorf("password must not exceed 128 characters")
-	}
-
-	var (
-		hasUpper   = false
-		hasLower   = false
-		hasNumber  = false
-		hasSpecial = false
-	)
-
-	for _, char := range password {
-		switch {
-		case unicode.IsUpper(char):
-			hasUpper = true
-		case unicode.IsLower(char):
-			hasLower = true
-		case unicode.IsNumber(char):
-			hasNumber = true
-		case unicode.IsPunct(char) || unicode.IsSymbol(char):
-			hasSpecial = true
-		}
-	}
-
-	if !hasUpper {
-		return fmt.Errorf("password must contain at least one uppercase letter")
-	}
-	if !hasLower {
-		return fmt.Errorf("password must contain at least one lowercase letter")
-	}
-	if !hasNumber {
-		return fmt.Errorf("password must contain at least one number")
-	}
-	if !hasSpecial {
-		return fmt.Errorf("password must contain at least one special character")
-	}
-
-	return nil
-}
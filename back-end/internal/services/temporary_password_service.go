@@ -67,20 +67,6 @@ func (s *TemporaryPasswordService) CreateTemporaryPassword(ctx context.Context,
 		return "", fmt.Errorf("failed to update user password: %w", err)
 	}
 
-	// Log the temporary password creation
-	_, err = tx.Exec(ctx, `
-		INSERT INTO audit_logs (
-			user_id, action, table_name, record_id, 
-			new_values, ip_address, user_agent
-		) VALUES (
-			$1, 'TEMP_PASSWORD_CREATED', 'user_profiles', $2,
-			$3, '127.0.0.1', 'System'
-		)
-	`, userID, userID.String(), fmt.Sprintf(`{"expiration_date": "%s"}`, expirationDate.Format(time.RFC3339)))
-	if err != nil {
-		return "", fmt.Errorf("failed to log temporary password creation: %w", err)
-	}
-
 	// Commit transaction
 	if err := tx.Commit(ctx); err != nil {
 		return "", fmt.Errorf("failed to commit transaction: %w", err)
@@ -170,24 +156,6 @@ func (s *TemporaryPasswordService) MarkTemporaryPasswordAsUsed(ctx context.Conte
 	return nil
 }
 
-// RegenerateTemporaryPassword regenerates a temporary password for a user
-func (s *TemporaryPasswordService) RegenerateTemporaryPassword(ctx context.Context, userID uuid.UUID, expirationHours int) (string, error) {
-	// Log the regeneration attempt
-	s.auditService.LogSecurityEvent(ctx, SecurityEvent{
-		EventType:   "TEMPORARY_PASSWORD_REGENERATION_REQUESTED",
-		Description: "Temporary password regeneration requested",
-		UserID:      &userID,
-		IPAddress:   "127.0.0.1",
-		UserAgent:   "System",
-		Metadata: map[string]interface{}{
-			"user_id": userID,
-		},
-	})
-
-	// Create new temporary password (this will overwrite the old one)
-	return s.CreateTemporaryPassword(ctx, userID, expirationHours)
-}
-
 // CleanupExpiredTemporaryPasswords removes expired temporary password markers
 func (s *TemporaryPasswordService) CleanupExpiredTemporaryPasswords(ctx context.Context) (int, error) {
 	result, err := s.db.GetConnection().Exec(ctx, `
@@ -0,0 +1,377 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mowesport/internal/models"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Import files are small spreadsheets; larger XLSX parts are rejected rather than inflated
+const xlsxMaxPartSize = 50 << 20
+
+// importColumns maps accepted header names (English and Spanish) to row fields
+var importColumns = map[string]string{
+	"role":                "role",
+	"rol":                 "role",
+	"first_name":          "first_name",
+	"nombres":             "first_name",
+	"nombre":              "first_name",
+	"last_name":           "last_name",
+	"apellidos":           "last_name",
+	"apellido":            "last_name",
+	"email":               "email",
+	"correo":              "email",
+	"phone":               "phone",
+	"telefono":            "phone",
+	"celular":             "phone",
+	"identification":      "identification",
+	"identificacion":      "identification",
+	"documento":           "identification",
+	"cedula":              "identification",
+	"identification_type": "identification_type",
+	"document_type":       "identification_type",
+	"tipo_documento":      "identification_type",
+	"tipo_de_documento":   "identification_type",
+	"tipo_identificacion": "identification_type",
+	"team":                "team",
+	"equipo":              "team",
+	"date_of_birth":       "date_of_birth",
+	"fecha_nacimiento":    "date_of_birth",
+	"fecha_de_nacimiento": "date_of_birth",
+	"position":            "position",
+	"posicion":            "position",
+	"jersey_number":       "jersey_number",
+	"dorsal":              "jersey_number",
+	"blood_type":          "blood_type",
+	"tipo_sangre":         "blood_type",
+	"rh":                  "blood_type",
+}
+
+// readImportSheet returns the records of a CSV file or of the first worksheet of an XLSX file
+func readImportSheet(fileName string, data []byte) ([][]string, error) {
+	switch strings.ToLower(path.Ext(fileName)) {
+	case ".csv":
+		return readImportCSV(data)
+	case ".xlsx":
+		return readImportXLSX(data)
+	default:
+		return nil, fmt.Errorf("invalid file: only .csv and .xlsx files are supported")
+	}
+}
+
+// readImportCSV reads comma or semicolon separated files, as exported by spreadsheet
+// applications in English and Spanish locales
+func readImportCSV(data []byte) ([][]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if !utf8.Valid(data) {
+		return nil, fmt.Errorf("invalid file: CSV files must be UTF-8 encoded")
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	firstLine, _, _ := strings.Cut(string(data), "\n")
+	if strings.Count(firstLine, ";") > strings.Count(firstLine, ",") {
+		reader.Comma = ';'
+	}
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid file: %w", err)
+	}
+	return records, nil
+}
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		RelID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	if len(t.Runs) == 0 {
+		return t.Text
+	}
+	var b strings.Builder
+	for _, run := range t.Runs {
+		b.WriteString(run.Text)
+	}
+	return b.String()
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxText `xml:"si"`
+}
+
+type xlsxWorksheet struct {
+	Rows []struct {
+		Number int `xml:"r,attr"`
+		Cells  []struct {
+			Ref    string   `xml:"r,attr"`
+			Type   string   `xml:"t,attr"`
+			Value  string   `xml:"v"`
+			Inline xlsxText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// readImportXLSX reads cell values of the first worksheet. Formatting is ignored, so
+// dates arrive as serial numbers (see parseImportDate).
+func readImportXLSX(data []byte) ([][]string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid file: not a valid XLSX workbook")
+	}
+
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, file := range archive.File {
+		files[file.Name] = file
+	}
+
+	var workbook xlsxWorkbook
+	if err := decodeXLSXPart(files, "xl/workbook.xml", &workbook); err != nil {
+		return nil, err
+	}
+	if len(workbook.Sheets) == 0 {
+		return nil, fmt.Errorf("invalid file: the workbook has no worksheets")
+	}
+
+	var relationships xlsxRelationships
+	if err := decodeXLSXPart(files, "xl/_rels/workbook.xml.rels", &relationships); err != nil {
+		return nil, err
+	}
+	sheetPath := ""
+	for _, rel := range relationships.Relationships {
+		if rel.ID == workbook.Sheets[0].RelID {
+			sheetPath = rel.Target
+			break
+		}
+	}
+	if sheetPath == "" {
+		return nil, fmt.Errorf("invalid file: first worksheet not found")
+	}
+	if strings.HasPrefix(sheetPath, "/") {
+		sheetPath = strings.TrimPrefix(sheetPath, "/")
+	} else {
+		sheetPath = path.Join("xl", sheetPath)
+	}
+
+	var sharedStrings xlsxSharedStrings
+	if _, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := decodeXLSXPart(files, "xl/sharedStrings.xml", &sharedStrings); err != nil {
+			return nil, err
+		}
+	}
+
+	var sheet xlsxWorksheet
+	if err := decodeXLSXPart(files, sheetPath, &sheet); err != nil {
+		return nil, err
+	}
+
+	records := make([][]string, 0, len(sheet.Rows))
+	for _, row := range sheet.Rows {
+		// Empty rows are left out of the sheet; keep line numbers aligned with the spreadsheet
+		for row.Number > len(records)+1 {
+			records = append(records, nil)
+		}
+
+		var record []string
+		for i, cell := range row.Cells {
+			column := xlsxColumnIndex(cell.Ref)
+			if column < 0 {
+				column = i
+			}
+			for len(record) <= column {
+				record = append(record, "")
+			}
+
+			switch cell.Type {
+			case "s":
+				index, err := strconv.Atoi(cell.Value)
+				if err != nil || index < 0 || index >= len(sharedStrings.Items) {
+					return nil, fmt.Errorf("invalid file: bad shared string in cell %s", cell.Ref)
+				}
+				record[column] = sharedStrings.Items[index].String()
+			case "inlineStr":
+				record[column] = cell.Inline.String()
+			case "", "n":
+				record[column] = normalizeXLSXNumber(cell.Value)
+			default:
+				record[column] = cell.Value
+			}
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+func decodeXLSXPart(files map[string]*zip.File, name string, v interface{}) error {
+	file, ok := files[name]
+	if !ok {
+		return fmt.Errorf("invalid file: %s is missing from the workbook", name)
+	}
+	if file.UncompressedSize64 > xlsxMaxPartSize {
+		return fmt.Errorf("invalid file: %s is too large", name)
+	}
+
+	reader, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("invalid file: %w", err)
+	}
+	defer reader.Close()
+
+	if err := xml.NewDecoder(io.LimitReader(reader, xlsxMaxPartSize)).Decode(v); err != nil {
+		return fmt.Errorf("invalid file: cannot read %s", name)
+	}
+	return nil
+}
+
+// xlsxColumnIndex converts the column letters of a cell reference such as "AB12" to a zero-based index
+func xlsxColumnIndex(ref string) int {
+	index := 0
+	letters := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		index = index*26 + int(r-'A'+1)
+		letters++
+	}
+	if letters == 0 {
+		return -1
+	}
+	return index - 1
+}
+
+// normalizeXLSXNumber formats numeric cells without exponents, so identification and
+// phone numbers typed as numbers keep all their digits
+func normalizeXLSXNumber(value string) string {
+	if !strings.ContainsAny(value, "eE") {
+		return value
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return value
+	}
+	return strconv.FormatFloat(number, 'f', -1, 64)
+}
+
+// parseImportRows maps records to rows using the first non-blank line as header. Rows
+// without a role column value get defaultRole. Blank lines are skipped.
+func parseImportRows(records [][]string, defaultRole string) ([]models.UserImportRow, error) {
+	headerLine := 0
+	for headerLine < len(records) && isBlankRecord(records[headerLine]) {
+		headerLine++
+	}
+	if headerLine == len(records) {
+		return nil, fmt.Errorf("invalid file: the file is empty")
+	}
+
+	columns := make(map[string]int)
+	for i, header := range records[headerLine] {
+		name := strings.ToLower(strings.TrimSpace(header))
+		name = strings.NewReplacer(" ", "_", "-", "_", "á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u").Replace(name)
+		if field, ok := importColumns[name]; ok {
+			if _, duplicate := columns[field]; duplicate {
+				return nil, fmt.Errorf("invalid file: column %s appears more than once", field)
+			}
+			columns[field] = i
+		}
+	}
+	for _, required := range []string{"first_name", "last_name", "email"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("invalid file: missing required column %s", required)
+		}
+	}
+	if _, ok := columns["role"]; !ok && defaultRole == "" {
+		return nil, fmt.Errorf("invalid file: missing role column and no default role given")
+	}
+
+	var rows []models.UserImportRow
+	for line := headerLine + 1; line < len(records); line++ {
+		record := records[line]
+		value := func(field string) string {
+			index, ok := columns[field]
+			if !ok || index >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[index])
+		}
+
+		if isBlankRecord(record) {
+			continue
+		}
+
+		row := models.UserImportRow{
+			RowNumber:          line + 1, // Spreadsheet line number
+			Role:               strings.ToLower(value("role")),
+			FirstName:          value("first_name"),
+			LastName:           value("last_name"),
+			Email:              strings.ToLower(value("email")),
+			Phone:              value("phone"),
+			Identification:     value("identification"),
+			IdentificationType: strings.ToUpper(value("identification_type")),
+			Team:               value("team"),
+			DateOfBirth:        value("date_of_birth"),
+			Position:           value("position"),
+			BloodType:          strings.ToUpper(value("blood_type")),
+		}
+		if row.Role == "" {
+			row.Role = defaultRole
+		}
+		if jersey := value("jersey_number"); jersey != "" {
+			number, err := strconv.Atoi(jersey)
+			if err != nil {
+				number = -1 // Reported by row validation
+			}
+			row.JerseyNumber = &number
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func isBlankRecord(record []string) bool {
+	return strings.TrimSpace(strings.Join(record, "")) == ""
+}
+
+// parseImportDate accepts YYYY-MM-DD, DD/MM/YYYY or a spreadsheet date serial number
+func parseImportDate(value string) (time.Time, error) {
+	if date, err := time.Parse("2006-01-02", value); err == nil {
+		return date, nil
+	}
+	if date, err := time.Parse("02/01/2006", value); err == nil {
+		return date, nil
+	}
+	if serial, err := strconv.ParseFloat(value, 64); err == nil && serial > 0 && serial < 100000 {
+		// Serial dates count days from 1899-12-30 (accounting for the 1900 leap year bug)
+		return time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC).AddDate(0, 0, int(serial)), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid date of birth, use YYYY-MM-DD")
+}
@@ -0,0 +1,858 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mowesport/internal/config"
+	"mowesport/internal/database"
+	"mowesport/internal/models"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Upload limits for bulk imports
+const (
+	UserImportMaxFileSize = 5 << 20
+	userImportMaxRows     = 5000
+)
+
+// importableRoles are the roles a league roster file may contain
+var importableRoles = []string{models.RoleOwner, models.RoleReferee, models.RoleCoach, models.RolePlayer}
+
+var importBloodTypes = map[string]bool{
+	"A+": true, "A-": true, "B+": true, "B-": true, "AB+": true, "AB-": true, "O+": true, "O-": true,
+}
+
+// UserImportService registers owners, referees, coaches and players in bulk from CSV/XLSX
+// files. Rows get the same checks as single registrations and every user receives an invitation.
+type UserImportService struct {
+	db                *database.Database
+	config            *config.Config
+	delegation        *DelegationService
+	invitationService *InvitationService
+	securityValidator *SecurityValidationService
+	fieldEncryption   *FieldEncryptionService
+	auditService      *SecurityAuditService
+}
+
+// NewUserImportService creates a new user import service
+func NewUserImportService(db *database.Database, cfg *config.Config) *UserImportService {
+	return &UserImportService{
+		db:                db,
+		config:            cfg,
+		delegation:        NewDelegationService(db),
+		invitationService: NewInvitationService(db, cfg),
+		securityValidator: NewSecurityValidationService(),
+		fieldEncryption:   NewFieldEncryptionService(db, cfg),
+		auditService:      NewSecurityAuditService(db),
+	}
+}
+
+// importScope holds what every row of an import shares
+type importScope struct {
+	request     *models.UserImportRequest
+	requestedBy uuid.UUID
+	scopes      map[string]ResourceScope // Resolved per role
+	denied      map[string]string        // Roles the requester may not create, with the reason
+}
+
+// Import validates the file and, unless it is a dry run, commits it. Atomic imports with
+// invalid rows are not committed; the report lists the problems. Resuming a partial import
+// skips the emails it already imported.
+func (s *UserImportService) Import(ctx context.Context, req *models.UserImportRequest, data []byte, requestedBy uuid.UUID) (*models.UserImportReport, error) {
+	if len(data) > UserImportMaxFileSize {
+		return nil, fmt.Errorf("invalid file: files are limited to %d MB", UserImportMaxFileSize>>20)
+	}
+	if req.Mode == "" {
+		req.Mode = models.UserImportModeAtomic
+	}
+	if req.Mode != models.UserImportModeAtomic && req.Mode != models.UserImportModePartial {
+		return nil, fmt.Errorf("invalid mode: use atomic or partial")
+	}
+	if req.Role != "" && !isImportableRole(req.Role) {
+		return nil, fmt.Errorf("invalid role: imports accept %s", strings.Join(importableRoles, ", "))
+	}
+
+	imported := map[string]uuid.UUID{}
+	if req.ResumeImportID != nil {
+		var err error
+		if imported, err = s.loadResumedImport(ctx, req, requestedBy); err != nil {
+			return nil, err
+		}
+	}
+
+	records, err := readImportSheet(req.FileName, data)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := parseImportRows(records, req.Role)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("invalid file: no rows to import")
+	}
+	if len(rows) > userImportMaxRows {
+		return nil, fmt.Errorf("invalid file: imports are limited to %d rows", userImportMaxRows)
+	}
+
+	scope := &importScope{
+		request:     req,
+		requestedBy: requestedBy,
+		scopes:      map[string]ResourceScope{},
+		denied:      map[string]string{},
+	}
+	results, err := s.validateRows(ctx, scope, rows, imported)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.UserImportReport{
+		FileName: req.FileName,
+		DryRun:   req.DryRun,
+		Mode:     req.Mode,
+		Rows:     results,
+	}
+	summarizeImport(report)
+
+	if req.DryRun || report.ValidRows == 0 || (req.Mode == models.UserImportModeAtomic && report.InvalidRows > 0) {
+		return report, nil
+	}
+
+	checksum := sha256.Sum256(data)
+	if req.Mode == models.UserImportModeAtomic {
+		err = s.commitAtomic(ctx, scope, rows, report, hex.EncodeToString(checksum[:]))
+	} else {
+		err = s.commitPartial(ctx, scope, rows, report, hex.EncodeToString(checksum[:]))
+	}
+	if err != nil {
+		return nil, err
+	}
+	summarizeImport(report)
+
+	s.auditService.LogSecurityEvent(ctx, SecurityEvent{
+		EventType:   EventTypeUserImport,
+		Description: fmt.Sprintf("Imported %d users from %s", report.ImportedRows, req.FileName),
+		UserID:      &requestedBy,
+		Metadata: map[string]interface{}{
+			"import_id":     report.ImportID,
+			"mode":          req.Mode,
+			"status":        report.Status,
+			"city_id":       req.CityID,
+			"sport_id":      req.SportID,
+			"total_rows":    report.TotalRows,
+			"imported_rows": report.ImportedRows,
+			"failed_rows":   report.FailedRows,
+			"invalid_rows":  report.InvalidRows,
+			"skipped_rows":  report.SkippedRows,
+		},
+	})
+
+	return report, nil
+}
+
+// loadResumedImport checks the resumed import belongs to the requester, takes its
+// city/sport/tournament and returns the emails it already imported
+func (s *UserImportService) loadResumedImport(ctx context.Context, req *models.UserImportRequest, requestedBy uuid.UUID) (map[string]uuid.UUID, error) {
+	var createdBy uuid.UUID
+	var status string
+	err := s.db.GetConnection().QueryRow(ctx, `
+		SELECT created_by_user_id, status, city_id, sport_id, tournament_id
+		FROM user_imports WHERE import_id = $1
+	`, *req.ResumeImportID).Scan(&createdBy, &status, &req.CityID, &req.SportID, &req.TournamentID)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && createdBy != requestedBy) {
+		return nil, fmt.Errorf("import not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load import: %w", err)
+	}
+	if status == models.UserImportStatusCompleted {
+		return nil, fmt.Errorf("import already completed")
+	}
+	req.Mode = models.UserImportModePartial
+
+	rows, err := s.db.GetConnection().Query(ctx, `
+		SELECT email, user_id FROM user_import_rows
+		WHERE import_id = $1 AND status = 'imported' AND user_id IS NOT NULL
+	`, *req.ResumeImportID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load imported rows: %w", err)
+	}
+	defer rows.Close()
+
+	imported := map[string]uuid.UUID{}
+	for rows.Next() {
+		var email string
+		var userID uuid.UUID
+		if err := rows.Scan(&email, &userID); err != nil {
+			return nil, fmt.Errorf("failed to scan imported row: %w", err)
+		}
+		imported[email] = userID
+	}
+	return imported, rows.Err()
+}
+
+// validateRows runs the registration checks on every row, plus duplicate, team and
+// delegation checks across the file
+func (s *UserImportService) validateRows(ctx context.Context, scope *importScope, rows []models.UserImportRow, imported map[string]uuid.UUID) ([]models.UserImportRowResult, error) {
+	var emails, identifications []string
+	for _, row := range rows {
+		emails = append(emails, row.Email)
+		if row.Identification != "" {
+			identifications = append(identifications, row.Identification)
+		}
+	}
+
+	existingEmails, err := s.existingValues(ctx, `SELECT lower(email) FROM user_profiles WHERE lower(email) = ANY($1)`, emails)
+	if err != nil {
+		return nil, err
+	}
+	existingIDs, err := s.existingValues(ctx, `SELECT identification FROM user_profiles WHERE identification = ANY($1)`, identifications)
+	if err != nil {
+		return nil, err
+	}
+
+	// Player identifications are encrypted and only comparable through their blind index
+	playerIndexes, err := s.fieldEncryption.BlindIndexes(ctx, identifications)
+	if err != nil {
+		return nil, err
+	}
+	indexes := make([]string, 0, len(playerIndexes))
+	for index := range playerIndexes {
+		indexes = append(indexes, index)
+	}
+	existingIndexes, err := s.existingValues(ctx, `SELECT identification_bidx FROM players WHERE identification_bidx = ANY($1)`, indexes)
+	if err != nil {
+		return nil, err
+	}
+	for index := range existingIndexes {
+		existingIDs[playerIndexes[index]] = true
+	}
+
+	// Teams created by owner rows can be joined by player rows anywhere in the file
+	newTeams := map[string]bool{}
+	for _, row := range rows {
+		if row.Role == models.RoleOwner && row.Team != "" {
+			newTeams[strings.ToLower(row.Team)] = true
+		}
+	}
+	teams, err := s.existingTeams(ctx, scope.request.CityID, scope.request.SportID)
+	if err != nil {
+		return nil, err
+	}
+
+	seenEmails := map[string]int{}
+	seenIDs := map[string]int{}
+	seenTeams := map[string]int{}
+	results := make([]models.UserImportRowResult, 0, len(rows))
+	for _, row := range rows {
+		result := models.UserImportRowResult{RowNumber: row.RowNumber, Email: row.Email, Role: row.Role}
+
+		if userID, ok := imported[row.Email]; ok {
+			result.Status = models.UserImportRowSkipped
+			result.UserID = &userID
+			results = append(results, result)
+			continue
+		}
+
+		addError := func(format string, args ...interface{}) {
+			result.Errors = append(result.Errors, fmt.Sprintf(format, args...))
+		}
+
+		if !isImportableRole(row.Role) {
+			addError("role must be one of %s", strings.Join(importableRoles, ", "))
+		} else if reason, err := s.checkDelegation(ctx, scope, row.Role); err != nil {
+			return nil, err
+		} else if reason != "" {
+			addError("%s", reason)
+		}
+
+		if n := len([]rune(row.FirstName)); n < 2 || n > 100 {
+			addError("first_name must be between 2 and 100 characters")
+		}
+		if n := len([]rune(row.LastName)); n < 2 || n > 100 {
+			addError("last_name must be between 2 and 100 characters")
+		}
+		if err := s.securityValidator.ValidateRegistrationFields(row.Email, row.Phone, row.IdentificationType, row.Identification); err != nil {
+			addError("%s", err.Error())
+		}
+		if findings := s.securityValidator.DetectSuspiciousPatterns(map[string]string{
+			"first_name": row.FirstName, "last_name": row.LastName, "team": row.Team, "position": row.Position,
+		}); len(findings) > 0 {
+			addError("suspicious content: %s", strings.Join(findings, "; "))
+		}
+
+		if line, ok := seenEmails[row.Email]; ok {
+			addError("email repeats row %d", line)
+		} else if existingEmails[row.Email] {
+			addError("email is already registered")
+		}
+		seenEmails[row.Email] = row.RowNumber
+
+		if row.Identification != "" {
+			normalized := NormalizeIdentification(row.Identification)
+			if line, ok := seenIDs[normalized]; ok {
+				addError("identification repeats row %d", line)
+			} else if existingIDs[row.Identification] {
+				addError("identification is already registered")
+			}
+			seenIDs[normalized] = row.RowNumber
+		}
+
+		team := strings.ToLower(row.Team)
+		switch {
+		case row.Team == "":
+		case row.Role == models.RoleOwner:
+			if len(row.Team) > 200 {
+				addError("team name is too long")
+			} else if _, exists := teams[team]; exists {
+				addError("team %q already exists", row.Team)
+			} else if line, ok := seenTeams[team]; ok {
+				addError("team %q is already created by row %d", row.Team, line)
+			}
+			seenTeams[team] = row.RowNumber
+		case row.Role == models.RolePlayer:
+			if _, exists := teams[team]; !exists && !newTeams[team] {
+				addError("team %q not found in this city/sport", row.Team)
+			}
+		default:
+			addError("team assignment is only supported for owners and players")
+		}
+
+		if row.Role == models.RolePlayer {
+			// Player records require both fields
+			if row.DateOfBirth == "" {
+				addError("date_of_birth is required for players")
+			} else if dateOfBirth, err := parseImportDate(row.DateOfBirth); err != nil {
+				addError("%s", err.Error())
+			} else if row.Identification != "" {
+				if err := s.securityValidator.ValidatePlayerIdentification(row.IdentificationType, row.Identification, dateOfBirth, time.Now()); err != nil {
+					addError("identification validation failed: %s", err.Error())
+				}
+			}
+			if row.Identification == "" {
+				addError("identification is required for players")
+			}
+			if row.JerseyNumber != nil && (*row.JerseyNumber < 1 || *row.JerseyNumber > 99) {
+				addError("jersey_number must be between 1 and 99")
+			}
+			if row.BloodType != "" && !importBloodTypes[row.BloodType] {
+				addError("invalid blood_type")
+			}
+			if len(row.Position) > 50 {
+				addError("position is too long")
+			}
+		}
+
+		result.Status = models.UserImportRowValid
+		if len(result.Errors) > 0 {
+			result.Status = models.UserImportRowInvalid
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// checkDelegation resolves the scope of role once per import and returns why the
+// requester may not create it, if they may not
+func (s *UserImportService) checkDelegation(ctx context.Context, scope *importScope, role string) (string, error) {
+	if reason, ok := scope.denied[role]; ok {
+		return reason, nil
+	}
+	if _, ok := scope.scopes[role]; ok {
+		return "", nil
+	}
+
+	req := scope.request
+	resourceScope, err := s.delegation.ResolveScope(ctx, role, &req.CityID, &req.SportID, req.TournamentID)
+	if err == nil {
+		err = s.delegation.Authorize(ctx, scope.requestedBy, DelegationCreate, role, resourceScope)
+	}
+	if err != nil {
+		message := err.Error()
+		if !strings.Contains(message, "insufficient permissions") && !strings.Contains(message, "invalid scope") &&
+			!strings.Contains(message, "tournament not found") {
+			return "", err
+		}
+		scope.denied[role] = message
+		return message, nil
+	}
+
+	scope.scopes[role] = resourceScope
+	return "", nil
+}
+
+func (s *UserImportService) existingValues(ctx context.Context, query string, values []string) (map[string]bool, error) {
+	existing := map[string]bool{}
+	if len(values) == 0 {
+		return existing, nil
+	}
+
+	rows, err := s.db.GetConnection().Query(ctx, query, values)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing users: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, fmt.Errorf("failed to scan existing user: %w", err)
+		}
+		existing[value] = true
+	}
+	return existing, rows.Err()
+}
+
+// existingTeams returns the active teams of a city/sport by lower-cased name
+func (s *UserImportService) existingTeams(ctx context.Context, cityID, sportID uuid.UUID) (map[string]uuid.UUID, error) {
+	rows, err := s.db.GetConnection().Query(ctx, `
+		SELECT lower(name), team_id FROM teams WHERE city_id = $1 AND sport_id = $2 AND is_active = true
+	`, cityID, sportID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load teams: %w", err)
+	}
+	defer rows.Close()
+
+	teams := map[string]uuid.UUID{}
+	for rows.Next() {
+		var name string
+		var teamID uuid.UUID
+		if err := rows.Scan(&name, &teamID); err != nil {
+			return nil, fmt.Errorf("failed to scan team: %w", err)
+		}
+		teams[name] = teamID
+	}
+	return teams, rows.Err()
+}
+
+// commitAtomic imports every valid row in one transaction. When a row fails nothing is
+// kept and the report marks the failing row.
+func (s *UserImportService) commitAtomic(ctx context.Context, scope *importScope, rows []models.UserImportRow, report *models.UserImportReport, checksum string) error {
+	tx, err := s.db.GetConnection().Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	importID, err := s.createImport(ctx, tx, scope, len(rows), checksum)
+	if err != nil {
+		return err
+	}
+
+	for _, i := range commitOrder(rows, report.Rows) {
+		userID, err := s.importRow(ctx, tx, scope, &rows[i])
+		if err != nil {
+			// Rows committed so far are rolled back with the failing one
+			for j := range report.Rows {
+				if report.Rows[j].Status == models.UserImportRowImported {
+					report.Rows[j].Status = models.UserImportRowValid
+					report.Rows[j].UserID = nil
+				}
+			}
+			report.Rows[i].Status = models.UserImportRowFailed
+			report.Rows[i].Errors = []string{err.Error()}
+			report.Status = models.UserImportStatusFailed
+			return nil
+		}
+		report.Rows[i].Status = models.UserImportRowImported
+		report.Rows[i].UserID = &userID
+
+		if err := s.recordRow(ctx, tx, importID, &report.Rows[i]); err != nil {
+			return err
+		}
+	}
+
+	if err := s.finishImport(ctx, tx, importID, len(rows), report); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit import: %w", err)
+	}
+
+	report.ImportID = &importID
+	return nil
+}
+
+// commitPartial imports each valid row in its own transaction and records every outcome,
+// so the import can be resumed with a corrected file
+func (s *UserImportService) commitPartial(ctx context.Context, scope *importScope, rows []models.UserImportRow, report *models.UserImportReport, checksum string) error {
+	conn := s.db.GetConnection()
+
+	var importID uuid.UUID
+	if scope.request.ResumeImportID != nil {
+		importID = *scope.request.ResumeImportID
+		if _, err := conn.Exec(ctx, `
+			UPDATE user_imports SET status = 'running', file_name = $2, file_sha256 = $3, completed_at = NULL
+			WHERE import_id = $1
+		`, importID, scope.request.FileName, checksum); err != nil {
+			return fmt.Errorf("failed to resume import: %w", err)
+		}
+	} else {
+		var err error
+		if importID, err = s.createImport(ctx, conn, scope, len(rows), checksum); err != nil {
+			return err
+		}
+	}
+
+	for i := range report.Rows {
+		if report.Rows[i].Status == models.UserImportRowInvalid {
+			if err := s.recordRow(ctx, conn, importID, &report.Rows[i]); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, i := range commitOrder(rows, report.Rows) {
+		userID, err := s.importRowInTx(ctx, scope, importID, &rows[i], &report.Rows[i])
+		if err != nil {
+			report.Rows[i].Status = models.UserImportRowFailed
+			report.Rows[i].Errors = []string{err.Error()}
+			if err := s.recordRow(ctx, conn, importID, &report.Rows[i]); err != nil {
+				return err
+			}
+			continue
+		}
+		report.Rows[i].UserID = &userID
+	}
+
+	if err := s.finishImport(ctx, conn, importID, len(rows), report); err != nil {
+		return err
+	}
+
+	report.ImportID = &importID
+	return nil
+}
+
+// importRowInTx commits one row together with its import record
+func (s *UserImportService) importRowInTx(ctx context.Context, scope *importScope, importID uuid.UUID, row *models.UserImportRow, result *models.UserImportRowResult) (uuid.UUID, error) {
+	tx, err := s.db.GetConnection().Begin(ctx)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	userID, err := s.importRow(ctx, tx, scope, row)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	imported := *result
+	imported.Status = models.UserImportRowImported
+	imported.UserID = &userID
+	imported.Errors = nil
+	if err := s.recordRow(ctx, tx, importID, &imported); err != nil {
+		return uuid.Nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to commit row: %w", err)
+	}
+
+	*result = imported
+	return userID, nil
+}
+
+// commitOrder returns the indexes of valid rows, owners first so their teams exist
+// before players join them
+func commitOrder(rows []models.UserImportRow, results []models.UserImportRowResult) []int {
+	var order []int
+	for i := range rows {
+		if results[i].Status == models.UserImportRowValid {
+			order = append(order, i)
+		}
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return rows[order[a]].Role == models.RoleOwner && rows[order[b]].Role != models.RoleOwner
+	})
+	return order
+}
+
+// importRow creates the user, role assignment, team or player records and the
+// invitation of one row on tx
+func (s *UserImportService) importRow(ctx context.Context, tx pgx.Tx, scope *importScope, row *models.UserImportRow) (uuid.UUID, error) {
+	req := scope.request
+	var phone, identification *string
+	if row.Phone != "" {
+		phone = &row.Phone
+	}
+	if row.Identification != "" {
+		identification = &row.Identification
+	}
+	identificationType := IdentificationTypeFor(row.IdentificationType, row.Identification)
+
+	// The password is chosen by the invitee when accepting the invitation
+	var userID uuid.UUID
+	err := tx.QueryRow(ctx, `
+		INSERT INTO user_profiles (user_id, email, password_hash, first_name, last_name, phone,
+		 identification, identification_type, primary_role, is_active, account_status, failed_login_attempts,
+		 two_factor_enabled, created_at, updated_at)
+		VALUES (gen_random_uuid(), $1, $2, $3, $4, $5, $6, $7, $8, true, $9, 0, false, NOW(), NOW())
+		RETURNING user_id
+	`, row.Email, InvitationPendingPasswordHash, row.FirstName, row.LastName, phone, identification, identificationType,
+		row.Role, models.AccountStatusActive).Scan(&userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			return uuid.Nil, fmt.Errorf("email or identification is already registered")
+		}
+		return uuid.Nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO user_roles_by_city_sport (role_assignment_id, user_id, city_id, sport_id,
+		 tournament_id, role_name, assigned_by_user_id, is_active, created_at)
+		VALUES (gen_random_uuid(), $1, $2, $3, $4, $5, $6, true, NOW())
+	`, userID, req.CityID, req.SportID, AssignmentTournament(row.Role, scope.scopes[row.Role]), row.Role, scope.requestedBy)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to assign role: %w", err)
+	}
+
+	switch row.Role {
+	case models.RoleOwner:
+		if row.Team != "" {
+			_, err = tx.Exec(ctx, `
+				INSERT INTO teams (name, owner_user_id, city_id, sport_id) VALUES ($1, $2, $3, $4)
+			`, row.Team, userID, req.CityID, req.SportID)
+			if err != nil {
+				if strings.Contains(err.Error(), "duplicate key") {
+					return uuid.Nil, fmt.Errorf("team %q already exists", row.Team)
+				}
+				return uuid.Nil, fmt.Errorf("failed to create team: %w", err)
+			}
+		}
+	case models.RolePlayer:
+		if err := s.createPlayer(ctx, tx, scope, row, userID, phone); err != nil {
+			return uuid.Nil, err
+		}
+	}
+
+	invitation, token, err := s.invitationService.CreateInvitation(ctx, tx, userID, row.Email, row.Role, &req.CityID, &req.SportID, scope.requestedBy)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if err := s.invitationService.QueueInvitation(ctx, tx, invitation.InvitationID, token); err != nil {
+		return uuid.Nil, err
+	}
+
+	return userID, nil
+}
+
+// createPlayer creates the player record of a player row and adds it to its team
+func (s *UserImportService) createPlayer(ctx context.Context, tx pgx.Tx, scope *importScope, row *models.UserImportRow, userID uuid.UUID, phone *string) error {
+	dateOfBirth, err := parseImportDate(row.DateOfBirth)
+	if err != nil {
+		return err
+	}
+
+	var bloodType, position *string
+	if row.BloodType != "" {
+		bloodType = &row.BloodType
+	}
+	if row.Position != "" {
+		position = &row.Position
+	}
+
+	sensitive := PlayerSensitiveFields{Identification: &row.Identification, BloodType: bloodType}
+	if err := s.fieldEncryption.EncryptPlayerFields(ctx, &sensitive); err != nil {
+		return fmt.Errorf("failed to encrypt player record: %w", err)
+	}
+
+	var playerID uuid.UUID
+	err = tx.QueryRow(ctx, `
+		INSERT INTO players (player_id, user_profile_id, first_name, last_name, date_of_birth,
+		 identification, identification_bidx, identification_type, blood_type, email, phone, preferred_position,
+		 is_active, created_at, updated_at)
+		VALUES (gen_random_uuid(), $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, true, NOW(), NOW())
+		RETURNING player_id
+	`, userID, row.FirstName, row.LastName, dateOfBirth, sensitive.Identification, sensitive.IdentificationIndex,
+		IdentificationTypeFor(row.IdentificationType, row.Identification), sensitive.BloodType, row.Email, phone, position).Scan(&playerID)
+	if err != nil {
+		return fmt.Errorf("failed to create player record: %w", err)
+	}
+
+	if row.Team == "" {
+		return nil
+	}
+
+	var teamID uuid.UUID
+	err = tx.QueryRow(ctx, `
+		SELECT team_id FROM teams
+		WHERE lower(name) = lower($1) AND city_id = $2 AND sport_id = $3 AND is_active = true
+	`, row.Team, scope.request.CityID, scope.request.SportID).Scan(&teamID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("team %q not found in this city/sport", row.Team)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load team: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO team_players (team_id, player_id, position, jersey_number, registered_by_user_id)
+		VALUES ($1, $2, $3, $4, $5)
+	`, teamID, playerID, position, row.JerseyNumber, scope.requestedBy)
+	if err != nil {
+		return fmt.Errorf("failed to add player to team: %w", err)
+	}
+
+	return nil
+}
+
+func (s *UserImportService) createImport(ctx context.Context, q dbExecutor, scope *importScope, totalRows int, checksum string) (uuid.UUID, error) {
+	req := scope.request
+	var defaultRole *string
+	if req.Role != "" {
+		defaultRole = &req.Role
+	}
+
+	var importID uuid.UUID
+	err := q.QueryRow(ctx, `
+		INSERT INTO user_imports (file_name, file_sha256, mode, default_role, city_id, sport_id,
+		 tournament_id, total_rows, created_by_user_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING import_id
+	`, req.FileName, checksum, req.Mode, defaultRole, req.CityID, req.SportID, req.TournamentID, totalRows, scope.requestedBy).Scan(&importID)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to create import: %w", err)
+	}
+	return importID, nil
+}
+
+// recordRow stores the outcome of a row, replacing the outcome of a previous attempt
+func (s *UserImportService) recordRow(ctx context.Context, q dbExecutor, importID uuid.UUID, result *models.UserImportRowResult) error {
+	var rowError *string
+	if len(result.Errors) > 0 {
+		joined := strings.Join(result.Errors, "; ")
+		rowError = &joined
+	}
+	status := result.Status
+	if status == models.UserImportRowInvalid {
+		status = models.UserImportRowFailed
+	}
+
+	var recorded int
+	err := q.QueryRow(ctx, `
+		INSERT INTO user_import_rows (import_id, row_number, email, role_name, status, user_id, error)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (import_id, email) DO UPDATE
+		SET row_number = EXCLUDED.row_number, role_name = EXCLUDED.role_name, status = EXCLUDED.status,
+		    user_id = EXCLUDED.user_id, error = EXCLUDED.error, updated_at = NOW()
+		RETURNING row_number
+	`, importID, result.RowNumber, result.Email, result.Role, status, result.UserID, rowError).Scan(&recorded)
+	if err != nil {
+		return fmt.Errorf("failed to record import row: %w", err)
+	}
+	return nil
+}
+
+// finishImport updates the totals of an import from its recorded rows
+func (s *UserImportService) finishImport(ctx context.Context, q dbExecutor, importID uuid.UUID, totalRows int, report *models.UserImportReport) error {
+	var completedAt time.Time
+	err := q.QueryRow(ctx, `
+		UPDATE user_imports i SET
+			total_rows = $2,
+			imported_rows = counts.imported,
+			failed_rows = counts.failed,
+			status = CASE WHEN counts.failed = 0 THEN 'completed' ELSE 'partial' END,
+			completed_at = NOW()
+		FROM (
+			SELECT COUNT(*) FILTER (WHERE status = 'imported') AS imported,
+			       COUNT(*) FILTER (WHERE status = 'failed') AS failed
+			FROM user_import_rows WHERE import_id = $1
+		) counts
+		WHERE i.import_id = $1
+		RETURNING i.status, i.completed_at
+	`, importID, totalRows).Scan(&report.Status, &completedAt)
+	if err != nil {
+		return fmt.Errorf("failed to finish import: %w", err)
+	}
+	report.CompletedAt = &completedAt
+	return nil
+}
+
+// GetImport returns a committed import with the outcome of its rows. Admins other than
+// super admins only see their own imports.
+func (s *UserImportService) GetImport(ctx context.Context, importID, requestedBy uuid.UUID, requesterRole string) (*models.UserImportReport, error) {
+	conn := s.db.GetConnection()
+
+	report := &models.UserImportReport{ImportID: &importID}
+	var createdBy uuid.UUID
+	var createdAt time.Time
+	err := conn.QueryRow(ctx, `
+		SELECT file_name, mode, status, total_rows, created_by_user_id, created_at, completed_at
+		FROM user_imports WHERE import_id = $1
+	`, importID).Scan(&report.FileName, &report.Mode, &report.Status, &report.TotalRows, &createdBy, &createdAt, &report.CompletedAt)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && requesterRole != models.RoleSuperAdmin && createdBy != requestedBy) {
+		return nil, fmt.Errorf("import not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load import: %w", err)
+	}
+	report.CreatedAt = &createdAt
+
+	rows, err := conn.Query(ctx, `
+		SELECT row_number, email, role_name, status, user_id, error
+		FROM user_import_rows WHERE import_id = $1
+		ORDER BY row_number
+	`, importID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load import rows: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var result models.UserImportRowResult
+		var rowError *string
+		if err := rows.Scan(&result.RowNumber, &result.Email, &result.Role, &result.Status, &result.UserID, &rowError); err != nil {
+			return nil, fmt.Errorf("failed to scan import row: %w", err)
+		}
+		if rowError != nil {
+			result.Errors = strings.Split(*rowError, "; ")
+		}
+		report.Rows = append(report.Rows, result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	summarizeImport(report)
+	return report, nil
+}
+
+// summarizeImport recounts the report totals from its rows
+func summarizeImport(report *models.UserImportReport) {
+	if len(report.Rows) > report.TotalRows {
+		report.TotalRows = len(report.Rows)
+	}
+	report.ValidRows, report.InvalidRows, report.ImportedRows, report.SkippedRows, report.FailedRows = 0, 0, 0, 0, 0
+	for _, row := range report.Rows {
+		switch row.Status {
+		case models.UserImportRowValid:
+			report.ValidRows++
+		case models.UserImportRowInvalid:
+			report.InvalidRows++
+		case models.UserImportRowImported:
+			report.ValidRows++
+			report.ImportedRows++
+		case models.UserImportRowSkipped:
+			report.SkippedRows++
+		case models.UserImportRowFailed:
+			report.FailedRows++
+		}
+	}
+}
+
+func isImportableRole(role string) bool {
+	for _, importable := range importableRoles {
+		if role == importable {
+			return true
+		}
+	}
+	return false
+}
@@ -2,26 +2,35 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"mowesport/internal/config"
 	"mowesport/internal/database"
 	"mowesport/internal/models"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
 type UserManagementService struct {
 	db                *database.Database
 	securityValidator *SecurityValidationService
 	auditService      *SecurityAuditService
+	delegation        *DelegationService
+	accountStatus     *AccountStatusService
+	lockoutPolicy     config.LockoutPolicyConfig
 }
 
-func NewUserManagementService(db *database.Database) *UserManagementService {
+func NewUserManagementService(db *database.Database, cfg *config.Config) *UserManagementService {
 	return &UserManagementService{
 		db:                db,
 		securityValidator: NewSecurityValidationService(),
 		auditService:      NewSecurityAuditService(db),
+		delegation:        NewDelegationService(db),
+		accountStatus:     NewAccountStatusService(db, cfg),
+		lockoutPolicy:     cfg.Security.Lockout,
 	}
 }
 
@@ -34,7 +43,7 @@ func (s *UserManagementService) GetUserProfile(ctx context.Context, userID uuid.
 
 	var user models.UserProfile
 	err := s.db.GetConnection().QueryRow(ctx, `
-		SELECT user_id, email, first_name, last_name, phone, identification, 
+		SELECT user_id, email, first_name, last_name, phone, identification, identification_type,
 		       photo_url, primary_role, is_active, account_status, last_login_at,
 		       failed_login_attempts, locked_until, two_factor_enabled, 
 		       created_at, updated_at
@@ -42,7 +51,7 @@ func (s *UserManagementService) GetUserProfile(ctx context.Context, userID uuid.
 		WHERE user_id = $1
 	`, userID).Scan(
 		&user.UserID, &user.Email, &user.FirstName, &user.LastName,
-		&user.Phone, &user.Identification, &user.PhotoURL, &user.PrimaryRole,
+		&user.Phone, &user.Identification, &user.IdentificationType, &user.PhotoURL, &user.PrimaryRole,
 		&user.IsActive, &user.AccountStatus, &user.LastLoginAt,
 		&user.FailedLoginAttempts, &user.LockedUntil, &user.TwoFactorEnabled,
 		&user.CreatedAt, &user.UpdatedAt,
@@ -113,13 +122,14 @@ func (s *UserManagementService) UpdateUserProfile(ctx context.Context, userID uu
 		argIndex++
 	}
 
-	if req.Identification != nil {
-		if err := s.securityValidator.ValidateIdentificationFormat(*req.Identification, "CO"); err != nil {
-			return nil, fmt.Errorf("invalid identification format: %w", err)
+	if req.Identification != nil || req.IdentificationType != nil {
+		identification, identificationType, err := s.resolveIdentification(ctx, tx, userID, req)
+		if err != nil {
+			return nil, err
 		}
-		setParts = append(setParts, fmt.Sprintf("identification = $%d", argIndex))
-		args = append(args, req.Identification)
-		argIndex++
+		setParts = append(setParts, fmt.Sprintf("identification = $%d, identification_type = $%d", argIndex, argIndex+1))
+		args = append(args, identification, identificationType)
+		argIndex += 2
 	}
 
 	if req.PhotoURL != nil {
@@ -132,11 +142,15 @@ func (s *UserManagementService) UpdateUserProfile(ctx context.Context, userID uu
 		setParts = append(setParts, fmt.Sprintf("is_active = $%d", argIndex))
 		args = append(args, *req.IsActive)
 		argIndex++
+		// Deactivation revokes live tokens like a status change
+		if !*req.IsActive {
+			setParts = append(setParts, "token_version = token_version + 1")
+		}
 	}
 
-	if req.AccountStatus != nil {
-		setParts = append(setParts, fmt.Sprintf("account_status = $%d", argIndex))
-		args = append(args, *req.AccountStatus)
+	if req.PreferredLocale != nil {
+		setParts = append(setParts, fmt.Sprintf("preferred_locale = $%d", argIndex))
+		args = append(args, *req.PreferredLocale)
 		argIndex++
 	}
 
@@ -148,25 +162,35 @@ func (s *UserManagementService) UpdateUserProfile(ctx context.Context, userID uu
 		UPDATE user_profiles 
 		SET %s 
 		WHERE user_id = $%d
-		RETURNING user_id, email, first_name, last_name, phone, identification,
+		RETURNING user_id, email, first_name, last_name, phone, identification, identification_type,
 		          photo_url, primary_role, is_active, account_status, last_login_at,
-		          failed_login_attempts, locked_until, two_factor_enabled,
+		          failed_login_attempts, locked_until, two_factor_enabled, preferred_locale,
 		          created_at, updated_at
 	`, strings.Join(setParts, ", "), whereIndex)
 
 	var updatedUser models.UserProfile
 	err = tx.QueryRow(ctx, query, args...).Scan(
 		&updatedUser.UserID, &updatedUser.Email, &updatedUser.FirstName, &updatedUser.LastName,
-		&updatedUser.Phone, &updatedUser.Identification, &updatedUser.PhotoURL, &updatedUser.PrimaryRole,
+		&updatedUser.Phone, &updatedUser.Identification, &updatedUser.IdentificationType, &updatedUser.PhotoURL, &updatedUser.PrimaryRole,
 		&updatedUser.IsActive, &updatedUser.AccountStatus, &updatedUser.LastLoginAt,
 		&updatedUser.FailedLoginAttempts, &updatedUser.LockedUntil, &updatedUser.TwoFactorEnabled,
-		&updatedUser.CreatedAt, &updatedUser.UpdatedAt,
+		&updatedUser.PreferredLocale, &updatedUser.CreatedAt, &updatedUser.UpdatedAt,
 	)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to update user profile: %w", err)
 	}
 
+	// Status changes carry their effects on tokens and tournaments
+	var statusChange *models.AccountStatusChange
+	if req.AccountStatus != nil {
+		statusChange, err = s.accountStatus.Apply(ctx, tx, userID, *req.AccountStatus, "")
+		if err != nil {
+			return nil, err
+		}
+		updatedUser.AccountStatus = statusChange.Status
+	}
+
 	// Commit transaction
 	if err := tx.Commit(ctx); err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
@@ -185,22 +209,75 @@ func (s *UserManagementService) UpdateUserProfile(ctx context.Context, userID uu
 		},
 		Timestamp: time.Now(),
 	})
+	if statusChange != nil {
+		s.accountStatus.logStatusChange(ctx, statusChange, &updatedBy, nil)
+	}
 
 	return &updatedUser, nil
 }
 
-// AssignUserRole assigns a role to a user for a specific city/sport
-func (s *UserManagementService) AssignUserRole(ctx context.Context, req *models.RoleAssignmentRequest, assignedBy uuid.UUID) (*models.UserRoleByCitySport, error) {
-	// Validate requester has admin permissions
-	if err := s.validateAdminPermissions(ctx, assignedBy); err != nil {
-		return nil, err
+// resolveIdentification merges an identification and/or document type change with the
+// stored values and validates the result. Holders of a player record are also checked
+// against the age rules of national documents.
+func (s *UserManagementService) resolveIdentification(ctx context.Context, tx pgx.Tx, userID uuid.UUID, req *models.UserUpdateRequest) (*string, *string, error) {
+	var identification, identificationType *string
+	var dateOfBirth *time.Time
+	err := tx.QueryRow(ctx, `
+		SELECT u.identification, u.identification_type,
+		       (SELECT p.date_of_birth FROM players p WHERE p.user_profile_id = u.user_id ORDER BY p.created_at LIMIT 1)
+		FROM user_profiles u WHERE u.user_id = $1
+	`, userID).Scan(&identification, &identificationType, &dateOfBirth)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil, fmt.Errorf("user not found")
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load identification: %w", err)
 	}
 
+	if req.Identification != nil {
+		identification = req.Identification
+	}
+	if req.IdentificationType != nil {
+		identificationType = req.IdentificationType
+	}
+	if identification == nil || strings.TrimSpace(*identification) == "" {
+		return nil, nil, nil
+	}
+
+	docType := ""
+	if identificationType != nil {
+		docType = *identificationType
+	}
+	if err := s.securityValidator.ValidateColombianIdentification(docType, *identification); err != nil {
+		return nil, nil, fmt.Errorf("invalid identification format: %w", err)
+	}
+	if dateOfBirth != nil {
+		if err := s.securityValidator.ValidatePlayerIdentification(docType, *identification, *dateOfBirth, time.Now()); err != nil {
+			return nil, nil, fmt.Errorf("invalid identification format: %w", err)
+		}
+	}
+	return identification, IdentificationTypeFor(docType, *identification), nil
+}
+
+// AssignUserRole assigns a role to a user for a specific city/sport, or tournament for
+// tournament-bound roles, as allowed by the delegation matrix
+func (s *UserManagementService) AssignUserRole(ctx context.Context, req *models.RoleAssignmentRequest, assignedBy uuid.UUID) (*models.UserRoleByCitySport, error) {
 	// Validate request
 	if err := s.validateRoleAssignmentRequest(req); err != nil {
 		return nil, err
 	}
 
+	// Resolve the assignment scope and check the requester may delegate the role in it
+	scope, err := s.delegation.ResolveScope(ctx, req.RoleName, req.CityID, req.SportID, req.TournamentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.delegation.Authorize(ctx, assignedBy, DelegationAssign, req.RoleName, scope); err != nil {
+		return nil, err
+	}
+	req.CityID, req.SportID = scope.CityID, scope.SportID
+	req.TournamentID = AssignmentTournament(req.RoleName, scope)
+
 	// Start transaction
 	tx, err := s.db.GetConnection().Begin(ctx)
 	if err != nil {
@@ -245,8 +322,9 @@ func (s *UserManagementService) AssignUserRole(ctx context.Context, req *models.
 	var existingCount int
 	err = tx.QueryRow(ctx, `
 		SELECT COUNT(*) FROM user_roles_by_city_sport 
-		WHERE user_id = $1 AND city_id = $2 AND sport_id = $3 AND role_name = $4 AND is_active = true
-	`, req.UserID, req.CityID, req.SportID, req.RoleName).Scan(&existingCount)
+		WHERE user_id = $1 AND city_id IS NOT DISTINCT FROM $2 AND sport_id IS NOT DISTINCT FROM $3
+		AND tournament_id IS NOT DISTINCT FROM $4 AND role_name = $5 AND is_active = true
+	`, req.UserID, req.CityID, req.SportID, req.TournamentID, req.RoleName).Scan(&existingCount)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to check existing role: %w", err)
@@ -261,14 +339,14 @@ func (s *UserManagementService) AssignUserRole(ctx context.Context, req *models.
 
 	err = tx.QueryRow(ctx, `
 		INSERT INTO user_roles_by_city_sport (
-			role_assignment_id, user_id, city_id, sport_id, role_name,
+			role_assignment_id, user_id, city_id, sport_id, tournament_id, role_name,
 			assigned_by_user_id, is_active, created_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
-		RETURNING role_assignment_id, user_id, city_id, sport_id, role_name,
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+		RETURNING role_assignment_id, user_id, city_id, sport_id, tournament_id, role_name,
 		          assigned_by_user_id, is_active, created_at
-	`, roleAssignmentID, req.UserID, req.CityID, req.SportID, req.RoleName, assignedBy, true).Scan(
+	`, roleAssignmentID, req.UserID, req.CityID, req.SportID, req.TournamentID, req.RoleName, assignedBy, true).Scan(
 		&roleAssignment.RoleAssignmentID, &roleAssignment.UserID, &roleAssignment.CityID,
-		&roleAssignment.SportID, &roleAssignment.RoleName, &roleAssignment.AssignedByUserID,
+		&roleAssignment.SportID, &roleAssignment.TournamentID, &roleAssignment.RoleName, &roleAssignment.AssignedByUserID,
 		&roleAssignment.IsActive, &roleAssignment.CreatedAt,
 	)
 
@@ -294,6 +372,7 @@ func (s *UserManagementService) AssignUserRole(ctx context.Context, req *models.
 			"role_name":          req.RoleName,
 			"city_id":            req.CityID,
 			"sport_id":           req.SportID,
+			"tournament_id":      req.TournamentID,
 		},
 		Timestamp: time.Now(),
 	})
@@ -301,13 +380,8 @@ func (s *UserManagementService) AssignUserRole(ctx context.Context, req *models.
 	return &roleAssignment, nil
 }
 
-// RevokeUserRole revokes a role from a user
+// RevokeUserRole revokes a role from a user, as allowed by the delegation matrix
 func (s *UserManagementService) RevokeUserRole(ctx context.Context, roleAssignmentID uuid.UUID, revokedBy uuid.UUID) error {
-	// Validate requester has admin permissions
-	if err := s.validateAdminPermissions(ctx, revokedBy); err != nil {
-		return err
-	}
-
 	// Start transaction
 	tx, err := s.db.GetConnection().Begin(ctx)
 	if err != nil {
@@ -318,18 +392,28 @@ func (s *UserManagementService) RevokeUserRole(ctx context.Context, roleAssignme
 	// Get role assignment details for audit
 	var roleAssignment models.UserRoleByCitySport
 	err = tx.QueryRow(ctx, `
-		SELECT role_assignment_id, user_id, city_id, sport_id, role_name, is_active
+		SELECT role_assignment_id, user_id, city_id, sport_id, tournament_id, role_name, is_active
 		FROM user_roles_by_city_sport 
 		WHERE role_assignment_id = $1
 	`, roleAssignmentID).Scan(
 		&roleAssignment.RoleAssignmentID, &roleAssignment.UserID, &roleAssignment.CityID,
-		&roleAssignment.SportID, &roleAssignment.RoleName, &roleAssignment.IsActive,
+		&roleAssignment.SportID, &roleAssignment.TournamentID, &roleAssignment.RoleName, &roleAssignment.IsActive,
 	)
 
 	if err != nil {
 		return fmt.Errorf("role assignment not found: %w", err)
 	}
 
+	// The requester must be allowed to revoke this role in the assignment's own scope
+	scope := ResourceScope{
+		CityID:       roleAssignment.CityID,
+		SportID:      roleAssignment.SportID,
+		TournamentID: roleAssignment.TournamentID,
+	}
+	if err := s.delegation.Authorize(ctx, revokedBy, DelegationRevoke, roleAssignment.RoleName, scope); err != nil {
+		return err
+	}
+
 	if !roleAssignment.IsActive {
 		return fmt.Errorf("role assignment is already inactive")
 	}
@@ -363,6 +447,7 @@ func (s *UserManagementService) RevokeUserRole(ctx context.Context, roleAssignme
 			"role_name":          roleAssignment.RoleName,
 			"city_id":            roleAssignment.CityID,
 			"sport_id":           roleAssignment.SportID,
+			"tournament_id":      roleAssignment.TournamentID,
 		},
 		Timestamp: time.Now(),
 	})
@@ -378,7 +463,7 @@ func (s *UserManagementService) GetUserRoles(ctx context.Context, userID uuid.UU
 	}
 
 	rows, err := s.db.GetConnection().Query(ctx, `
-		SELECT ur.role_assignment_id, ur.user_id, ur.city_id, ur.sport_id, 
+		SELECT ur.role_assignment_id, ur.user_id, ur.city_id, ur.sport_id, ur.tournament_id,
 		       ur.role_name, ur.assigned_by_user_id, ur.is_active, ur.created_at
 		FROM user_roles_by_city_sport ur
 		WHERE ur.user_id = $1
@@ -394,7 +479,7 @@ func (s *UserManagementService) GetUserRoles(ctx context.Context, userID uuid.UU
 	for rows.Next() {
 		var role models.UserRoleByCitySport
 		err := rows.Scan(
-			&role.RoleAssignmentID, &role.UserID, &role.CityID, &role.SportID,
+			&role.RoleAssignmentID, &role.UserID, &role.CityID, &role.SportID, &role.TournamentID,
 			&role.RoleName, &role.AssignedByUserID, &role.IsActive, &role.CreatedAt,
 		)
 		if err != nil {
@@ -497,11 +582,12 @@ func (s *UserManagementService) SetViewPermission(ctx context.Context, req *mode
 	return &permission, nil
 }
 
-// UpdateAccountStatus updates a user's account status
-func (s *UserManagementService) UpdateAccountStatus(ctx context.Context, userID uuid.UUID, status string, reason string, updatedBy uuid.UUID) error {
+// UpdateAccountStatus updates a user's account status, revoking their live tokens and
+// holding or releasing the tournaments they administer
+func (s *UserManagementService) UpdateAccountStatus(ctx context.Context, userID uuid.UUID, status string, reason string, updatedBy uuid.UUID) (*models.AccountStatusChange, error) {
 	// Validate requester has admin permissions
 	if err := s.validateAdminPermissions(ctx, updatedBy); err != nil {
-		return err
+		return nil, err
 	}
 
 	// Validate status
@@ -521,41 +607,118 @@ func (s *UserManagementService) UpdateAccountStatus(ctx context.Context, userID
 	}
 
 	if !isValidStatus {
-		return fmt.Errorf("invalid account status: %s", status)
+		return nil, fmt.Errorf("invalid account status: %s", status)
 	}
 
-	// Update account status
-	result, err := s.db.GetConnection().Exec(ctx, `
-		UPDATE user_profiles 
-		SET account_status = $1, updated_at = NOW()
-		WHERE user_id = $2
-	`, status, userID)
+	return s.accountStatus.ChangeStatus(ctx, userID, status, reason, updatedBy)
+}
+
+// ScheduleReactivation schedules a suspended, payment pending or disabled account to become active again
+func (s *UserManagementService) ScheduleReactivation(ctx context.Context, userID uuid.UUID, req *models.AccountReactivationRequest, scheduledBy uuid.UUID) (*models.AccountStatusSchedule, error) {
+	if err := s.validateAdminPermissions(ctx, scheduledBy); err != nil {
+		return nil, err
+	}
 
+	notifyUser := true
+	if req.NotifyUser != nil {
+		notifyUser = *req.NotifyUser
+	}
+	return s.accountStatus.ScheduleChange(ctx, userID, models.AccountStatusActive, req.ScheduledFor, req.Reason, notifyUser, scheduledBy)
+}
+
+// CancelScheduledReactivation cancels the pending scheduled status change of an account
+func (s *UserManagementService) CancelScheduledReactivation(ctx context.Context, userID uuid.UUID, cancelledBy uuid.UUID) error {
+	if err := s.validateAdminPermissions(ctx, cancelledBy); err != nil {
+		return err
+	}
+	return s.accountStatus.CancelScheduledChange(ctx, userID, cancelledBy)
+}
+
+// GetStatusSchedules returns the scheduled status changes of an account
+func (s *UserManagementService) GetStatusSchedules(ctx context.Context, userID uuid.UUID, requestedBy uuid.UUID) ([]models.AccountStatusSchedule, error) {
+	if err := s.validateAdminPermissions(ctx, requestedBy); err != nil {
+		return nil, err
+	}
+	return s.accountStatus.ListScheduledChanges(ctx, userID)
+}
+
+// LockAccount locks a user out of login until the given time has passed.
+// A zero duration uses the lockout policy's maximum lock duration.
+func (s *UserManagementService) LockAccount(ctx context.Context, userID uuid.UUID, duration time.Duration, reason string, lockedBy uuid.UUID) (*models.AccountLockStatus, error) {
+	if err := s.validateAdminPermissions(ctx, lockedBy); err != nil {
+		return nil, err
+	}
+	if userID == lockedBy {
+		return nil, fmt.Errorf("cannot lock your own account")
+	}
+
+	if duration <= 0 {
+		duration = s.lockoutPolicy.MaxDuration
+	}
+	lockedUntil := time.Now().Add(duration)
+
+	status := &models.AccountLockStatus{UserID: userID, Locked: true, LockedUntil: &lockedUntil}
+	err := s.db.GetConnection().QueryRow(ctx, `
+		UPDATE user_profiles SET locked_until = $2, updated_at = NOW()
+		WHERE user_id = $1
+		RETURNING failed_login_attempts
+	`, userID, lockedUntil).Scan(&status.FailedLoginAttempts)
 	if err != nil {
-		return fmt.Errorf("failed to update account status: %w", err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("user not found")
+		}
+		return nil, fmt.Errorf("failed to lock account: %w", err)
+	}
+
+	s.auditService.LogSecurityEvent(ctx, SecurityEvent{
+		EventType:   EventTypeAccountLocked,
+		Description: fmt.Sprintf("Account locked by admin %s", lockedBy),
+		UserID:      &userID,
+		Metadata: map[string]interface{}{
+			"locked_by":    lockedBy,
+			"locked_until": lockedUntil,
+			"reason":       reason,
+		},
+	})
+
+	return status, nil
+}
+
+// UnlockAccount lifts a lock and resets the failed login counter, so the next
+// failure starts the progressive lockout from the first level again
+func (s *UserManagementService) UnlockAccount(ctx context.Context, userID uuid.UUID, reason string, unlockedBy uuid.UUID) (*models.AccountLockStatus, error) {
+	if err := s.validateAdminPermissions(ctx, unlockedBy); err != nil {
+		return nil, err
 	}
 
-	rowsAffected := result.RowsAffected()
-	if rowsAffected == 0 {
-		return fmt.Errorf("user not found")
+	var previousLock *time.Time
+	var previousAttempts int
+	err := s.db.GetConnection().QueryRow(ctx, `
+		UPDATE user_profiles p SET locked_until = NULL, failed_login_attempts = 0, updated_at = NOW()
+		FROM (SELECT user_id, locked_until, failed_login_attempts FROM user_profiles WHERE user_id = $1 FOR UPDATE) old
+		WHERE p.user_id = old.user_id
+		RETURNING old.locked_until, old.failed_login_attempts
+	`, userID).Scan(&previousLock, &previousAttempts)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("user not found")
+		}
+		return nil, fmt.Errorf("failed to unlock account: %w", err)
 	}
 
-	// Log status change for audit
 	s.auditService.LogSecurityEvent(ctx, SecurityEvent{
-		EventType:   "ACCOUNT_STATUS_CHANGED",
-		Description: fmt.Sprintf("Account status changed to %s by admin %s", status, updatedBy),
+		EventType:   EventTypeAccountUnlocked,
+		Description: fmt.Sprintf("Account unlocked by admin %s", unlockedBy),
 		UserID:      &userID,
-		IPAddress:   s.securityValidator.GetClientIP(ctx),
 		Metadata: map[string]interface{}{
-			"updated_user_id": userID,
-			"updated_by":      updatedBy,
-			"new_status":      status,
-			"reason":          reason,
+			"unlocked_by":       unlockedBy,
+			"was_locked":        previousLock != nil && previousLock.After(time.Now()),
+			"previous_attempts": previousAttempts,
+			"reason":            reason,
 		},
-		Timestamp: time.Now(),
 	})
 
-	return nil
+	return &models.AccountLockStatus{UserID: userID}, nil
 }
 
 // Helper methods
@@ -578,6 +741,19 @@ func (s *UserManagementService) validateAdminPermissions(ctx context.Context, us
 	return nil
 }
 
+// isSuperAdmin checks whether the user is an active super admin
+func (s *UserManagementService) isSuperAdmin(ctx context.Context, userID uuid.UUID) (bool, error) {
+	var isSuperAdmin bool
+	err := s.db.GetConnection().QueryRow(ctx,
+		"SELECT EXISTS(SELECT 1 FROM user_profiles WHERE user_id = $1 AND primary_role = $2 AND is_active = true)",
+		userID, models.RoleSuperAdmin,
+	).Scan(&isSuperAdmin)
+	if err != nil {
+		return false, fmt.Errorf("failed to check requester role: %w", err)
+	}
+	return isSuperAdmin, nil
+}
+
 func (s *UserManagementService) validateSuperAdminPermissions(ctx context.Context, userID uuid.UUID) error {
 	var role string
 	err := s.db.GetConnection().QueryRow(ctx,
@@ -677,10 +853,30 @@ func (s *UserManagementService) validateViewPermissionRequest(req *models.ViewPe
 		return fmt.Errorf("view name is required")
 	}
 
+	if _, ok := LookupView(req.ViewName); !ok {
+		return fmt.Errorf("unknown view: %s", req.ViewName)
+	}
+
+	if req.RoleName != nil && !roleInList(*req.RoleName, allRoles) {
+		return fmt.Errorf("invalid role name: %s", *req.RoleName)
+	}
+
 	return nil
 }
 
-// GetUserList retrieves a paginated list of users (admin only)
+// userListSorts are the sortable columns of the user list. Users who never logged in sort
+// as the oldest logins.
+var userListSorts = map[string]listSort{
+	"first_name":    {"up.first_name", "text"},
+	"last_name":     {"up.last_name", "text"},
+	"email":         {"up.email", "text"},
+	"primary_role":  {"up.primary_role", "text"},
+	"created_at":    {"up.created_at", "timestamptz"},
+	"last_login_at": {"COALESCE(up.last_login_at, '-infinity')", "timestamptz"},
+}
+
+// GetUserList retrieves a page of users (admin only), after the request cursor or at the
+// requested page number
 func (s *UserManagementService) GetUserList(ctx context.Context, req *models.UserListRequest, requestedBy uuid.UUID) (*models.UserListResponse, error) {
 	// Validate requester has admin permissions
 	if err := s.validateAdminPermissions(ctx, requestedBy); err != nil {
@@ -688,115 +884,68 @@ func (s *UserManagementService) GetUserList(ctx context.Context, req *models.Use
 	}
 
 	// Set defaults
-	if req.Page <= 0 {
-		req.Page = 1
-	}
 	if req.Limit <= 0 {
 		req.Limit = 20
 	}
 	if req.Limit > 100 {
 		req.Limit = 100
 	}
-	if req.SortBy == "" {
-		req.SortBy = "created_at"
-	}
-	if req.SortOrder == "" {
-		req.SortOrder = "desc"
-	}
-
-	// Calculate offset
-	offset := (req.Page - 1) * req.Limit
-
-	// Build WHERE clause
-	whereConditions := []string{"1=1"} // Always true condition to simplify building
-	args := []interface{}{}
-	argIndex := 1
-
-	// Add search filter
-	if req.Search != "" {
-		searchPattern := "%" + strings.ToLower(req.Search) + "%"
-		whereConditions = append(whereConditions, fmt.Sprintf("(LOWER(first_name) LIKE $%d OR LOWER(last_name) LIKE $%d OR LOWER(email) LIKE $%d)", argIndex, argIndex, argIndex))
-		args = append(args, searchPattern)
-		argIndex++
-	}
 
-	// Add role filter
-	if req.Role != "" {
-		whereConditions = append(whereConditions, fmt.Sprintf("primary_role = $%d", argIndex))
-		args = append(args, req.Role)
-		argIndex++
-	}
-
-	// Add account status filter
-	if req.AccountStatus != "" {
-		whereConditions = append(whereConditions, fmt.Sprintf("account_status = $%d", argIndex))
-		args = append(args, req.AccountStatus)
-		argIndex++
-	}
-
-	// Add is_active filter
-	if req.IsActive != nil {
-		whereConditions = append(whereConditions, fmt.Sprintf("is_active = $%d", argIndex))
-		args = append(args, *req.IsActive)
-		argIndex++
-	}
-
-	whereClause := strings.Join(whereConditions, " AND ")
-
-	// Build ORDER BY clause
-	validSortFields := map[string]string{
-		"first_name":    "first_name",
-		"last_name":     "last_name",
-		"email":         "email",
-		"primary_role":  "primary_role",
-		"created_at":    "created_at",
-		"last_login_at": "last_login_at",
-	}
-
-	sortField, exists := validSortFields[req.SortBy]
-	if !exists {
-		sortField = "created_at"
-	}
-
-	sortOrder := "DESC"
-	if req.SortOrder == "asc" {
-		sortOrder = "ASC"
+	q, sort, err := s.userListQuery(ctx, req, requestedBy)
+	if err != nil {
+		return nil, err
 	}
 
-	// Count total records
-	countQuery := fmt.Sprintf(`
-		SELECT COUNT(*)
-		FROM user_profiles
-		WHERE %s
-	`, whereClause)
-
-	var total int
-	err := s.db.GetConnection().QueryRow(ctx, countQuery, args...).Scan(&total)
-	if err != nil {
-		return nil, fmt.Errorf("failed to count users: %w", err)
+	response := &models.UserListResponse{Users: []models.UserSummary{}, Limit: req.Limit}
+	offset := 0
+	if req.Cursor != "" {
+		cursor, err := decodeListCursor(req.Cursor, req.SortBy, req.SortOrder)
+		if err != nil {
+			return nil, err
+		}
+		q.after(sort, req.SortOrder, cursor)
+		response.HasPrev = true
+	} else {
+		// Count total records
+		var total int
+		err := s.db.GetConnection().QueryRow(ctx, fmt.Sprintf(`
+			SELECT COUNT(*) FROM user_profiles up WHERE %s
+		`, q.whereClause()), q.args...).Scan(&total)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count users: %w", err)
+		}
+		response.Total = &total
+		response.TotalPages = (total + req.Limit - 1) / req.Limit
+		if req.Page > 1 {
+			offset = (req.Page - 1) * req.Limit
+			response.Page = req.Page
+			response.HasPrev = true
+		} else if req.Page == 1 {
+			response.Page = 1
+		}
 	}
 
-	// Get paginated results
+	// One extra row tells whether there is a next page
 	query := fmt.Sprintf(`
-		SELECT user_id, email, first_name, last_name, phone, photo_url,
-		       primary_role, account_status, is_active, last_login_at, created_at
-		FROM user_profiles
+		SELECT up.user_id, up.email, up.first_name, up.last_name, up.phone, up.photo_url,
+		       up.primary_role, up.account_status, up.is_active, up.last_login_at, up.created_at,
+		       (%s)::text
+		FROM user_profiles up
 		WHERE %s
-		ORDER BY %s %s
-		LIMIT $%d OFFSET $%d
-	`, whereClause, sortField, sortOrder, argIndex, argIndex+1)
-
-	args = append(args, req.Limit, offset)
+		ORDER BY %s
+		LIMIT %s OFFSET %s
+	`, sort.expr, q.whereClause(), orderBy(sort, req.SortOrder), q.arg(req.Limit+1), q.arg(offset))
 
-	rows, err := s.db.GetConnection().Query(ctx, query, args...)
+	rows, err := s.db.GetConnection().Query(ctx, query, q.args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query users: %w", err)
 	}
 	defer rows.Close()
 
-	var users []models.UserSummary
+	var lastKey string
 	for rows.Next() {
 		var user models.UserSummary
+		var sortKey string
 		err := rows.Scan(
 			&user.UserID,
 			&user.Email,
@@ -809,31 +958,142 @@ func (s *UserManagementService) GetUserList(ctx context.Context, req *models.Use
 			&user.IsActive,
 			&user.LastLoginAt,
 			&user.CreatedAt,
+			&sortKey,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan user: %w", err)
 		}
-		users = append(users, user)
+		if len(response.Users) == req.Limit {
+			response.HasNext = true
+			break
+		}
+		response.Users = append(response.Users, user)
+		lastKey = sortKey
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("error iterating over user rows: %w", err)
 	}
 
-	// Calculate pagination info
-	totalPages := (total + req.Limit - 1) / req.Limit
-	hasNext := req.Page < totalPages
-	hasPrev := req.Page > 1
-
-	return &models.UserListResponse{
-		Users:      users,
-		Total:      total,
-		Page:       req.Page,
-		Limit:      req.Limit,
-		TotalPages: totalPages,
-		HasNext:    hasNext,
-		HasPrev:    hasPrev,
-	}, nil
+	if response.HasNext {
+		last := response.Users[len(response.Users)-1]
+		response.NextCursor = encodeListCursor(listCursor{Sort: req.SortBy, Order: req.SortOrder, Key: lastKey, ID: last.UserID})
+	}
+
+	return response, nil
+}
+
+// ExportUserList streams every user matching the list filters to write, in list order, and
+// audits the export. Pagination fields of the request are ignored.
+func (s *UserManagementService) ExportUserList(ctx context.Context, req *models.UserListRequest, requestedBy uuid.UUID, write func(models.UserSummary) error) (int, error) {
+	if err := s.validateAdminPermissions(ctx, requestedBy); err != nil {
+		return 0, err
+	}
+
+	q, sort, err := s.userListQuery(ctx, req, requestedBy)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := s.db.GetConnection().Query(ctx, fmt.Sprintf(`
+		SELECT up.user_id, up.email, up.first_name, up.last_name, up.phone, up.photo_url,
+		       up.primary_role, up.account_status, up.is_active, up.last_login_at, up.created_at
+		FROM user_profiles up
+		WHERE %s
+		ORDER BY %s
+	`, q.whereClause(), orderBy(sort, req.SortOrder)), q.args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query users: %w", err)
+	}
+
+	exported := 0
+	for rows.Next() {
+		var user models.UserSummary
+		if err := rows.Scan(
+			&user.UserID, &user.Email, &user.FirstName, &user.LastName, &user.Phone, &user.PhotoURL,
+			&user.PrimaryRole, &user.AccountStatus, &user.IsActive, &user.LastLoginAt, &user.CreatedAt,
+		); err != nil {
+			rows.Close()
+			return exported, fmt.Errorf("failed to scan user: %w", err)
+		}
+		if err := write(user); err != nil {
+			rows.Close()
+			return exported, err
+		}
+		exported++
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return exported, fmt.Errorf("error iterating over user rows: %w", err)
+	}
+
+	s.auditService.LogSecurityEvent(ctx, SecurityEvent{
+		EventType:   EventTypeUserListExported,
+		Description: fmt.Sprintf("User list exported by admin %s", requestedBy),
+		UserID:      &requestedBy,
+		IPAddress:   s.securityValidator.GetClientIP(ctx),
+		Metadata: map[string]interface{}{
+			"list":    "users",
+			"rows":    exported,
+			"filters": req,
+		},
+	})
+
+	return exported, nil
+}
+
+// userListQuery applies the list filters, plus the city admin's own scope, and resolves
+// the sort
+func (s *UserManagementService) userListQuery(ctx context.Context, req *models.UserListRequest, requestedBy uuid.UUID) (*listQuery, listSort, error) {
+	if req.SortBy == "" {
+		req.SortBy = "created_at"
+	}
+	if req.SortOrder == "" {
+		req.SortOrder = "desc"
+	}
+	sort, exists := userListSorts[req.SortBy]
+	if !exists {
+		return nil, listSort{}, fmt.Errorf("invalid sort field: %s", req.SortBy)
+	}
+
+	q := &listQuery{}
+	if req.Search != "" {
+		q.search(req.Search)
+	}
+	if req.Role != "" {
+		q.where("up.primary_role = " + q.arg(req.Role))
+	}
+	if req.AccountStatus != "" {
+		q.where("up.account_status = " + q.arg(req.AccountStatus))
+	}
+	if req.IsActive != nil {
+		q.where("up.is_active = " + q.arg(*req.IsActive))
+	}
+	q.assignment(req.CityID, req.SportID, req.AssignedRole)
+	if err := q.dateRange("up.created_at", req.CreatedFrom, req.CreatedTo); err != nil {
+		return nil, listSort{}, err
+	}
+	if err := q.dateRange("up.last_login_at", req.LastLoginFrom, req.LastLoginTo); err != nil {
+		return nil, listSort{}, err
+	}
+
+	// City admins only see users assigned within their own city/sport
+	isSuperAdmin, err := s.isSuperAdmin(ctx, requestedBy)
+	if err != nil {
+		return nil, listSort{}, err
+	}
+	if !isSuperAdmin {
+		q.where(fmt.Sprintf(`up.user_id IN (
+			SELECT target.user_id FROM user_roles_by_city_sport target
+			JOIN user_roles_by_city_sport mine ON mine.user_id = %s
+			 AND mine.role_name = '%s' AND mine.is_active = true
+			 AND (mine.city_id IS NULL OR mine.city_id = target.city_id)
+			 AND (mine.sport_id IS NULL OR mine.sport_id = target.sport_id)
+			WHERE target.is_active = true
+		)`, q.arg(requestedBy), models.RoleCityAdmin))
+	}
+
+	return q, sort, nil
 }
 
 // GetDB returns the database connection for use in handlers
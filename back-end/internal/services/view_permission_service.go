@@ -0,0 +1,236 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"mowesport/internal/database"
+	"mowesport/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// View name constants, kept in sync with the frontend navigation
+const (
+	ViewDashboard   = "dashboard"
+	ViewAdmins      = "administration.admins"
+	ViewSuperAdmin  = "administration.super_admin"
+	ViewUsers       = "administration.users"
+	ViewPlayers     = "administration.players"
+	ViewReferees    = "administration.referees"
+	ViewTournaments = "main.tournaments"
+	ViewTeams       = "main.teams"
+	ViewMatches     = "main.matches"
+	ViewStatistics  = "main.statistics"
+	ViewSports      = "main.sports"
+	ViewCalendar    = "main.calendar"
+	ViewProfile     = "profile"
+	ViewSettings    = "settings"
+
+	// ViewMedicalData is not a screen: it allows reading decrypted medical and identity
+	// fields of players in API responses
+	ViewMedicalData = "administration.players.medical_data"
+)
+
+var allRoles = []string{
+	models.RoleSuperAdmin, models.RoleCityAdmin, models.RoleTournamentAdmin, models.RoleOwner,
+	models.RoleCoach, models.RoleReferee, models.RolePlayer, models.RoleClient,
+}
+
+// viewRegistry lists every named view and the roles allowed by default
+var viewRegistry = []models.ViewDefinition{
+	{Name: ViewDashboard, Label: "Dashboard", DefaultRoles: allRoles},
+	{Name: ViewAdmins, Label: "Administradores", DefaultRoles: []string{models.RoleSuperAdmin}},
+	{Name: ViewSuperAdmin, Label: "Super Admin", DefaultRoles: []string{models.RoleSuperAdmin}},
+	// Tournament admins register and assign referees (see the delegation matrix)
+	{Name: ViewUsers, Label: "Usuarios", DefaultRoles: []string{models.RoleSuperAdmin, models.RoleCityAdmin, models.RoleTournamentAdmin, models.RoleOwner}},
+	{Name: ViewPlayers, Label: "Jugadores", DefaultRoles: []string{models.RoleSuperAdmin, models.RoleCityAdmin, models.RoleOwner, models.RoleCoach}},
+	{Name: ViewReferees, Label: "Árbitros", DefaultRoles: []string{models.RoleSuperAdmin, models.RoleCityAdmin, models.RoleTournamentAdmin}},
+	{Name: ViewTournaments, Label: "Torneos", DefaultRoles: allRoles},
+	{Name: ViewTeams, Label: "Equipos", DefaultRoles: []string{models.RoleSuperAdmin, models.RoleCityAdmin, models.RoleTournamentAdmin, models.RoleOwner, models.RoleCoach, models.RolePlayer, models.RoleClient}},
+	{Name: ViewMatches, Label: "Partidos", DefaultRoles: allRoles},
+	{Name: ViewStatistics, Label: "Estadísticas", DefaultRoles: []string{models.RoleSuperAdmin, models.RoleCityAdmin, models.RoleTournamentAdmin, models.RoleOwner, models.RoleCoach, models.RolePlayer, models.RoleClient}},
+	{Name: ViewSports, Label: "Deportes", DefaultRoles: allRoles},
+	{Name: ViewCalendar, Label: "Calendario", DefaultRoles: allRoles},
+	{Name: ViewProfile, Label: "Perfil", DefaultRoles: allRoles},
+	{Name: ViewSettings, Label: "Configuración", DefaultRoles: []string{models.RoleSuperAdmin, models.RoleCityAdmin, models.RoleTournamentAdmin, models.RoleOwner}},
+	{Name: ViewMedicalData, Label: "Datos médicos de jugadores", DefaultRoles: []string{models.RoleSuperAdmin}},
+}
+
+// LookupView returns the registry definition of a named view
+func LookupView(name string) (models.ViewDefinition, bool) {
+	for _, view := range viewRegistry {
+		if view.Name == name {
+			return view, true
+		}
+	}
+	return models.ViewDefinition{}, false
+}
+
+// ViewPermissionService resolves view access from the registry and user_view_permissions.
+// Precedence: super admin, then a user-specific row, then a row for the user's role,
+// then the registry default.
+type ViewPermissionService struct {
+	db *database.Database
+}
+
+// NewViewPermissionService creates a new view permission service
+func NewViewPermissionService(db *database.Database) *ViewPermissionService {
+	return &ViewPermissionService{db: db}
+}
+
+// HasViewPermission checks whether a user may access a named view
+func (s *ViewPermissionService) HasViewPermission(ctx context.Context, userID uuid.UUID, viewName string) (bool, error) {
+	if _, ok := LookupView(viewName); !ok {
+		return false, fmt.Errorf("unknown view: %s", viewName)
+	}
+
+	permissions, err := s.GetEffectivePermissions(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return permissions.Views[viewName], nil
+}
+
+// GetEffectivePermissions resolves access to every registered view for a user
+func (s *ViewPermissionService) GetEffectivePermissions(ctx context.Context, userID uuid.UUID) (*models.EffectivePermissionsResponse, error) {
+	var primaryRole string
+	err := s.db.GetConnection().QueryRow(ctx,
+		"SELECT primary_role FROM user_profiles WHERE user_id = $1 AND is_active = true",
+		userID,
+	).Scan(&primaryRole)
+	if err != nil {
+		return nil, fmt.Errorf("user not found or inactive: %w", err)
+	}
+
+	userOverrides := make(map[string]bool)
+	roleOverrides := make(map[string]bool)
+	rows, err := s.db.GetConnection().Query(ctx, `
+		SELECT user_id IS NOT NULL, view_name, is_allowed
+		FROM user_view_permissions
+		WHERE user_id = $1 OR role_name = $2
+	`, userID, primaryRole)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load view permissions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var isUserRow, isAllowed bool
+		var viewName string
+		if err := rows.Scan(&isUserRow, &viewName, &isAllowed); err != nil {
+			return nil, fmt.Errorf("failed to scan view permission: %w", err)
+		}
+		if isUserRow {
+			userOverrides[viewName] = isAllowed
+		} else {
+			roleOverrides[viewName] = isAllowed
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over view permissions: %w", err)
+	}
+
+	response := &models.EffectivePermissionsResponse{
+		UserID:      userID,
+		PrimaryRole: primaryRole,
+		Views:       make(map[string]bool, len(viewRegistry)),
+		Permissions: make([]models.EffectivePermission, 0, len(viewRegistry)),
+	}
+
+	for _, view := range viewRegistry {
+		permission := models.EffectivePermission{ViewName: view.Name, Label: view.Label}
+		userAllowed, hasUserRow := userOverrides[view.Name]
+		roleAllowed, hasRoleRow := roleOverrides[view.Name]
+		switch {
+		case primaryRole == models.RoleSuperAdmin:
+			permission.Allowed, permission.Source = true, models.PermissionSourceSuperAdmin
+		case hasUserRow:
+			permission.Allowed, permission.Source = userAllowed, models.PermissionSourceUser
+		case hasRoleRow:
+			permission.Allowed, permission.Source = roleAllowed, models.PermissionSourceRole
+		default:
+			permission.Allowed, permission.Source = roleInList(primaryRole, view.DefaultRoles), models.PermissionSourceDefault
+		}
+
+		response.Views[view.Name] = permission.Allowed
+		response.Permissions = append(response.Permissions, permission)
+	}
+
+	return response, nil
+}
+
+// GetPermissionMatrix returns role-level access for every view plus all stored overrides
+func (s *ViewPermissionService) GetPermissionMatrix(ctx context.Context) (*models.PermissionMatrixResponse, error) {
+	rows, err := s.db.GetConnection().Query(ctx, `
+		SELECT p.permission_id, p.user_id, u.email, u.first_name || ' ' || u.last_name,
+		       p.role_name, p.view_name, p.is_allowed, p.configured_by_user_id, p.updated_at
+		FROM user_view_permissions p
+		LEFT JOIN user_profiles u ON u.user_id = p.user_id
+		ORDER BY p.view_name, p.role_name NULLS LAST, u.email
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load view permissions: %w", err)
+	}
+	defer rows.Close()
+
+	response := &models.PermissionMatrixResponse{
+		Views:         viewRegistry,
+		Roles:         allRoles,
+		RoleMatrix:    make(map[string]map[string]bool, len(viewRegistry)),
+		UserOverrides: []models.PermissionOverride{},
+		RoleOverrides: []models.PermissionOverride{},
+	}
+
+	roleOverrides := make(map[string]map[string]bool)
+	for rows.Next() {
+		var override models.PermissionOverride
+		if err := rows.Scan(
+			&override.PermissionID, &override.UserID, &override.UserEmail, &override.UserName,
+			&override.RoleName, &override.ViewName, &override.IsAllowed,
+			&override.ConfiguredByUserID, &override.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan view permission: %w", err)
+		}
+
+		if override.UserID != nil {
+			response.UserOverrides = append(response.UserOverrides, override)
+			continue
+		}
+		response.RoleOverrides = append(response.RoleOverrides, override)
+		if override.RoleName != nil {
+			if roleOverrides[override.ViewName] == nil {
+				roleOverrides[override.ViewName] = make(map[string]bool)
+			}
+			roleOverrides[override.ViewName][*override.RoleName] = override.IsAllowed
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over view permissions: %w", err)
+	}
+
+	for _, view := range viewRegistry {
+		response.RoleMatrix[view.Name] = make(map[string]bool, len(allRoles))
+		for _, role := range allRoles {
+			allowed := roleInList(role, view.DefaultRoles)
+			if override, ok := roleOverrides[view.Name][role]; ok {
+				allowed = override
+			}
+			if role == models.RoleSuperAdmin {
+				allowed = true
+			}
+			response.RoleMatrix[view.Name][role] = allowed
+		}
+	}
+
+	return response, nil
+}
+
+// roleInList checks if a role is part of a role list
+func roleInList(role string, roles []string) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
@@ -116,7 +116,7 @@ func (rts *RLSTestSuite) setupTestData() error {
 		('33333333-3333-3333-3333-333333333333', '22222222-2222-2222-2222-222222222222', '22222222-2222-2222-2222-222222222222', 'city_admin'),
 		('44444444-4444-4444-4444-444444444444', '11111111-1111-1111-1111-111111111111', '11111111-1111-1111-1111-111111111111', 'owner'),
 		('55555555-5555-5555-5555-555555555555', '22222222-2222-2222-2222-222222222222', '22222222-2222-2222-2222-222222222222', 'owner')
-		ON CONFLICT (user_id, city_id, sport_id, role_name) DO NOTHING
+		ON CONFLICT (user_id, city_id, sport_id, role_name) WHERE tournament_id IS NULL DO NOTHING
 	`)
 	if err != nil {
 		return fmt.Errorf("failed to create user role assignments: %v", err)
@@ -0,0 +1,9 @@
+-- =====================================================
+-- MOWE SPORT PLATFORM - API KEYS ROLLBACK
+-- =====================================================
+-- Migration: 009_create_api_keys (DOWN)
+-- Description: Rollback API keys table
+-- =====================================================
+
+DROP TRIGGER IF EXISTS update_api_keys_updated_at ON public.api_keys;
+DROP TABLE IF EXISTS public.api_keys;
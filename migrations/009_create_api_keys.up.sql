@@ -0,0 +1,46 @@
+-- =====================================================
+-- MOWE SPORT PLATFORM - API KEYS
+-- =====================================================
+-- Migration: 009_create_api_keys
+-- Description: Scoped API keys for machine integrations (scoreboards, overlays, scorer tablets)
+-- =====================================================
+
+-- =====================================================
+-- API KEYS TABLE
+-- =====================================================
+CREATE TABLE IF NOT EXISTS public.api_keys (
+    api_key_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) NOT NULL,
+    key_prefix VARCHAR(16) NOT NULL UNIQUE,
+    key_hash VARCHAR(64) NOT NULL,
+    permissions TEXT[] NOT NULL DEFAULT '{}',
+    tournament_id UUID REFERENCES public.tournaments(tournament_id) ON DELETE CASCADE,
+    city_id UUID REFERENCES public.cities(city_id) ON DELETE CASCADE,
+    sport_id UUID REFERENCES public.sports(sport_id) ON DELETE CASCADE,
+    created_by_user_id UUID NOT NULL REFERENCES public.user_profiles(user_id),
+    expires_at TIMESTAMP WITH TIME ZONE,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    revoked_by_user_id UUID REFERENCES public.user_profiles(user_id),
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    -- A key is scoped either to a tournament or to a city (optionally narrowed to a sport)
+    CONSTRAINT api_keys_scope_check CHECK (
+        tournament_id IS NOT NULL OR city_id IS NOT NULL
+    )
+);
+
+COMMENT ON TABLE public.api_keys IS 'API keys for machine integrations, hashed at rest';
+COMMENT ON COLUMN public.api_keys.key_prefix IS 'Public lookup prefix of the key, safe to display';
+COMMENT ON COLUMN public.api_keys.key_hash IS 'SHA-256 hex digest of the full key';
+COMMENT ON COLUMN public.api_keys.permissions IS 'Granted permissions, e.g. matches:read, events:write';
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_created_by ON public.api_keys(created_by_user_id);
+CREATE INDEX IF NOT EXISTS idx_api_keys_tournament ON public.api_keys(tournament_id);
+CREATE INDEX IF NOT EXISTS idx_api_keys_city_sport ON public.api_keys(city_id, sport_id);
+
+CREATE TRIGGER update_api_keys_updated_at
+    BEFORE UPDATE ON public.api_keys
+    FOR EACH ROW EXECUTE FUNCTION public.update_updated_at_column();
@@ -0,0 +1,11 @@
+-- =====================================================
+-- MOWE SPORT PLATFORM - EMAIL VERIFICATION ROLLBACK
+-- =====================================================
+-- Migration: 010_email_verification (DOWN)
+-- Description: Rollback email verification tables and column
+-- =====================================================
+
+DROP TABLE IF EXISTS public.email_verification_tokens;
+DROP TABLE IF EXISTS public.email_change_requests;
+
+ALTER TABLE public.user_profiles DROP COLUMN IF EXISTS email_verified_at;
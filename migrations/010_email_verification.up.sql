@@ -0,0 +1,55 @@
+-- =====================================================
+-- MOWE SPORT PLATFORM - EMAIL VERIFICATION
+-- =====================================================
+-- Migration: 010_email_verification
+-- Description: Email verification for self-signup and confirmed email changes
+-- =====================================================
+
+-- Existing and admin-created accounts are considered verified; self-signup inserts NULL
+ALTER TABLE public.user_profiles
+    ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP WITH TIME ZONE DEFAULT NOW();
+
+COMMENT ON COLUMN public.user_profiles.email_verified_at IS 'When the user proved ownership of the email address, NULL while unverified';
+
+-- =====================================================
+-- EMAIL CHANGE REQUESTS TABLE
+-- =====================================================
+CREATE TABLE IF NOT EXISTS public.email_change_requests (
+    request_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES public.user_profiles(user_id) ON DELETE CASCADE,
+    old_email VARCHAR(255) NOT NULL,
+    new_email VARCHAR(255) NOT NULL,
+    old_confirmed_at TIMESTAMP WITH TIME ZONE,
+    new_confirmed_at TIMESTAMP WITH TIME ZONE,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    cancelled_at TIMESTAMP WITH TIME ZONE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE public.email_change_requests IS 'Pending email changes, applied once both old and new addresses confirm';
+
+CREATE INDEX IF NOT EXISTS idx_email_change_requests_user ON public.email_change_requests(user_id);
+
+-- =====================================================
+-- EMAIL VERIFICATION TOKENS TABLE
+-- =====================================================
+CREATE TABLE IF NOT EXISTS public.email_verification_tokens (
+    token_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES public.user_profiles(user_id) ON DELETE CASCADE,
+    purpose VARCHAR(30) NOT NULL CHECK (
+        purpose IN ('signup', 'email_change_old', 'email_change_new')
+    ),
+    email VARCHAR(255) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL,
+    change_request_id UUID REFERENCES public.email_change_requests(request_id) ON DELETE CASCADE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE public.email_verification_tokens IS 'Single-use signed email verification links';
+COMMENT ON COLUMN public.email_verification_tokens.email IS 'Address the link was sent to';
+COMMENT ON COLUMN public.email_verification_tokens.token_hash IS 'SHA-256 hex digest of the random token part';
+
+CREATE INDEX IF NOT EXISTS idx_email_verification_tokens_user_purpose ON public.email_verification_tokens(user_id, purpose, created_at);
@@ -0,0 +1,9 @@
+-- =====================================================
+-- MOWE SPORT PLATFORM - USER INVITATIONS ROLLBACK
+-- =====================================================
+-- Migration: 011_create_user_invitations (DOWN)
+-- Description: Rollback user invitations table
+-- =====================================================
+
+DROP TRIGGER IF EXISTS update_user_invitations_updated_at ON public.user_invitations;
+DROP TABLE IF EXISTS public.user_invitations;
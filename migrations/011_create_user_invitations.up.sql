@@ -0,0 +1,45 @@
+-- =====================================================
+-- MOWE SPORT PLATFORM - USER INVITATIONS
+-- =====================================================
+-- Migration: 011_create_user_invitations
+-- Description: Invitation links for admin-registered users instead of emailed temporary passwords
+-- =====================================================
+
+-- =====================================================
+-- USER INVITATIONS TABLE
+-- =====================================================
+CREATE TABLE IF NOT EXISTS public.user_invitations (
+    invitation_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES public.user_profiles(user_id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    role_name VARCHAR(50) NOT NULL,
+    city_id UUID REFERENCES public.cities(city_id) ON DELETE SET NULL,
+    sport_id UUID REFERENCES public.sports(sport_id) ON DELETE SET NULL,
+    token_hash VARCHAR(64) NOT NULL,
+    invited_by_user_id UUID NOT NULL REFERENCES public.user_profiles(user_id),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    accepted_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    revoked_by_user_id UUID REFERENCES public.user_profiles(user_id),
+    send_count INTEGER NOT NULL DEFAULT 1,
+    last_sent_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE public.user_invitations IS 'Single-use invitation links; the invitee chooses their own password on acceptance';
+COMMENT ON COLUMN public.user_invitations.token_hash IS 'SHA-256 hex digest of the current link secret, replaced on resend';
+COMMENT ON COLUMN public.user_invitations.send_count IS 'How many times the invitation email was sent';
+
+CREATE INDEX IF NOT EXISTS idx_user_invitations_user ON public.user_invitations(user_id);
+CREATE INDEX IF NOT EXISTS idx_user_invitations_invited_by ON public.user_invitations(invited_by_user_id);
+CREATE INDEX IF NOT EXISTS idx_user_invitations_scope ON public.user_invitations(city_id, sport_id);
+
+-- Only one open invitation per user
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_invitations_pending_user
+    ON public.user_invitations(user_id)
+    WHERE accepted_at IS NULL AND revoked_at IS NULL;
+
+CREATE TRIGGER update_user_invitations_updated_at
+    BEFORE UPDATE ON public.user_invitations
+    FOR EACH ROW EXECUTE FUNCTION public.update_updated_at_column();
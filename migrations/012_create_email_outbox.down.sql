@@ -0,0 +1,9 @@
+-- =====================================================
+-- MOWE SPORT PLATFORM - EMAIL OUTBOX ROLLBACK
+-- =====================================================
+-- Migration: 012_create_email_outbox (DOWN)
+-- Description: Rollback email outbox table
+-- =====================================================
+
+DROP TRIGGER IF EXISTS update_email_outbox_updated_at ON public.email_outbox;
+DROP TABLE IF EXISTS public.email_outbox;
@@ -0,0 +1,42 @@
+-- =====================================================
+-- MOWE SPORT PLATFORM - EMAIL OUTBOX
+-- =====================================================
+-- Migration: 012_create_email_outbox
+-- Description: Transactional outbox for outgoing email, delivered by a background worker
+-- =====================================================
+
+-- =====================================================
+-- EMAIL OUTBOX TABLE
+-- =====================================================
+CREATE TABLE IF NOT EXISTS public.email_outbox (
+    message_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    to_email VARCHAR(255) NOT NULL,
+    subject VARCHAR(500) NOT NULL,
+    body TEXT NOT NULL,
+    is_html BOOLEAN NOT NULL DEFAULT true,
+    attachments JSONB NOT NULL DEFAULT '[]'::jsonb,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (
+        status IN ('pending', 'sending', 'sent', 'failed')
+    ),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 8,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    locked_at TIMESTAMP WITH TIME ZONE,
+    last_error TEXT,
+    provider_message_id VARCHAR(255),
+    sent_at TIMESTAMP WITH TIME ZONE,
+    failed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE public.email_outbox IS 'Outgoing email written in the same transaction as the change that triggers it';
+COMMENT ON COLUMN public.email_outbox.status IS 'pending, sending (claimed by a worker), sent, or failed (dead-lettered after max_attempts)';
+COMMENT ON COLUMN public.email_outbox.provider_message_id IS 'Message-ID of the delivered email';
+
+CREATE INDEX IF NOT EXISTS idx_email_outbox_due ON public.email_outbox(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_email_outbox_status ON public.email_outbox(status, created_at);
+
+CREATE TRIGGER update_email_outbox_updated_at
+    BEFORE UPDATE ON public.email_outbox
+    FOR EACH ROW EXECUTE FUNCTION public.update_updated_at_column();
@@ -0,0 +1,10 @@
+-- =====================================================
+-- MOWE SPORT PLATFORM - EMAIL OUTBOX MIME FIELDS ROLLBACK
+-- =====================================================
+-- Migration: 013_add_email_outbox_mime_fields (DOWN)
+-- Description: Rollback email outbox MIME fields
+-- =====================================================
+
+ALTER TABLE public.email_outbox
+    DROP COLUMN IF EXISTS reply_to,
+    DROP COLUMN IF EXISTS text_body;
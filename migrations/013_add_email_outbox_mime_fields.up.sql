@@ -0,0 +1,13 @@
+-- =====================================================
+-- MOWE SPORT PLATFORM - EMAIL OUTBOX MIME FIELDS
+-- =====================================================
+-- Migration: 013_add_email_outbox_mime_fields
+-- Description: Store the plain-text alternative and Reply-To address of queued email
+-- =====================================================
+
+ALTER TABLE public.email_outbox
+    ADD COLUMN IF NOT EXISTS text_body TEXT,
+    ADD COLUMN IF NOT EXISTS reply_to VARCHAR(255);
+
+COMMENT ON COLUMN public.email_outbox.text_body IS 'Plain-text alternative of an HTML body; derived from the HTML at send time when NULL';
+COMMENT ON COLUMN public.email_outbox.reply_to IS 'Per-message Reply-To address; falls back to REPLY_TO_EMAIL when NULL';
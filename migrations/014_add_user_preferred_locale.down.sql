@@ -0,0 +1,9 @@
+-- =====================================================
+-- MOWE SPORT PLATFORM - USER PREFERRED LOCALE ROLLBACK
+-- =====================================================
+-- Migration: 014_add_user_preferred_locale (DOWN)
+-- Description: Rollback user preferred locale
+-- =====================================================
+
+ALTER TABLE public.user_profiles
+    DROP COLUMN IF EXISTS preferred_locale;
@@ -0,0 +1,12 @@
+-- =====================================================
+-- MOWE SPORT PLATFORM - USER PREFERRED LOCALE
+-- =====================================================
+-- Migration: 014_add_user_preferred_locale
+-- Description: Language preference used to pick localized email templates
+-- =====================================================
+
+ALTER TABLE public.user_profiles
+    ADD COLUMN IF NOT EXISTS preferred_locale VARCHAR(10) NOT NULL DEFAULT 'es-CO'
+        CHECK (preferred_locale IN ('es-CO', 'en'));
+
+COMMENT ON COLUMN public.user_profiles.preferred_locale IS 'Locale of the email templates sent to the user (es-CO or en)';
@@ -0,0 +1,10 @@
+-- =====================================================
+-- MOWE SPORT PLATFORM - SECURITY AUDIT LOG ROLLBACK
+-- =====================================================
+-- Migration: 015_create_security_audit_log (DOWN)
+-- Description: Rollback unified security audit log
+-- =====================================================
+
+DROP TRIGGER IF EXISTS mirror_audit_logs ON public.audit_logs;
+DROP FUNCTION IF EXISTS public.mirror_audit_log_to_security_audit_log();
+DROP TABLE IF EXISTS public.security_audit_log;
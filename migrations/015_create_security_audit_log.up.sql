@@ -0,0 +1,97 @@
+-- =====================================================
+-- MOWE SPORT PLATFORM - SECURITY AUDIT LOG
+-- =====================================================
+-- Migration: 015_create_security_audit_log
+-- Description: Single audit trail for application and database events.
+--              Rows written to the legacy audit_logs table by database
+--              functions and triggers are mirrored into security_audit_log.
+-- =====================================================
+
+-- =====================================================
+-- SECURITY AUDIT LOG TABLE
+-- =====================================================
+CREATE TABLE IF NOT EXISTS public.security_audit_log (
+    event_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    event_type VARCHAR(50) NOT NULL,
+    description TEXT NOT NULL,
+    user_id UUID REFERENCES public.user_profiles(user_id) ON DELETE SET NULL,
+    ip_address INET,
+    user_agent TEXT,
+    request_id VARCHAR(100),
+    metadata JSONB NOT NULL DEFAULT '{}'::jsonb,
+    timestamp TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    severity VARCHAR(20) NOT NULL DEFAULT 'MEDIUM'
+        CHECK (severity IN ('LOW', 'MEDIUM', 'HIGH', 'CRITICAL')),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Earlier deployments created the table at runtime without these columns
+ALTER TABLE public.security_audit_log ADD COLUMN IF NOT EXISTS request_id VARCHAR(100);
+
+CREATE INDEX IF NOT EXISTS idx_security_audit_event_type ON public.security_audit_log(event_type);
+CREATE INDEX IF NOT EXISTS idx_security_audit_user_id ON public.security_audit_log(user_id);
+CREATE INDEX IF NOT EXISTS idx_security_audit_timestamp ON public.security_audit_log(timestamp);
+CREATE INDEX IF NOT EXISTS idx_security_audit_severity ON public.security_audit_log(severity);
+CREATE INDEX IF NOT EXISTS idx_security_audit_ip_address ON public.security_audit_log(ip_address);
+
+COMMENT ON TABLE public.security_audit_log IS 'Unified audit trail; purged after the configured retention period';
+COMMENT ON COLUMN public.security_audit_log.request_id IS 'X-Request-ID of the HTTP request that produced the event';
+
+-- =====================================================
+-- LEGACY AUDIT_LOGS MIRROR
+-- =====================================================
+CREATE OR REPLACE FUNCTION public.mirror_audit_log_to_security_audit_log()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO public.security_audit_log (
+        event_id, event_type, description, user_id, ip_address, user_agent, metadata, timestamp, severity
+    ) VALUES (
+        NEW.log_id,
+        NEW.action,
+        NEW.action || COALESCE(' on ' || NEW.table_name, ''),
+        NEW.user_id,
+        NEW.ip_address,
+        NEW.user_agent,
+        jsonb_strip_nulls(jsonb_build_object(
+            'source', 'audit_logs',
+            'table_name', NEW.table_name,
+            'record_id', NEW.record_id,
+            'old_values', NEW.old_values,
+            'new_values', NEW.new_values
+        )),
+        NEW.created_at,
+        'LOW'
+    )
+    ON CONFLICT (event_id) DO NOTHING;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS mirror_audit_logs ON public.audit_logs;
+CREATE TRIGGER mirror_audit_logs
+    AFTER INSERT ON public.audit_logs
+    FOR EACH ROW EXECUTE FUNCTION public.mirror_audit_log_to_security_audit_log();
+
+-- Backfill existing legacy rows
+INSERT INTO public.security_audit_log (
+    event_id, event_type, description, user_id, ip_address, user_agent, metadata, timestamp, severity
+)
+SELECT
+    log_id,
+    action,
+    action || COALESCE(' on ' || table_name, ''),
+    user_id,
+    ip_address,
+    user_agent,
+    jsonb_strip_nulls(jsonb_build_object(
+        'source', 'audit_logs',
+        'table_name', table_name,
+        'record_id', record_id,
+        'old_values', old_values,
+        'new_values', new_values
+    )),
+    created_at,
+    'LOW'
+FROM public.audit_logs
+ON CONFLICT (event_id) DO NOTHING;
@@ -0,0 +1,9 @@
+-- =====================================================
+-- MOWE SPORT PLATFORM - SECURITY ALERTS ROLLBACK
+-- =====================================================
+-- Migration: 016_create_security_alerts (DOWN)
+-- Description: Rollback security alerts table
+-- =====================================================
+
+DROP TRIGGER IF EXISTS update_security_alerts_updated_at ON public.security_alerts;
+DROP TABLE IF EXISTS public.security_alerts;
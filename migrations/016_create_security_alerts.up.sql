@@ -0,0 +1,42 @@
+-- =====================================================
+-- MOWE SPORT PLATFORM - SECURITY ALERTS
+-- =====================================================
+-- Migration: 016_create_security_alerts
+-- Description: Alerts raised from the security audit log by threshold rules,
+--              shown to super admins and used to deduplicate notifications
+-- =====================================================
+
+-- =====================================================
+-- SECURITY ALERTS TABLE
+-- =====================================================
+CREATE TABLE IF NOT EXISTS public.security_alerts (
+    alert_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    rule_name VARCHAR(100) NOT NULL,
+    fingerprint VARCHAR(255) NOT NULL,
+    severity VARCHAR(20) NOT NULL CHECK (severity IN ('LOW', 'MEDIUM', 'HIGH', 'CRITICAL')),
+    title VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL,
+    user_id UUID REFERENCES public.user_profiles(user_id) ON DELETE SET NULL,
+    ip_addresses TEXT[] NOT NULL DEFAULT '{}',
+    event_count INTEGER NOT NULL,
+    suppressed_count INTEGER NOT NULL DEFAULT 0,
+    first_event_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_event_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_notified_at TIMESTAMP WITH TIME ZONE,
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'acknowledged')),
+    acknowledged_by UUID REFERENCES public.user_profiles(user_id) ON DELETE SET NULL,
+    acknowledged_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE public.security_alerts IS 'Incidents detected in security_audit_log; one row per incident, not per event';
+COMMENT ON COLUMN public.security_alerts.fingerprint IS 'Rule name and grouping key (account, IP or event type) used to deduplicate alerts';
+COMMENT ON COLUMN public.security_alerts.suppressed_count IS 'Notifications skipped because the alert was still within its cooldown';
+
+CREATE INDEX IF NOT EXISTS idx_security_alerts_fingerprint ON public.security_alerts(fingerprint, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_security_alerts_status ON public.security_alerts(status, created_at DESC);
+
+CREATE TRIGGER update_security_alerts_updated_at
+    BEFORE UPDATE ON public.security_alerts
+    FOR EACH ROW EXECUTE FUNCTION public.update_updated_at_column();
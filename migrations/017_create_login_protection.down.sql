@@ -0,0 +1,10 @@
+-- =====================================================
+-- MOWE SPORT PLATFORM - LOGIN PROTECTION ROLLBACK
+-- =====================================================
+-- Migration: 017_create_login_protection (DOWN)
+-- Description: Rollback login attempts, IP blocks and login challenges
+-- =====================================================
+
+DROP TABLE IF EXISTS public.login_challenges;
+DROP TABLE IF EXISTS public.ip_blocks;
+DROP TABLE IF EXISTS public.login_attempts;
@@ -0,0 +1,66 @@
+-- =====================================================
+-- MOWE SPORT PLATFORM - LOGIN PROTECTION
+-- =====================================================
+-- Migration: 017_create_login_protection
+-- Description: Login attempt history for brute-force and credential-stuffing
+--              detection across accounts, temporary IP blocks and emailed
+--              step-up challenges
+-- =====================================================
+
+-- =====================================================
+-- LOGIN ATTEMPTS TABLE
+-- =====================================================
+CREATE TABLE IF NOT EXISTS public.login_attempts (
+    attempt_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    ip_address INET NOT NULL,
+    subnet CIDR NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    user_id UUID REFERENCES public.user_profiles(user_id) ON DELETE SET NULL,
+    success BOOLEAN NOT NULL,
+    failure_reason VARCHAR(50),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE public.login_attempts IS 'Every login outcome, including unknown emails, used to detect attacks spread across accounts';
+COMMENT ON COLUMN public.login_attempts.subnet IS '/24 for IPv4 and /64 for IPv6 addresses';
+COMMENT ON COLUMN public.login_attempts.email IS 'Email as submitted, lowercased; may not belong to any account';
+
+CREATE INDEX IF NOT EXISTS idx_login_attempts_ip ON public.login_attempts(ip_address, created_at) WHERE NOT success;
+CREATE INDEX IF NOT EXISTS idx_login_attempts_subnet ON public.login_attempts(subnet, created_at) WHERE NOT success;
+CREATE INDEX IF NOT EXISTS idx_login_attempts_created_at ON public.login_attempts(created_at);
+
+-- =====================================================
+-- IP BLOCKS TABLE
+-- =====================================================
+CREATE TABLE IF NOT EXISTS public.ip_blocks (
+    block_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    network CIDR NOT NULL,
+    reason VARCHAR(100) NOT NULL,
+    failure_count INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    lifted_by UUID REFERENCES public.user_profiles(user_id) ON DELETE SET NULL,
+    lifted_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE public.ip_blocks IS 'Temporary login blocks for an IP address (/32, /128) or subnet';
+
+CREATE INDEX IF NOT EXISTS idx_ip_blocks_active ON public.ip_blocks(expires_at) WHERE lifted_at IS NULL;
+
+-- =====================================================
+-- LOGIN CHALLENGES TABLE
+-- =====================================================
+CREATE TABLE IF NOT EXISTS public.login_challenges (
+    challenge_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES public.user_profiles(user_id) ON DELETE CASCADE,
+    code_hash VARCHAR(255) NOT NULL,
+    ip_address INET,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    consumed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE public.login_challenges IS 'One-time codes emailed when a login comes from a network under attack';
+
+CREATE INDEX IF NOT EXISTS idx_login_challenges_user ON public.login_challenges(user_id, created_at DESC) WHERE consumed_at IS NULL;
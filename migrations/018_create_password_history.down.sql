@@ -0,0 +1,9 @@
+-- =====================================================
+-- MOWE SPORT PLATFORM - PASSWORD HISTORY ROLLBACK
+-- =====================================================
+-- Migration: 018_create_password_history (DOWN)
+-- Description: Rollback password history and password change timestamp
+-- =====================================================
+
+ALTER TABLE public.user_profiles DROP COLUMN IF EXISTS password_changed_at;
+DROP TABLE IF EXISTS public.password_history;
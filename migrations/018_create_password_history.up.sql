@@ -0,0 +1,31 @@
+-- =====================================================
+-- MOWE SPORT PLATFORM - PASSWORD HISTORY
+-- =====================================================
+-- Migration: 018_create_password_history
+-- Description: Previous password hashes for reuse checks and the timestamp
+--              of the last password change for forced rotation
+-- =====================================================
+
+-- =====================================================
+-- PASSWORD HISTORY TABLE
+-- =====================================================
+CREATE TABLE IF NOT EXISTS public.password_history (
+    history_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES public.user_profiles(user_id) ON DELETE CASCADE,
+    password_hash VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_password_history_user_created
+    ON public.password_history(user_id, created_at DESC);
+
+COMMENT ON TABLE public.password_history IS 'Bcrypt hashes of passwords previously set by each user, pruned to the configured history size';
+
+-- =====================================================
+-- PASSWORD CHANGE TIMESTAMP
+-- =====================================================
+-- Existing accounts start their expiration period when this migration runs
+ALTER TABLE public.user_profiles
+    ADD COLUMN IF NOT EXISTS password_changed_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL;
+
+COMMENT ON COLUMN public.user_profiles.password_changed_at IS 'When the current password was set; drives forced password rotation';
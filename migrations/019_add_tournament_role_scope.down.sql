@@ -0,0 +1,19 @@
+-- =====================================================
+-- MOWE SPORT PLATFORM - TOURNAMENT ROLE SCOPE ROLLBACK
+-- =====================================================
+-- Migration: 019_add_tournament_role_scope (DOWN)
+-- Description: Rollback tournament-bound role assignments
+-- =====================================================
+
+DROP INDEX IF EXISTS public.idx_user_roles_tournament;
+DROP INDEX IF EXISTS public.idx_user_roles_unique_tournament_assignment;
+DROP INDEX IF EXISTS public.idx_user_roles_unique_assignment;
+
+-- Tournament-bound assignments cannot be represented without the column
+DELETE FROM public.user_roles_by_city_sport WHERE tournament_id IS NOT NULL;
+
+ALTER TABLE public.user_roles_by_city_sport DROP COLUMN IF EXISTS tournament_id;
+
+ALTER TABLE public.user_roles_by_city_sport
+    ADD CONSTRAINT user_roles_by_city_sport_user_id_city_id_sport_id_role_name_key
+    UNIQUE (user_id, city_id, sport_id, role_name);
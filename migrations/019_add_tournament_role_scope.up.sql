@@ -0,0 +1,30 @@
+-- =====================================================
+-- MOWE SPORT PLATFORM - TOURNAMENT ROLE SCOPE
+-- =====================================================
+-- Migration: 019_add_tournament_role_scope
+-- Description: Bind role assignments (tournament admins) to a specific
+--              tournament in addition to its city and sport
+-- =====================================================
+
+ALTER TABLE public.user_roles_by_city_sport
+    ADD COLUMN IF NOT EXISTS tournament_id UUID REFERENCES public.tournaments(tournament_id) ON DELETE CASCADE;
+
+COMMENT ON COLUMN public.user_roles_by_city_sport.tournament_id IS 'Tournament the assignment is limited to; NULL covers every tournament in the city/sport';
+
+-- A user may administer several tournaments of the same city and sport. NULLs are
+-- distinct in unique indexes, so assignments without a tournament keep the original
+-- uniqueness in their own partial index and tournament-bound ones get another.
+ALTER TABLE public.user_roles_by_city_sport
+    DROP CONSTRAINT IF EXISTS user_roles_by_city_sport_user_id_city_id_sport_id_role_name_key;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_roles_unique_assignment
+    ON public.user_roles_by_city_sport(user_id, city_id, sport_id, role_name)
+    WHERE tournament_id IS NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_roles_unique_tournament_assignment
+    ON public.user_roles_by_city_sport(user_id, city_id, sport_id, role_name, tournament_id)
+    WHERE tournament_id IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_user_roles_tournament
+    ON public.user_roles_by_city_sport(tournament_id)
+    WHERE tournament_id IS NOT NULL;
@@ -0,0 +1,25 @@
+-- =====================================================
+-- MOWE SPORT PLATFORM - ACCOUNT STATUS EFFECTS ROLLBACK
+-- =====================================================
+-- Migration: 020_add_account_status_effects (DOWN)
+-- Description: Rollback token versions, tournament holds and scheduled
+--              account status changes
+-- =====================================================
+
+DROP TABLE IF EXISTS public.account_status_schedules CASCADE;
+
+DROP POLICY IF EXISTS "public_view_public_tournaments" ON public.tournaments;
+CREATE POLICY "public_view_public_tournaments" ON public.tournaments
+    FOR SELECT TO anon, authenticated
+    USING (is_public = TRUE AND status IN ('approved', 'active', 'completed'));
+
+DROP INDEX IF EXISTS public.idx_tournaments_admin_on_hold;
+
+ALTER TABLE public.tournaments
+    DROP COLUMN IF EXISTS on_hold_reason,
+    DROP COLUMN IF EXISTS on_hold_since;
+
+ALTER TABLE public.user_profiles
+    DROP COLUMN IF EXISTS account_status_changed_at,
+    DROP COLUMN IF EXISTS account_status_reason,
+    DROP COLUMN IF EXISTS token_version;
@@ -0,0 +1,66 @@
+-- =====================================================
+-- MOWE SPORT PLATFORM - ACCOUNT STATUS EFFECTS
+-- =====================================================
+-- Migration: 020_add_account_status_effects
+-- Description: Token versions to revoke live tokens on status changes,
+--              the reason of the current status, tournaments put on hold
+--              while their admin is suspended or disabled, and scheduled
+--              status changes (reactivations)
+-- =====================================================
+
+-- =====================================================
+-- USER PROFILE COLUMNS
+-- =====================================================
+ALTER TABLE public.user_profiles
+    ADD COLUMN IF NOT EXISTS token_version INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS account_status_reason TEXT,
+    ADD COLUMN IF NOT EXISTS account_status_changed_at TIMESTAMP WITH TIME ZONE;
+
+COMMENT ON COLUMN public.user_profiles.token_version IS 'Incremented on account status changes; tokens carrying an older version are rejected';
+COMMENT ON COLUMN public.user_profiles.account_status_reason IS 'Reason given for the current account status';
+
+-- =====================================================
+-- TOURNAMENT HOLDS
+-- =====================================================
+ALTER TABLE public.tournaments
+    ADD COLUMN IF NOT EXISTS on_hold_since TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS on_hold_reason VARCHAR(100);
+
+COMMENT ON COLUMN public.tournaments.on_hold_since IS 'Set while the tournament admin account is suspended or disabled; held tournaments are hidden from the public';
+
+CREATE INDEX IF NOT EXISTS idx_tournaments_admin_on_hold
+    ON public.tournaments(admin_user_id) WHERE on_hold_since IS NOT NULL;
+
+DROP POLICY IF EXISTS "public_view_public_tournaments" ON public.tournaments;
+CREATE POLICY "public_view_public_tournaments" ON public.tournaments
+    FOR SELECT TO anon, authenticated
+    USING (is_public = TRUE AND on_hold_since IS NULL AND status IN ('approved', 'active', 'completed'));
+
+-- =====================================================
+-- SCHEDULED ACCOUNT STATUS CHANGES
+-- =====================================================
+CREATE TABLE IF NOT EXISTS public.account_status_schedules (
+    schedule_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES public.user_profiles(user_id) ON DELETE CASCADE,
+    target_status VARCHAR(20) NOT NULL CHECK (
+        target_status IN ('active', 'suspended', 'payment_pending', 'disabled')
+    ),
+    reason TEXT NOT NULL,
+    scheduled_for TIMESTAMP WITH TIME ZONE NOT NULL,
+    notify_user BOOLEAN NOT NULL DEFAULT TRUE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (
+        status IN ('pending', 'applied', 'cancelled', 'failed')
+    ),
+    created_by_user_id UUID REFERENCES public.user_profiles(user_id) ON DELETE SET NULL,
+    applied_at TIMESTAMP WITH TIME ZONE,
+    last_error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE public.account_status_schedules IS 'Account status changes applied by the scheduler at scheduled_for, e.g. reactivating a suspended account';
+
+-- At most one pending change per account
+CREATE UNIQUE INDEX IF NOT EXISTS idx_account_status_schedules_pending_user
+    ON public.account_status_schedules(user_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_account_status_schedules_due
+    ON public.account_status_schedules(scheduled_for) WHERE status = 'pending';
@@ -0,0 +1,9 @@
+-- =====================================================
+-- MOWE SPORT PLATFORM - USER IMPORTS ROLLBACK
+-- =====================================================
+-- Migration: 021_create_user_imports (DOWN)
+-- Description: Rollback bulk user import tracking
+-- =====================================================
+
+DROP TABLE IF EXISTS public.user_import_rows;
+DROP TABLE IF EXISTS public.user_imports;
@@ -0,0 +1,54 @@
+-- =====================================================
+-- MOWE SPORT PLATFORM - USER IMPORTS
+-- =====================================================
+-- Migration: 021_create_user_imports
+-- Description: Bulk user/player imports from CSV or XLSX files, with the
+--              outcome of every row so partial imports can be resumed
+-- =====================================================
+
+-- =====================================================
+-- USER IMPORTS TABLE
+-- =====================================================
+CREATE TABLE IF NOT EXISTS public.user_imports (
+    import_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    file_name VARCHAR(255) NOT NULL,
+    file_sha256 VARCHAR(64) NOT NULL,
+    mode VARCHAR(20) NOT NULL CHECK (mode IN ('atomic', 'partial')),
+    status VARCHAR(20) NOT NULL DEFAULT 'running' CHECK (
+        status IN ('running', 'completed', 'partial', 'failed')
+    ),
+    default_role VARCHAR(50),
+    city_id UUID NOT NULL REFERENCES public.cities(city_id) ON DELETE RESTRICT,
+    sport_id UUID NOT NULL REFERENCES public.sports(sport_id) ON DELETE RESTRICT,
+    tournament_id UUID REFERENCES public.tournaments(tournament_id) ON DELETE SET NULL,
+    total_rows INTEGER NOT NULL DEFAULT 0,
+    imported_rows INTEGER NOT NULL DEFAULT 0,
+    failed_rows INTEGER NOT NULL DEFAULT 0,
+    created_by_user_id UUID NOT NULL REFERENCES public.user_profiles(user_id) ON DELETE RESTRICT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+    completed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_imports_created_by
+    ON public.user_imports(created_by_user_id, created_at DESC);
+
+COMMENT ON TABLE public.user_imports IS 'Bulk user imports committed from CSV/XLSX files';
+COMMENT ON COLUMN public.user_imports.file_sha256 IS 'Checksum of the last file committed for this import';
+
+-- =====================================================
+-- USER IMPORT ROWS TABLE
+-- =====================================================
+CREATE TABLE IF NOT EXISTS public.user_import_rows (
+    import_id UUID NOT NULL REFERENCES public.user_imports(import_id) ON DELETE CASCADE,
+    row_number INTEGER NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    role_name VARCHAR(50) NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('imported', 'failed')),
+    user_id UUID REFERENCES public.user_profiles(user_id) ON DELETE SET NULL,
+    error TEXT,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+
+    PRIMARY KEY (import_id, email)
+);
+
+COMMENT ON TABLE public.user_import_rows IS 'Outcome of each committed import row by email; imported emails are skipped when the import is resumed';
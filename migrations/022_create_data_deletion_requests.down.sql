@@ -0,0 +1,10 @@
+-- =====================================================
+-- MOWE SPORT PLATFORM - PERSONAL DATA REQUESTS ROLLBACK
+-- =====================================================
+-- Migration: 022_create_data_deletion_requests (DOWN)
+-- Description: Rollback data deletion requests and anonymization markers
+-- =====================================================
+
+ALTER TABLE public.players DROP COLUMN IF EXISTS anonymized_at;
+ALTER TABLE public.user_profiles DROP COLUMN IF EXISTS anonymized_at;
+DROP TABLE IF EXISTS public.data_deletion_requests;
@@ -0,0 +1,51 @@
+-- =====================================================
+-- MOWE SPORT PLATFORM - PERSONAL DATA REQUESTS
+-- =====================================================
+-- Migration: 022_create_data_deletion_requests
+-- Description: Data-subject deletion requests (Ley 1581 de 2012 / Habeas
+--              Data). Approved requests anonymize personal fields after a
+--              grace period; match statistics are kept.
+-- =====================================================
+
+-- =====================================================
+-- DATA DELETION REQUESTS TABLE
+-- =====================================================
+CREATE TABLE IF NOT EXISTS public.data_deletion_requests (
+    request_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES public.user_profiles(user_id) ON DELETE CASCADE,
+    reason TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (
+        status IN ('pending', 'approved', 'rejected', 'cancelled', 'completed', 'failed')
+    ),
+    requested_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    reviewed_by_user_id UUID REFERENCES public.user_profiles(user_id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    review_notes TEXT,
+    scheduled_for TIMESTAMP WITH TIME ZONE,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    last_error TEXT,
+
+    CHECK (status NOT IN ('approved', 'completed') OR scheduled_for IS NOT NULL)
+);
+
+COMMENT ON TABLE public.data_deletion_requests IS 'Requests to anonymize a user''s personal data, approved by an admin and applied after a grace period';
+COMMENT ON COLUMN public.data_deletion_requests.scheduled_for IS 'End of the grace period; the user can still cancel until then';
+
+-- One open request per user
+CREATE UNIQUE INDEX IF NOT EXISTS idx_data_deletion_requests_open_user
+    ON public.data_deletion_requests(user_id) WHERE status IN ('pending', 'approved');
+
+CREATE INDEX IF NOT EXISTS idx_data_deletion_requests_due
+    ON public.data_deletion_requests(scheduled_for) WHERE status = 'approved';
+
+-- =====================================================
+-- ANONYMIZATION MARKERS
+-- =====================================================
+ALTER TABLE public.user_profiles
+    ADD COLUMN IF NOT EXISTS anonymized_at TIMESTAMP WITH TIME ZONE;
+
+ALTER TABLE public.players
+    ADD COLUMN IF NOT EXISTS anonymized_at TIMESTAMP WITH TIME ZONE;
+
+COMMENT ON COLUMN public.user_profiles.anonymized_at IS 'When personal fields were anonymized after an approved deletion request';
+COMMENT ON COLUMN public.players.anonymized_at IS 'When personal fields were anonymized; statistics stay linked to the player';
@@ -0,0 +1,25 @@
+-- =====================================================
+-- MOWE SPORT PLATFORM - FIELD ENCRYPTION ROLLBACK
+-- =====================================================
+-- Migration: 023_add_field_encryption (DOWN)
+-- Description: Rollback field encryption. Run
+--              `go run ./cmd/field-encryption decrypt` first; the type
+--              changes fail while encrypted values remain.
+-- =====================================================
+
+ALTER TABLE public.user_profiles
+    ALTER COLUMN two_factor_secret TYPE VARCHAR(255);
+
+DROP INDEX IF EXISTS public.idx_players_identification_bidx;
+
+ALTER TABLE public.players
+    DROP COLUMN IF EXISTS identification_bidx,
+    ALTER COLUMN medical_info TYPE JSONB USING medical_info::jsonb,
+    ALTER COLUMN emergency_contact TYPE JSONB USING emergency_contact::jsonb,
+    ALTER COLUMN blood_type TYPE VARCHAR(5),
+    ALTER COLUMN identification TYPE VARCHAR(50),
+    ADD CONSTRAINT players_identification_key UNIQUE (identification);
+
+CREATE INDEX IF NOT EXISTS idx_players_identification ON public.players(identification);
+
+DROP TABLE IF EXISTS public.encryption_keys;
@@ -0,0 +1,64 @@
+-- =====================================================
+-- MOWE SPORT PLATFORM - FIELD ENCRYPTION
+-- =====================================================
+-- Migration: 023_add_field_encryption
+-- Description: Envelope encryption of sensitive player fields and 2FA
+--              secrets. Data keys are stored wrapped by a master key kept
+--              outside the database; identification lookups and uniqueness
+--              use a blind index. Existing plaintext values are encrypted
+--              by `go run ./cmd/field-encryption reencrypt`.
+-- =====================================================
+
+-- =====================================================
+-- ENCRYPTION KEYS TABLE
+-- =====================================================
+CREATE TABLE IF NOT EXISTS public.encryption_keys (
+    key_id SERIAL PRIMARY KEY,
+    purpose VARCHAR(20) NOT NULL CHECK (purpose IN ('data', 'blind_index')),
+    wrapped_key TEXT NOT NULL,
+    master_key_id VARCHAR(16) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'retired')),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    retired_at TIMESTAMP WITH TIME ZONE,
+    rewrapped_at TIMESTAMP WITH TIME ZONE
+);
+
+COMMENT ON TABLE public.encryption_keys IS 'Data keys for field encryption, each wrapped (AES-256-GCM) by the master key from the configuration';
+COMMENT ON COLUMN public.encryption_keys.master_key_id IS 'Fingerprint of the master key that wrapped this key';
+COMMENT ON COLUMN public.encryption_keys.status IS 'Retired data keys only decrypt values written before the last rotation';
+
+-- One active key per purpose
+CREATE UNIQUE INDEX IF NOT EXISTS idx_encryption_keys_active
+    ON public.encryption_keys(purpose) WHERE status = 'active';
+
+-- =====================================================
+-- ENCRYPTED PLAYER FIELDS
+-- =====================================================
+-- Ciphertexts ("enc:v1:<key_id>:<base64>") do not fit the original types
+ALTER TABLE public.players
+    ALTER COLUMN identification TYPE TEXT,
+    ALTER COLUMN blood_type TYPE TEXT,
+    ALTER COLUMN emergency_contact TYPE TEXT USING emergency_contact::text,
+    ALTER COLUMN medical_info TYPE TEXT USING medical_info::text,
+    ADD COLUMN IF NOT EXISTS identification_bidx VARCHAR(64);
+
+COMMENT ON COLUMN public.players.identification IS 'Encrypted; look up and deduplicate through identification_bidx';
+COMMENT ON COLUMN public.players.identification_bidx IS 'HMAC-SHA256 blind index of the normalized identification';
+COMMENT ON COLUMN public.players.blood_type IS 'Encrypted';
+COMMENT ON COLUMN public.players.emergency_contact IS 'Encrypted JSON';
+COMMENT ON COLUMN public.players.medical_info IS 'Encrypted JSON';
+
+-- Uniqueness moves from the plaintext to the blind index
+ALTER TABLE public.players DROP CONSTRAINT IF EXISTS players_identification_key;
+DROP INDEX IF EXISTS public.idx_players_identification;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_players_identification_bidx
+    ON public.players(identification_bidx) WHERE identification_bidx IS NOT NULL;
+
+-- =====================================================
+-- ENCRYPTED 2FA SECRETS
+-- =====================================================
+ALTER TABLE public.user_profiles
+    ALTER COLUMN two_factor_secret TYPE TEXT;
+
+COMMENT ON COLUMN public.user_profiles.two_factor_secret IS 'Encrypted TOTP secret';
@@ -0,0 +1,14 @@
+-- =====================================================
+-- MOWE SPORT PLATFORM - GUARDIANS OF MINOR PLAYERS ROLLBACK
+-- =====================================================
+-- Migration: 024_create_player_guardians (DOWN)
+-- Description: Rollback guardian links, consents and match notifications.
+--              tournament_team_players is kept, since it may predate this
+--              migration; only the consent check is removed.
+-- =====================================================
+
+DROP TABLE IF EXISTS public.guardian_match_notifications;
+DROP TRIGGER IF EXISTS trg_tournament_team_players_minor_consent ON public.tournament_team_players;
+DROP FUNCTION IF EXISTS public.check_minor_roster_consent();
+DROP TABLE IF EXISTS public.guardian_consents;
+DROP TABLE IF EXISTS public.player_guardians;
@@ -0,0 +1,143 @@
+-- =====================================================
+-- MOWE SPORT PLATFORM - GUARDIANS OF MINOR PLAYERS
+-- =====================================================
+-- Migration: 024_create_player_guardians
+-- Description: Guardian (parent) accounts linked to minor players, their
+--              consent records, and the match notifications sent to them.
+--              Minors cannot be added to a tournament roster without the
+--              consent of a linked guardian.
+-- =====================================================
+
+-- =====================================================
+-- PLAYER GUARDIANS TABLE
+-- =====================================================
+CREATE TABLE IF NOT EXISTS public.player_guardians (
+    guardian_link_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    player_id UUID NOT NULL REFERENCES public.players(player_id) ON DELETE CASCADE,
+    guardian_user_id UUID NOT NULL REFERENCES public.user_profiles(user_id) ON DELETE CASCADE,
+    relationship VARCHAR(20) NOT NULL CHECK (
+        relationship IN ('mother', 'father', 'legal_guardian', 'other')
+    ),
+    notify_matches BOOLEAN NOT NULL DEFAULT TRUE,
+    linked_by_user_id UUID REFERENCES public.user_profiles(user_id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    revoked_by_user_id UUID REFERENCES public.user_profiles(user_id) ON DELETE SET NULL
+);
+
+COMMENT ON TABLE public.player_guardians IS 'Guardian accounts acting on behalf of minor players';
+COMMENT ON COLUMN public.player_guardians.notify_matches IS 'Whether the guardian receives match reminders and schedule changes';
+
+-- One active link per player and guardian
+CREATE UNIQUE INDEX IF NOT EXISTS idx_player_guardians_active
+    ON public.player_guardians(player_id, guardian_user_id) WHERE revoked_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_player_guardians_guardian
+    ON public.player_guardians(guardian_user_id) WHERE revoked_at IS NULL;
+
+-- =====================================================
+-- GUARDIAN CONSENTS TABLE
+-- =====================================================
+CREATE TABLE IF NOT EXISTS public.guardian_consents (
+    consent_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    player_id UUID NOT NULL REFERENCES public.players(player_id) ON DELETE CASCADE,
+    guardian_user_id UUID NOT NULL REFERENCES public.user_profiles(user_id) ON DELETE CASCADE,
+    document_version VARCHAR(20) NOT NULL,
+    consented_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    ip_address INET,
+    user_agent TEXT,
+    revoked_at TIMESTAMP WITH TIME ZONE
+);
+
+COMMENT ON TABLE public.guardian_consents IS 'Consent of a guardian for a minor to take part in tournaments';
+COMMENT ON COLUMN public.guardian_consents.document_version IS 'Version of the consent document the guardian accepted';
+
+CREATE INDEX IF NOT EXISTS idx_guardian_consents_player
+    ON public.guardian_consents(player_id, guardian_user_id) WHERE revoked_at IS NULL;
+
+-- =====================================================
+-- TOURNAMENT TEAM PLAYERS TABLE
+-- =====================================================
+-- Defined in database/01_schema but missing from the migrations; created here
+-- so the consent check below has a table to guard
+CREATE TABLE IF NOT EXISTS public.tournament_team_players (
+    tournament_team_player_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tournament_team_id UUID NOT NULL REFERENCES public.tournament_teams(tournament_team_id) ON DELETE CASCADE,
+    player_id UUID NOT NULL REFERENCES public.players(player_id) ON DELETE CASCADE,
+    jersey_number INTEGER NOT NULL,
+    position VARCHAR(50),
+    is_captain BOOLEAN NOT NULL DEFAULT FALSE,
+    is_vice_captain BOOLEAN NOT NULL DEFAULT FALSE,
+    registration_date TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    is_eligible BOOLEAN NOT NULL DEFAULT TRUE,
+    eligibility_notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    UNIQUE(tournament_team_id, jersey_number),
+    UNIQUE(tournament_team_id, player_id),
+    CHECK (jersey_number >= 1 AND jersey_number <= 99),
+    CHECK (NOT (is_captain = TRUE AND is_vice_captain = TRUE))
+);
+
+CREATE INDEX IF NOT EXISTS idx_tournament_team_players_team ON public.tournament_team_players(tournament_team_id);
+CREATE INDEX IF NOT EXISTS idx_tournament_team_players_player ON public.tournament_team_players(player_id);
+
+-- =====================================================
+-- CONSENT CHECK FOR MINORS ON TOURNAMENT ROSTERS
+-- =====================================================
+-- A player under 18 on the tournament start date (or today, for tournaments
+-- already under way) needs an unrevoked consent from a guardian still linked to them
+CREATE OR REPLACE FUNCTION public.check_minor_roster_consent()
+RETURNS TRIGGER AS $$
+DECLARE
+    v_birth_date DATE;
+    v_reference_date DATE;
+BEGIN
+    SELECT p.date_of_birth, GREATEST(t.start_date, CURRENT_DATE)
+    INTO v_birth_date, v_reference_date
+    FROM public.players p
+    JOIN public.tournament_teams tt ON tt.tournament_team_id = NEW.tournament_team_id
+    JOIN public.tournaments t ON t.tournament_id = tt.tournament_id
+    WHERE p.player_id = NEW.player_id;
+
+    IF v_birth_date IS NULL OR v_birth_date <= v_reference_date - INTERVAL '18 years' THEN
+        RETURN NEW;
+    END IF;
+
+    IF NOT EXISTS (
+        SELECT 1 FROM public.guardian_consents gc
+        JOIN public.player_guardians pg
+            ON pg.player_id = gc.player_id AND pg.guardian_user_id = gc.guardian_user_id AND pg.revoked_at IS NULL
+        WHERE gc.player_id = NEW.player_id AND gc.revoked_at IS NULL
+    ) THEN
+        RAISE EXCEPTION 'guardian consent required for minor player %', NEW.player_id
+            USING ERRCODE = 'check_violation';
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_tournament_team_players_minor_consent ON public.tournament_team_players;
+CREATE TRIGGER trg_tournament_team_players_minor_consent
+    BEFORE INSERT OR UPDATE OF player_id, tournament_team_id ON public.tournament_team_players
+    FOR EACH ROW EXECUTE FUNCTION public.check_minor_roster_consent();
+
+-- =====================================================
+-- GUARDIAN MATCH NOTIFICATIONS TABLE
+-- =====================================================
+-- The schedule last sent to a guardian, so reminders go out once and changes
+-- (new date or time, cancellation, postponement) are sent again
+CREATE TABLE IF NOT EXISTS public.guardian_match_notifications (
+    match_id UUID NOT NULL REFERENCES public.matches(match_id) ON DELETE CASCADE,
+    guardian_user_id UUID NOT NULL REFERENCES public.user_profiles(user_id) ON DELETE CASCADE,
+    player_id UUID NOT NULL REFERENCES public.players(player_id) ON DELETE CASCADE,
+    match_date DATE NOT NULL,
+    match_time TIME NOT NULL,
+    match_status VARCHAR(20) NOT NULL,
+    notified_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (match_id, guardian_user_id, player_id)
+);
+
+COMMENT ON TABLE public.guardian_match_notifications IS 'Match schedule last emailed to each guardian of a rostered minor';
@@ -0,0 +1,17 @@
+-- =====================================================
+-- MOWE SPORT PLATFORM - IDENTIFICATION DOCUMENT TYPES ROLLBACK
+-- =====================================================
+-- Migration: 025_add_identification_types (DOWN)
+-- Description: Rollback identification document types
+-- =====================================================
+
+DROP INDEX IF EXISTS public.idx_players_identification_untyped;
+DROP INDEX IF EXISTS public.idx_user_profiles_identification_untyped;
+
+ALTER TABLE public.players
+    DROP CONSTRAINT IF EXISTS players_identification_type_check,
+    DROP COLUMN IF EXISTS identification_type;
+
+ALTER TABLE public.user_profiles
+    DROP CONSTRAINT IF EXISTS user_profiles_identification_type_check,
+    DROP COLUMN IF EXISTS identification_type;
@@ -0,0 +1,40 @@
+-- =====================================================
+-- MOWE SPORT PLATFORM - IDENTIFICATION DOCUMENT TYPES
+-- =====================================================
+-- Migration: 025_add_identification_types
+-- Description: Document type of user and player identifications (CC, TI,
+--              RC, CE, PEP, PPT, passport and, for users only, NIT). Rows
+--              stored before this migration keep a NULL type until
+--              `go run ./cmd/identification-types backfill` infers it;
+--              the rest are listed there for manual review.
+-- =====================================================
+
+ALTER TABLE public.user_profiles
+    ADD COLUMN IF NOT EXISTS identification_type VARCHAR(5);
+
+ALTER TABLE public.user_profiles
+    DROP CONSTRAINT IF EXISTS user_profiles_identification_type_check,
+    ADD CONSTRAINT user_profiles_identification_type_check CHECK (
+        identification_type IN ('CC', 'TI', 'RC', 'CE', 'PEP', 'PPT', 'PA', 'NIT')
+    );
+
+COMMENT ON COLUMN public.user_profiles.identification_type IS 'Document type of identification; NULL for identifications stored before it was recorded';
+
+-- Players are people, so they are never identified by a NIT
+ALTER TABLE public.players
+    ADD COLUMN IF NOT EXISTS identification_type VARCHAR(5);
+
+ALTER TABLE public.players
+    DROP CONSTRAINT IF EXISTS players_identification_type_check,
+    ADD CONSTRAINT players_identification_type_check CHECK (
+        identification_type IN ('CC', 'TI', 'RC', 'CE', 'PEP', 'PPT', 'PA')
+    );
+
+COMMENT ON COLUMN public.players.identification_type IS 'Document type of identification; NULL for identifications stored before it was recorded';
+
+-- Rows still waiting for a type
+CREATE INDEX IF NOT EXISTS idx_user_profiles_identification_untyped
+    ON public.user_profiles(user_id) WHERE identification IS NOT NULL AND identification_type IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_players_identification_untyped
+    ON public.players(player_id) WHERE identification IS NOT NULL AND identification_type IS NULL;
@@ -0,0 +1,11 @@
+-- =====================================================
+-- MOWE SPORT PLATFORM - USER LIST SEARCH ROLLBACK
+-- =====================================================
+-- Migration: 026_add_user_list_search (DOWN)
+-- Description: Rollback user list search indexes. The unaccent and pg_trgm
+--              extensions are kept, as other objects may use them.
+-- =====================================================
+
+DROP INDEX IF EXISTS public.idx_user_profiles_created_keyset;
+DROP INDEX IF EXISTS public.idx_user_profiles_search_trgm;
+DROP FUNCTION IF EXISTS public.f_unaccent(text);
@@ -0,0 +1,28 @@
+-- =====================================================
+-- MOWE SPORT PLATFORM - USER LIST SEARCH
+-- =====================================================
+-- Migration: 026_add_user_list_search
+-- Description: Accent-insensitive trigram search and keyset pagination
+--              indexes for the user and admin lists.
+-- =====================================================
+
+CREATE EXTENSION IF NOT EXISTS unaccent;
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- unaccent() is only STABLE, since its dictionary can change; pinning the
+-- dictionary makes it usable in index expressions
+CREATE OR REPLACE FUNCTION public.f_unaccent(text)
+RETURNS text AS $$
+    SELECT public.unaccent('public.unaccent'::regdictionary, $1)
+$$ LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT;
+
+COMMENT ON FUNCTION public.f_unaccent(text) IS 'unaccent() with a fixed dictionary, for index expressions';
+
+-- Must match userSearchDocument in services/list_query.go
+CREATE INDEX IF NOT EXISTS idx_user_profiles_search_trgm
+    ON public.user_profiles
+    USING gin (public.f_unaccent(lower(first_name || ' ' || last_name || ' ' || email)) gin_trgm_ops);
+
+-- Default list order (newest first), with the user ID as tie breaker
+CREATE INDEX IF NOT EXISTS idx_user_profiles_created_keyset
+    ON public.user_profiles(created_at, user_id);
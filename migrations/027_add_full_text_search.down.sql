@@ -0,0 +1,18 @@
+-- =====================================================
+-- MOWE SPORT PLATFORM - FULL-TEXT SEARCH ROLLBACK
+-- =====================================================
+-- Migration: 027_add_full_text_search (DOWN)
+-- Description: Rollback full-text search columns and configuration
+-- =====================================================
+
+DROP INDEX IF EXISTS public.idx_user_profiles_search;
+DROP INDEX IF EXISTS public.idx_tournaments_search;
+DROP INDEX IF EXISTS public.idx_players_search;
+DROP INDEX IF EXISTS public.idx_teams_search;
+
+ALTER TABLE public.user_profiles DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE public.tournaments DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE public.players DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE public.teams DROP COLUMN IF EXISTS search_vector;
+
+DROP TEXT SEARCH CONFIGURATION IF EXISTS public.spanish_unaccent;
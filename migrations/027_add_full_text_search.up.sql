@@ -0,0 +1,62 @@
+-- =====================================================
+-- MOWE SPORT PLATFORM - FULL-TEXT SEARCH
+-- =====================================================
+-- Migration: 027_add_full_text_search
+-- Description: Spanish, accent-insensitive tsvector columns on teams,
+--              players, tournaments and users for GET /api/search.
+--              Requires the unaccent extension (026).
+-- =====================================================
+
+-- Spanish stemming after removing accents, so "gomez" matches "Gómez"
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_ts_config c
+        JOIN pg_namespace n ON n.oid = c.cfgnamespace
+        WHERE n.nspname = 'public' AND c.cfgname = 'spanish_unaccent'
+    ) THEN
+        CREATE TEXT SEARCH CONFIGURATION public.spanish_unaccent (COPY = pg_catalog.spanish);
+        ALTER TEXT SEARCH CONFIGURATION public.spanish_unaccent
+            ALTER MAPPING FOR hword, hword_part, word WITH public.unaccent, spanish_stem;
+    END IF;
+END
+$$;
+
+COMMENT ON TEXT SEARCH CONFIGURATION public.spanish_unaccent IS 'Spanish configuration that ignores accents, used by the search_vector columns';
+
+-- Weights: A names, B email, C venues and positions, D descriptions
+ALTER TABLE public.teams
+    ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('public.spanish_unaccent', coalesce(name, '') || ' ' || coalesce(short_name, '')), 'A') ||
+        setweight(to_tsvector('public.spanish_unaccent', coalesce(home_venue, '')), 'C') ||
+        setweight(to_tsvector('public.spanish_unaccent', coalesce(description, '')), 'D')
+    ) STORED;
+
+ALTER TABLE public.players
+    ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('public.spanish_unaccent', first_name || ' ' || last_name), 'A') ||
+        setweight(to_tsvector('public.spanish_unaccent', coalesce(preferred_position, '')), 'C')
+    ) STORED;
+
+ALTER TABLE public.tournaments
+    ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('public.spanish_unaccent', name), 'A') ||
+        setweight(to_tsvector('public.spanish_unaccent', coalesce(location, '')), 'C') ||
+        setweight(to_tsvector('public.spanish_unaccent', coalesce(description, '')), 'D')
+    ) STORED;
+
+ALTER TABLE public.user_profiles
+    ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('public.spanish_unaccent', first_name || ' ' || last_name), 'A') ||
+        setweight(to_tsvector('public.spanish_unaccent', email), 'B')
+    ) STORED;
+
+COMMENT ON COLUMN public.teams.search_vector IS 'Full-text search document (name, venue, description)';
+COMMENT ON COLUMN public.players.search_vector IS 'Full-text search document (names, position)';
+COMMENT ON COLUMN public.tournaments.search_vector IS 'Full-text search document (name, location, description)';
+COMMENT ON COLUMN public.user_profiles.search_vector IS 'Full-text search document (names, email)';
+
+CREATE INDEX IF NOT EXISTS idx_teams_search ON public.teams USING gin (search_vector);
+CREATE INDEX IF NOT EXISTS idx_players_search ON public.players USING gin (search_vector);
+CREATE INDEX IF NOT EXISTS idx_tournaments_search ON public.tournaments USING gin (search_vector);
+CREATE INDEX IF NOT EXISTS idx_user_profiles_search ON public.user_profiles USING gin (search_vector);
@@ -161,22 +161,22 @@ class AdminService extends BaseApiService {
   }
 
   /**
-   * Regenerate temporary password for administrator
+   * Resend the invitation link of an administrator who has not accepted it yet
    */
-  async regeneratePassword(adminId: string): Promise<{ temporary_password: string; message: string }> {
+  async resendInvitation(invitationId: string): Promise<{ message: string }> {
     try {
-      if (!adminId) {
-        throw new Error('ID de administrador requerido');
+      if (!invitationId) {
+        throw new Error('ID de invitación requerido');
       }
 
-      const response = await this.post<{ temporary_password: string; message: string }>(
-        `/admin/${adminId}/regenerate-password`
+      const response = await this.post<{ message: string }>(
+        `/admin/invitations/${invitationId}/resend`
       );
       
-      console.info('Password regenerated for admin:', adminId);
+      console.info('Invitation resent:', invitationId);
       return response;
     } catch (error) {
-      console.error('Error in regeneratePassword:', error);
+      console.error('Error in resendInvitation:', error);
       throw error;
     }
   }